@@ -61,7 +61,7 @@ var logger = logging.MustGetLogger("aeron")
 func Connect(ctx *Context) (*Aeron, error) {
 	aeron := new(Aeron)
 	aeron.context = ctx
-	logger.Debugf("Connecting with context: %v", ctx)
+	ctx.logger.Debugf("Connecting with context: %v", ctx)
 
 	ctr, cnc, err := counters.MapFile(ctx.CncFileName())
 	if err != nil {
@@ -83,6 +83,7 @@ func Connect(ctx *Context) (*Aeron, error) {
 
 	clientLivenessTo := time.Duration(aeron.counters.ClientLivenessTo.Get())
 
+	aeron.conductor.logger = ctx.logger
 	aeron.conductor.Init(&aeron.driverProxy, aeron.toClientsCopyReceiver, clientLivenessTo, ctx.mediaDriverTo,
 		ctx.publicationConnectionTo, ctx.resourceLingerTo, aeron.counters)
 
@@ -0,0 +1,31 @@
+/*
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package atomic
+
+// BufferVector is a range of a Buffer, one part of a message gathered from several buffers
+type BufferVector struct {
+	Buffer *Buffer
+	Offset int32
+	Length int32
+}
+
+// VectorsLength is the total length of the vectors
+func VectorsLength(vectors []BufferVector) int32 {
+	var length int32
+	for i := range vectors {
+		length += vectors[i].Length
+	}
+	return length
+}
@@ -87,6 +87,13 @@ const (
 	parseStateParamsValue
 )
 
+// ValidateChannelUri returns the error from parsing an Aeron URI, if any, for
+// use with properties.Properties.ChannelVar
+func ValidateChannelUri(uriStr string) error {
+	_, err := ParseChannelUri(uriStr)
+	return err
+}
+
 // ParseChannelUri parses a string which contains an Aeron URI.
 func ParseChannelUri(uriStr string) (uri ChannelUri, err error) {
 	uri.params = make(map[string]string)
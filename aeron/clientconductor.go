@@ -50,10 +50,10 @@ const (
 	resourceTimeoutNS  = 1000 * int64(time.Millisecond)
 
 	// heartbeatTypeId is the type id of a heartbeat counter.
-	heartbeatTypeId = int32(11)
+	heartbeatTypeId = ctr.ClientHeartbeatTypeId
 
 	// registrationIdOffset is the offset in the key metadata for the registration id of the counter.
-	heartheatRegistrationIdOffset = int32(0)
+	heartheatRegistrationIdOffset = ctr.ClientHeartbeatClientIdOffset
 )
 
 type publicationStateDefn struct {
@@ -145,13 +145,18 @@ type ClientConductor struct {
 	resourceLingerTimeoutNs         int64
 
 	heartbeatTimestamp *ctr.AtomicCounter
+
+	logger *logging.ZapLogger // The Context's logger, or the package logger if not set
 }
 
 // Init is the primary initialization method for ClientConductor
 func (cc *ClientConductor) Init(driverProxy *driver.Proxy, bcast *broadcast.CopyReceiver,
 	interServiceTo, driverTo, pubConnectionTo, lingerTo time.Duration, counters *ctr.MetaDataFlyweight) *ClientConductor {
 
-	logger.Debugf("Initializing ClientConductor with: %v %v %d %d %d", driverProxy, bcast, interServiceTo,
+	if cc.logger == nil {
+		cc.logger = logger
+	}
+	cc.logger.Debugf("Initializing ClientConductor with: %v %v %d %d %d", driverProxy, bcast, interServiceTo,
 		driverTo, pubConnectionTo)
 
 	cc.driverProxy = driverProxy
@@ -178,7 +183,7 @@ func (cc *ClientConductor) Init(driverProxy *driver.Proxy, bcast *broadcast.Copy
 // Close will terminate the Run() goroutine body and close all active publications and subscription. Run() can
 // be restarted in a another goroutine.
 func (cc *ClientConductor) Close() (err error) {
-	logger.Debugf("Closing ClientConductor")
+	cc.logger.Debugf("Closing ClientConductor")
 
 	now := time.Now().UnixNano()
 
@@ -196,11 +201,11 @@ func (cc *ClientConductor) Close() (err error) {
 	}
 	if cc.conductorRunning.Get() {
 		msg := fmt.Sprintf("failed to stop conductor after %v", timeoutDuration)
-		logger.Warning(msg)
+		cc.logger.Warning(msg)
 		err = errors.New(msg)
 	}
 
-	logger.Debugf("Closed ClientConductor")
+	cc.logger.Debugf("Closed ClientConductor")
 	return err
 }
 
@@ -224,14 +229,14 @@ func (cc *ClientConductor) run(idleStrategy idlestrategy.Idler) {
 	defer func() {
 		if err := recover(); err != nil {
 			errStr := fmt.Sprintf("Panic: %v", err)
-			logger.Error(errStr)
+			cc.logger.Error(errStr)
 			cc.onError(errors.New(errStr))
 			cc.running.Set(false)
 		}
 		cc.forceCloseResources()
 		cc.conductorRunning.Set(false)
 
-		logger.Infof("ClientConductor done")
+		cc.logger.Infof("ClientConductor done")
 	}()
 
 	cc.conductorRunning.Set(true)
@@ -249,12 +254,12 @@ func (cc *ClientConductor) forceCloseResources() {
 	for {
 		select {
 		case r := <-cc.lingeringResources:
-			logger.Debugf("Force closing resource: %v", r)
+			cc.logger.Debugf("Force closing resource: %v", r)
 			res := r.resource
 			if res != nil {
 				err := res.Close()
 				if err != nil {
-					logger.Warningf("Failed to force close resource: %v", err)
+					cc.logger.Warningf("Failed to force close resource: %v", err)
 					cc.onError(err)
 				}
 			}
@@ -280,7 +285,7 @@ func (cc *ClientConductor) getDriverStatus() error {
 
 // AddPublication sends the add publication command through the driver proxy
 func (cc *ClientConductor) AddPublication(channel string, streamID int32) (int64, error) {
-	logger.Debugf("AddPublication: channel=%s, streamId=%d", channel, streamID)
+	cc.logger.Debugf("AddPublication: channel=%s, streamId=%d", channel, streamID)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return 0, err
@@ -306,7 +311,7 @@ func (cc *ClientConductor) AddPublication(channel string, streamID int32) (int64
 
 // AddExclusivePublication sends the add publication command through the driver proxy
 func (cc *ClientConductor) AddExclusivePublication(channel string, streamID int32) (int64, error) {
-	logger.Debugf("AddExclusivePublication: channel=%s, streamId=%d", channel, streamID)
+	cc.logger.Debugf("AddExclusivePublication: channel=%s, streamId=%d", channel, streamID)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return 0, err
@@ -368,7 +373,7 @@ func (cc *ClientConductor) FindPublication(registrationID int64) (*Publication,
 }
 
 func (cc *ClientConductor) releasePublication(regID int64) error {
-	logger.Debugf("ReleasePublication: regID=%d", regID)
+	cc.logger.Debugf("ReleasePublication: regID=%d", regID)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return err
@@ -401,7 +406,7 @@ func (cc *ClientConductor) releasePublication(regID int64) error {
 
 // AddSubscription sends the add subscription command through the driver proxy
 func (cc *ClientConductor) AddSubscription(channel string, streamID int32) (int64, error) {
-	logger.Debugf("AddSubscription: channel=%s, streamId=%d", channel, streamID)
+	cc.logger.Debugf("AddSubscription: channel=%s, streamId=%d", channel, streamID)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return 0, err
@@ -461,7 +466,7 @@ func timeoutExceeded(timeOfRegistration int64, driverTimeoutNs int64) error {
 }
 
 func (cc *ClientConductor) releaseSubscription(regID int64, images []Image) error {
-	logger.Debugf("ReleaseSubscription: regID=%d", regID)
+	cc.logger.Debugf("ReleaseSubscription: regID=%d", regID)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return err
@@ -475,8 +480,8 @@ func (cc *ClientConductor) releaseSubscription(regID int64, images []Image) erro
 	subcnt := len(cc.subs)
 	for i, sub := range cc.subs {
 		if sub != nil && sub.regID == regID {
-			if logger.IsEnabledFor(logging.DEBUG) {
-				logger.Debugf("Removing subscription: %d; %v", regID, images)
+			if cc.logger.IsEnabledFor(logging.DEBUG) {
+				cc.logger.Debugf("Removing subscription: %d; %v", regID, images)
 			}
 
 			if err := cc.driverProxy.RemoveSubscription(regID); err != nil {
@@ -502,7 +507,7 @@ func (cc *ClientConductor) releaseSubscription(regID int64, images []Image) erro
 
 // AddDestination sends the add destination command through the driver proxy
 func (cc *ClientConductor) AddDestination(registrationID int64, endpointChannel string) error {
-	logger.Debugf("AddDestination: regID=%d endpointChannel=%s", registrationID, endpointChannel)
+	cc.logger.Debugf("AddDestination: regID=%d endpointChannel=%s", registrationID, endpointChannel)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return err
@@ -517,7 +522,7 @@ func (cc *ClientConductor) AddDestination(registrationID int64, endpointChannel
 
 // RemoveDestination sends the remove destination command through the driver proxy
 func (cc *ClientConductor) RemoveDestination(registrationID int64, endpointChannel string) error {
-	logger.Debugf("RemoveDestination: regID=%d endpointChannel=%s", registrationID, endpointChannel)
+	cc.logger.Debugf("RemoveDestination: regID=%d endpointChannel=%s", registrationID, endpointChannel)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return err
@@ -532,7 +537,7 @@ func (cc *ClientConductor) RemoveDestination(registrationID int64, endpointChann
 
 // AddRcvDestination sends the add rcv destination command through the driver proxy
 func (cc *ClientConductor) AddRcvDestination(registrationID int64, endpointChannel string) error {
-	logger.Debugf("AddRcvDestination: regID=%d endpointChannel=%s", registrationID, endpointChannel)
+	cc.logger.Debugf("AddRcvDestination: regID=%d endpointChannel=%s", registrationID, endpointChannel)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return err
@@ -547,7 +552,7 @@ func (cc *ClientConductor) AddRcvDestination(registrationID int64, endpointChann
 
 // RemoveRcvDestination sends the remove rcv destination command through the driver proxy
 func (cc *ClientConductor) RemoveRcvDestination(registrationID int64, endpointChannel string) error {
-	logger.Debugf("RemoveRcvDestination: regID=%d endpointChannel=%s", registrationID, endpointChannel)
+	cc.logger.Debugf("RemoveRcvDestination: regID=%d endpointChannel=%s", registrationID, endpointChannel)
 
 	if err := cc.getDriverStatus(); err != nil {
 		return err
@@ -563,7 +568,7 @@ func (cc *ClientConductor) RemoveRcvDestination(registrationID int64, endpointCh
 func (cc *ClientConductor) OnNewPublication(streamID int32, sessionID int32, posLimitCounterID int32,
 	channelStatusIndicatorID int32, logFileName string, regID int64, origRegID int64) {
 
-	logger.Debugf("OnNewPublication: streamId=%d, sessionId=%d, posLimitCounterID=%d, channelStatusIndicatorID=%d, logFileName=%s, correlationID=%d, regID=%d",
+	cc.logger.Debugf("OnNewPublication: streamId=%d, sessionId=%d, posLimitCounterID=%d, channelStatusIndicatorID=%d, logFileName=%s, correlationID=%d, regID=%d",
 		streamID, sessionID, posLimitCounterID, channelStatusIndicatorID, logFileName, regID, origRegID)
 
 	cc.adminLock.Lock()
@@ -579,7 +584,7 @@ func (cc *ClientConductor) OnNewPublication(streamID int32, sessionID int32, pos
 			pubDef.buffers.IncRef()
 			pubDef.origRegID = origRegID
 
-			logger.Debugf("Updated publication: %v", pubDef)
+			cc.logger.Debugf("Updated publication: %v", pubDef)
 
 			if cc.onNewPublicationHandler != nil {
 				cc.onNewPublicationHandler(pubDef.channel, streamID, sessionID, regID)
@@ -592,7 +597,7 @@ func (cc *ClientConductor) OnNewPublication(streamID int32, sessionID int32, pos
 func (cc *ClientConductor) OnNewExclusivePublication(streamID int32, sessionID int32, posLimitCounterID int32,
 	channelStatusIndicatorID int32, logFileName string, regID int64, origRegID int64) {
 
-	logger.Debugf("OnNewExclusivePublication: streamId=%d, sessionId=%d, posLimitCounterID=%d, channelStatusIndicatorID=%d, logFileName=%s, correlationID=%d, regID=%d",
+	cc.logger.Debugf("OnNewExclusivePublication: streamId=%d, sessionId=%d, posLimitCounterID=%d, channelStatusIndicatorID=%d, logFileName=%s, correlationID=%d, regID=%d",
 		streamID, sessionID, posLimitCounterID, channelStatusIndicatorID, logFileName, regID, origRegID)
 
 	cc.adminLock.Lock()
@@ -608,7 +613,7 @@ func (cc *ClientConductor) OnNewExclusivePublication(streamID int32, sessionID i
 			pubDef.buffers.IncRef()
 			pubDef.origRegID = origRegID
 
-			logger.Debugf("Updated publication: %v", pubDef)
+			cc.logger.Debugf("Updated publication: %v", pubDef)
 
 			if cc.onNewPublicationHandler != nil {
 				cc.onNewPublicationHandler(pubDef.channel, streamID, sessionID, regID)
@@ -618,27 +623,27 @@ func (cc *ClientConductor) OnNewExclusivePublication(streamID int32, sessionID i
 }
 
 func (cc *ClientConductor) OnAvailableCounter(correlationID int64, counterID int32) {
-	logger.Debugf("OnAvailableCounter: correlationID=%d, counterID=%d",
+	cc.logger.Debugf("OnAvailableCounter: correlationID=%d, counterID=%d",
 		correlationID, counterID)
 
 	cc.adminLock.Lock()
 	defer cc.adminLock.Unlock()
 
-	logger.Debug("OnAvailableCounter: Not supported yet")
+	cc.logger.Debug("OnAvailableCounter: Not supported yet")
 }
 
 func (cc *ClientConductor) OnUnavailableCounter(correlationID int64, counterID int32) {
-	logger.Debugf("OnUnavailableCounter: correlationID=%d, counterID=%d",
+	cc.logger.Debugf("OnUnavailableCounter: correlationID=%d, counterID=%d",
 		correlationID, counterID)
 
 	cc.adminLock.Lock()
 	defer cc.adminLock.Unlock()
 
-	logger.Debug("OnUnavailableCounter: Not supported yet")
+	cc.logger.Debug("OnUnavailableCounter: Not supported yet")
 }
 
 func (cc *ClientConductor) OnClientTimeout(clientID int64) {
-	logger.Debugf("OnClientTimeout: clientID=%d", clientID)
+	cc.logger.Debugf("OnClientTimeout: clientID=%d", clientID)
 
 	cc.adminLock.Lock()
 	defer cc.adminLock.Unlock()
@@ -651,7 +656,7 @@ func (cc *ClientConductor) OnClientTimeout(clientID int64) {
 }
 
 func (cc *ClientConductor) OnSubscriptionReady(correlationID int64, channelStatusIndicatorID int32) {
-	logger.Debugf("OnSubscriptionReady: correlationID=%d, channelStatusIndicatorID=%d",
+	cc.logger.Debugf("OnSubscriptionReady: correlationID=%d, channelStatusIndicatorID=%d",
 		correlationID, channelStatusIndicatorID)
 
 	cc.adminLock.Lock()
@@ -674,7 +679,7 @@ func (cc *ClientConductor) OnSubscriptionReady(correlationID int64, channelStatu
 //go:norace
 func (cc *ClientConductor) OnAvailableImage(streamID int32, sessionID int32, logFilename string, sourceIdentity string,
 	subscriberPositionID int32, subsRegID int64, corrID int64) {
-	logger.Debugf("OnAvailableImage: streamId=%d, sessionId=%d, logFilename=%s, sourceIdentity=%s, subsRegID=%d, corrID=%d",
+	cc.logger.Debugf("OnAvailableImage: streamId=%d, sessionId=%d, logFilename=%s, sourceIdentity=%s, subsRegID=%d, corrID=%d",
 		streamID, sessionID, logFilename, sourceIdentity, subsRegID, corrID)
 
 	cc.adminLock.Lock()
@@ -684,14 +689,14 @@ func (cc *ClientConductor) OnAvailableImage(streamID int32, sessionID int32, log
 
 		// if sub.streamID == streamID && sub.subscription != nil {
 		if sub.subscription != nil {
-			// logger.Debugf("OnAvailableImage: sub.regID=%d subsRegID=%d corrID=%d %#v", sub.regID, subsRegID, corrID, sub)
+			// cc.logger.Debugf("OnAvailableImage: sub.regID=%d subsRegID=%d corrID=%d %#v", sub.regID, subsRegID, corrID, sub)
 			if sub.regID == subsRegID {
 
 				image := NewImage(sessionID, corrID, logbuffer.Wrap(logFilename))
 				image.subscriptionRegistrationID = sub.regID
 				image.sourceIdentity = sourceIdentity
 				image.subscriberPosition = NewPosition(cc.counterValuesBuffer, subscriberPositionID)
-				logger.Debugf("OnAvailableImage: new image position: %v -> %d",
+				cc.logger.Debugf("OnAvailableImage: new image position: %v -> %d",
 					image.subscriberPosition, image.subscriberPosition.get())
 
 				sub.subscription.addImage(image)
@@ -705,7 +710,7 @@ func (cc *ClientConductor) OnAvailableImage(streamID int32, sessionID int32, log
 }
 
 func (cc *ClientConductor) OnUnavailableImage(corrID int64, subscriptionRegistrationID int64) {
-	logger.Debugf("OnUnavailableImage: corrID=%d subscriptionRegistrationID=%d", corrID, subscriptionRegistrationID)
+	cc.logger.Debugf("OnUnavailableImage: corrID=%d subscriptionRegistrationID=%d", corrID, subscriptionRegistrationID)
 
 	cc.adminLock.Lock()
 	defer cc.adminLock.Unlock()
@@ -725,7 +730,7 @@ func (cc *ClientConductor) OnUnavailableImage(corrID int64, subscriptionRegistra
 }
 
 func (cc *ClientConductor) OnOperationSuccess(corrID int64) {
-	logger.Debugf("OnOperationSuccess: correlationId=%d", corrID)
+	cc.logger.Debugf("OnOperationSuccess: correlationId=%d", corrID)
 
 	cc.adminLock.Lock()
 	defer cc.adminLock.Unlock()
@@ -733,7 +738,7 @@ func (cc *ClientConductor) OnOperationSuccess(corrID int64) {
 }
 
 func (cc *ClientConductor) OnChannelEndpointError(corrID int64, errorMessage string) {
-	logger.Debugf("OnChannelEndpointError: correlationID=%d, errorMessage=%s", corrID, errorMessage)
+	cc.logger.Debugf("OnChannelEndpointError: correlationID=%d, errorMessage=%s", corrID, errorMessage)
 
 	cc.adminLock.Lock()
 	defer cc.adminLock.Unlock()
@@ -754,7 +759,7 @@ func (cc *ClientConductor) OnChannelEndpointError(corrID int64, errorMessage str
 }
 
 func (cc *ClientConductor) OnErrorResponse(corrID int64, errorCode int32, errorMessage string) {
-	logger.Debugf("OnErrorResponse: correlationID=%d, errorCode=%d, errorMessage=%s", corrID, errorCode, errorMessage)
+	cc.logger.Debugf("OnErrorResponse: correlationID=%d, errorCode=%d, errorMessage=%s", corrID, errorCode, errorMessage)
 
 	cc.adminLock.Lock()
 	defer cc.adminLock.Unlock()
@@ -820,7 +825,7 @@ func (cc *ClientConductor) onHeartbeatCheckTimeouts() (int, error) {
 			if counterId != ctr.NullCounterId {
 				var ctrErr error
 				if cc.heartbeatTimestamp, ctrErr = ctr.NewAtomicCounter(cc.counterReader, counterId); ctrErr != nil {
-					logger.Warning("unable to allocate heartbeat counter %d", counterId)
+					cc.logger.Warning("unable to allocate heartbeat counter %d", counterId)
 				} else {
 					cc.heartbeatTimestamp.Set(now / time.Millisecond.Nanoseconds())
 				}
@@ -845,15 +850,15 @@ func (cc *ClientConductor) onCheckManagedResources(now int64) {
 	for moreToCheck {
 		select {
 		case r := <-cc.lingeringResources:
-			logger.Debugf("Resource to linger: %v", r)
+			cc.logger.Debugf("Resource to linger: %v", r)
 			if cc.resourceLingerTimeoutNs < now-r.lastTime {
 				res := r.resource
-				logger.Debugf("lingering resource expired(%dms old): %v",
+				cc.logger.Debugf("lingering resource expired(%dms old): %v",
 					(now-r.lastTime)/time.Millisecond.Nanoseconds(), res)
 				if res != nil {
 					err := res.Close()
 					if err != nil {
-						logger.Warningf("Failed to close lingering resource: %v", err)
+						cc.logger.Warningf("Failed to close lingering resource: %v", err)
 						cc.onError(err)
 					}
 				}
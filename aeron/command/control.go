@@ -46,6 +46,8 @@ const (
 	AddRcvDestination = 0x0c
 	// RemoveRcvDestination removes a Destination for existing Subscription.
 	RemoveRcvDestination = 0x0D
+	// TerminateDriver requests the driver to terminate, with a token it validates.
+	TerminateDriver = 0x0E
 )
 
 const (
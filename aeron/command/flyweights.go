@@ -137,3 +137,23 @@ func (m *DestinationMessage) Wrap(buf *atomic.Buffer, offset int) flyweight.Flyw
 	m.SetSize(pos - offset)
 	return m
 }
+
+// TerminateDriverMessage requests termination of the driver with a token,
+// which the driver's termination validator checks.
+type TerminateDriverMessage struct {
+	flyweight.FWBase
+
+	ClientID      flyweight.Int64Field
+	CorrelationID flyweight.Int64Field
+	Token         flyweight.StringField
+}
+
+func (m *TerminateDriverMessage) Wrap(buf *atomic.Buffer, offset int) flyweight.Flyweight {
+	pos := offset
+	pos += m.ClientID.Wrap(buf, pos)
+	pos += m.CorrelationID.Wrap(buf, pos)
+	pos += m.Token.Wrap(buf, pos, m, true)
+
+	m.SetSize(pos - offset)
+	return m
+}
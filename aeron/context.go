@@ -17,10 +17,25 @@ limitations under the License.
 package aeron
 
 import (
+	"errors"
 	"time"
 
 	"github.com/lirm/aeron-go/aeron/counters"
 	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/logging"
+	"github.com/lirm/aeron-go/aeron/properties"
+)
+
+// Property names read by NewContextFromEnv() and ApplyProperties(), shared
+// with the Java client where it has them. Bare numbers are in the Java
+// property's unit.
+const (
+	AeronDirPropName                     = "aeron.dir"                             // AeronDir
+	DriverTimeoutPropName                = "aeron.driver.timeout"                  // MediaDriverTimeout, milliseconds
+	ResourceLingerDurationPropName       = "aeron.client.resource.linger.duration" // ResourceLingerTimeout, nanoseconds
+	PublicationConnectionTimeoutPropName = "aeron.publication.connection.timeout"  // PublicationConnectionTimeout, nanoseconds
+	ClientLivenessTimeoutPropName        = "aeron.client.liveness.timeout"         // InterServiceTimeout, nanoseconds
+	IdleStrategyPropName                 = "aeron.client.idle.strategy"            // IdleStrategy, see idlestrategy.Parse()
 )
 
 // Context configuration options are located here https://github.com/real-logic/Aeron/wiki/Configuration-Options#aeron-client-options
@@ -40,6 +55,8 @@ type Context struct {
 	interServiceTo          time.Duration
 
 	idleStrategy idlestrategy.Idler
+
+	logger *logging.ZapLogger
 }
 
 // NewContext creates and initializes new Context for Aeron
@@ -48,7 +65,8 @@ func NewContext() *Context {
 
 	ctx.aeronDir = DefaultAeronDir + "/aeron-" + UserName
 
-	ctx.errorHandler = func(err error) { logger.Error(err) }
+	ctx.logger = logger
+	ctx.errorHandler = func(err error) { ctx.logger.Error(err) }
 
 	ctx.newPublicationHandler = func(string, int32, int32, int64) {}
 	ctx.newSubscriptionHandler = func(string, int32, int64) {}
@@ -124,6 +142,13 @@ func (ctx *Context) UnavailableImageHandler(handler func(Image)) *Context {
 	return ctx
 }
 
+// Logger sets the backend for the client's logs, in place of the process wide
+// default. The logs keep the level of the "aeron" named logger.
+func (ctx *Context) Logger(l logging.Logger) *Context {
+	ctx.logger = logger.WithBackend(l)
+	return ctx
+}
+
 // CncFileName returns the name of the Counters file
 func (ctx *Context) CncFileName() string {
 	return ctx.aeronDir + "/" + counters.CncFile
@@ -135,3 +160,41 @@ func (ctx *Context) IdleStrategy(idleStrategy idlestrategy.Idler) *Context {
 	ctx.idleStrategy = idleStrategy
 	return ctx
 }
+
+// NewContextFromEnv creates a Context from the defaults overridden by the
+// AERON_* environment variables, e.g. AERON_DIR for aeron.dir. See package
+// properties for the naming and precedence.
+func NewContextFromEnv() (*Context, error) {
+	return NewContextFromProperties(properties.FromEnv())
+}
+
+// NewContextFromProperties creates a Context from the defaults overridden by
+// props, which may be merged from files and the environment with
+// properties.Load()
+func NewContextFromProperties(props properties.Properties) (*Context, error) {
+	ctx := NewContext()
+	if err := ctx.ApplyProperties(props); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// ApplyProperties overrides the settings with those set in props. Every value
+// is validated, and the error lists each invalid property, leaving the
+// Context unchanged for those.
+func (ctx *Context) ApplyProperties(props properties.Properties) error {
+	return errors.Join(
+		props.StringVar(&ctx.aeronDir, AeronDirPropName),
+		props.TimeoutVar(&ctx.mediaDriverTo, DriverTimeoutPropName, time.Millisecond),
+		props.DurationVar(&ctx.resourceLingerTo, ResourceLingerDurationPropName, time.Nanosecond),
+		props.TimeoutVar(&ctx.publicationConnectionTo, PublicationConnectionTimeoutPropName, time.Nanosecond),
+		props.TimeoutVar(&ctx.interServiceTo, ClientLivenessTimeoutPropName, time.Nanosecond),
+		props.Var(IdleStrategyPropName, func(value string) error {
+			idler, err := idlestrategy.Parse(value)
+			if err == nil {
+				ctx.idleStrategy = idler
+			}
+			return err
+		}),
+	)
+}
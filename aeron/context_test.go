@@ -0,0 +1,48 @@
+package aeron
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/properties"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewContextFromProperties(t *testing.T) {
+	ctx, err := NewContextFromProperties(properties.Properties{
+		AeronDirPropName:                     "/dev/shm/aeron-test",
+		DriverTimeoutPropName:                "2000",
+		ResourceLingerDurationPropName:       "100ms",
+		PublicationConnectionTimeoutPropName: "1000000000",
+		ClientLivenessTimeoutPropName:        "20s",
+		IdleStrategyPropName:                 "yield",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "/dev/shm/aeron-test", ctx.aeronDir)
+	assert.Equal(t, 2*time.Second, ctx.mediaDriverTo)
+	assert.Equal(t, 100*time.Millisecond, ctx.resourceLingerTo)
+	assert.Equal(t, time.Second, ctx.publicationConnectionTo)
+	assert.Equal(t, 20*time.Second, ctx.interServiceTo)
+	assert.Equal(t, idlestrategy.Yielding{}, ctx.idleStrategy)
+}
+
+func TestNewContextFromEnv(t *testing.T) {
+	t.Setenv("AERON_DIR", "/dev/shm/aeron-env")
+	t.Setenv("AERON_DRIVER_TIMEOUT", "")
+	ctx, err := NewContextFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "/dev/shm/aeron-env", ctx.aeronDir)
+	assert.Equal(t, NewContext().mediaDriverTo, ctx.mediaDriverTo)
+}
+
+func TestNewContextFromProperties_ReportsEachInvalidValue(t *testing.T) {
+	_, err := NewContextFromProperties(properties.Properties{
+		DriverTimeoutPropName: "0",
+		IdleStrategyPropName:  "spin",
+	})
+	require.Error(t, err)
+	assert.ErrorContains(t, err, "invalid aeron.driver.timeout (AERON_DRIVER_TIMEOUT)")
+	assert.ErrorContains(t, err, `invalid aeron.client.idle.strategy (AERON_CLIENT_IDLE_STRATEGY) "spin": unknown idle strategy`)
+}
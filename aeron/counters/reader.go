@@ -93,6 +93,14 @@ const RecordAllocated int32 = 1
 
 const NullCounterId = int32(-1)
 
+// ClientHeartbeatTypeId is the type id of the counter in which each client
+// heartbeats, keyed by the client id at ClientHeartbeatClientIdOffset
+const ClientHeartbeatTypeId = int32(11)
+
+// ClientHeartbeatClientIdOffset is the offset of the client id in the key of a
+// client heartbeat counter
+const ClientHeartbeatClientIdOffset = int32(0)
+
 type Reader struct {
 	metaData *atomic.Buffer
 	values   *atomic.Buffer
@@ -263,6 +271,14 @@ func (reader *Reader) GetCounterTypeId(counterId int32) int32 {
 	return reader.metaData.GetInt32(counterId*MetadataLength + TypeIdOffset)
 }
 
+// GetCounterLabel returns the label of a counter, or "" if it is not allocated.
+func (reader *Reader) GetCounterLabel(counterId int32) string {
+	if !reader.IsCounterAllocated(counterId) {
+		return ""
+	}
+	return reader.labelValue(counterId * MetadataLength)
+}
+
 func (reader *Reader) IsCounterAllocated(counterId int32) bool {
 	return counterId >= 0 && counterId < int32(reader.maxCounterID) &&
 		reader.metaData.GetInt32Volatile(counterId*MetadataLength) == RecordAllocated
@@ -0,0 +1,56 @@
+// Copyright 2022 Talos, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aeron
+
+// CredentialsSupplier is used by the archive and cluster clients to authenticate a connection.
+// EncodedCredentials is sent with the connect request, and should the server respond with a
+// challenge then OnChallenge is called to compute the response that is sent back.
+type CredentialsSupplier interface {
+	// EncodedCredentials to be sent with the connect request. May be nil.
+	EncodedCredentials() []byte
+
+	// OnChallenge is called with the encoded challenge from the server and returns the
+	// encoded credentials to be sent in the ChallengeResponse.
+	OnChallenge(encodedChallenge []byte) []byte
+}
+
+// NullCredentialsSupplier supplies no credentials and answers any challenge with no credentials
+type NullCredentialsSupplier struct{}
+
+// EncodedCredentials returns nil
+func (NullCredentialsSupplier) EncodedCredentials() []byte {
+	return nil
+}
+
+// OnChallenge returns nil
+func (NullCredentialsSupplier) OnChallenge(encodedChallenge []byte) []byte {
+	return nil
+}
+
+// StaticCredentialsSupplier supplies fixed credentials and answers any challenge with a fixed response
+type StaticCredentialsSupplier struct {
+	Credentials       []byte // Sent with the connect request
+	ChallengeResponse []byte // Sent in reply to any challenge
+}
+
+// EncodedCredentials returns the fixed credentials
+func (s *StaticCredentialsSupplier) EncodedCredentials() []byte {
+	return s.Credentials
+}
+
+// OnChallenge returns the fixed challenge response regardless of the challenge
+func (s *StaticCredentialsSupplier) OnChallenge(encodedChallenge []byte) []byte {
+	return s.ChallengeResponse
+}
@@ -19,12 +19,20 @@ package driver
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/lirm/aeron-go/aeron/atomic"
 	"github.com/lirm/aeron-go/aeron/command"
 	rb "github.com/lirm/aeron-go/aeron/ringbuffer"
 )
 
+// commandBufferLength is the space for encoding each command
+const commandBufferLength = 512
+
+// maxTerminateTokenLength fits the token in the command after the client and
+// correlation ids and its length
+const maxTerminateTokenLength = commandBufferLength - 20
+
 // Proxy is a media driver proxy class that is used to send commands
 type Proxy struct {
 	toDriverCommandBuffer *rb.ManyToOne
@@ -330,8 +338,39 @@ func (driver *Proxy) RemoveRcvDestination(registrationID int64, channel string)
 	}
 }
 
+// TerminateDriver sends driver command to terminate, with a token the driver
+// validates. The driver does not respond; it stops heartbeating once it exits.
+func (driver *Proxy) TerminateDriver(token []byte) error {
+	if len(token) > maxTerminateTokenLength {
+		return fmt.Errorf("termination token length %d exceeds %d", len(token), maxTerminateTokenLength)
+	}
+
+	correlationID := driver.toDriverCommandBuffer.NextCorrelationID()
+
+	logger.Debugf("driver.TerminateDriver: clientId=%d correlationId=%d",
+		driver.clientID, correlationID)
+
+	filler := func(buffer *atomic.Buffer, length *int) int32 {
+
+		var message command.TerminateDriverMessage
+		message.Wrap(buffer, 0)
+
+		message.ClientID.Set(driver.clientID)
+		message.CorrelationID.Set(correlationID)
+		if len(token) > 0 {
+			message.Token.Set(string(token))
+		}
+
+		*length = message.Size()
+
+		return command.TerminateDriver
+	}
+
+	return driver.writeCommandToDriver(filler)
+}
+
 func (driver *Proxy) writeCommandToDriver(filler func(*atomic.Buffer, *int) int32) error {
-	messageBuffer := make([]byte, 512)
+	messageBuffer := make([]byte, commandBufferLength)
 
 	buffer := atomic.NewBufferSlice(messageBuffer)
 
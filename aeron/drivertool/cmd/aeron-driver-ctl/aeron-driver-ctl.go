@@ -0,0 +1,94 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command aeron-driver-ctl checks, waits for and stops a media driver.
+//
+//	aeron-driver-ctl [-dir <aeron-dir>] [-timeout 10s] [-wait 30s] [-token <token>] <command>
+//
+// The aeron dir defaults to AERON_DIR, or the client's default.
+//
+// Commands:
+//
+//	describe   details of the driver from its CnC file
+//	pid        pid of the driver
+//	is-active  exits with status 0 if the driver heartbeated within the timeout, otherwise 1
+//	await      waits for the driver to become active, exiting with status 1 on timeout
+//	terminate  requests the driver to terminate with the token, and waits for it to stop
+//	clients    clients with heartbeat counters, and whether each is active
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/drivertool"
+	"github.com/lirm/aeron-go/aeron/properties"
+)
+
+func main() {
+	defaultDir, ok := properties.FromEnv().Lookup(aeron.AeronDirPropName)
+	if !ok {
+		defaultDir = aeron.DefaultAeronDir + "/aeron-" + aeron.UserName
+	}
+	dir := flag.String("dir", defaultDir, "aeron dir of the driver")
+	timeout := flag.Duration("timeout", drivertool.DefaultDriverTimeout, "driver heartbeat timeout")
+	wait := flag.Duration("wait", 30*time.Second, "how long await and terminate wait, zero for terminate not to wait")
+	token := flag.String("token", "", "termination token for terminate")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage: %s [options] describe|pid|is-active|await|terminate|clients\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	command := flag.Arg(0)
+
+	var err error
+	switch command {
+	case "describe":
+		err = drivertool.Describe(os.Stdout, *dir, *timeout)
+	case "pid":
+		var info *drivertool.DriverInfo
+		if info, err = drivertool.ReadDriverInfo(*dir); err == nil {
+			fmt.Println(info.Pid)
+		}
+	case "is-active":
+		var active bool
+		if active, err = drivertool.IsDriverActive(*dir, *timeout); err == nil {
+			fmt.Println(active)
+			if !active {
+				os.Exit(1)
+			}
+		}
+	case "await":
+		err = drivertool.AwaitDriverActive(*dir, *timeout, *wait)
+	case "terminate":
+		if err = drivertool.RequestDriverTermination(*dir, []byte(*token)); err == nil && *wait > 0 {
+			err = drivertool.AwaitDriverInactive(*dir, *timeout, *wait)
+		}
+	case "clients":
+		err = drivertool.ListClients(os.Stdout, *dir)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", command, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,252 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drivertool administers a media driver through the CnC file in its
+// aeron dir, in the manner of the Java CommonContext: whether the driver is
+// active, requesting its termination, and listing its connected clients. It
+// does not connect a client to the driver.
+package drivertool
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/counters"
+	"github.com/lirm/aeron-go/aeron/driver"
+	rb "github.com/lirm/aeron-go/aeron/ringbuffer"
+	"github.com/lirm/aeron-go/aeron/util/memmap"
+)
+
+// DefaultDriverTimeout after which a driver which has not heartbeated is considered inactive
+const DefaultDriverTimeout = 10 * time.Second
+
+// pollInterval between checks of the driver's heartbeat while waiting
+const pollInterval = 100 * time.Millisecond
+
+// ErrTimeout is returned when a driver did not become active, or inactive, in time
+var ErrTimeout = errors.New("timed out waiting for driver")
+
+// DriverInfo is a copy of the driver's details in the CnC file
+type DriverInfo struct {
+	CncFilename           string
+	CncVersion            int32
+	Pid                   int64
+	StartTimestamp        int64 // Epoch ms
+	HeartbeatTimestamp    int64 // Epoch ms the driver last consumed the to-driver buffer
+	ClientLivenessTimeout time.Duration
+}
+
+// IsActive is true if the driver heartbeated within timeout of now
+func (info *DriverInfo) IsActive(now time.Time, timeout time.Duration) bool {
+	return now.UnixMilli()-info.HeartbeatTimestamp <= timeout.Milliseconds()
+}
+
+func (info *DriverInfo) String() string {
+	return fmt.Sprintf("%s: cncVersion=%d pid=%d startTimestamp=%s heartbeatTimestamp=%s clientLivenessTimeout=%s",
+		info.CncFilename, info.CncVersion, info.Pid, formatTimestamp(info.StartTimestamp),
+		formatTimestamp(info.HeartbeatTimestamp), info.ClientLivenessTimeout)
+}
+
+// ClientInfo describes a client from its heartbeat counter
+type ClientInfo struct {
+	ClientId           int64
+	CounterId          int32
+	HeartbeatTimestamp int64 // Epoch ms
+	Label              string
+}
+
+// IsActive is true if the client heartbeated within timeout of now
+func (info *ClientInfo) IsActive(now time.Time, timeout time.Duration) bool {
+	return now.UnixMilli()-info.HeartbeatTimestamp <= timeout.Milliseconds()
+}
+
+func (info *ClientInfo) String() string {
+	return fmt.Sprintf("clientId=%d counterId=%d heartbeatTimestamp=%s label=%q",
+		info.ClientId, info.CounterId, formatTimestamp(info.HeartbeatTimestamp), info.Label)
+}
+
+// CncFilename returns the name of the CnC file in aeronDir
+func CncFilename(aeronDir string) string {
+	return filepath.Join(aeronDir, counters.CncFile)
+}
+
+// cnc is a mapped CnC file, which must be closed
+type cnc struct {
+	filename string
+	meta     *counters.MetaDataFlyweight
+	file     *memmap.File
+	toDriver rb.ManyToOne
+}
+
+func mapCnc(aeronDir string) (*cnc, error) {
+	filename := CncFilename(aeronDir)
+	meta, file, err := counters.MapFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("mapping %s: %w", filename, err)
+	}
+	c := &cnc{filename: filename, meta: meta, file: file}
+	c.toDriver.Init(meta.ToDriverBuf.Get())
+	return c, nil
+}
+
+func (c *cnc) close() {
+	c.file.Close()
+}
+
+// ReadDriverInfo copies the driver's details from the CnC file in aeronDir.
+// The error wraps os.ErrNotExist if there is no CnC file.
+func ReadDriverInfo(aeronDir string) (*DriverInfo, error) {
+	c, err := mapCnc(aeronDir)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+	return &DriverInfo{
+		CncFilename:           c.filename,
+		CncVersion:            c.meta.CncVersion.Get(),
+		Pid:                   c.meta.DriverPid.Get(),
+		StartTimestamp:        c.meta.DriverStartTimestamp.Get(),
+		HeartbeatTimestamp:    c.toDriver.ConsumerHeartbeatTime(),
+		ClientLivenessTimeout: time.Duration(c.meta.ClientLivenessTo.Get()),
+	}, nil
+}
+
+// IsDriverActive is true if the driver in aeronDir heartbeated within timeout.
+// It is false, without error, if there is no CnC file.
+func IsDriverActive(aeronDir string, timeout time.Duration) (bool, error) {
+	info, err := ReadDriverInfo(aeronDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return info.IsActive(time.Now(), timeout), nil
+}
+
+// AwaitDriverActive waits up to wait for the driver in aeronDir to heartbeat
+// within timeout, returning ErrTimeout if it does not. A CnC file which is
+// missing or not yet initialised is waited for.
+func AwaitDriverActive(aeronDir string, timeout, wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+	for {
+		info, err := ReadDriverInfo(aeronDir)
+		if err == nil && info.IsActive(time.Now(), timeout) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			if err != nil {
+				return fmt.Errorf("%w to start in %s: %v", ErrTimeout, aeronDir, err)
+			}
+			return fmt.Errorf("%w to start in %s", ErrTimeout, aeronDir)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// AwaitDriverInactive waits up to wait for the driver in aeronDir to stop
+// heartbeating within timeout, or its CnC file to be removed, returning
+// ErrTimeout if it does not
+func AwaitDriverInactive(aeronDir string, timeout, wait time.Duration) error {
+	deadline := time.Now().Add(wait)
+	for {
+		active, err := IsDriverActive(aeronDir, timeout)
+		if err != nil {
+			return err
+		}
+		if !active {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w to stop in %s", ErrTimeout, aeronDir)
+		}
+		time.Sleep(pollInterval)
+	}
+}
+
+// RequestDriverTermination sends the driver in aeronDir a terminate-driver
+// command with token, which the driver's termination validator must accept.
+// The driver does not respond, so use AwaitDriverInactive() to wait for it to
+// exit.
+func RequestDriverTermination(aeronDir string, token []byte) error {
+	c, err := mapCnc(aeronDir)
+	if err != nil {
+		return err
+	}
+	defer c.close()
+	var proxy driver.Proxy
+	proxy.Init(&c.toDriver)
+	return proxy.TerminateDriver(token)
+}
+
+// Clients returns the clients with a heartbeat counter in the driver in aeronDir
+func Clients(aeronDir string) ([]ClientInfo, error) {
+	c, err := mapCnc(aeronDir)
+	if err != nil {
+		return nil, err
+	}
+	defer c.close()
+	reader := counters.NewReader(c.meta.ValuesBuf.Get(), c.meta.MetaDataBuf.Get())
+	var clients []ClientInfo
+	reader.ScanForType(counters.ClientHeartbeatTypeId, func(counterId int32, keyBuffer *atomic.Buffer) bool {
+		clients = append(clients, ClientInfo{
+			ClientId:           keyBuffer.GetInt64(counters.ClientHeartbeatClientIdOffset),
+			CounterId:          counterId,
+			HeartbeatTimestamp: reader.GetCounterValue(counterId),
+			Label:              reader.GetCounterLabel(counterId),
+		})
+		return true
+	})
+	return clients, nil
+}
+
+// Describe writes the driver's details in aeronDir, and whether it is active
+func Describe(w io.Writer, aeronDir string, timeout time.Duration) error {
+	info, err := ReadDriverInfo(aeronDir)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s active=%t\n", info, info.IsActive(time.Now(), timeout))
+	return err
+}
+
+// ListClients writes the clients of the driver in aeronDir, and whether each
+// heartbeated within the driver's client liveness timeout
+func ListClients(w io.Writer, aeronDir string) error {
+	info, err := ReadDriverInfo(aeronDir)
+	if err != nil {
+		return err
+	}
+	clients, err := Clients(aeronDir)
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	for idx := range clients {
+		if _, err := fmt.Fprintf(w, "%s active=%t\n", &clients[idx], clients[idx].IsActive(now, info.ClientLivenessTimeout)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatTimestamp(epochMs int64) string {
+	if epochMs <= 0 {
+		return fmt.Sprintf("%d", epochMs)
+	}
+	return time.UnixMilli(epochMs).UTC().Format("2006-01-02T15:04:05.000Z")
+}
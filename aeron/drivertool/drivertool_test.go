@@ -0,0 +1,180 @@
+package drivertool
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/command"
+	"github.com/lirm/aeron-go/aeron/counters"
+	rb "github.com/lirm/aeron-go/aeron/ringbuffer"
+	"github.com/lirm/aeron-go/aeron/util"
+	"github.com/lirm/aeron-go/aeron/util/memmap"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	headerLength       = 128
+	toDriverCapacity   = 1024
+	toDriverLength     = toDriverCapacity + 768
+	counterCount       = 2
+	metaDataLength     = counterCount * counters.MetadataLength
+	valuesLength       = counterCount * counters.CounterLength
+	consumerHeartbeat  = headerLength + toDriverCapacity + 640
+	metaDataBufOffset  = headerLength + toDriverLength
+	valuesBufOffset    = metaDataBufOffset + metaDataLength
+	cncLength          = valuesBufOffset + valuesLength
+	clientLivenessTime = 5 * time.Second
+)
+
+// newCnc creates a CnC file in a new aeron dir, as a driver which heartbeated
+// at heartbeat would
+func newCnc(t *testing.T, heartbeat time.Time) (string, *atomic.Buffer) {
+	dir := t.TempDir()
+	file, err := memmap.NewFile(CncFilename(dir), 0, int(cncLength))
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+	buf := atomic.NewBufferPointer(file.GetMemoryPtr(), int32(file.GetMemorySize()))
+	buf.PutInt32(4, toDriverLength)
+	buf.PutInt32(8, 0)
+	buf.PutInt32(12, metaDataLength)
+	buf.PutInt32(16, valuesLength)
+	buf.PutInt32(20, 0)
+	buf.PutInt64(24, int64(clientLivenessTime))
+	buf.PutInt64(32, heartbeat.Add(-time.Minute).UnixMilli())
+	buf.PutInt64(40, 1234)
+	buf.PutInt64(consumerHeartbeat, heartbeat.UnixMilli())
+	buf.PutInt32(0, counters.CurrentCncVersion)
+	return dir, buf
+}
+
+func addClientCounter(buf *atomic.Buffer, counterId int32, clientId int64, heartbeat time.Time, label string) {
+	offset := metaDataBufOffset + counterId*counters.MetadataLength
+	buf.PutInt32(offset+counters.TypeIdOffset, counters.ClientHeartbeatTypeId)
+	buf.PutInt64(offset+counters.KeyOffset+counters.ClientHeartbeatClientIdOffset, clientId)
+	buf.PutInt32(offset+counters.LabelOffset, int32(len(label)))
+	labelBytes := []byte(label)
+	buf.PutBytesArray(offset+counters.LabelOffset+4, &labelBytes, 0, int32(len(label)))
+	buf.PutInt64(valuesBufOffset+counterId*counters.CounterLength, heartbeat.UnixMilli())
+	buf.PutInt32(offset, counters.RecordAllocated)
+}
+
+func TestIsDriverActive(t *testing.T) {
+	now := time.Now()
+	dir, _ := newCnc(t, now)
+	active, err := IsDriverActive(dir, DefaultDriverTimeout)
+	require.NoError(t, err)
+	assert.True(t, active)
+	require.NoError(t, AwaitDriverActive(dir, DefaultDriverTimeout, 0))
+
+	info, err := ReadDriverInfo(dir)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1234, info.Pid)
+	assert.Equal(t, now.UnixMilli(), info.HeartbeatTimestamp)
+	assert.Equal(t, clientLivenessTime, info.ClientLivenessTimeout)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, Describe(out, dir, DefaultDriverTimeout))
+	assert.Contains(t, out.String(), "pid=1234")
+	assert.Contains(t, out.String(), "active=true")
+}
+
+func TestIsDriverActive_Stale(t *testing.T) {
+	dir, _ := newCnc(t, time.Now().Add(-time.Minute))
+	active, err := IsDriverActive(dir, DefaultDriverTimeout)
+	require.NoError(t, err)
+	assert.False(t, active)
+	require.NoError(t, AwaitDriverInactive(dir, DefaultDriverTimeout, 0))
+	assert.ErrorIs(t, AwaitDriverActive(dir, DefaultDriverTimeout, 0), ErrTimeout)
+}
+
+func TestIsDriverActive_NoCncFile(t *testing.T) {
+	dir := t.TempDir()
+	active, err := IsDriverActive(dir, DefaultDriverTimeout)
+	require.NoError(t, err)
+	assert.False(t, active)
+
+	_, err = ReadDriverInfo(dir)
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.ErrorIs(t, AwaitDriverActive(filepath.Join(dir, "missing"), DefaultDriverTimeout, 0), ErrTimeout)
+}
+
+// runTestDriver heartbeats in the CnC file until it reads a terminate-driver
+// command with token from the to-driver buffer, then sends the tokens it read.
+// The driver is stopped before the CnC file is unmapped.
+func runTestDriver(t *testing.T, buf *atomic.Buffer, token string) <-chan string {
+	terminated := make(chan string, 1)
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	t.Cleanup(func() {
+		close(stop)
+		<-stopped
+	})
+	go func() {
+		defer close(stopped)
+		var received []string
+		for offset := int32(headerLength); ; {
+			buf.PutInt64Ordered(consumerHeartbeat, time.Now().UnixMilli())
+			if length := buf.GetInt32Volatile(offset); length > 0 {
+				if buf.GetInt32(offset+4) == command.TerminateDriver {
+					var message command.TerminateDriverMessage
+					message.Wrap(buf, int(offset+8))
+					received = append(received, message.Token.Get())
+				}
+				offset += util.AlignInt32(length, rb.RecordDescriptor.RecordAlignment)
+			}
+			if len(received) > 0 && received[len(received)-1] == token {
+				terminated <- strings.Join(received, ",")
+				return
+			}
+			select {
+			case <-stop:
+				return
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}()
+	return terminated
+}
+
+func TestRequestDriverTermination(t *testing.T) {
+	dir, buf := newCnc(t, time.Now().Add(-time.Minute))
+	terminated := runTestDriver(t, buf, "secret")
+	require.NoError(t, AwaitDriverActive(dir, DefaultDriverTimeout, time.Second))
+
+	assert.Error(t, RequestDriverTermination(dir, make([]byte, 1024)))
+	require.NoError(t, RequestDriverTermination(dir, []byte("wrong")))
+	require.NoError(t, RequestDriverTermination(dir, []byte("secret")))
+	select {
+	case received := <-terminated:
+		assert.Equal(t, "wrong,secret", received)
+	case <-time.After(time.Second):
+		t.Fatal("driver did not receive the termination request")
+	}
+
+	// The driver stopped heartbeating, so becomes inactive after the timeout
+	require.NoError(t, AwaitDriverInactive(dir, 50*time.Millisecond, time.Second))
+}
+
+func TestClients(t *testing.T) {
+	now := time.Now()
+	dir, buf := newCnc(t, now)
+	addClientCounter(buf, 0, 42, now, "client-heartbeat: 42")
+	addClientCounter(buf, 1, 43, now.Add(-time.Minute), "client-heartbeat: 43")
+
+	clients, err := Clients(dir)
+	require.NoError(t, err)
+	require.Len(t, clients, 2)
+	assert.Equal(t, ClientInfo{ClientId: 42, CounterId: 0, HeartbeatTimestamp: now.UnixMilli(), Label: "client-heartbeat: 42"}, clients[0])
+	assert.EqualValues(t, 43, clients[1].ClientId)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, ListClients(out, dir))
+	assert.Contains(t, out.String(), `clientId=42 counterId=0`)
+	assert.Contains(t, out.String(), `label="client-heartbeat: 42" active=true`)
+	assert.Contains(t, out.String(), `label="client-heartbeat: 43" active=false`)
+}
@@ -0,0 +1,130 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package errorlog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/util"
+)
+
+// maxStackDepth limits the frames recorded with each error
+const maxStackDepth = 32
+
+// DistinctErrorLog records errors in a buffer, such as the error region of a
+// mark file, so they can be read by another process. Each distinct error,
+// identified by its message and the stack it was recorded from, is written
+// once and subsequent observations update its count and last observation
+// timestamp. Safe for concurrent use.
+type DistinctErrorLog struct {
+	buffer       *atomic.Buffer
+	clock        func() int64
+	mutex        sync.Mutex
+	offsetByKey  map[string]int32
+	nextOffset   int32
+	droppedCount int64
+}
+
+// NewDistinctErrorLog over buffer, with timestamps from clock in epoch
+// milliseconds or from the system clock if nil. The buffer is expected to be
+// zeroed or to hold only records written by a previous DistinctErrorLog.
+func NewDistinctErrorLog(buffer *atomic.Buffer, clock func() int64) *DistinctErrorLog {
+	if clock == nil {
+		clock = func() int64 { return time.Now().UnixMilli() }
+	}
+	log := &DistinctErrorLog{
+		buffer:      buffer,
+		clock:       clock,
+		offsetByKey: make(map[string]int32),
+	}
+	// Continue after any existing records, which are not deduplicated against
+	for log.nextOffset+EncodedErrorOffset <= buffer.Capacity() {
+		length := buffer.GetInt32Volatile(log.nextOffset + LengthOffset)
+		if length <= 0 {
+			break
+		}
+		log.nextOffset += util.AlignInt32(length, RecordAlignment)
+	}
+	return log
+}
+
+// Record an observation of err along with the stack of the caller. Returns
+// false if the error is new and there is no space left in the buffer.
+func (log *DistinctErrorLog) Record(err error) bool {
+	return log.RecordEncoded(encodeError(err, 1))
+}
+
+// RecordEncoded records an observation of an error which is already encoded,
+// using the whole encoding to identify it
+func (log *DistinctErrorLog) RecordEncoded(encodedError string) bool {
+	timestamp := log.clock()
+
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+
+	if offset, ok := log.offsetByKey[encodedError]; ok {
+		count := log.buffer.GetInt32(offset + ObservationCountOffset)
+		log.buffer.PutInt32Ordered(offset+ObservationCountOffset, count+1)
+		log.buffer.PutInt64Ordered(offset+LastObservationTimestampOffset, timestamp)
+		return true
+	}
+
+	length := int32(EncodedErrorOffset + len(encodedError))
+	offset := log.nextOffset
+	if offset+length > log.buffer.Capacity() {
+		log.droppedCount++
+		return false
+	}
+	encoded := []byte(encodedError)
+	log.buffer.PutBytesArray(offset+EncodedErrorOffset, &encoded, 0, int32(len(encoded)))
+	log.buffer.PutInt64(offset+FirstObservationTimestampOffset, timestamp)
+	log.buffer.PutInt64(offset+LastObservationTimestampOffset, timestamp)
+	log.buffer.PutInt32(offset+ObservationCountOffset, 1)
+	log.buffer.PutInt32Ordered(offset+LengthOffset, length)
+
+	log.offsetByKey[encodedError] = offset
+	log.nextOffset = offset + util.AlignInt32(length, RecordAlignment)
+	return true
+}
+
+// DroppedCount is the number of new errors which did not fit in the buffer
+func (log *DistinctErrorLog) DroppedCount() int64 {
+	log.mutex.Lock()
+	defer log.mutex.Unlock()
+	return log.droppedCount
+}
+
+// encodeError as its message followed by the stack from skip frames above encodeError's caller
+func encodeError(err error, skip int) string {
+	pcs := make([]uintptr, maxStackDepth)
+	n := runtime.Callers(skip+2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	sb.WriteString(err.Error())
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			fmt.Fprintf(&sb, "\n\tat %s(%s:%d)", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,66 @@
+package errorlog
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func readAll(buffer *atomic.Buffer) []observation {
+	var observations []observation
+	Read(buffer, func(observationCount int32, firstObservationTimestamp, lastObservationTimestamp int64, encodedError string) {
+		observations = append(observations, observation{observationCount, firstObservationTimestamp, lastObservationTimestamp, encodedError})
+	}, 0)
+	return observations
+}
+
+func TestDistinctErrorLog_Dedupe(t *testing.T) {
+	buffer := atomic.MakeBuffer(make([]byte, 64*1024))
+	now := int64(1000)
+	log := NewDistinctErrorLog(buffer, func() int64 { return now })
+
+	record := func(err error) {
+		require.True(t, log.Record(err))
+	}
+	for i := 0; i < 3; i++ {
+		record(errors.New("take snapshot failed"))
+		now += 10
+	}
+	// Same message from a different stack
+	require.True(t, log.Record(errors.New("take snapshot failed")))
+	record(errors.New("another failure"))
+
+	observations := readAll(buffer)
+	require.Len(t, observations, 3)
+	assert.Equal(t, int32(3), observations[0].count)
+	assert.Equal(t, int64(1000), observations[0].first)
+	assert.Equal(t, int64(1020), observations[0].last)
+	assert.True(t, strings.HasPrefix(observations[0].encodedError, "take snapshot failed\n\tat "))
+	assert.Contains(t, observations[0].encodedError, "TestDistinctErrorLog_Dedupe")
+	assert.Equal(t, int32(1), observations[1].count)
+	assert.NotEqual(t, observations[0].encodedError, observations[1].encodedError)
+	assert.True(t, strings.HasPrefix(observations[2].encodedError, "another failure\n"))
+}
+
+func TestDistinctErrorLog_Full(t *testing.T) {
+	buffer := atomic.MakeBuffer(make([]byte, 64))
+	log := NewDistinctErrorLog(buffer, func() int64 { return 1 })
+	assert.True(t, log.RecordEncoded("short"))
+	assert.False(t, log.RecordEncoded(strings.Repeat("x", 40)))
+	assert.True(t, log.RecordEncoded("short"))
+	assert.EqualValues(t, 1, log.DroppedCount())
+	assert.Equal(t, []observation{{2, 1, 1, "short"}}, readAll(buffer))
+}
+
+func TestDistinctErrorLog_Existing(t *testing.T) {
+	buffer := atomic.MakeBuffer(make([]byte, 1024))
+	NewDistinctErrorLog(buffer, func() int64 { return 1 }).RecordEncoded("before restart")
+
+	log := NewDistinctErrorLog(buffer, func() int64 { return 2 })
+	log.RecordEncoded("after restart")
+	assert.Equal(t, []observation{{1, 1, 1, "before restart"}, {1, 2, 2, "after restart"}}, readAll(buffer))
+}
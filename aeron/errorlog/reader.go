@@ -0,0 +1,79 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package errorlog reads and writes the distinct error log format used by
+// the media driver, archive and cluster to record errors in their CnC and
+// mark files.
+//
+// See ~agrona/agrona/src/main/java/org/agrona/concurrent/errors/DistinctErrorLog.java
+//
+// Each distinct error is a record aligned to 8 bytes:
+//
+//	 0                   1                   2                   3
+//	 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+//	+---------------------------------------------------------------+
+//	|                            Length                             |
+//	+---------------------------------------------------------------+
+//	|                       Observation Count                       |
+//	+---------------------------------------------------------------+
+//	|                  Last Observation Timestamp                   |
+//	|                                                               |
+//	+---------------------------------------------------------------+
+//	|                  First Observation Timestamp                  |
+//	|                                                               |
+//	+---------------------------------------------------------------+
+//	|                   ASCII Encoded Error                        ...
+//	...                                                             |
+//	+---------------------------------------------------------------+
+package errorlog
+
+import (
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/util"
+)
+
+const (
+	LengthOffset                    = 0
+	ObservationCountOffset          = 4
+	LastObservationTimestampOffset  = 8
+	FirstObservationTimestampOffset = 16
+	EncodedErrorOffset              = 24
+	RecordAlignment                 = 8
+)
+
+// ErrorConsumer is called for each distinct error read from the log, with
+// timestamps in epoch milliseconds
+type ErrorConsumer func(observationCount int32, firstObservationTimestamp, lastObservationTimestamp int64, encodedError string)
+
+// Read the distinct errors in buffer last observed at or after sinceTimestamp,
+// returning the number of errors consumed
+func Read(buffer *atomic.Buffer, consumer ErrorConsumer, sinceTimestamp int64) int {
+	count := 0
+	capacity := buffer.Capacity()
+	for offset := int32(0); offset+EncodedErrorOffset <= capacity; {
+		length := buffer.GetInt32Volatile(offset + LengthOffset)
+		if length <= 0 || offset+length > capacity {
+			break
+		}
+		lastObservationTimestamp := buffer.GetInt64Volatile(offset + LastObservationTimestampOffset)
+		if lastObservationTimestamp >= sinceTimestamp {
+			count++
+			consumer(
+				buffer.GetInt32Volatile(offset+ObservationCountOffset),
+				buffer.GetInt64(offset+FirstObservationTimestampOffset),
+				lastObservationTimestamp,
+				string(buffer.GetBytesArray(offset+EncodedErrorOffset, length-EncodedErrorOffset)))
+		}
+		offset += util.AlignInt32(length, RecordAlignment)
+	}
+	return count
+}
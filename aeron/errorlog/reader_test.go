@@ -0,0 +1,51 @@
+package errorlog
+
+import (
+	"testing"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/stretchr/testify/assert"
+)
+
+type observation struct {
+	count        int32
+	first, last  int64
+	encodedError string
+}
+
+func putRecord(buffer *atomic.Buffer, offset int32, count int32, first, last int64, encodedError string) int32 {
+	length := int32(EncodedErrorOffset + len(encodedError))
+	buffer.PutInt32(offset+LengthOffset, length)
+	buffer.PutInt32(offset+ObservationCountOffset, count)
+	buffer.PutInt64(offset+LastObservationTimestampOffset, last)
+	buffer.PutInt64(offset+FirstObservationTimestampOffset, first)
+	data := []byte(encodedError)
+	buffer.PutBytesArray(offset+EncodedErrorOffset, &data, 0, int32(len(data)))
+	return offset + (length+RecordAlignment-1)&^(RecordAlignment-1)
+}
+
+func TestRead(t *testing.T) {
+	buffer := atomic.MakeBuffer(make([]byte, 1024))
+	offset := putRecord(buffer, 0, 3, 100, 300, "first error")
+	putRecord(buffer, offset, 1, 200, 200, "second")
+
+	var observations []observation
+	consumer := func(observationCount int32, firstObservationTimestamp, lastObservationTimestamp int64, encodedError string) {
+		observations = append(observations, observation{observationCount, firstObservationTimestamp, lastObservationTimestamp, encodedError})
+	}
+	assert.Equal(t, 2, Read(buffer, consumer, 0))
+	assert.Equal(t, []observation{{3, 100, 300, "first error"}, {1, 200, 200, "second"}}, observations)
+
+	observations = nil
+	assert.Equal(t, 1, Read(buffer, consumer, 250))
+	assert.Equal(t, "first error", observations[0].encodedError)
+}
+
+func TestRead_Empty(t *testing.T) {
+	buffer := atomic.MakeBuffer(make([]byte, 64))
+	assert.Zero(t, Read(buffer, func(int32, int64, int64, string) { t.Fail() }, 0))
+
+	// A record running past the end of the buffer is not read
+	buffer.PutInt32(0, 128)
+	assert.Zero(t, Read(buffer, func(int32, int64, int64, string) { t.Fail() }, 0))
+}
@@ -0,0 +1,105 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idlestrategy
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// AdaptiveIdleStrategy is an idling strategy which, like BackoffIdleStrategy,
+// spins for maxSpins, then yields for maxYields, then parks. Rather than start
+// each idle period parking for the minimum, it keeps the park period it has
+// learnt from the work observed:
+//
+//   - work found after at most one park halves the period, towards
+//     minParkPeriodNs, as the park delayed it
+//   - each further park without work doubles the period, towards
+//     maxParkPeriodNs, as the work is sparse
+//
+// So a loop with bursty work parks briefly, and an idle loop parks for long.
+type AdaptiveIdleStrategy struct {
+	// configured max spins, yield, and min / max park period
+	maxSpins, maxYields, minParkPeriodNs, maxParkPeriodNs int64
+	// current number of spins, yields and parks in this idle period
+	spins, yields, parks int64
+	// park period learnt from previous idle periods
+	parkPeriodNs int64
+}
+
+// NewAdaptiveIdleStrategy returns an AdaptiveIdleStrategy with the given parameters.
+func NewAdaptiveIdleStrategy(maxSpins, maxYields, minParkPeriodNs, maxParkPeriodNs int64) *AdaptiveIdleStrategy {
+	return &AdaptiveIdleStrategy{
+		maxSpins:        maxSpins,
+		maxYields:       maxYields,
+		minParkPeriodNs: minParkPeriodNs,
+		maxParkPeriodNs: maxParkPeriodNs,
+		parkPeriodNs:    minParkPeriodNs,
+	}
+}
+
+// NewDefaultAdaptiveIdleStrategy returns an AdaptiveIdleStrategy using DefaultMaxSpins, DefaultMaxYields,
+// DefaultMinParkNs, and DefaultMaxParkNs.
+func NewDefaultAdaptiveIdleStrategy() *AdaptiveIdleStrategy {
+	return NewAdaptiveIdleStrategy(DefaultMaxSpins, DefaultMaxYields, DefaultMinParkNs, DefaultMaxParkNs)
+}
+
+func (s *AdaptiveIdleStrategy) Idle(workCount int) {
+	if workCount > 0 {
+		s.reset()
+	} else {
+		s.idle()
+	}
+}
+
+// ParkPeriod returns the period the strategy currently parks for
+func (s *AdaptiveIdleStrategy) ParkPeriod() time.Duration {
+	return time.Duration(s.parkPeriodNs)
+}
+
+func (s *AdaptiveIdleStrategy) String() string {
+	return fmt.Sprintf("AdaptiveIdleStrategy(MaxSpins:%d, MaxYields:%d, MinParkPeriodNs:%d, MaxParkPeriodNs:%d, ParkPeriodNs:%d)",
+		s.maxSpins, s.maxYields, s.minParkPeriodNs, s.maxParkPeriodNs, s.parkPeriodNs)
+}
+
+func (s *AdaptiveIdleStrategy) reset() {
+	if s.spins+s.yields+s.parks > 0 && s.parks <= 1 {
+		s.parkPeriodNs >>= 1
+		if s.parkPeriodNs < s.minParkPeriodNs {
+			s.parkPeriodNs = s.minParkPeriodNs
+		}
+	}
+	s.spins = 0
+	s.yields = 0
+	s.parks = 0
+}
+
+func (s *AdaptiveIdleStrategy) idle() {
+	switch {
+	case s.spins < s.maxSpins:
+		s.spins++
+	case s.yields < s.maxYields:
+		s.yields++
+		runtime.Gosched()
+	default:
+		if s.parks > 0 {
+			s.parkPeriodNs <<= 1
+			if s.parkPeriodNs > s.maxParkPeriodNs {
+				s.parkPeriodNs = s.maxParkPeriodNs
+			}
+		}
+		s.parks++
+		time.Sleep(time.Duration(s.parkPeriodNs))
+	}
+}
@@ -0,0 +1,89 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idlestrategy
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron/counters"
+)
+
+// Modes of a ControllableIdleStrategy, as the values of its status indicator
+const (
+	ControllableNotControlled int64 = 0 // Park, as when the indicator has not been set
+	ControllableNoOp          int64 = 1
+	ControllableBusySpin      int64 = 2
+	ControllableYield         int64 = 3
+	ControllablePark          int64 = 4
+)
+
+// ControllableIdleStrategyTypeId is the type id of the counter which controls
+// a ControllableIdleStrategy, matching the Java implementation
+const ControllableIdleStrategyTypeId = 5
+
+// DefaultControllableParkPeriod is the period a ControllableIdleStrategy parks for
+const DefaultControllableParkPeriod = time.Microsecond
+
+// StatusIndicator is read by a ControllableIdleStrategy for its mode, and is
+// implemented by counters.ReadableCounter and counters.AtomicCounter
+type StatusIndicator interface {
+	Get() int64
+}
+
+// ControllableIdleStrategy idles as set by the value of a status indicator,
+// usually a counter in the CnC file, so that a loop can be switched between
+// not idling, busy spinning, yielding and parking while running.
+type ControllableIdleStrategy struct {
+	indicator    StatusIndicator
+	parkPeriodNs int64
+}
+
+// NewControllableIdleStrategy returns a ControllableIdleStrategy reading its mode from indicator
+func NewControllableIdleStrategy(indicator StatusIndicator) *ControllableIdleStrategy {
+	return &ControllableIdleStrategy{indicator: indicator, parkPeriodNs: int64(DefaultControllableParkPeriod)}
+}
+
+// NewControllableIdleStrategyFromCounters returns a ControllableIdleStrategy
+// reading its mode from the first counter with ControllableIdleStrategyTypeId,
+// which is allocated by the driver or another client. Use counters.Reader.FindCounter
+// to choose between several.
+func NewControllableIdleStrategyFromCounters(reader *counters.Reader) (*ControllableIdleStrategy, error) {
+	counterId := reader.FindCounter(ControllableIdleStrategyTypeId, nil)
+	if counterId == counters.NullCounterId {
+		return nil, fmt.Errorf("no counter with typeId=%d", ControllableIdleStrategyTypeId)
+	}
+	counter, err := counters.NewReadableCounter(reader, counterId)
+	if err != nil {
+		return nil, err
+	}
+	return NewControllableIdleStrategy(counter), nil
+}
+
+func (s *ControllableIdleStrategy) Idle(workCount int) {
+	if workCount > 0 {
+		return
+	}
+	switch s.indicator.Get() {
+	case ControllableNoOp, ControllableBusySpin:
+	case ControllableYield:
+		runtime.Gosched()
+	default:
+		time.Sleep(time.Duration(s.parkPeriodNs))
+	}
+}
+
+func (s *ControllableIdleStrategy) String() string {
+	return fmt.Sprintf("ControllableIdleStrategy(Mode:%d, ParkPeriodNs:%d)", s.indicator.Get(), s.parkPeriodNs)
+}
@@ -0,0 +1,113 @@
+package idlestrategy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testIndicator int64
+
+func (t *testIndicator) Get() int64 {
+	return int64(*t)
+}
+
+func TestParse(t *testing.T) {
+	cases := map[string]Idler{
+		"noop":                     NoOp{},
+		"busy":                     Busy{},
+		"Yield":                    Yielding{},
+		"sleep:4ms":                NewSleepingMillis(4),
+		"park: 100us":              NewSleeping(100 * time.Microsecond),
+		"backoff":                  NewDefaultBackoffIdleStrategy(),
+		"backoff:10,20,1us,1ms":    NewBackoffIdleStrategy(10, 20, 1000, int64(time.Millisecond)),
+		"adaptive:1,2,500,1000000": NewAdaptiveIdleStrategy(1, 2, 500, 1000000),
+	}
+	for config, expected := range cases {
+		idler, err := Parse(config)
+		require.NoError(t, err, config)
+		assert.Equal(t, expected, idler, config)
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	for _, config := range []string{
+		"",
+		"spin",
+		"noop:1",
+		"sleep",
+		"sleep:soon",
+		"backoff:1,2,3",
+		"backoff:x,2,1us,1ms",
+		"backoff:1,2,1ms,1us",
+		"controllable:1",
+	} {
+		_, err := Parse(config)
+		assert.Error(t, err, config)
+	}
+}
+
+func TestParseWithIndicators(t *testing.T) {
+	mode := testIndicator(ControllableYield)
+	idler, err := ParseWithIndicators("controllable:7", func(counterId int32) (StatusIndicator, error) {
+		assert.Equal(t, int32(7), counterId)
+		return &mode, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, NewControllableIdleStrategy(&mode), idler)
+}
+
+func TestControllableIdleStrategy(t *testing.T) {
+	mode := testIndicator(ControllableNoOp)
+	s := NewControllableIdleStrategy(&mode)
+	s.parkPeriodNs = int64(50 * time.Millisecond)
+
+	start := time.Now()
+	s.Idle(0)
+	mode = testIndicator(ControllableBusySpin)
+	s.Idle(0)
+	mode = testIndicator(ControllableYield)
+	s.Idle(0)
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+
+	mode = testIndicator(ControllablePark)
+	s.Idle(1) // Work was done so no parking
+	assert.Less(t, time.Since(start), 50*time.Millisecond)
+	s.Idle(0)
+	assert.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+}
+
+func TestAdaptiveIdleStrategy(t *testing.T) {
+	s := NewAdaptiveIdleStrategy(1, 1, 1000, 16000)
+
+	// Spin, yield, then park while idle, doubling the period on each park after the first
+	for i := 0; i < 6; i++ {
+		s.Idle(0)
+	}
+	assert.Equal(t, 8*time.Microsecond, s.ParkPeriod())
+	s.Idle(0)
+	s.Idle(0)
+	assert.Equal(t, 16*time.Microsecond, s.ParkPeriod())
+
+	// Long idle periods keep the learnt period
+	s.Idle(1)
+	assert.Equal(t, 16*time.Microsecond, s.ParkPeriod())
+
+	// Work found after at most one park halves it
+	s.Idle(0)
+	s.Idle(0)
+	s.Idle(0)
+	s.Idle(1)
+	assert.Equal(t, 8*time.Microsecond, s.ParkPeriod())
+	s.Idle(0)
+	s.Idle(1)
+	assert.Equal(t, 4*time.Microsecond, s.ParkPeriod())
+	for i := 0; i < 5; i++ {
+		s.Idle(1)
+		s.Idle(0)
+	}
+	s.Idle(1)
+	assert.Equal(t, time.Microsecond, s.ParkPeriod())
+}
@@ -0,0 +1,145 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package idlestrategy
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Parse returns the strategy described by config, which is the name of the
+// strategy optionally followed by a colon and its comma separated parameters:
+//
+//	noop
+//	busy                                  (or busy-spin)
+//	yield                                 (or yielding)
+//	sleep:<period>                        (or sleeping, park)
+//	backoff[:<spins>,<yields>,<minPark>,<maxPark>]
+//	adaptive[:<spins>,<yields>,<minPark>,<maxPark>]
+//	controllable:<counterId>              (see ParseWithIndicators)
+//
+// Periods are durations such as "1us" or "4ms", or a number of nanoseconds.
+// Backoff and adaptive default to DefaultMaxSpins, DefaultMaxYields,
+// DefaultMinParkNs and DefaultMaxParkNs, e.g. "backoff:10,20,1us,1ms".
+func Parse(config string) (Idler, error) {
+	return ParseWithIndicators(config, nil)
+}
+
+// ParseWithIndicators is Parse, with indicator returning the status indicator
+// of a controllable strategy from its counter id, for example by wrapping
+// counters.NewReadableCounter with the client's CounterReader()
+func ParseWithIndicators(config string, indicator func(counterId int32) (StatusIndicator, error)) (Idler, error) {
+	name, params, hasParams := strings.Cut(strings.TrimSpace(config), ":")
+	var args []string
+	if hasParams {
+		args = strings.Split(params, ",")
+		for i := range args {
+			args[i] = strings.TrimSpace(args[i])
+		}
+	}
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "noop":
+		return NoOp{}, checkArgs(config, args, 0)
+	case "busy", "busy-spin":
+		return Busy{}, checkArgs(config, args, 0)
+	case "yield", "yielding":
+		return Yielding{}, checkArgs(config, args, 0)
+	case "sleep", "sleeping", "park":
+		if err := checkArgs(config, args, 1); err != nil {
+			return nil, err
+		}
+		period, err := parsePeriod(config, args[0])
+		if err != nil {
+			return nil, err
+		}
+		return NewSleeping(time.Duration(period)), nil
+	case "backoff":
+		spins, yields, minPark, maxPark, err := parseBackoffArgs(config, args)
+		if err != nil {
+			return nil, err
+		}
+		return NewBackoffIdleStrategy(spins, yields, minPark, maxPark), nil
+	case "adaptive":
+		spins, yields, minPark, maxPark, err := parseBackoffArgs(config, args)
+		if err != nil {
+			return nil, err
+		}
+		return NewAdaptiveIdleStrategy(spins, yields, minPark, maxPark), nil
+	case "controllable":
+		if err := checkArgs(config, args, 1); err != nil {
+			return nil, err
+		}
+		if indicator == nil {
+			return nil, fmt.Errorf("idle strategy %q needs a status indicator, see ParseWithIndicators", config)
+		}
+		counterId, err := strconv.ParseInt(args[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("idle strategy %q has an invalid counter id: %w", config, err)
+		}
+		status, err := indicator(int32(counterId))
+		if err != nil {
+			return nil, fmt.Errorf("idle strategy %q: %w", config, err)
+		}
+		return NewControllableIdleStrategy(status), nil
+	default:
+		return nil, fmt.Errorf("unknown idle strategy %q", config)
+	}
+}
+
+func checkArgs(config string, args []string, count int) error {
+	if len(args) != count {
+		return fmt.Errorf("idle strategy %q takes %d parameters, has %d", config, count, len(args))
+	}
+	return nil
+}
+
+func parseBackoffArgs(config string, args []string) (spins, yields, minPark, maxPark int64, err error) {
+	if len(args) == 0 {
+		return DefaultMaxSpins, DefaultMaxYields, DefaultMinParkNs, DefaultMaxParkNs, nil
+	}
+	if err = checkArgs(config, args, 4); err != nil {
+		return
+	}
+	if spins, err = strconv.ParseInt(args[0], 10, 64); err != nil {
+		err = fmt.Errorf("idle strategy %q has invalid spins: %w", config, err)
+		return
+	}
+	if yields, err = strconv.ParseInt(args[1], 10, 64); err != nil {
+		err = fmt.Errorf("idle strategy %q has invalid yields: %w", config, err)
+		return
+	}
+	if minPark, err = parsePeriod(config, args[2]); err != nil {
+		return
+	}
+	if maxPark, err = parsePeriod(config, args[3]); err != nil {
+		return
+	}
+	if minPark > maxPark {
+		err = fmt.Errorf("idle strategy %q has a min park period above its max", config)
+	}
+	return
+}
+
+// parsePeriod returns the period in nanoseconds
+func parsePeriod(config string, arg string) (int64, error) {
+	if ns, err := strconv.ParseInt(arg, 10, 64); err == nil {
+		return ns, nil
+	}
+	d, err := time.ParseDuration(arg)
+	if err != nil {
+		return 0, fmt.Errorf("idle strategy %q has an invalid period: %w", config, err)
+	}
+	return int64(d), nil
+}
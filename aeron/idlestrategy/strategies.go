@@ -32,10 +32,28 @@ func (s Busy) Idle(fragmentsRead int) {
 
 }
 
+// NoOp does nothing, leaving a loop to run flat out
+type NoOp struct {
+}
+
+func (s NoOp) Idle(fragmentsRead int) {
+
+}
+
 type Sleeping struct {
 	SleepFor time.Duration
 }
 
+// NewSleeping returns a strategy which parks for the period when there is no work
+func NewSleeping(period time.Duration) Sleeping {
+	return Sleeping{SleepFor: period}
+}
+
+// NewSleepingMillis returns a strategy which parks for millis milliseconds when there is no work
+func NewSleepingMillis(millis int64) Sleeping {
+	return Sleeping{SleepFor: time.Duration(millis) * time.Millisecond}
+}
+
 func (s Sleeping) Idle(fragmentsRead int) {
 	if fragmentsRead == 0 {
 		time.Sleep(s.SleepFor)
@@ -0,0 +1,48 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package aeron
+
+import (
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+)
+
+// NewInMemoryStream returns a Publication and an Image of it which share log
+// buffers on the heap, with no media driver. It is intended for tests and
+// tools which need to drive code written against a Publication, such as a
+// ClusteredService taking a snapshot, and read back what it wrote.
+//
+// The terms are never cleaned or reused, so the stream is bounded: once
+// termLength * (logbuffer.PartitionCount - 1) bytes have been written,
+// further offers return BackPressured. The remaining term stays clean so the
+// Image stops at the end of the stream. Closing the Publication does not
+// close the Image.
+func NewInMemoryStream(channel string, streamID int32, sessionID int32, termLength int32) (*Publication, Image) {
+	const initialTermID = 0
+	logBuffers := logbuffer.NewInMemory(termLength, initialTermID, 8*1024, sessionID, streamID)
+
+	pub := NewPublication(logBuffers)
+	pub.channel = channel
+	pub.streamID = streamID
+	pub.sessionID = sessionID
+	pub.channelStatusIndicatorID = ChannelStatusNoIdAllocated
+	limit := atomic.MakeBuffer(make([]byte, 8))
+	limit.PutInt64(0, int64(termLength)*(logbuffer.PartitionCount-1))
+	pub.pubLimit = NewPosition(limit, 0)
+
+	image := NewImage(sessionID, 0, logBuffers)
+	image.sourceIdentity = "in-memory"
+	image.subscriberPosition = NewPosition(atomic.MakeBuffer(make([]byte, 8)), 0)
+
+	return pub, image
+}
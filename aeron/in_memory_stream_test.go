@@ -0,0 +1,84 @@
+package aeron
+
+import (
+	"testing"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInMemoryStream(t *testing.T) {
+	termLength := logbuffer.TermMinLength
+	pub, image := NewInMemoryStream("aeron:ipc", 10, 7, termLength)
+	assert.True(t, pub.IsConnected())
+	assert.Equal(t, int32(7), image.SessionID())
+	assert.Equal(t, termLength, image.TermBufferLength())
+
+	msg := atomic.MakeBuffer(make([]byte, 1000))
+	sent := 0
+	for {
+		msg.PutInt32(0, int32(sent))
+		result := pub.Offer(msg, 0, msg.Capacity(), nil)
+		if result == AdminAction {
+			continue
+		}
+		if result == BackPressured {
+			break
+		}
+		require.Greater(t, result, int64(0))
+		sent++
+	}
+	assert.Greater(t, pub.Position(), int64(termLength))
+
+	received := 0
+	for image.Poll(func(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+		assert.Equal(t, int32(1000), length)
+		assert.Equal(t, int32(received), buffer.GetInt32(offset))
+		assert.Equal(t, int32(10), header.StreamId())
+		assert.Equal(t, int32(7), header.SessionId())
+		received++
+	}, 100) > 0 {
+	}
+	assert.Equal(t, sent, received)
+	assert.False(t, image.IsEndOfStream())
+
+	require.NoError(t, pub.Close())
+	assert.True(t, pub.IsClosed())
+}
+
+func TestInMemoryStream_OfferV(t *testing.T) {
+	pub, image := NewInMemoryStream("aeron:ipc", 10, 7, logbuffer.TermMinLength*4)
+
+	parts := []string{"header:", "small body", ":trailer"}
+	vectors := make([]atomic.BufferVector, len(parts))
+	for i, part := range parts {
+		vectors[i] = atomic.BufferVector{Buffer: atomic.MakeBuffer([]byte("xx" + part)), Offset: 2, Length: int32(len(part))}
+	}
+	require.Greater(t, pub.OfferV(vectors, nil), int64(0))
+
+	// Larger than the max payload length, so fragmented with a vector spanning fragments
+	large := make([]byte, 20000)
+	for i := range large {
+		large[i] = byte(i)
+	}
+	largeVectors := []atomic.BufferVector{
+		{Buffer: atomic.MakeBuffer([]byte("begin")), Offset: 0, Length: 5},
+		{Buffer: atomic.MakeBuffer(large), Offset: 0, Length: int32(len(large))},
+		{Buffer: atomic.MakeBuffer([]byte("x")), Offset: 0, Length: 0},
+		{Buffer: atomic.MakeBuffer([]byte("end")), Offset: 0, Length: 3},
+	}
+	require.Greater(t, pub.OfferV(largeVectors, nil), int64(0))
+
+	var received [][]byte
+	assembler := NewFragmentAssembler(func(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+		received = append(received, buffer.GetBytesArray(offset, length))
+	}, DefaultFragmentAssemblyBufferLength)
+	for image.Poll(assembler.OnFragment, 10) > 0 {
+	}
+	require.Len(t, received, 2)
+	assert.Equal(t, "header:small body:trailer", string(received[0]))
+	expected := append(append([]byte("begin"), large...), "end"...)
+	assert.Equal(t, expected, received[1])
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package logbuffer
 
 import (
+	"math"
 	"unsafe"
 
 	"github.com/lirm/aeron-go/aeron/atomic"
@@ -29,6 +30,7 @@ var logger = logging.MustGetLogger("logbuffers")
 // LogBuffers is the struct providing access to the file or files representing the terms containing the ring buffer
 type LogBuffers struct {
 	mmapFiles []*memmap.File
+	heap      []byte // Backing memory when allocated by NewInMemory
 	buffers   [PartitionCount + 1]atomic.Buffer
 	meta      LogBufferMetaData
 	refCount  int
@@ -101,6 +103,44 @@ func Wrap(fileName string) *LogBuffers {
 	return buffers
 }
 
+// NewInMemory allocates LogBuffers on the heap, rather than mapping a file
+// created by the driver, for a stream which never leaves the process. The
+// metadata is initialised as the driver would for a new publication.
+func NewInMemory(termLength int32, initialTermID int32, mtuLength int32, sessionID int32, streamID int32) *LogBuffers {
+	checkTermLength(termLength)
+
+	buffers := new(LogBuffers)
+	buffers.heap = make([]byte, int(termLength)*PartitionCount+int(LogMetaDataLength))
+	for i := 0; i < PartitionCount; i++ {
+		buffers.buffers[i].Wrap(unsafe.Pointer(&buffers.heap[i*int(termLength)]), termLength)
+	}
+	metaData := buffers.heap[PartitionCount*int(termLength):]
+	buffers.buffers[LogMetaDataSectionIndex].Wrap(unsafe.Pointer(&metaData[0]), LogMetaDataLength)
+	buffers.meta.Wrap(&buffers.buffers[LogMetaDataSectionIndex], 0)
+
+	meta := &buffers.meta
+	meta.TailCounter[0].Set(int64(initialTermID) << 32)
+	for i := 1; i < PartitionCount; i++ {
+		meta.TailCounter[i].Set(int64(initialTermID+int32(i)-PartitionCount) << 32)
+	}
+	meta.EndOfStreamPosOff.Set(math.MaxInt64)
+	meta.IsConnected.Set(1)
+	meta.InitTermID.Set(initialTermID)
+	meta.DefaultFrameHdrLen.Set(DataFrameHeader_Length)
+	meta.MTULen.Set(mtuLength)
+	meta.TermLen.Set(termLength)
+	meta.PageSize.Set(pageMinSize)
+
+	header := meta.DefaultFrameHeader.Get()
+	header.PutInt32(DataFrameHeader_FrameLengthFieldOffset, DataFrameHeader_Length)
+	header.PutInt8(DataFrameHeader_VersionFieldOffset, DataFrameHeader_CurrentVersion)
+	header.PutUInt16(DataFrameHeader_TypeFieldOffset, DataFrameHeader_TypeData)
+	header.PutInt32(DataFrameHeader_SessionIDFieldOffset, sessionID)
+	header.PutInt32(DataFrameHeader_StreamIDFieldOffset, streamID)
+
+	return buffers
+}
+
 // Meta return log buffer meta data flyweight
 func (logBuffers *LogBuffers) Meta() *LogBufferMetaData {
 	return &logBuffers.meta
@@ -321,6 +321,117 @@ func (appender *Appender) AppendFragmentedMessage2(
 	return resultingOffset, termID
 }
 
+// AppendUnfragmentedMessageV appends the vectors, in order, as an unfragmented message in a single frame to the term
+func (appender *Appender) AppendUnfragmentedMessageV(
+	vectors []atomic.BufferVector, length int32,
+	reservedValueSupplier ReservedValueSupplier,
+) (resultingOffset int64, termID int32) {
+
+	frameLength := length + logbuffer.DataFrameHeader_Length
+	alignedLength := util.AlignInt32(frameLength, logbuffer.FrameAlignment)
+	rawTail := appender.getAndAddRawTail(alignedLength)
+	termLength := appender.termBuffer.Capacity()
+
+	termID = logbuffer.TermID(rawTail)
+	termOffset := rawTail & 0xFFFFFFFF
+	resultingOffset = termOffset + int64(alignedLength)
+	if resultingOffset > int64(termLength) {
+		resultingOffset = handleEndOfLogCondition(termID, appender.termBuffer, int32(termOffset),
+			&appender.headerWriter, termLength)
+	} else {
+		offset := int32(termOffset)
+		appender.headerWriter.write(appender.termBuffer, offset, frameLength, termID)
+		dataOffset := offset + logbuffer.DataFrameHeader_Length
+		for i := range vectors {
+			vector := &vectors[i]
+			appender.termBuffer.PutBytes(dataOffset, vector.Buffer, vector.Offset, vector.Length)
+			dataOffset += vector.Length
+		}
+
+		if nil != reservedValueSupplier {
+			reservedValue := reservedValueSupplier(appender.termBuffer, offset, frameLength)
+			appender.termBuffer.PutInt64(offset+logbuffer.DataFrameHeader_ReservedValueFieldOffset, reservedValue)
+		}
+
+		logbuffer.SetFrameLength(appender.termBuffer, offset, frameLength)
+	}
+
+	return resultingOffset, termID
+}
+
+// AppendFragmentedMessageV appends the vectors, in order and with a combined length greater than max frame
+// length, as a batch of fragments
+func (appender *Appender) AppendFragmentedMessageV(
+	vectors []atomic.BufferVector, length int32,
+	maxPayloadLength int32, reservedValueSupplier ReservedValueSupplier,
+) (resultingOffset int64, termID int32) {
+	numMaxPayloads := length / maxPayloadLength
+	remainingPayload := length % maxPayloadLength
+	var lastFrameLength int32
+	if remainingPayload > 0 {
+		lastFrameLength = util.AlignInt32(remainingPayload+logbuffer.DataFrameHeader_Length, logbuffer.FrameAlignment)
+	}
+	requiredLength := (numMaxPayloads * (maxPayloadLength + logbuffer.DataFrameHeader_Length)) + lastFrameLength
+	rawTail := appender.getAndAddRawTail(requiredLength)
+
+	termLength := appender.termBuffer.Capacity()
+
+	termID = logbuffer.TermID(rawTail)
+	termOffset := rawTail & 0xFFFFFFFF
+	resultingOffset = termOffset + int64(requiredLength)
+	if resultingOffset > int64(termLength) {
+		resultingOffset = handleEndOfLogCondition(termID, appender.termBuffer, int32(termOffset),
+			&appender.headerWriter, termLength)
+	} else {
+		flags := beginFrag
+		remaining := length
+		frameOffset := int32(termOffset)
+		var vectorIndex int
+		var vectorPos int32
+
+		for remaining > 0 {
+			bytesToWrite := minInt32(remaining, maxPayloadLength)
+			frameLength := bytesToWrite + logbuffer.DataFrameHeader_Length
+			alignedLength := util.AlignInt32(frameLength, logbuffer.FrameAlignment)
+
+			appender.headerWriter.write(appender.termBuffer, frameOffset, frameLength, termID)
+
+			var bytesWritten int32
+			payloadOffset := frameOffset + logbuffer.DataFrameHeader_Length
+			for bytesWritten < bytesToWrite {
+				vector := &vectors[vectorIndex]
+				numBytes := minInt32(bytesToWrite-bytesWritten, vector.Length-vectorPos)
+				if numBytes > 0 {
+					appender.termBuffer.PutBytes(payloadOffset, vector.Buffer, vector.Offset+vectorPos, numBytes)
+					bytesWritten += numBytes
+					payloadOffset += numBytes
+					vectorPos += numBytes
+				}
+				if vectorPos == vector.Length {
+					vectorIndex++
+					vectorPos = 0
+				}
+			}
+
+			if remaining <= maxPayloadLength {
+				flags |= endFrag
+			}
+			logbuffer.FrameFlags(appender.termBuffer, frameOffset, flags)
+
+			reservedValue := reservedValueSupplier(appender.termBuffer, frameOffset, frameLength)
+			appender.termBuffer.PutInt64(frameOffset+logbuffer.DataFrameHeader_ReservedValueFieldOffset, reservedValue)
+
+			logbuffer.SetFrameLength(appender.termBuffer, frameOffset, frameLength)
+
+			flags = 0
+			frameOffset += alignedLength
+			remaining -= bytesToWrite
+		}
+	}
+
+	return resultingOffset, termID
+}
+
 func handleEndOfLogCondition(termID int32, termBuffer *atomic.Buffer, termOffset int32, header *headerWriter,
 	termLength int32) int64 {
 	newOffset := AppenderFailed
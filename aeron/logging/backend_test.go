@@ -0,0 +1,73 @@
+package logging
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingBackend struct {
+	minLevel Level
+	messages []string
+	names    []string
+}
+
+func (r *recordingBackend) Enabled(level Level) bool {
+	return level >= r.minLevel
+}
+
+func (r *recordingBackend) Log(level Level, name string, msg string) {
+	r.names = append(r.names, name)
+	r.messages = append(r.messages, level.String()+" "+msg)
+}
+
+func TestSetBackend(t *testing.T) {
+	logger := MustGetLogger("backendtest")
+	backend := &recordingBackend{minLevel: DEBUG}
+	SetBackend(backend, "backendtest")
+	defer logger.SetBackend(nil)
+
+	logger.Infof("hello %d", 1)
+	logger.Debug("silent") // Below the logger's level
+	logger.SetLevel(DEBUG)
+	logger.Debug("now ", "logged")
+
+	assert.Equal(t, []string{"info hello 1", "debug now logged"}, backend.messages)
+	assert.Equal(t, []string{"backendtest", "backendtest"}, backend.names)
+
+	// The backend may filter further
+	backend.minLevel = WARNING
+	assert.False(t, logger.IsEnabledFor(INFO))
+	logger.Info("filtered")
+	assert.Len(t, backend.messages, 2)
+
+	logger.SetBackend(nil)
+	assert.Equal(t, Default(), logger.Backend())
+}
+
+func TestSetDefault(t *testing.T) {
+	logger := MustGetLogger("defaulttest")
+	previous := Default()
+	backend := &recordingBackend{minLevel: DEBUG}
+	SetDefault(backend)
+	defer SetDefault(previous)
+
+	logger.Warning("to default")
+	assert.Equal(t, []string{"warn to default"}, backend.messages)
+	assert.NotNil(t, logger.Logger()) // No-op when the backend is not zap
+}
+
+func TestWithBackend(t *testing.T) {
+	logger := MustGetLogger("withtest")
+	backend := &recordingBackend{minLevel: DEBUG}
+	copied := logger.WithBackend(backend)
+
+	copied.Info("copied")
+	SetLevel(ERROR, "withtest")
+	assert.EqualValues(t, ERROR, copied.GetLevel())
+	copied.Info("silent")
+	copied.Error("error")
+
+	assert.Equal(t, []string{"info copied", "error error"}, backend.messages)
+	assert.Equal(t, Default(), logger.Backend())
+}
@@ -17,21 +17,40 @@
 //
 // This provides a largely api compatible layer so we can quickly
 // drop in a replacement.
+//
+// Named loggers hold their level and format their messages, which they
+// pass to a Logger backend. The default backend is zap, configured as
+// before, and may be replaced process wide with SetDefault(), per named
+// logger with SetBackend(), or for a copy of a logger with WithBackend().
 package logging
 
 import (
+	"fmt"
+	"os"
 	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
 
+// Level of a log message, using the zap levels
+type Level = zapcore.Level
+
+// Logger is the interface of a logging backend. Named loggers only call Log
+// for messages at or above their level, and first check Enabled so a backend
+// may filter further without the message being formatted.
+type Logger interface {
+	Enabled(level Level) bool
+	Log(level Level, name string, msg string)
+}
+
 // Zaplogger is a container to wrap zap logging with the parts of the go-logging API we use
 type ZapLogger struct {
-	name   string
-	sugar  *zap.SugaredLogger
-	logger *zap.Logger
-	config zap.Config
+	name    string
+	config  zap.Config
+	parent  *ZapLogger // Set by WithBackend, which shares the parent's level
+	backend atomic.Pointer[Logger]
 }
 
 // Mapping of go-logging to zap log levels. It's imperfect but good enough
@@ -50,18 +69,34 @@ const (
 // mechanism for that
 var namedLoggers sync.Map // [string]*ZapLogger
 
+// defaultBackend is used by named loggers without a backend of their own
+var defaultBackend atomic.Pointer[Logger]
+
+func init() {
+	config := aeronLoggingConfig()
+	config.Level = zap.NewAtomicLevelAt(zap.DebugLevel) // Named loggers hold the level
+	logger, err := config.Build()
+	if err != nil {
+		panic("Failed to make logger")
+	}
+	SetDefault(NewZapAdapter(logger))
+}
+
+// SetDefault sets the backend of all named loggers which have not had one set
+func SetDefault(l Logger) {
+	defaultBackend.Store(&l)
+}
+
+// Default returns the backend of named loggers which have not had one set
+func Default() Logger {
+	return *defaultBackend.Load()
+}
+
 // MustGetLogger returns a new logger or panic()s
 func MustGetLogger(name string) *ZapLogger {
 	z := new(ZapLogger)
 	z.name = name
-
 	z.config = aeronLoggingConfig()
-	logger, err := z.config.Build()
-	if err != nil {
-		panic("Failed to make logger")
-	}
-	z.logger = logger.Named(name).WithOptions(zap.AddCallerSkip(1))
-	z.sugar = z.logger.Sugar()
 
 	// Keep a reference
 	namedLoggers.Store(name, z)
@@ -103,16 +138,15 @@ func aeronLoggingConfig() zap.Config {
 	}
 }
 
-// SetConfigAndRebuild so you can replace the default config
+// SetConfigAndRebuild so you can replace the default config. The logger
+// takes its level from the config and logs to a zap backend built from it.
 func (z *ZapLogger) SetConfigAndRebuild(c zap.Config) error {
-	z.config = c
-	logger, err := z.config.Build()
+	logger, err := c.Build()
 	if err != nil {
 		return err
 	}
-
-	z.logger = logger.Named(z.name)
-	z.sugar = z.logger.Sugar()
+	z.config = c
+	z.SetBackend(NewZapAdapter(logger))
 	return nil
 }
 
@@ -137,97 +171,163 @@ func GetLevel(name string) zapcore.Level {
 	return zapcore.InfoLevel
 }
 
-// Sugar returns the internal Sugared logger
+// SetBackend on a named logger
+func SetBackend(l Logger, name string) {
+	z, ok := namedLoggers.Load(name)
+	if ok {
+		zlogger := z.(*ZapLogger)
+		zlogger.SetBackend(l)
+	}
+}
+
+// SetBackend sets the backend of this logger, or restores the default if nil
+func (z *ZapLogger) SetBackend(l Logger) {
+	if l == nil {
+		z.backend.Store(nil)
+		return
+	}
+	z.backend.Store(&l)
+}
+
+// Backend returns the backend this logger logs to
+func (z *ZapLogger) Backend() Logger {
+	if l := z.backend.Load(); l != nil {
+		return *l
+	}
+	return Default()
+}
+
+// WithBackend returns a copy of the logger which logs to a different backend,
+// sharing the name and level of this logger
+func (z *ZapLogger) WithBackend(l Logger) *ZapLogger {
+	c := &ZapLogger{name: z.name, parent: z.root()}
+	c.SetBackend(l)
+	return c
+}
+
+func (z *ZapLogger) root() *ZapLogger {
+	if z.parent != nil {
+		return z.parent
+	}
+	return z
+}
+
+// Sugar returns a Sugared logger for the backend, see Logger()
 func (z *ZapLogger) Sugar() *zap.SugaredLogger {
-	return z.sugar
+	return z.Logger().Sugar()
 }
 
-// SetSugar sets our internal Sugared logger
+// SetSugar sets the backend to the Sugared logger
 func (z *ZapLogger) SetSugar(s *zap.SugaredLogger) {
-	z.sugar = s
+	z.SetLogger(s.Desugar())
 }
 
-// Logger returns the internal zap logger
+// Logger returns the zap logger of the backend, named after this logger, or a
+// no-op logger if the backend is not zap
 func (z *ZapLogger) Logger() *zap.Logger {
-	return z.logger
+	if a, ok := z.Backend().(*zapAdapter); ok {
+		return a.named(z.name).WithOptions(zap.AddCallerSkip(-zapAdapterCallerSkip))
+	}
+	return zap.NewNop()
 }
 
-// SetLogger sets the internal zap logger
+// SetLogger sets the backend to the zap logger
 func (z *ZapLogger) SetLogger(l *zap.Logger) {
-	z.logger = l
+	z.SetBackend(NewZapAdapter(l))
 }
 
 // SetLevel sets the log level at which we will log
 func (z *ZapLogger) SetLevel(l zapcore.Level) {
-	z.config.Level.SetLevel(l)
+	z.root().config.Level.SetLevel(l)
 }
 
 // GetLevel returns the log level at which we will log
 func (z *ZapLogger) GetLevel() zapcore.Level {
-	return z.config.Level.Level()
+	return z.root().config.Level.Level()
 }
 
 // IsEnabledFor returns true if logging is enabled for the specified level
 func (z *ZapLogger) IsEnabledFor(level zapcore.Level) bool {
-	return level >= z.GetLevel()
+	return level >= z.GetLevel() && z.Backend().Enabled(level)
+}
+
+func (z *ZapLogger) logf(level Level, template string, args []interface{}) {
+	if !z.IsEnabledFor(level) {
+		return
+	}
+	z.Backend().Log(level, z.name, fmt.Sprintf(template, args...))
+}
+
+func (z *ZapLogger) log(level Level, args []interface{}) {
+	if !z.IsEnabledFor(level) {
+		return
+	}
+	z.Backend().Log(level, z.name, fmt.Sprint(args...))
+}
+
+// fatal logs at log level Fatal, regardless of the logger's level, then exits
+// as zap would, whatever the backend
+func (z *ZapLogger) fatal(msg string) {
+	z.Backend().Log(zapcore.FatalLevel, z.name, msg)
+	os.Exit(1)
 }
 
 // Fatalf logs a formatted string at log level Fatal and will then always exit()
 func (z *ZapLogger) Fatalf(template string, args ...interface{}) {
-	z.sugar.Fatalf(template, args...)
+	z.fatal(fmt.Sprintf(template, args...))
 }
 
 // Errorf logs a formatted string at log level Error
 func (z *ZapLogger) Errorf(template string, args ...interface{}) {
-	z.sugar.Errorf(template, args...)
+	z.logf(zapcore.ErrorLevel, template, args)
 }
 
 // Warningf logs a formatted string at log level Warning
 func (z *ZapLogger) Warningf(template string, args ...interface{}) {
-	z.sugar.Warnf(template, args...)
+	z.logf(zapcore.WarnLevel, template, args)
 }
 
 // Infof logs a formatted string at log level Info
 func (z *ZapLogger) Infof(template string, args ...interface{}) {
-	z.sugar.Infof(template, args...)
+	z.logf(zapcore.InfoLevel, template, args)
 }
 
 // Noticef logs a formatted string at log level *Info*
 func (z *ZapLogger) Noticef(template string, args ...interface{}) {
-	z.sugar.Infof(template, args...)
+	z.logf(zapcore.InfoLevel, template, args)
 }
 
 // Debugf logs a formatted string at log level Debug
 func (z *ZapLogger) Debugf(template string, args ...interface{}) {
-	z.sugar.Debugf(template, args...)
+	z.logf(zapcore.DebugLevel, template, args)
 }
 
-// Fatal logs it's arguments at log level Fatal
+// Fatal logs it's arguments at log level Fatal and will then always exit()
 func (z *ZapLogger) Fatal(args ...interface{}) {
-	z.sugar.Fatal(args...)
+	z.fatal(fmt.Sprint(args...))
 }
 
 // Error logs it's arguments at log level Error
 func (z *ZapLogger) Error(args ...interface{}) {
-	z.sugar.Error(args...)
+	z.log(zapcore.ErrorLevel, args)
 }
 
 // Warning logs it's arguments at log level Warning
 func (z *ZapLogger) Warning(args ...interface{}) {
-	z.sugar.Warn(args...)
+	z.log(zapcore.WarnLevel, args)
 }
 
 // Info logs it's arguments at log level Info
 func (z *ZapLogger) Info(args ...interface{}) {
-	z.sugar.Info(args...)
+	z.log(zapcore.InfoLevel, args)
 }
 
 // Notice logs it's arguments at log level *Info*
 func (z *ZapLogger) Notice(args ...interface{}) {
-	z.sugar.Info(args...)
+	z.log(zapcore.InfoLevel, args)
 }
 
 // Debug logs it's arguments at log level Debug
 func (z *ZapLogger) Debug(args ...interface{}) {
-	z.sugar.Debug(args...)
+	z.log(zapcore.DebugLevel, args)
 }
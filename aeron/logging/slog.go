@@ -0,0 +1,75 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.21
+
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// slogAdapterCallerSkip skips runtime.Callers, the adapter and the named
+// logger so the source reported is the code which logged
+const slogAdapterCallerSkip = 4
+
+// SlogNameKey is the attribute holding the name of the logger, matching the
+// key of the default zap encoder
+const SlogNameKey = "logger"
+
+// slogAdapter is a Logger backend writing to a slog logger
+type slogAdapter struct {
+	handler slog.Handler
+}
+
+// NewSlogAdapter returns a Logger backend which writes to the slog logger,
+// with the name of each named logger as the SlogNameKey attribute. Zap
+// levels above error are logged at slog.LevelError.
+func NewSlogAdapter(logger *slog.Logger) Logger {
+	return &slogAdapter{handler: logger.Handler()}
+}
+
+// SlogLevel maps a zap level to its slog level
+func SlogLevel(level Level) slog.Level {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return slog.LevelDebug
+	case level == zapcore.InfoLevel:
+		return slog.LevelInfo
+	case level == zapcore.WarnLevel:
+		return slog.LevelWarn
+	default:
+		return slog.LevelError
+	}
+}
+
+func (a *slogAdapter) Enabled(level Level) bool {
+	return a.handler.Enabled(context.Background(), SlogLevel(level))
+}
+
+func (a *slogAdapter) Log(level Level, name string, msg string) {
+	ctx := context.Background()
+	slevel := SlogLevel(level)
+	if !a.handler.Enabled(ctx, slevel) {
+		return
+	}
+	var pcs [1]uintptr
+	runtime.Callers(slogAdapterCallerSkip, pcs[:])
+	r := slog.NewRecord(time.Now(), slevel, msg, pcs[0])
+	r.AddAttrs(slog.String(SlogNameKey, name))
+	_ = a.handler.Handle(ctx, r)
+}
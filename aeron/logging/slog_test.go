@@ -0,0 +1,38 @@
+//go:build go1.21
+
+package logging
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlogAdapter(t *testing.T) {
+	var out bytes.Buffer
+	handler := slog.NewJSONHandler(&out, &slog.HandlerOptions{Level: slog.LevelInfo, AddSource: true})
+	logger := MustGetLogger("slogtest")
+	logger.SetBackend(NewSlogAdapter(slog.New(handler)))
+	logger.SetLevel(DEBUG)
+
+	logger.Debugf("filtered by the handler")
+	assert.False(t, logger.IsEnabledFor(DEBUG))
+	logger.Warningf("disk %d%% full", 90)
+
+	logged := out.String()
+	assert.NotContains(t, logged, "filtered")
+	assert.Contains(t, logged, `"level":"WARN"`)
+	assert.Contains(t, logged, `"msg":"disk 90% full"`)
+	assert.Contains(t, logged, `"logger":"slogtest"`)
+	assert.Contains(t, logged, "slog_test.go") // The source is the caller
+}
+
+func TestSlogLevel(t *testing.T) {
+	assert.Equal(t, slog.LevelDebug, SlogLevel(DEBUG))
+	assert.Equal(t, slog.LevelInfo, SlogLevel(INFO))
+	assert.Equal(t, slog.LevelWarn, SlogLevel(WARNING))
+	assert.Equal(t, slog.LevelError, SlogLevel(ERROR))
+	assert.Equal(t, slog.LevelError, SlogLevel(CRITICAL))
+}
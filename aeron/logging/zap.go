@@ -0,0 +1,53 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"sync"
+
+	"go.uber.org/zap"
+)
+
+// zapAdapterCallerSkip skips the adapter and the named logger so the caller
+// reported is the code which logged
+const zapAdapterCallerSkip = 3
+
+// zapAdapter is a Logger backend writing to a zap logger
+type zapAdapter struct {
+	logger  *zap.Logger
+	loggers sync.Map // [string]*zap.Logger named after each named logger
+}
+
+// NewZapAdapter returns a Logger backend which writes to the zap logger,
+// named after each named logger
+func NewZapAdapter(logger *zap.Logger) Logger {
+	return &zapAdapter{logger: logger.WithOptions(zap.AddCallerSkip(zapAdapterCallerSkip))}
+}
+
+func (a *zapAdapter) named(name string) *zap.Logger {
+	if l, ok := a.loggers.Load(name); ok {
+		return l.(*zap.Logger)
+	}
+	l, _ := a.loggers.LoadOrStore(name, a.logger.Named(name))
+	return l.(*zap.Logger)
+}
+
+func (a *zapAdapter) Enabled(level Level) bool {
+	return a.logger.Core().Enabled(level)
+}
+
+func (a *zapAdapter) Log(level Level, name string, msg string) {
+	if ce := a.named(name).Check(level, msg); ce != nil {
+		ce.Write()
+	}
+}
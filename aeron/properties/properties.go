@@ -0,0 +1,343 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package properties reads configuration using the standard Aeron property
+// names, such as aeron.dir, from Java style properties files and environment
+// variables, so Go and Java processes can share their deployment config.
+//
+// An environment variable names a property in upper case with its dots
+// replaced by underscores, so AERON_DIR sets aeron.dir. Only variables
+// starting with AERON_ are read.
+//
+// Sources are merged with explicit precedence, later ones overriding earlier
+// ones: the defaults of the options being loaded, then each properties file in
+// the order given, then the environment. An empty value is treated as unset.
+package properties
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix is the prefix of the environment variables holding properties
+const EnvPrefix = "AERON_"
+
+// Properties holds values keyed by property name
+type Properties map[string]string
+
+// EnvName returns the name of the environment variable setting the property
+func EnvName(key string) string {
+	return strings.ToUpper(strings.ReplaceAll(key, ".", "_"))
+}
+
+// FromEnviron returns the properties set by the environment variables, given
+// as "NAME=value" like os.Environ()
+func FromEnviron(environ []string) Properties {
+	props := make(Properties)
+	for _, kv := range environ {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !strings.HasPrefix(name, EnvPrefix) {
+			continue
+		}
+		props[strings.ToLower(strings.ReplaceAll(name, "_", "."))] = value
+	}
+	return props
+}
+
+// FromEnv returns the properties set by the process's environment
+func FromEnv() Properties {
+	return FromEnviron(os.Environ())
+}
+
+// Merge returns the properties of all the layers, later layers overriding
+// earlier ones
+func Merge(layers ...Properties) Properties {
+	props := make(Properties)
+	for _, layer := range layers {
+		for k, v := range layer {
+			props[k] = v
+		}
+	}
+	return props
+}
+
+// Load reads the properties files in order, then the environment, and merges
+// them, later sources overriding earlier ones
+func Load(files ...string) (Properties, error) {
+	layers := make([]Properties, 0, len(files)+1)
+	for _, file := range files {
+		props, err := LoadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, props)
+	}
+	layers = append(layers, FromEnv())
+	return Merge(layers...), nil
+}
+
+// LoadFile reads a Java style properties file
+func LoadFile(path string) (Properties, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	props, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return props, nil
+}
+
+// Parse reads properties in the Java properties file format: "key=value",
+// "key: value" or "key value" lines, with # or ! starting a comment line, a
+// trailing backslash continuing a line, and backslash escapes including \uXXXX
+func Parse(r io.Reader) (Properties, error) {
+	props := make(Properties)
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		start := lineNumber
+		line := strings.TrimLeft(scanner.Text(), " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
+			continue
+		}
+		for continues(line) && scanner.Scan() {
+			lineNumber++
+			line = line[:len(line)-1] + strings.TrimLeft(scanner.Text(), " \t\f")
+		}
+		if continues(line) {
+			line = line[:len(line)-1]
+		}
+		key, value := splitLine(line)
+		k, err := unescape(key)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+		v, err := unescape(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", start, err)
+		}
+		props[k] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return props, nil
+}
+
+// continues reports whether the line ends with an unescaped backslash
+func continues(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitLine splits a line at the first unescaped '=', ':' or whitespace
+func splitLine(line string) (key, value string) {
+	i := 0
+	for ; i < len(line); i++ {
+		c := line[i]
+		if c == '\\' {
+			i++
+			continue
+		}
+		if c == '=' || c == ':' || c == ' ' || c == '\t' || c == '\f' {
+			break
+		}
+	}
+	if i >= len(line) {
+		return line, ""
+	}
+	key = line[:i]
+	value = strings.TrimLeft(line[i+1:], " \t\f")
+	if sep := line[i]; sep != '=' && sep != ':' && value != "" && (value[0] == '=' || value[0] == ':') {
+		value = strings.TrimLeft(value[1:], " \t\f")
+	}
+	return key, value
+}
+
+func unescape(s string) (string, error) {
+	if !strings.Contains(s, "\\") {
+		return s, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			r, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("malformed \\u escape in %q", s)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// Lookup returns the trimmed value of the property, and whether it is set
+func (p Properties) Lookup(key string) (string, bool) {
+	value := strings.TrimSpace(p[key])
+	return value, value != ""
+}
+
+// Var calls set with the value of the property if it is set, returning its
+// error annotated with the property
+func (p Properties) Var(key string, set func(value string) error) error {
+	value, ok := p.Lookup(key)
+	if !ok {
+		return nil
+	}
+	if err := set(value); err != nil {
+		return fmt.Errorf("invalid %s (%s) %q: %w", key, EnvName(key), value, err)
+	}
+	return nil
+}
+
+// StringVar sets dst to the property if it is set
+func (p Properties) StringVar(dst *string, key string) error {
+	return p.Var(key, func(value string) error {
+		*dst = value
+		return nil
+	})
+}
+
+// ChannelVar sets dst to the property if it is set, failing if validate
+// rejects it, e.g. aeron.ValidateChannelUri
+func (p Properties) ChannelVar(dst *string, key string, validate func(channel string) error) error {
+	return p.Var(key, func(value string) error {
+		if err := validate(value); err != nil {
+			return err
+		}
+		*dst = value
+		return nil
+	})
+}
+
+// Int32Var sets dst to the property if it is set, failing if it is not a
+// decimal int32
+func (p Properties) Int32Var(dst *int32, key string) error {
+	return p.Var(key, func(value string) error {
+		v, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return errors.Unwrap(err)
+		}
+		*dst = int32(v)
+		return nil
+	})
+}
+
+// IntVar sets dst to the property if it is set, failing if it is not a
+// decimal int
+func (p Properties) IntVar(dst *int, key string) error {
+	return p.Var(key, func(value string) error {
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.Unwrap(err)
+		}
+		*dst = v
+		return nil
+	})
+}
+
+// BoolVar sets dst to the property if it is set, failing unless it is true or
+// false in any case
+func (p Properties) BoolVar(dst *bool, key string) error {
+	return p.Var(key, func(value string) error {
+		switch {
+		case strings.EqualFold(value, "true"):
+			*dst = true
+		case strings.EqualFold(value, "false"):
+			*dst = false
+		default:
+			return errors.New("expected true or false")
+		}
+		return nil
+	})
+}
+
+// DurationVar sets dst to the property if it is set. The value is a duration
+// such as "10s" or "500us", or a number in units of unit, matching the Java
+// property's unit, e.g. time.Nanosecond for aeron.client.liveness.timeout and
+// time.Millisecond for aeron.driver.timeout. Negative durations fail.
+func (p Properties) DurationVar(dst *time.Duration, key string, unit time.Duration) error {
+	return p.Var(key, func(value string) error {
+		d, err := ParseDuration(value, unit)
+		if err != nil {
+			return err
+		}
+		*dst = d
+		return nil
+	})
+}
+
+// TimeoutVar is DurationVar, failing unless the duration is positive
+func (p Properties) TimeoutVar(dst *time.Duration, key string, unit time.Duration) error {
+	return p.Var(key, func(value string) error {
+		d, err := ParseDuration(value, unit)
+		if err != nil {
+			return err
+		}
+		if d == 0 {
+			return errors.New("timeout must be positive")
+		}
+		*dst = d
+		return nil
+	})
+}
+
+// ParseDuration parses a duration such as "10s", or a number in units of unit
+func ParseDuration(value string, unit time.Duration) (time.Duration, error) {
+	var d time.Duration
+	if n, err := strconv.ParseInt(value, 10, 64); err == nil {
+		d = time.Duration(n) * unit
+		if unit != 0 && d/unit != time.Duration(n) {
+			return 0, errors.New("duration out of range")
+		}
+	} else if d, err = time.ParseDuration(value); err != nil {
+		return 0, errors.New("expected a duration such as 10s, or a number")
+	}
+	if d < 0 {
+		return 0, errors.New("duration must not be negative")
+	}
+	return d, nil
+}
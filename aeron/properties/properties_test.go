@@ -0,0 +1,133 @@
+package properties
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	props, err := Parse(strings.NewReader(`
+# comment
+! also a comment
+aeron.dir=/dev/shm/aeron
+aeron.driver.timeout : 10000
+aeron.archive.control.channel aeron:udp?endpoint=localhost:8010\
+    |term-length=64k
+key\ with\ spaces = \u0041\tb
+empty=
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Properties{
+		"aeron.dir":                     "/dev/shm/aeron",
+		"aeron.driver.timeout":          "10000",
+		"aeron.archive.control.channel": "aeron:udp?endpoint=localhost:8010|term-length=64k",
+		"key with spaces":               "A\tb",
+		"empty":                         "",
+	}, props)
+
+	_, err = Parse(strings.NewReader("a=1\nb=\\u00g1\n"))
+	assert.ErrorContains(t, err, "line 2")
+}
+
+func TestFromEnviron(t *testing.T) {
+	props := FromEnviron([]string{"AERON_DIR=/tmp/aeron", "AERON_CLIENT_LIVENESS_TIMEOUT=5s", "HOME=/root", "AERON_BAD"})
+	assert.Equal(t, Properties{"aeron.dir": "/tmp/aeron", "aeron.client.liveness.timeout": "5s"}, props)
+	assert.Equal(t, "AERON_CLIENT_LIVENESS_TIMEOUT", EnvName("aeron.client.liveness.timeout"))
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "first.properties")
+	second := filepath.Join(dir, "second.properties")
+	require.NoError(t, os.WriteFile(first, []byte("aeron.dir=/first\naeron.cluster.id=1\naeron.cluster.service.id=1\n"), 0644))
+	require.NoError(t, os.WriteFile(second, []byte("aeron.cluster.id=2\naeron.cluster.service.id=2\n"), 0644))
+	t.Setenv("AERON_CLUSTER_SERVICE_ID", "3")
+
+	props, err := Load(first, second)
+	require.NoError(t, err)
+	assert.Equal(t, "/first", props["aeron.dir"])
+	assert.Equal(t, "2", props["aeron.cluster.id"])
+	assert.Equal(t, "3", props["aeron.cluster.service.id"])
+
+	_, err = Load(filepath.Join(dir, "missing.properties"))
+	assert.Error(t, err)
+}
+
+func TestVars(t *testing.T) {
+	props := Properties{
+		"str":      " value ",
+		"int":      "42",
+		"bool":     "TRUE",
+		"millis":   "250",
+		"duration": "1.5s",
+		"blank":    "  ",
+	}
+	s, i, b, d, ns := "default", int32(0), false, time.Duration(0), time.Duration(0)
+	assert.NoError(t, props.StringVar(&s, "str"))
+	assert.NoError(t, props.Int32Var(&i, "int"))
+	assert.NoError(t, props.BoolVar(&b, "bool"))
+	assert.NoError(t, props.DurationVar(&d, "millis", time.Millisecond))
+	assert.NoError(t, props.TimeoutVar(&ns, "duration", time.Nanosecond))
+	assert.Equal(t, "value", s)
+	assert.Equal(t, int32(42), i)
+	assert.True(t, b)
+	assert.Equal(t, 250*time.Millisecond, d)
+	assert.Equal(t, 1500*time.Millisecond, ns)
+
+	assert.NoError(t, props.StringVar(&s, "blank"))
+	assert.NoError(t, props.StringVar(&s, "missing"))
+	assert.Equal(t, "value", s)
+}
+
+func TestVars_Invalid(t *testing.T) {
+	props := Properties{
+		"aeron.stream.id": "1e3",
+		"aeron.big":       "4294967296",
+		"aeron.flag":      "yes",
+		"aeron.timeout":   "-1s",
+		"aeron.zero":      "0",
+		"aeron.period":    "soon",
+	}
+	var i int32
+	var b bool
+	var d time.Duration
+	err := props.Int32Var(&i, "aeron.stream.id")
+	assert.EqualError(t, err, `invalid aeron.stream.id (AERON_STREAM_ID) "1e3": invalid syntax`)
+	assert.ErrorContains(t, props.Int32Var(&i, "aeron.big"), "out of range")
+	assert.ErrorContains(t, props.BoolVar(&b, "aeron.flag"), "expected true or false")
+	assert.ErrorContains(t, props.DurationVar(&d, "aeron.timeout", time.Nanosecond), "must not be negative")
+	assert.ErrorContains(t, props.TimeoutVar(&d, "aeron.zero", time.Nanosecond), "must be positive")
+	assert.ErrorContains(t, props.DurationVar(&d, "aeron.period", time.Nanosecond), "expected a duration")
+	assert.Zero(t, i)
+	assert.False(t, b)
+	assert.Zero(t, d)
+}
+
+func TestChannelVar(t *testing.T) {
+	props := Properties{
+		"aeron.channel": "aeron:ipc",
+		"aeron.bad":     "ipc",
+	}
+	validate := func(channel string) error {
+		if !strings.HasPrefix(channel, "aeron:") {
+			return errors.New("expected an aeron URI")
+		}
+		return nil
+	}
+	channel := "aeron:udp"
+	assert.NoError(t, props.ChannelVar(&channel, "aeron.channel", validate))
+	assert.Equal(t, "aeron:ipc", channel)
+	assert.NoError(t, props.ChannelVar(&channel, "aeron.missing", validate))
+	assert.Equal(t, "aeron:ipc", channel)
+
+	err := props.ChannelVar(&channel, "aeron.bad", validate)
+	assert.EqualError(t, err, `invalid aeron.bad (AERON_BAD) "ipc": expected an aeron URI`)
+	assert.Equal(t, "aeron:ipc", channel)
+}
@@ -123,6 +123,12 @@ func (pub *Publication) InitialTermID() int32 {
 	return pub.initialTermID
 }
 
+// MaxMessageLength returns the maximum length of a message that can be offered,
+// messages longer than the MTU are fragmented up to this length.
+func (pub *Publication) MaxMessageLength() int32 {
+	return pub.maxMessageLength
+}
+
 // IsConnected returns whether this publication is connected to the driver (not whether it has any Subscriptions)
 func (pub *Publication) IsConnected() bool {
 	return !pub.IsClosed() && pub.metaData.IsConnected.Get() == 1
@@ -142,6 +148,10 @@ func (pub *Publication) IsOriginal() bool {
 func (pub *Publication) Close() error {
 	// FIXME Why can pub be nil?!
 	if pub != nil && pub.isClosed.CompareAndSet(false, true) {
+		if pub.conductor == nil {
+			// In memory, see NewInMemoryStream
+			return nil
+		}
 		return pub.conductor.releasePublication(pub.regID)
 	}
 
@@ -267,6 +277,55 @@ func (pub *Publication) Offer2(
 	return pub.newPosition(termCount, termOffset, termId, position, resultingOffset)
 }
 
+// OfferV attempts to publish a message gathered, in order, from the vectors
+func (pub *Publication) OfferV(vectors []atomic.BufferVector, reservedValueSupplier term.ReservedValueSupplier) int64 {
+	length := int32(0)
+	for i := range vectors {
+		if vectors[i].Length < 0 {
+			logger.Debugf("Offered negative length (vector %d length: %d)", i, vectors[i].Length)
+			return 0
+		}
+		length += vectors[i].Length
+		if length < 0 {
+			panic(fmt.Sprintf("Length overflow (vector %d length: %d)", i, vectors[i].Length))
+		}
+	}
+	if pub.IsClosed() {
+		return PublicationClosed
+	}
+
+	if reservedValueSupplier == nil {
+		reservedValueSupplier = term.DefaultReservedValueSupplier
+	}
+
+	limit := pub.pubLimit.get()
+	termCount := pub.metaData.ActiveTermCountOff.Get()
+	termIndex := termCount % logbuffer.PartitionCount
+	termAppender := pub.appenders[termIndex]
+	rawTail := termAppender.RawTail()
+	termOffset := rawTail & 0xFFFFFFFF
+	termId := logbuffer.TermID(rawTail)
+	position := computeTermBeginPosition(termId, pub.positionBitsToShift, pub.initialTermID) + termOffset
+
+	if termCount != (termId - pub.metaData.InitTermID.Get()) {
+		return AdminAction
+	}
+
+	if position >= limit {
+		return pub.backPressureStatus(position, length)
+	}
+
+	var resultingOffset int64
+	if length <= pub.maxPayloadLength {
+		resultingOffset, termId = termAppender.AppendUnfragmentedMessageV(vectors, length, reservedValueSupplier)
+	} else {
+		pub.checkForMaxMessageLength(length)
+		resultingOffset, termId = termAppender.AppendFragmentedMessageV(
+			vectors, length, pub.maxPayloadLength, reservedValueSupplier)
+	}
+	return pub.newPosition(termCount, termOffset, termId, position, resultingOffset)
+}
+
 func (pub *Publication) newPosition(termCount int32, termOffset int64, termId int32, position int64, resultingOffset int64) int64 {
 	if resultingOffset > 0 {
 		return (position - termOffset) + resultingOffset
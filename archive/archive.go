@@ -41,6 +41,9 @@ type Archive struct {
 	Events       *RecordingEventsAdapter // For async recording events (must be enabled)
 	Listeners    *ArchiveListeners       // Per client event listeners for async callbacks
 	mtx          sync.Mutex              // To ensure no overlapped I/O on archive RPC calls
+
+	protocolVersion     int32    // Semantic version of the archive protocol reported on connect
+	replicationSessions sync.Map // [int64]*ReplicationSession by replication id
 }
 
 // Constant values used to control behaviour of StartReplay
@@ -50,6 +53,9 @@ const (
 	RecordingLengthMax    = int64(2<<31 - 1) // Replay the whole stream
 )
 
+// RecordingTimestampNull is the stop timestamp of a recording which is active
+const RecordingTimestampNull = int64(-1)
+
 // replication flag used for duplication instead of extension, see Replicate and variants
 const (
 	RecordingIdNullValue = int32(-1)
@@ -81,7 +87,8 @@ type ArchiveListeners struct {
 	RecordingEventProgressListener func(*codecs.RecordingProgress)
 	RecordingEventStoppedListener  func(*codecs.RecordingStopped)
 
-	// Async protocol event
+	// Async protocol event. The event is reused by the next poll, so copy
+	// it to keep it beyond the call.
 	RecordingSignalListener func(*codecs.RecordingSignalEvent)
 
 	// Async events from the underlying Aeron instance
@@ -303,8 +310,9 @@ func NewArchive(options *Options, context *aeron.Context) (*Archive, error) {
 	defer correlations.Delete(correlationID)           // Clear the lookup
 
 	// Use Auth if requested
-	if archive.Options.AuthEnabled {
-		if err = archive.Proxy.AuthConnectRequest(correlationID, archive.Options.ResponseStream, responseChannel, archive.Options.AuthCredentials); err != nil {
+	if archive.Options.AuthEnabled || archive.Options.CredentialsSupplier != nil {
+		credentials := archive.Options.credentialsSupplier().EncodedCredentials()
+		if err = archive.Proxy.AuthConnectRequest(correlationID, archive.Options.ResponseStream, responseChannel, credentials); err != nil {
 			logger.Errorf("AuthConnectRequest failed: %s", err)
 			return nil, err
 		}
@@ -401,6 +409,13 @@ func (archive *Archive) SetAeronPublicationConnectionTimeout(timeout time.Durati
 	archive.aeronContext.PublicationConnectionTimeout(timeout)
 }
 
+// ProtocolVersion of the connected archive as a semantic version, see
+// util.SemanticVersionToString(). Used to determine which requests
+// the archive supports.
+func (archive *Archive) ProtocolVersion() int32 {
+	return archive.protocolVersion
+}
+
 // AeronCncFileName returns the name of the Counters file
 func (archive *Archive) AeronCncFileName() string {
 	return archive.aeronContext.CncFileName()
@@ -811,6 +826,60 @@ func (archive *Archive) BoundedReplay(recordingID int64, position int64, length
 	return archive.Control.PollForResponse(correlationID, archive.SessionID)
 }
 
+// StartReplayWithParams to start a replay of a recording using the
+// optional parameters in a ReplayParams. If the params specify a
+// BoundingLimitCounterId then the replay is bounded as per BoundedReplay.
+//
+// Setting a ReplayToken requires an archive which supports the newer
+// protocol, see ProtocolVersion(), and fails otherwise.
+//
+// Returns a ReplaySessionID - the id of the replay session which will be the same as the Image sessionId
+// of the received replay for correlation with the matching session id in the lower 32 bits
+func (archive *Archive) StartReplayWithParams(recordingID int64, replayChannel string, replayStream int32, params *ReplayParams) (int64, error) {
+	if params.ReplayToken != aeron.NullValue {
+		if err := archive.checkProtocolVersion(ProtocolVersionWithReplayTokens, "ReplayToken"); err != nil {
+			return 0, err
+		}
+	}
+
+	correlationID := nextCorrelationID()
+	logger.Debugf("StartReplayWithParams(%d, %s, %d, %#v), correlationID:%d", recordingID, replayChannel, replayStream, params, correlationID)
+	correlations.Store(correlationID, archive.Control) // For subsequent lookup in the fragment assemblers
+	defer correlations.Delete(correlationID)           // Clear the lookup
+
+	archive.mtx.Lock()
+	defer archive.mtx.Unlock()
+	if err := archive.Proxy.ReplayRequestWithParams(correlationID, recordingID, replayChannel, replayStream, params); err != nil {
+		return 0, err
+	}
+
+	return archive.Control.PollForResponse(correlationID, archive.SessionID)
+}
+
+// RequestReplayToken for a recording. The token may be passed in the
+// ReplayParams of StartReplayWithParams to authorise a replay on a
+// response channel.
+//
+// Returns the replay token
+func (archive *Archive) RequestReplayToken(recordingID int64) (int64, error) {
+	if err := archive.checkProtocolVersion(ProtocolVersionWithReplayTokens, "RequestReplayToken"); err != nil {
+		return 0, err
+	}
+
+	correlationID := nextCorrelationID()
+	logger.Debugf("RequestReplayToken(%d), correlationID:%d", recordingID, correlationID)
+	correlations.Store(correlationID, archive.Control) // For subsequent lookup in the fragment assemblers
+	defer correlations.Delete(correlationID)           // Clear the lookup
+
+	archive.mtx.Lock()
+	defer archive.mtx.Unlock()
+	if err := archive.Proxy.ReplayTokenRequest(correlationID, recordingID); err != nil {
+		return 0, err
+	}
+
+	return archive.Control.PollForResponse(correlationID, archive.SessionID)
+}
+
 // StopReplay for a  session.
 //
 // Returns error on failure, nil on success
@@ -888,6 +957,53 @@ func (archive *Archive) GetRecordingPosition(recordingID int64) (int64, error) {
 	return archive.Control.PollForResponse(correlationID, archive.SessionID)
 }
 
+// GetMaxRecordedPosition of a recording. For an active recording this
+// is the position that has been durably recorded which may be ahead of
+// the recording position counter.
+//
+// Returns the max recorded position
+func (archive *Archive) GetMaxRecordedPosition(recordingID int64) (int64, error) {
+	if err := archive.checkProtocolVersion(ProtocolVersionWithArchiveId, "GetMaxRecordedPosition"); err != nil {
+		return 0, err
+	}
+
+	correlationID := nextCorrelationID()
+	logger.Debugf("GetMaxRecordedPosition(%d), correlationID:%d", recordingID, correlationID)
+	correlations.Store(correlationID, archive.Control) // For subsequent lookup in the fragment assemblers
+	defer correlations.Delete(correlationID)           // Clear the lookup
+
+	archive.mtx.Lock()
+	defer archive.mtx.Unlock()
+	if err := archive.Proxy.MaxRecordedPositionRequest(correlationID, recordingID); err != nil {
+		return 0, err
+	}
+
+	return archive.Control.PollForResponse(correlationID, archive.SessionID)
+}
+
+// ArchiveId of the connected archive which is unique within a cluster
+// of archives.
+//
+// Returns the archive id
+func (archive *Archive) ArchiveId() (int64, error) {
+	if err := archive.checkProtocolVersion(ProtocolVersionWithArchiveId, "ArchiveId"); err != nil {
+		return 0, err
+	}
+
+	correlationID := nextCorrelationID()
+	logger.Debugf("ArchiveId(), correlationID:%d", correlationID)
+	correlations.Store(correlationID, archive.Control) // For subsequent lookup in the fragment assemblers
+	defer correlations.Delete(correlationID)           // Clear the lookup
+
+	archive.mtx.Lock()
+	defer archive.mtx.Unlock()
+	if err := archive.Proxy.ArchiveIdRequest(correlationID); err != nil {
+		return 0, err
+	}
+
+	return archive.Control.PollForResponse(correlationID, archive.SessionID)
+}
+
 // TruncateRecording of a stopped recording to a given position that
 // is less than the stopped position. The provided position must be on
 // a fragment boundary. Truncating a recording to the start position
@@ -1241,6 +1357,41 @@ func (archive *Archive) TaggedReplicate(srcRecordingID int64, dstRecordingID int
 	return archive.Control.PollForResponse(correlationID, archive.SessionID)
 }
 
+// ReplicateWithParams to replicate a recording from a source archive
+// to a destination using the optional parameters in a
+// ReplicationParams. See Replicate2 and TaggedReplicate for details
+// of replication.
+//
+// Setting EncodedCredentials or a SrcResponseChannel requires an
+// archive which supports the newer protocol, see ProtocolVersion().
+//
+// Returns the replication session id which can be passed StopReplication()
+func (archive *Archive) ReplicateWithParams(srcRecordingID int64, srcControlStreamID int32, srcControlChannel string, params *ReplicationParams) (int64, error) {
+	if params.SrcResponseChannel != "" {
+		if err := archive.checkProtocolVersion(ProtocolVersionWithReplayTokens, "SrcResponseChannel"); err != nil {
+			return 0, err
+		}
+	}
+	if len(params.EncodedCredentials) > 0 {
+		if err := archive.checkProtocolVersion(ProtocolVersionWithArchiveId, "EncodedCredentials"); err != nil {
+			return 0, err
+		}
+	}
+
+	correlationID := nextCorrelationID()
+	logger.Debugf("ReplicateWithParams(%d, %d, %s, %#v), correlationID:%d", srcRecordingID, srcControlStreamID, srcControlChannel, params, correlationID)
+	correlations.Store(correlationID, archive.Control) // For subsequent lookup in the fragment assemblers
+	defer correlations.Delete(correlationID)           // Clear the lookup
+
+	archive.mtx.Lock()
+	defer archive.mtx.Unlock()
+	if err := archive.Proxy.ReplicateRequestWithParams(correlationID, srcRecordingID, srcControlStreamID, srcControlChannel, params); err != nil {
+		return 0, err
+	}
+
+	return archive.Control.PollForResponse(correlationID, archive.SessionID)
+}
+
 // StopReplication of a replication request
 //
 // Returns error on failure, nil on success
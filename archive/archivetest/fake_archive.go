@@ -0,0 +1,893 @@
+// Copyright (C) 2021-2022 Talos, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package archivetest provides an in-memory FakeArchive implementing
+// archive.ArchiveClient for testing code which uses an archive
+// without needing a media driver and Java archive.
+package archivetest
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/util"
+	"github.com/lirm/aeron-go/archive"
+	"github.com/lirm/aeron-go/archive/codecs"
+)
+
+// ErrNotSupported is returned for operations the FakeArchive does not simulate
+var ErrNotSupported = errors.New("not supported by FakeArchive")
+
+// FakeArchive is an in-memory archive. Recordings are started as
+// with a real archive and then receive the data passed to Offer().
+// Replays are served by a FakeImage obtained with ReplayImage(), and
+// recording signals are delivered synchronously to the
+// RecordingSignalListener.
+//
+// Replication and segment migration are not simulated and bounded
+// replays are not bounded by their counter.
+type FakeArchive struct {
+	Listeners         *archive.ArchiveListeners
+	ControlSessionId  int64
+	Id                int64            // Returned by ArchiveId()
+	TermBufferLength  int32            // [init] for new recordings
+	SegmentFileLength int32            // [init] for new recordings
+	MtuLength         int32            // [init] for new recordings
+	Now               func() time.Time // Clock for recording timestamps
+
+	mtx           sync.Mutex
+	nextId        int64
+	recordings    map[int64]*fakeRecording
+	subscriptions map[int64]*fakeSubscription
+	replays       map[int64]*FakeImage
+	signals       []*codecs.RecordingSignalEvent
+	closed        bool
+}
+
+var _ archive.ArchiveClient = (*FakeArchive)(nil)
+
+type fakeSubscription struct {
+	subscriptionID int64
+	correlationID  int64
+	channel        string
+	stream         int32
+	autoStop       bool
+	extendID       int64 // Recording to extend, otherwise RecordingIdNullValue
+}
+
+type fakeRecording struct {
+	descriptor     codecs.RecordingDescriptor
+	subscription   *fakeSubscription // Set while the recording is active
+	recordedLength int64             // Position the recording has reached
+	detachedStart  int64             // Start position before any detached segments, otherwise the start position
+	terms          map[int64]*atomic.Buffer
+	frames         []*fakeFrame
+}
+
+type fakeFrame struct {
+	position   int64
+	buffer     *atomic.Buffer // Term buffer holding the frame
+	termOffset int32
+	length     int32 // Length of the frame payload
+}
+
+// NewFakeArchive creates and returns an empty FakeArchive
+func NewFakeArchive() *FakeArchive {
+	return &FakeArchive{
+		Listeners:         &archive.ArchiveListeners{},
+		ControlSessionId:  1,
+		Id:                1,
+		TermBufferLength:  64 * 1024,
+		SegmentFileLength: 128 * 1024 * 1024,
+		MtuLength:         1408,
+		Now:               time.Now,
+		nextId:            1,
+		recordings:        make(map[int64]*fakeRecording),
+		subscriptions:     make(map[int64]*fakeSubscription),
+		replays:           make(map[int64]*FakeImage),
+	}
+}
+
+// Offer data as a single fragment on a channel and stream. Any recording
+// subscription for the channel and stream records it, starting a new
+// recording for a sessionID not yet seen.
+//
+// Returns the new position of the recording
+func (fake *FakeArchive) Offer(channel string, stream int32, sessionID int32, data []byte) (int64, error) {
+	defer fake.dispatchSignals()
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+
+	var subscription *fakeSubscription
+	for _, sub := range fake.subscriptions {
+		if sub.channel == channel && sub.stream == stream {
+			subscription = sub
+			break
+		}
+	}
+	if subscription == nil {
+		return 0, fmt.Errorf("no recording subscription for %s:%d", channel, stream)
+	}
+
+	recording := fake.activeRecording(subscription, sessionID)
+	if recording == nil {
+		var err error
+		if recording, err = fake.startRecording(subscription, sessionID); err != nil {
+			return 0, err
+		}
+	}
+	recording.append(sessionID, data)
+	return recording.recordedLength, nil
+}
+
+// EndOfStream for a session on a channel and stream, as happens when
+// the publication closes. Recordings for the session are stopped and,
+// if the subscription was started with autoStop, so is the subscription.
+func (fake *FakeArchive) EndOfStream(channel string, stream int32, sessionID int32) {
+	defer fake.dispatchSignals()
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+
+	for _, recording := range fake.recordings {
+		subscription := recording.subscription
+		if subscription != nil && subscription.channel == channel && subscription.stream == stream && recording.descriptor.SessionId == sessionID {
+			fake.stopRecording(recording)
+			if subscription.autoStop {
+				delete(fake.subscriptions, subscription.subscriptionID)
+			}
+		}
+	}
+}
+
+// ReplayImage returns the FakeImage serving a replay, or nil if the
+// replay session is unknown
+func (fake *FakeArchive) ReplayImage(replaySessionID int64) *FakeImage {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	return fake.replays[replaySessionID]
+}
+
+// Close the archive
+func (fake *FakeArchive) Close() error {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	fake.closed = true
+	return nil
+}
+
+// ProtocolVersion of the archive codecs
+func (fake *FakeArchive) ProtocolVersion() int32 {
+	return codecs.SemanticVersion()
+}
+
+// ArchiveId returns Id
+func (fake *FakeArchive) ArchiveId() (int64, error) {
+	return fake.Id, fake.check()
+}
+
+// KeepAlive does nothing
+func (fake *FakeArchive) KeepAlive() error {
+	return fake.check()
+}
+
+// PollForErrorResponse delivers any pending signals
+func (fake *FakeArchive) PollForErrorResponse() (int, error) {
+	fake.mtx.Lock()
+	count := len(fake.signals)
+	fake.mtx.Unlock()
+	fake.dispatchSignals()
+	return count, fake.check()
+}
+
+// StartRecording creates a recording subscription for the channel and stream
+func (fake *FakeArchive) StartRecording(channel string, stream int32, isLocal bool, autoStop bool) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if err := fake.checkLocked(); err != nil {
+		return 0, err
+	}
+	for _, sub := range fake.subscriptions {
+		if sub.channel == channel && sub.stream == stream {
+			return 0, fmt.Errorf("recording exists for %s:%d", channel, stream)
+		}
+	}
+	return fake.addSubscription(channel, stream, autoStop, int64(archive.RecordingIdNullValue)), nil
+}
+
+// StopRecording for the channel and stream
+func (fake *FakeArchive) StopRecording(channel string, stream int32) error {
+	defer fake.dispatchSignals()
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if err := fake.checkLocked(); err != nil {
+		return err
+	}
+	for _, sub := range fake.subscriptions {
+		if sub.channel == channel && sub.stream == stream {
+			fake.stopSubscription(sub)
+			return nil
+		}
+	}
+	return fmt.Errorf("no recording found for %s:%d", channel, stream)
+}
+
+// StopRecordingByIdentity stops the subscription of an active recording
+//
+// Returns true if the recording was active
+func (fake *FakeArchive) StopRecordingByIdentity(recordingID int64) (bool, error) {
+	defer fake.dispatchSignals()
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return false, err
+	}
+	if !recording.isActive() {
+		return false, nil
+	}
+	fake.stopSubscription(recording.subscription)
+	return true, nil
+}
+
+// StopRecordingBySubscriptionId stops the subscription and its recordings
+func (fake *FakeArchive) StopRecordingBySubscriptionId(subscriptionID int64) error {
+	defer fake.dispatchSignals()
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if err := fake.checkLocked(); err != nil {
+		return err
+	}
+	sub, ok := fake.subscriptions[subscriptionID]
+	if !ok {
+		return fmt.Errorf("no recording subscription found for subscriptionId %d", subscriptionID)
+	}
+	fake.stopSubscription(sub)
+	return nil
+}
+
+// ExtendRecording creates a recording subscription which extends a stopped recording
+func (fake *FakeArchive) ExtendRecording(recordingID int64, stream int32, sourceLocation codecs.SourceLocationEnum, autoStop bool, channel string) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	if recording.isActive() {
+		return 0, fmt.Errorf("cannot extend active recording %d", recordingID)
+	}
+	if recording.descriptor.StreamId != stream {
+		return 0, fmt.Errorf("cannot extend recording %d with streamId %d", recordingID, stream)
+	}
+	return fake.addSubscription(channel, stream, autoStop, recordingID), nil
+}
+
+// TruncateRecording of a stopped recording to a position
+func (fake *FakeArchive) TruncateRecording(recordingID int64, position int64) error {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return err
+	}
+	if recording.isActive() {
+		return fmt.Errorf("cannot truncate active recording %d", recordingID)
+	}
+	descriptor := &recording.descriptor
+	if position < descriptor.StartPosition || position > descriptor.StopPosition || !recording.isFrameBoundary(position) {
+		return fmt.Errorf("invalid position %d for recording %d", position, recordingID)
+	}
+	descriptor.StopPosition = position
+	recording.recordedLength = position
+	recording.discardFrames(func(frame *fakeFrame) bool { return frame.position >= position })
+	return nil
+}
+
+// PurgeRecording of a stopped recording
+func (fake *FakeArchive) PurgeRecording(recordingID int64) error {
+	defer fake.dispatchSignals()
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return err
+	}
+	if recording.isActive() {
+		return fmt.Errorf("cannot purge active recording %d", recordingID)
+	}
+	for id, replay := range fake.replays {
+		if replay.recording == recording {
+			return fmt.Errorf("cannot purge recording %d with active replay %d", recordingID, id)
+		}
+	}
+	delete(fake.recordings, recordingID)
+	fake.signal(recording, aeron.NullValue, recording.descriptor.StopPosition, codecs.RecordingSignal.DELETE)
+	return nil
+}
+
+// ListRecordings up to recordCount recording descriptors
+func (fake *FakeArchive) ListRecordings(fromRecordingID int64, recordCount int32) ([]*codecs.RecordingDescriptor, error) {
+	return fake.listRecordings(fromRecordingID, recordCount, func(*fakeRecording) bool { return true })
+}
+
+// ListRecordingsForUri up to recordCount recording descriptors whose channel contains channelFragment
+func (fake *FakeArchive) ListRecordingsForUri(fromRecordingID int64, recordCount int32, channelFragment string, stream int32) ([]*codecs.RecordingDescriptor, error) {
+	return fake.listRecordings(fromRecordingID, recordCount, func(recording *fakeRecording) bool {
+		return recording.descriptor.StreamId == stream && strings.Contains(string(recording.descriptor.StrippedChannel), channelFragment)
+	})
+}
+
+// ListRecording returns the recording descriptor or nil if there is no match
+func (fake *FakeArchive) ListRecording(recordingID int64) (*codecs.RecordingDescriptor, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if err := fake.checkLocked(); err != nil {
+		return nil, err
+	}
+	recording, ok := fake.recordings[recordingID]
+	if !ok {
+		return nil, nil
+	}
+	return recording.copyDescriptor(), nil
+}
+
+// ListRecordingSubscriptions up to subscriptionCount from pseudoIndex
+func (fake *FakeArchive) ListRecordingSubscriptions(pseudoIndex int32, subscriptionCount int32, applyStreamID bool, stream int32, channelFragment string) ([]*codecs.RecordingSubscriptionDescriptor, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if err := fake.checkLocked(); err != nil {
+		return nil, err
+	}
+	ids := make([]int64, 0, len(fake.subscriptions))
+	for id := range fake.subscriptions {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	var descriptors []*codecs.RecordingSubscriptionDescriptor
+	for idx, id := range ids {
+		sub := fake.subscriptions[id]
+		if int32(idx) < pseudoIndex || int32(len(descriptors)) >= subscriptionCount {
+			continue
+		}
+		if (applyStreamID && sub.stream != stream) || !strings.Contains(sub.channel, channelFragment) {
+			continue
+		}
+		descriptors = append(descriptors, &codecs.RecordingSubscriptionDescriptor{
+			ControlSessionId: fake.ControlSessionId,
+			CorrelationId:    sub.correlationID,
+			SubscriptionId:   sub.subscriptionID,
+			StreamId:         sub.stream,
+			StrippedChannel:  []byte(sub.channel),
+		})
+	}
+	return descriptors, nil
+}
+
+// FindLastMatchingRecording returns the highest recording id from
+// minRecordingID matching the session, stream and channel fragment
+//
+// Returns RecordingIdNullValue if there is no match
+func (fake *FakeArchive) FindLastMatchingRecording(minRecordingID int64, sessionID int32, stream int32, channel string) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if err := fake.checkLocked(); err != nil {
+		return 0, err
+	}
+	result := int64(archive.RecordingIdNullValue)
+	for id, recording := range fake.recordings {
+		descriptor := &recording.descriptor
+		if id >= minRecordingID && id > result && descriptor.SessionId == sessionID && descriptor.StreamId == stream &&
+			strings.Contains(string(descriptor.StrippedChannel), channel) {
+			result = id
+		}
+	}
+	return result, nil
+}
+
+// GetRecordingPosition of an active recording, otherwise RecordingPositionNull
+func (fake *FakeArchive) GetRecordingPosition(recordingID int64) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	if !recording.isActive() {
+		return archive.RecordingPositionNull, nil
+	}
+	return recording.recordedLength, nil
+}
+
+// GetMaxRecordedPosition of a recording
+func (fake *FakeArchive) GetMaxRecordedPosition(recordingID int64) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	return recording.recordedLength, nil
+}
+
+// GetStartPosition of a recording
+func (fake *FakeArchive) GetStartPosition(recordingID int64) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	return recording.descriptor.StartPosition, nil
+}
+
+// GetStopPosition of a recording, RecordingPositionNull if active
+func (fake *FakeArchive) GetStopPosition(recordingID int64) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	return recording.descriptor.StopPosition, nil
+}
+
+// StartReplay of a recording, see ReplayImage() for the replayed data
+//
+// Returns the replay session id
+func (fake *FakeArchive) StartReplay(recordingID int64, position int64, length int64, replayChannel string, replayStream int32) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	descriptor := &recording.descriptor
+	if position == archive.RecordingPositionNull {
+		position = descriptor.StartPosition
+	}
+	if position < descriptor.StartPosition || position > recording.recordedLength || !recording.isFrameBoundary(position) {
+		return 0, fmt.Errorf("invalid replay position %d for recording %d", position, recordingID)
+	}
+
+	limit := int64(aeron.NullValue)
+	if length != archive.RecordingLengthNull && length != archive.RecordingLengthMax {
+		limit = position + length
+	} else if !recording.isActive() {
+		limit = descriptor.StopPosition
+	}
+
+	replaySessionID := fake.nextID()
+	fake.replays[replaySessionID] = &FakeImage{
+		archive:        fake,
+		recording:      recording,
+		replaySession:  replaySessionID,
+		subscriptionID: fake.nextID(),
+		position:       position,
+		limit:          limit,
+	}
+	return replaySessionID, nil
+}
+
+// BoundedReplay is as StartReplay, the limit counter is not applied
+func (fake *FakeArchive) BoundedReplay(recordingID int64, position int64, length int64, limitCounterID int32, replayStream int32, replayChannel string) (int64, error) {
+	return fake.StartReplay(recordingID, position, length, replayChannel, replayStream)
+}
+
+// StartReplayWithParams is as StartReplay
+func (fake *FakeArchive) StartReplayWithParams(recordingID int64, replayChannel string, replayStream int32, params *archive.ReplayParams) (int64, error) {
+	return fake.StartReplay(recordingID, params.Position, params.Length, replayChannel, replayStream)
+}
+
+// RequestReplayToken returns a new token
+func (fake *FakeArchive) RequestReplayToken(recordingID int64) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if _, err := fake.recording(recordingID); err != nil {
+		return 0, err
+	}
+	return fake.nextID(), nil
+}
+
+// StopReplay closes the replay's image
+func (fake *FakeArchive) StopReplay(replaySessionID int64) error {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	replay, ok := fake.replays[replaySessionID]
+	if !ok {
+		return fmt.Errorf("replay session not known: %d", replaySessionID)
+	}
+	replay.closed = true
+	delete(fake.replays, replaySessionID)
+	return nil
+}
+
+// StopAllReplays of a recording, or all recordings if RecordingIdNullValue
+func (fake *FakeArchive) StopAllReplays(recordingID int64) error {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	for id, replay := range fake.replays {
+		if recordingID == int64(archive.RecordingIdNullValue) || replay.recording.descriptor.RecordingId == recordingID {
+			replay.closed = true
+			delete(fake.replays, id)
+		}
+	}
+	return nil
+}
+
+// DetachSegments before newStartPosition from a recording
+func (fake *FakeArchive) DetachSegments(recordingID int64, newStartPosition int64) error {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return err
+	}
+	return recording.detachSegments(newStartPosition)
+}
+
+// DeleteDetachedSegments of a recording
+//
+// Returns the count of deleted segment files
+func (fake *FakeArchive) DeleteDetachedSegments(recordingID int64) (int64, error) {
+	defer fake.dispatchSignals()
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	return fake.deleteDetachedSegments(recording), nil
+}
+
+// PurgeSegments detaches and deletes the segments before newStartPosition
+//
+// Returns the count of deleted segment files
+func (fake *FakeArchive) PurgeSegments(recordingID int64, newStartPosition int64) (int64, error) {
+	defer fake.dispatchSignals()
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	if err := recording.detachSegments(newStartPosition); err != nil {
+		return 0, err
+	}
+	return fake.deleteDetachedSegments(recording), nil
+}
+
+// AttachSegments which were detached but not deleted
+//
+// Returns the count of attached segment files
+func (fake *FakeArchive) AttachSegments(recordingID int64) (int64, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	recording, err := fake.recording(recordingID)
+	if err != nil {
+		return 0, err
+	}
+	descriptor := &recording.descriptor
+	count := (descriptor.StartPosition - recording.detachedStart) / int64(descriptor.SegmentFileLength)
+	descriptor.StartPosition = recording.detachedStart
+	return count, nil
+}
+
+// MigrateSegments is not supported
+func (fake *FakeArchive) MigrateSegments(recordingID int64, position int64) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// Replicate is not supported
+func (fake *FakeArchive) Replicate(srcRecordingID int64, dstRecordingID int64, srcControlStreamID int32, srcControlChannel string, liveDestination string) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// Replicate2 is not supported
+func (fake *FakeArchive) Replicate2(srcRecordingID int64, dstRecordingID int64, stopPosition int64, channelTagID int64, srcControlStreamID int32, srcControlChannel string, liveDestination string, replicationChannel string) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// TaggedReplicate is not supported
+func (fake *FakeArchive) TaggedReplicate(srcRecordingID int64, dstRecordingID int64, channelTagID int64, subscriptionTagID int64, srcControlStreamID int32, srcControlChannel string, liveDestination string) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// ReplicateWithParams is not supported
+func (fake *FakeArchive) ReplicateWithParams(srcRecordingID int64, srcControlStreamID int32, srcControlChannel string, params *archive.ReplicationParams) (int64, error) {
+	return 0, ErrNotSupported
+}
+
+// StopReplication is not supported
+func (fake *FakeArchive) StopReplication(replicationID int64) error {
+	return ErrNotSupported
+}
+
+func (fake *FakeArchive) check() error {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	return fake.checkLocked()
+}
+
+func (fake *FakeArchive) checkLocked() error {
+	if fake.closed {
+		return errors.New("archive is closed")
+	}
+	return nil
+}
+
+func (fake *FakeArchive) nextID() int64 {
+	id := fake.nextId
+	fake.nextId++
+	return id
+}
+
+func (fake *FakeArchive) recording(recordingID int64) (*fakeRecording, error) {
+	if err := fake.checkLocked(); err != nil {
+		return nil, err
+	}
+	recording, ok := fake.recordings[recordingID]
+	if !ok {
+		return nil, fmt.Errorf("unknown recording id: %d", recordingID)
+	}
+	return recording, nil
+}
+
+func (fake *FakeArchive) listRecordings(fromRecordingID int64, recordCount int32, match func(*fakeRecording) bool) ([]*codecs.RecordingDescriptor, error) {
+	fake.mtx.Lock()
+	defer fake.mtx.Unlock()
+	if err := fake.checkLocked(); err != nil {
+		return nil, err
+	}
+	var descriptors []*codecs.RecordingDescriptor
+	for _, id := range fake.recordingIds() {
+		if id < fromRecordingID || int32(len(descriptors)) >= recordCount {
+			continue
+		}
+		if recording := fake.recordings[id]; match(recording) {
+			descriptors = append(descriptors, recording.copyDescriptor())
+		}
+	}
+	return descriptors, nil
+}
+
+func (fake *FakeArchive) recordingIds() []int64 {
+	ids := make([]int64, 0, len(fake.recordings))
+	for id := range fake.recordings {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+func (fake *FakeArchive) addSubscription(channel string, stream int32, autoStop bool, extendID int64) int64 {
+	sub := &fakeSubscription{
+		subscriptionID: fake.nextID(),
+		correlationID:  fake.nextID(),
+		channel:        channel,
+		stream:         stream,
+		autoStop:       autoStop,
+		extendID:       extendID,
+	}
+	fake.subscriptions[sub.subscriptionID] = sub
+	return sub.subscriptionID
+}
+
+func (fake *FakeArchive) activeRecording(subscription *fakeSubscription, sessionID int32) *fakeRecording {
+	for _, recording := range fake.recordings {
+		if recording.subscription == subscription && recording.descriptor.SessionId == sessionID {
+			return recording
+		}
+	}
+	return nil
+}
+
+func (fake *FakeArchive) startRecording(subscription *fakeSubscription, sessionID int32) (*fakeRecording, error) {
+	now := fake.Now().UnixMilli()
+
+	if subscription.extendID != int64(archive.RecordingIdNullValue) {
+		recording, ok := fake.recordings[subscription.extendID]
+		if !ok {
+			return nil, fmt.Errorf("unknown recording id: %d", subscription.extendID)
+		}
+		if recording.isActive() {
+			return nil, fmt.Errorf("recording %d is already being extended", subscription.extendID)
+		}
+		recording.subscription = subscription
+		recording.descriptor.SessionId = sessionID
+		recording.descriptor.StopPosition = archive.RecordingPositionNull
+		recording.descriptor.StopTimestamp = archive.RecordingTimestampNull
+		fake.signal(recording, subscription.subscriptionID, recording.recordedLength, codecs.RecordingSignal.EXTEND)
+		return recording, nil
+	}
+
+	recordingID := fake.nextID()
+	recording := &fakeRecording{
+		descriptor: codecs.RecordingDescriptor{
+			ControlSessionId:  fake.ControlSessionId,
+			CorrelationId:     subscription.correlationID,
+			RecordingId:       recordingID,
+			StartTimestamp:    now,
+			StopTimestamp:     archive.RecordingTimestampNull,
+			StartPosition:     0,
+			StopPosition:      archive.RecordingPositionNull,
+			InitialTermId:     0,
+			SegmentFileLength: fake.SegmentFileLength,
+			TermBufferLength:  fake.TermBufferLength,
+			MtuLength:         fake.MtuLength,
+			SessionId:         sessionID,
+			StreamId:          subscription.stream,
+			StrippedChannel:   []byte(subscription.channel),
+			OriginalChannel:   []byte(subscription.channel),
+			SourceIdentity:    []byte("fake"),
+		},
+		subscription: subscription,
+		terms:        make(map[int64]*atomic.Buffer),
+	}
+	fake.recordings[recordingID] = recording
+	fake.signal(recording, subscription.subscriptionID, 0, codecs.RecordingSignal.START)
+	return recording, nil
+}
+
+func (fake *FakeArchive) stopSubscription(subscription *fakeSubscription) {
+	for _, recording := range fake.recordings {
+		if recording.subscription == subscription {
+			fake.stopRecording(recording)
+		}
+	}
+	delete(fake.subscriptions, subscription.subscriptionID)
+}
+
+func (fake *FakeArchive) stopRecording(recording *fakeRecording) {
+	subscriptionID := recording.subscription.subscriptionID
+	recording.subscription = nil
+	recording.descriptor.StopPosition = recording.recordedLength
+	recording.descriptor.StopTimestamp = fake.Now().UnixMilli()
+	fake.signal(recording, subscriptionID, recording.recordedLength, codecs.RecordingSignal.STOP)
+}
+
+func (fake *FakeArchive) deleteDetachedSegments(recording *fakeRecording) int64 {
+	descriptor := &recording.descriptor
+	count := (descriptor.StartPosition - recording.detachedStart) / int64(descriptor.SegmentFileLength)
+	if count > 0 {
+		startPosition := descriptor.StartPosition
+		recording.discardFrames(func(frame *fakeFrame) bool { return frame.position < startPosition })
+		recording.detachedStart = startPosition
+		fake.signal(recording, aeron.NullValue, startPosition, codecs.RecordingSignal.DELETE)
+	}
+	return count
+}
+
+// signal is queued and delivered once the lock is released
+func (fake *FakeArchive) signal(recording *fakeRecording, subscriptionID int64, position int64, signal codecs.RecordingSignalEnum) {
+	fake.signals = append(fake.signals, &codecs.RecordingSignalEvent{
+		ControlSessionId: fake.ControlSessionId,
+		CorrelationId:    recording.descriptor.CorrelationId,
+		RecordingId:      recording.descriptor.RecordingId,
+		SubscriptionId:   subscriptionID,
+		Position:         position,
+		Signal:           signal,
+	})
+}
+
+func (fake *FakeArchive) dispatchSignals() {
+	fake.mtx.Lock()
+	signals := fake.signals
+	fake.signals = nil
+	fake.mtx.Unlock()
+
+	for _, signal := range signals {
+		if fake.Listeners != nil && fake.Listeners.RecordingSignalListener != nil {
+			fake.Listeners.RecordingSignalListener(signal)
+		}
+	}
+}
+
+func (recording *fakeRecording) isActive() bool {
+	return recording.subscription != nil
+}
+
+func (recording *fakeRecording) position() int64 {
+	return recording.recordedLength
+}
+
+func (recording *fakeRecording) copyDescriptor() *codecs.RecordingDescriptor {
+	descriptor := recording.descriptor
+	return &descriptor
+}
+
+func (recording *fakeRecording) isFrameBoundary(position int64) bool {
+	if position == recording.descriptor.StartPosition || position == recording.recordedLength {
+		return true
+	}
+	idx := sort.Search(len(recording.frames), func(i int) bool { return recording.frames[i].position >= position })
+	return idx < len(recording.frames) && recording.frames[idx].position == position
+}
+
+// append data as an unfragmented frame in the recording's term buffers
+func (recording *fakeRecording) append(sessionID int32, data []byte) {
+	descriptor := &recording.descriptor
+	termLength := descriptor.TermBufferLength
+	frameLength := logbuffer.DataFrameHeader.Length + int32(len(data))
+	alignedLength := util.AlignInt32(frameLength, logbuffer.FrameAlignment)
+
+	position := recording.recordedLength
+	termOffset := int32(position & int64(termLength-1))
+	if termOffset+alignedLength > termLength {
+		// Pad to the end of the term
+		position += int64(termLength - termOffset)
+		termOffset = 0
+	}
+	bitsToShift := positionBitsToShift(termLength)
+	termIndex := position >> bitsToShift
+	buffer, ok := recording.terms[termIndex]
+	if !ok {
+		buffer = atomic.MakeBuffer(make([]byte, termLength))
+		recording.terms[termIndex] = buffer
+	}
+
+	buffer.PutInt32(termOffset+logbuffer.DataFrameHeader.FrameLengthFieldOffset, frameLength)
+	buffer.PutInt8(termOffset+logbuffer.DataFrameHeader.VersionFieldOffset, logbuffer.DataFrameHeader.CurrentVersion)
+	buffer.PutUInt8(termOffset+logbuffer.DataFrameHeader.FlagsFieldOffset, 0xC0)
+	buffer.PutUInt16(termOffset+logbuffer.DataFrameHeader.TypeFieldOffset, logbuffer.DataFrameHeader.TypeData)
+	buffer.PutInt32(termOffset+logbuffer.DataFrameHeader.TermOffsetFieldOffset, termOffset)
+	buffer.PutInt32(termOffset+logbuffer.DataFrameHeader.SessionIDFieldOffset, sessionID)
+	buffer.PutInt32(termOffset+logbuffer.DataFrameHeader.StreamIDFieldOffset, descriptor.StreamId)
+	buffer.PutInt32(termOffset+logbuffer.DataFrameHeader.TermIDFieldOffset, descriptor.InitialTermId+int32(termIndex))
+	buffer.PutBytesArray(termOffset+logbuffer.DataFrameHeader.DataOffset, &data, 0, int32(len(data)))
+
+	recording.frames = append(recording.frames, &fakeFrame{
+		position:   position,
+		buffer:     buffer,
+		termOffset: termOffset,
+		length:     int32(len(data)),
+	})
+	recording.recordedLength = position + int64(alignedLength)
+}
+
+func (recording *fakeRecording) detachSegments(newStartPosition int64) error {
+	descriptor := &recording.descriptor
+	segmentBase := archive.SegmentFileBasePosition(descriptor.StartPosition, newStartPosition, descriptor.TermBufferLength, descriptor.SegmentFileLength)
+	limit := archive.SegmentFileBasePosition(descriptor.StartPosition, recording.recordedLength, descriptor.TermBufferLength, descriptor.SegmentFileLength)
+	if newStartPosition != segmentBase || newStartPosition <= descriptor.StartPosition || newStartPosition > limit {
+		return fmt.Errorf("invalid segment start position %d for recording %d", newStartPosition, descriptor.RecordingId)
+	}
+	if recording.detachedStart > descriptor.StartPosition || recording.detachedStart == 0 {
+		recording.detachedStart = descriptor.StartPosition
+	}
+	descriptor.StartPosition = newStartPosition
+	return nil
+}
+
+func (recording *fakeRecording) discardFrames(discard func(*fakeFrame) bool) {
+	frames := recording.frames[:0]
+	for _, frame := range recording.frames {
+		if !discard(frame) {
+			frames = append(frames, frame)
+		}
+	}
+	recording.frames = frames
+}
+
+func positionBitsToShift(termBufferLength int32) int32 {
+	return int32(bits.TrailingZeros32(uint32(termBufferLength)))
+}
@@ -0,0 +1,263 @@
+package archivetest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/archive"
+	"github.com/lirm/aeron-go/archive/codecs"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testChannel = "aeron:ipc"
+	testStream  = int32(1001)
+	testSession = int32(17)
+)
+
+func TestFakeArchive_RecordAndReplay(t *testing.T) {
+	fake := NewFakeArchive()
+	var signals []codecs.RecordingSignalEnum
+	fake.Listeners.RecordingSignalListener = func(rse *codecs.RecordingSignalEvent) {
+		signals = append(signals, rse.Signal)
+	}
+
+	_, err := fake.Offer(testChannel, testStream, testSession, []byte("dropped"))
+	assert.Error(t, err)
+
+	subscriptionID, err := fake.StartRecording(testChannel, testStream, true, true)
+	assert.NoError(t, err)
+
+	for _, msg := range []string{"one", "two", "three"} {
+		_, err := fake.Offer(testChannel, testStream, testSession, []byte(msg))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []codecs.RecordingSignalEnum{codecs.RecordingSignal.START}, signals)
+
+	recordingID, err := fake.FindLastMatchingRecording(0, testSession, testStream, "ipc")
+	assert.NoError(t, err)
+	position, err := fake.GetRecordingPosition(recordingID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3*64, position)
+
+	subscriptions, err := fake.ListRecordingSubscriptions(0, 10, true, testStream, "")
+	assert.NoError(t, err)
+	assert.Len(t, subscriptions, 1)
+	assert.Equal(t, subscriptionID, subscriptions[0].SubscriptionId)
+
+	// Follows the live recording
+	replayID, err := fake.StartReplay(recordingID, archive.RecordingPositionNull, archive.RecordingLengthNull, testChannel, 2002)
+	assert.NoError(t, err)
+	image := fake.ReplayImage(replayID)
+
+	var payloads []string
+	var positions []int64
+	handler := func(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+		payloads = append(payloads, string(buffer.GetBytesArray(offset, length)))
+		positions = append(positions, header.Position())
+		assert.Equal(t, testSession, header.SessionId())
+		assert.Equal(t, testStream, header.StreamId())
+	}
+	assert.Equal(t, 2, image.Poll(handler, 2))
+	assert.Equal(t, 1, image.Poll(handler, 10))
+	assert.Equal(t, []string{"one", "two", "three"}, payloads)
+	assert.Equal(t, []int64{64, 128, 192}, positions)
+	assert.False(t, image.IsEndOfStream())
+
+	_, err = fake.Offer(testChannel, testStream, testSession, []byte("four"))
+	assert.NoError(t, err)
+	fake.EndOfStream(testChannel, testStream, testSession)
+	assert.Equal(t, 1, image.Poll(handler, 10))
+	assert.Equal(t, "four", payloads[3])
+	assert.True(t, image.IsEndOfStream())
+	assert.Equal(t, []codecs.RecordingSignalEnum{codecs.RecordingSignal.START, codecs.RecordingSignal.STOP}, signals)
+
+	stopPosition, err := fake.GetStopPosition(recordingID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 256, stopPosition)
+
+	// autoStop removed the subscription
+	subscriptions, err = fake.ListRecordingSubscriptions(0, 10, false, 0, "")
+	assert.NoError(t, err)
+	assert.Empty(t, subscriptions)
+
+	// Bounded replay from a mid position
+	replayID, err = fake.StartReplay(recordingID, 64, 128, testChannel, 2002)
+	assert.NoError(t, err)
+	payloads = nil
+	image = fake.ReplayImage(replayID)
+	assert.Equal(t, 2, image.Poll(handler, 10))
+	assert.Equal(t, []string{"two", "three"}, payloads)
+	assert.True(t, image.IsEndOfStream())
+
+	_, err = fake.StartReplay(recordingID, 65, archive.RecordingLengthNull, testChannel, 2002)
+	assert.Error(t, err)
+
+	assert.Error(t, fake.PurgeRecording(recordingID))
+	assert.NoError(t, fake.StopAllReplays(recordingID))
+	assert.True(t, image.IsClosed())
+	assert.NoError(t, fake.PurgeRecording(recordingID))
+	assert.Equal(t, codecs.RecordingSignal.DELETE, signals[len(signals)-1])
+
+	descriptor, err := fake.ListRecording(recordingID)
+	assert.NoError(t, err)
+	assert.Nil(t, descriptor)
+}
+
+func TestFakeArchive_TermRollover(t *testing.T) {
+	fake := NewFakeArchive()
+	fake.TermBufferLength = 64 * 1024
+	_, err := fake.StartRecording(testChannel, testStream, true, false)
+	assert.NoError(t, err)
+
+	data := make([]byte, 40*1024)
+	for idx := range data {
+		data[idx] = byte(idx)
+	}
+	_, err = fake.Offer(testChannel, testStream, testSession, data)
+	assert.NoError(t, err)
+	position, err := fake.Offer(testChannel, testStream, testSession, data)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 64*1024+40*1024+32, position)
+
+	recordingID, _ := fake.FindLastMatchingRecording(0, testSession, testStream, testChannel)
+	replayID, err := fake.StartReplay(recordingID, 0, archive.RecordingLengthMax, testChannel, 2002)
+	assert.NoError(t, err)
+	var termIDs []int32
+	fake.ReplayImage(replayID).Poll(func(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+		assert.Equal(t, data, buffer.GetBytesArray(offset, length))
+		termIDs = append(termIDs, header.TermId())
+	}, 10)
+	assert.Equal(t, []int32{0, 1}, termIDs)
+}
+
+func TestFakeArchive_ExtendAndTruncate(t *testing.T) {
+	fake := NewFakeArchive()
+	var signals []codecs.RecordingSignalEnum
+	fake.Listeners.RecordingSignalListener = func(rse *codecs.RecordingSignalEvent) {
+		signals = append(signals, rse.Signal)
+	}
+	_, err := fake.StartRecording(testChannel, testStream, true, false)
+	assert.NoError(t, err)
+	fake.Offer(testChannel, testStream, testSession, []byte("one"))
+	fake.Offer(testChannel, testStream, testSession, []byte("two"))
+	assert.NoError(t, fake.StopRecording(testChannel, testStream))
+
+	recordingID, _ := fake.FindLastMatchingRecording(0, testSession, testStream, testChannel)
+	assert.Error(t, fake.TruncateRecording(recordingID, 10))
+	assert.NoError(t, fake.TruncateRecording(recordingID, 64))
+
+	_, err = fake.ExtendRecording(recordingID, testStream, codecs.SourceLocation.LOCAL, false, testChannel)
+	assert.NoError(t, err)
+	position, err := fake.Offer(testChannel, testStream, testSession+1, []byte("three"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 128, position)
+
+	descriptors, err := fake.ListRecordings(0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, descriptors, 1)
+	assert.EqualValues(t, archive.RecordingPositionNull, descriptors[0].StopPosition)
+	assert.EqualValues(t, archive.RecordingTimestampNull, descriptors[0].StopTimestamp)
+	assert.Equal(t, []codecs.RecordingSignalEnum{
+		codecs.RecordingSignal.START, codecs.RecordingSignal.STOP, codecs.RecordingSignal.EXTEND,
+	}, signals)
+}
+
+func TestFakeArchive_Retention(t *testing.T) {
+	fake := NewFakeArchive()
+	fake.SegmentFileLength = 64 * 1024
+	now := time.Now().Add(-2 * time.Hour)
+	fake.Now = func() time.Time { return now }
+
+	record := func(sessionID int32, count int) int64 {
+		for idx := 0; idx < count; idx++ {
+			_, err := fake.Offer(testChannel, testStream, sessionID, make([]byte, 1024-32))
+			assert.NoError(t, err)
+		}
+		fake.EndOfStream(testChannel, testStream, sessionID)
+		recordingID, _ := fake.FindLastMatchingRecording(0, sessionID, testStream, testChannel)
+		return recordingID
+	}
+	_, err := fake.StartRecording(testChannel, testStream, true, false)
+	assert.NoError(t, err)
+	old := record(1, 16)
+	now = time.Now()
+	large := record(2, 200)
+
+	policy := archive.NewRetentionPolicy(testChannel, testStream)
+	policy.MaxAge = 30 * time.Minute
+	policy.MaxBytes = 128 * 1024
+	manager := archive.NewRetentionManager(fake, policy)
+	manager.DryRun = true
+	report, err := manager.Apply()
+	assert.NoError(t, err)
+	assert.Len(t, report.Actions, 2)
+	descriptors, _ := fake.ListRecordings(0, 10)
+	assert.Len(t, descriptors, 2)
+
+	manager.DryRun = false
+	report, err = manager.Apply()
+	assert.NoError(t, err)
+	assert.Empty(t, report.Errors())
+
+	descriptor, _ := fake.ListRecording(old)
+	assert.Nil(t, descriptor)
+	startPosition, err := fake.GetStartPosition(large)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 128*1024, startPosition)
+
+	// Replay from the new start
+	replayID, err := fake.StartReplay(large, startPosition, archive.RecordingLengthNull, testChannel, 2002)
+	assert.NoError(t, err)
+	count := fake.ReplayImage(replayID).Poll(func(*atomic.Buffer, int32, int32, *logbuffer.Header) {}, 1000)
+	assert.Equal(t, 200-128, count)
+}
+
+func TestFakeArchive_Retention_ActiveRecording(t *testing.T) {
+	fake := NewFakeArchive()
+	fake.SegmentFileLength = 64 * 1024
+	now := time.Now().Add(-2 * time.Hour)
+	fake.Now = func() time.Time { return now }
+
+	_, err := fake.StartRecording(testChannel, testStream, true, false)
+	assert.NoError(t, err)
+	offer := func(sessionID int32, count int) {
+		for idx := 0; idx < count; idx++ {
+			_, err := fake.Offer(testChannel, testStream, sessionID, make([]byte, 1024-32))
+			assert.NoError(t, err)
+		}
+	}
+	offer(1, 16)
+	fake.EndOfStream(testChannel, testStream, 1)
+	offer(2, 16)
+	fake.EndOfStream(testChannel, testStream, 2)
+	offer(3, 200) // Still recording, so its position is the recording position
+	active, _ := fake.FindLastMatchingRecording(0, 3, testStream, testChannel)
+
+	policy := archive.NewRetentionPolicy(testChannel, testStream)
+	policy.MaxAge = 30 * time.Minute
+	policy.MaxBytes = 64 * 1024
+	manager := archive.NewRetentionManager(fake, policy)
+	manager.PageSize = 1
+	report, err := manager.Apply()
+	assert.NoError(t, err)
+	assert.Empty(t, report.Errors())
+
+	var types []archive.RetentionActionType
+	for _, action := range report.Actions {
+		assert.True(t, action.Applied)
+		types = append(types, action.Type)
+	}
+	assert.Equal(t, []archive.RetentionActionType{
+		archive.RetentionPurgeRecording, archive.RetentionPurgeRecording, archive.RetentionPurgeSegments,
+	}, types)
+	assert.EqualValues(t, active, report.Actions[2].RecordingId)
+
+	descriptors, _ := fake.ListRecordings(0, 10)
+	if assert.Len(t, descriptors, 1, "the active recording is never purged") {
+		assert.EqualValues(t, active, descriptors[0].RecordingId)
+		assert.EqualValues(t, 3*64*1024, descriptors[0].StartPosition, "the segment being recorded is kept")
+	}
+}
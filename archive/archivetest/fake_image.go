@@ -0,0 +1,174 @@
+// Copyright (C) 2021-2022 Talos, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archivetest
+
+import (
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
+)
+
+// FakeImage is an aeron.Image serving a replay of a FakeArchive
+// recording. Polling delivers the recorded fragments with headers
+// whose positions match the recording, and follows an active
+// recording as further data is offered.
+type FakeImage struct {
+	archive        *FakeArchive
+	recording      *fakeRecording
+	replaySession  int64
+	subscriptionID int64
+	position       int64
+	limit          int64 // Replay stops at this position, aeron.NullValue to follow a live recording
+	closed         bool
+}
+
+var _ aeron.Image = (*FakeImage)(nil)
+
+// IsClosed is true once the replay has been stopped or the image closed
+func (image *FakeImage) IsClosed() bool {
+	image.archive.mtx.Lock()
+	defer image.archive.mtx.Unlock()
+	return image.closed
+}
+
+// Poll for up to fragmentLimit fragments
+func (image *FakeImage) Poll(handler term.FragmentHandler, fragmentLimit int) int {
+	return image.BoundedPoll(handler, image.limitPosition(), fragmentLimit)
+}
+
+// BoundedPoll for up to fragmentLimit fragments which end at or before limitPosition
+func (image *FakeImage) BoundedPoll(handler term.FragmentHandler, limitPosition int64, fragmentLimit int) int {
+	return image.ControlledPoll(func(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) term.ControlledPollAction {
+		if header.Position() > limitPosition {
+			return term.ControlledPollActionAbort
+		}
+		handler(buffer, offset, length, header)
+		return term.ControlledPollActionContinue
+	}, fragmentLimit)
+}
+
+// ControlledPoll for up to fragmentLimit fragments
+func (image *FakeImage) ControlledPoll(handler term.ControlledFragmentHandler, fragmentLimit int) int {
+	frames := image.pendingFrames(fragmentLimit)
+	count := 0
+	for _, frame := range frames {
+		header := image.header(frame)
+		action := handler(frame.buffer, frame.termOffset+logbuffer.DataFrameHeader.DataOffset, frame.length, header)
+		if action == term.ControlledPollActionAbort {
+			break
+		}
+		image.archive.mtx.Lock()
+		image.position = header.Position()
+		image.archive.mtx.Unlock()
+		count++
+		if action == term.ControlledPollActionBreak {
+			break
+		}
+	}
+	return count
+}
+
+// Position of the next fragment to be delivered
+func (image *FakeImage) Position() int64 {
+	image.archive.mtx.Lock()
+	defer image.archive.mtx.Unlock()
+	return image.position
+}
+
+// IsEndOfStream is true once the replay has delivered everything up
+// to its limit, or the stop position of a stopped recording
+func (image *FakeImage) IsEndOfStream() bool {
+	image.archive.mtx.Lock()
+	defer image.archive.mtx.Unlock()
+	if image.limit != aeron.NullValue && image.position >= image.limit {
+		return true
+	}
+	return !image.recording.isActive() && image.position >= image.recording.descriptor.StopPosition
+}
+
+// SessionID of the replay, the lower 32 bits of the replay session id
+func (image *FakeImage) SessionID() int32 {
+	return int32(image.replaySession)
+}
+
+// CorrelationID of the replay session
+func (image *FakeImage) CorrelationID() int64 {
+	return image.replaySession
+}
+
+// SubscriptionRegistrationID is a dummy value unique to the image
+func (image *FakeImage) SubscriptionRegistrationID() int64 {
+	return image.subscriptionID
+}
+
+// TermBufferLength of the recording
+func (image *FakeImage) TermBufferLength() int32 {
+	return image.recording.descriptor.TermBufferLength
+}
+
+// ActiveTransportCount is always 1
+func (image *FakeImage) ActiveTransportCount() int32 {
+	return 1
+}
+
+// Close the image
+func (image *FakeImage) Close() error {
+	image.archive.mtx.Lock()
+	defer image.archive.mtx.Unlock()
+	image.closed = true
+	return nil
+}
+
+func (image *FakeImage) limitPosition() int64 {
+	image.archive.mtx.Lock()
+	defer image.archive.mtx.Unlock()
+	if image.limit == aeron.NullValue {
+		return image.recording.position()
+	}
+	return image.limit
+}
+
+// pendingFrames to be delivered, taken under the lock so the handlers may call back into the archive
+func (image *FakeImage) pendingFrames(fragmentLimit int) []*fakeFrame {
+	image.archive.mtx.Lock()
+	defer image.archive.mtx.Unlock()
+	if image.closed {
+		return nil
+	}
+	var frames []*fakeFrame
+	for _, frame := range image.recording.frames {
+		if len(frames) >= fragmentLimit {
+			break
+		}
+		if frame.position < image.position {
+			continue
+		}
+		if image.limit != aeron.NullValue && frame.position >= image.limit {
+			break
+		}
+		frames = append(frames, frame)
+	}
+	return frames
+}
+
+func (image *FakeImage) header(frame *fakeFrame) *logbuffer.Header {
+	descriptor := &image.recording.descriptor
+	header := new(logbuffer.Header).Wrap(frame.buffer.Ptr(), frame.buffer.Capacity())
+	header.SetOffset(frame.termOffset)
+	header.SetInitialTermID(descriptor.InitialTermId)
+	header.SetPositionBitsToShift(positionBitsToShift(descriptor.TermBufferLength))
+	return header
+}
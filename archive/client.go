@@ -0,0 +1,76 @@
+// Copyright (C) 2021-2022 Talos, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"github.com/lirm/aeron-go/archive/codecs"
+)
+
+// ArchiveClient covers the control operations of an Archive. Code
+// which depends upon it rather than *Archive may be tested against
+// the in-memory archivetest.FakeArchive.
+//
+// See the corresponding methods of Archive for documentation.
+type ArchiveClient interface {
+	Close() error
+	ProtocolVersion() int32
+	ArchiveId() (int64, error)
+	KeepAlive() error
+	PollForErrorResponse() (int, error)
+
+	// Recording
+	StartRecording(channel string, stream int32, isLocal bool, autoStop bool) (int64, error)
+	StopRecording(channel string, stream int32) error
+	StopRecordingByIdentity(recordingID int64) (bool, error)
+	StopRecordingBySubscriptionId(subscriptionID int64) error
+	ExtendRecording(recordingID int64, stream int32, sourceLocation codecs.SourceLocationEnum, autoStop bool, channel string) (int64, error)
+	TruncateRecording(recordingID int64, position int64) error
+	PurgeRecording(recordingID int64) error
+
+	// Catalog queries
+	ListRecordings(fromRecordingID int64, recordCount int32) ([]*codecs.RecordingDescriptor, error)
+	ListRecordingsForUri(fromRecordingID int64, recordCount int32, channelFragment string, stream int32) ([]*codecs.RecordingDescriptor, error)
+	ListRecording(recordingID int64) (*codecs.RecordingDescriptor, error)
+	ListRecordingSubscriptions(pseudoIndex int32, subscriptionCount int32, applyStreamID bool, stream int32, channelFragment string) ([]*codecs.RecordingSubscriptionDescriptor, error)
+	FindLastMatchingRecording(minRecordingID int64, sessionID int32, stream int32, channel string) (int64, error)
+	GetRecordingPosition(recordingID int64) (int64, error)
+	GetMaxRecordedPosition(recordingID int64) (int64, error)
+	GetStartPosition(recordingID int64) (int64, error)
+	GetStopPosition(recordingID int64) (int64, error)
+
+	// Replay
+	StartReplay(recordingID int64, position int64, length int64, replayChannel string, replayStream int32) (int64, error)
+	BoundedReplay(recordingID int64, position int64, length int64, limitCounterID int32, replayStream int32, replayChannel string) (int64, error)
+	StartReplayWithParams(recordingID int64, replayChannel string, replayStream int32, params *ReplayParams) (int64, error)
+	RequestReplayToken(recordingID int64) (int64, error)
+	StopReplay(replaySessionID int64) error
+	StopAllReplays(recordingID int64) error
+
+	// Segments
+	DetachSegments(recordingID int64, newStartPosition int64) error
+	DeleteDetachedSegments(recordingID int64) (int64, error)
+	PurgeSegments(recordingID int64, newStartPosition int64) (int64, error)
+	AttachSegments(recordingID int64) (int64, error)
+	MigrateSegments(recordingID int64, position int64) (int64, error)
+
+	// Replication
+	Replicate(srcRecordingID int64, dstRecordingID int64, srcControlStreamID int32, srcControlChannel string, liveDestination string) (int64, error)
+	Replicate2(srcRecordingID int64, dstRecordingID int64, stopPosition int64, channelTagID int64, srcControlStreamID int32, srcControlChannel string, liveDestination string, replicationChannel string) (int64, error)
+	TaggedReplicate(srcRecordingID int64, dstRecordingID int64, channelTagID int64, subscriptionTagID int64, srcControlStreamID int32, srcControlChannel string, liveDestination string) (int64, error)
+	ReplicateWithParams(srcRecordingID int64, srcControlStreamID int32, srcControlChannel string, params *ReplicationParams) (int64, error)
+	StopReplication(replicationID int64) error
+}
+
+var _ ArchiveClient = (*Archive)(nil)
@@ -17,6 +17,8 @@ type BoundedReplayRequest struct {
 	Length           int64
 	LimitCounterId   int32
 	ReplayStreamId   int32
+	FileIoMaxLength  int32
+	ReplayToken      int64
 	ReplayChannel    []uint8
 }
 
@@ -47,6 +49,12 @@ func (b *BoundedReplayRequest) Encode(_m *SbeGoMarshaller, _w io.Writer, doRange
 	if err := _m.WriteInt32(_w, b.ReplayStreamId); err != nil {
 		return err
 	}
+	if err := _m.WriteInt32(_w, b.FileIoMaxLength); err != nil {
+		return err
+	}
+	if err := _m.WriteInt64(_w, b.ReplayToken); err != nil {
+		return err
+	}
 	if err := _m.WriteUint32(_w, uint32(len(b.ReplayChannel))); err != nil {
 		return err
 	}
@@ -106,6 +114,20 @@ func (b *BoundedReplayRequest) Decode(_m *SbeGoMarshaller, _r io.Reader, actingV
 			return err
 		}
 	}
+	if !b.FileIoMaxLengthInActingVersion(actingVersion) {
+		b.FileIoMaxLength = b.FileIoMaxLengthNullValue()
+	} else {
+		if err := _m.ReadInt32(_r, &b.FileIoMaxLength); err != nil {
+			return err
+		}
+	}
+	if !b.ReplayTokenInActingVersion(actingVersion) {
+		b.ReplayToken = b.ReplayTokenNullValue()
+	} else {
+		if err := _m.ReadInt64(_r, &b.ReplayToken); err != nil {
+			return err
+		}
+	}
 	if actingVersion > b.SbeSchemaVersion() && blockLength > b.SbeBlockLength() {
 		io.CopyN(ioutil.Discard, _r, int64(blockLength-b.SbeBlockLength()))
 	}
@@ -167,15 +189,27 @@ func (b *BoundedReplayRequest) RangeCheck(actingVersion uint16, schemaVersion ui
 			return fmt.Errorf("Range check failed on b.ReplayStreamId (%v < %v > %v)", b.ReplayStreamIdMinValue(), b.ReplayStreamId, b.ReplayStreamIdMaxValue())
 		}
 	}
+	if b.FileIoMaxLengthInActingVersion(actingVersion) {
+		if b.FileIoMaxLength != b.FileIoMaxLengthNullValue() && (b.FileIoMaxLength < b.FileIoMaxLengthMinValue() || b.FileIoMaxLength > b.FileIoMaxLengthMaxValue()) {
+			return fmt.Errorf("Range check failed on b.FileIoMaxLength (%v < %v > %v)", b.FileIoMaxLengthMinValue(), b.FileIoMaxLength, b.FileIoMaxLengthMaxValue())
+		}
+	}
+	if b.ReplayTokenInActingVersion(actingVersion) {
+		if b.ReplayToken != b.ReplayTokenNullValue() && (b.ReplayToken < b.ReplayTokenMinValue() || b.ReplayToken > b.ReplayTokenMaxValue()) {
+			return fmt.Errorf("Range check failed on b.ReplayToken (%v < %v > %v)", b.ReplayTokenMinValue(), b.ReplayToken, b.ReplayTokenMaxValue())
+		}
+	}
 	return nil
 }
 
 func BoundedReplayRequestInit(b *BoundedReplayRequest) {
+	b.FileIoMaxLength = math.MinInt32
+	b.ReplayToken = math.MinInt64
 	return
 }
 
 func (*BoundedReplayRequest) SbeBlockLength() (blockLength uint16) {
-	return 48
+	return 60
 }
 
 func (*BoundedReplayRequest) SbeTemplateId() (templateId uint16) {
@@ -187,7 +221,7 @@ func (*BoundedReplayRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*BoundedReplayRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*BoundedReplayRequest) SbeSemanticType() (semanticType []byte) {
@@ -488,6 +522,90 @@ func (*BoundedReplayRequest) ReplayStreamIdNullValue() int32 {
 	return math.MinInt32
 }
 
+func (*BoundedReplayRequest) FileIoMaxLengthId() uint16 {
+	return 9
+}
+
+func (*BoundedReplayRequest) FileIoMaxLengthSinceVersion() uint16 {
+	return 7
+}
+
+func (b *BoundedReplayRequest) FileIoMaxLengthInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= b.FileIoMaxLengthSinceVersion()
+}
+
+func (*BoundedReplayRequest) FileIoMaxLengthDeprecated() uint16 {
+	return 0
+}
+
+func (*BoundedReplayRequest) FileIoMaxLengthMetaAttribute(meta int) string {
+	switch meta {
+	case 1:
+		return ""
+	case 2:
+		return ""
+	case 3:
+		return ""
+	case 4:
+		return "optional"
+	}
+	return ""
+}
+
+func (*BoundedReplayRequest) FileIoMaxLengthMinValue() int32 {
+	return math.MinInt32 + 1
+}
+
+func (*BoundedReplayRequest) FileIoMaxLengthMaxValue() int32 {
+	return math.MaxInt32
+}
+
+func (*BoundedReplayRequest) FileIoMaxLengthNullValue() int32 {
+	return math.MinInt32
+}
+
+func (*BoundedReplayRequest) ReplayTokenId() uint16 {
+	return 10
+}
+
+func (*BoundedReplayRequest) ReplayTokenSinceVersion() uint16 {
+	return 10
+}
+
+func (b *BoundedReplayRequest) ReplayTokenInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= b.ReplayTokenSinceVersion()
+}
+
+func (*BoundedReplayRequest) ReplayTokenDeprecated() uint16 {
+	return 0
+}
+
+func (*BoundedReplayRequest) ReplayTokenMetaAttribute(meta int) string {
+	switch meta {
+	case 1:
+		return ""
+	case 2:
+		return ""
+	case 3:
+		return ""
+	case 4:
+		return "optional"
+	}
+	return ""
+}
+
+func (*BoundedReplayRequest) ReplayTokenMinValue() int64 {
+	return math.MinInt64 + 1
+}
+
+func (*BoundedReplayRequest) ReplayTokenMaxValue() int64 {
+	return math.MaxInt64
+}
+
+func (*BoundedReplayRequest) ReplayTokenNullValue() int64 {
+	return math.MinInt64
+}
+
 func (*BoundedReplayRequest) ReplayChannelMetaAttribute(meta int) string {
 	switch meta {
 	case 1:
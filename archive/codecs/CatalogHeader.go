@@ -141,7 +141,7 @@ func (*CatalogHeader) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*CatalogHeader) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*CatalogHeader) SbeSemanticType() (semanticType []byte) {
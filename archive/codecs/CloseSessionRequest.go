@@ -70,7 +70,7 @@ func (*CloseSessionRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*CloseSessionRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*CloseSessionRequest) SbeSemanticType() (semanticType []byte) {
@@ -124,7 +124,7 @@ func (*ConnectRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*ConnectRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*ConnectRequest) SbeSemanticType() (semanticType []byte) {
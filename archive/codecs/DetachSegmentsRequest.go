@@ -118,7 +118,7 @@ func (*DetachSegmentsRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*DetachSegmentsRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*DetachSegmentsRequest) SbeSemanticType() (semanticType []byte) {
@@ -163,7 +163,7 @@ func (*ExtendRecordingRequest2) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*ExtendRecordingRequest2) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*ExtendRecordingRequest2) SbeSemanticType() (semanticType []byte) {
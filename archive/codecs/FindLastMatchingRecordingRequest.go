@@ -155,7 +155,7 @@ func (*FindLastMatchingRecordingRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*FindLastMatchingRecordingRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*FindLastMatchingRecordingRequest) SbeSemanticType() (semanticType []byte) {
@@ -118,7 +118,7 @@ func (*ListRecordingsRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*ListRecordingsRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*ListRecordingsRequest) SbeSemanticType() (semanticType []byte) {
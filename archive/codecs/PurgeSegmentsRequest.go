@@ -118,7 +118,7 @@ func (*PurgeSegmentsRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*PurgeSegmentsRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*PurgeSegmentsRequest) SbeSemanticType() (semanticType []byte) {
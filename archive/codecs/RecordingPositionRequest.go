@@ -102,7 +102,7 @@ func (*RecordingPositionRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*RecordingPositionRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*RecordingPositionRequest) SbeSemanticType() (semanticType []byte) {
@@ -160,7 +160,7 @@ func (*RecordingStarted) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*RecordingStarted) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*RecordingStarted) SbeSemanticType() (semanticType []byte) {
@@ -102,7 +102,7 @@ func (*RecordingStopped) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*RecordingStopped) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*RecordingStopped) SbeSemanticType() (semanticType []byte) {
@@ -139,7 +139,7 @@ func (*RecordingSubscriptionDescriptor) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*RecordingSubscriptionDescriptor) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*RecordingSubscriptionDescriptor) SbeSemanticType() (semanticType []byte) {
@@ -16,6 +16,8 @@ type ReplayRequest struct {
 	Position         int64
 	Length           int64
 	ReplayStreamId   int32
+	FileIoMaxLength  int32
+	ReplayToken      int64
 	ReplayChannel    []uint8
 }
 
@@ -43,6 +45,12 @@ func (r *ReplayRequest) Encode(_m *SbeGoMarshaller, _w io.Writer, doRangeCheck b
 	if err := _m.WriteInt32(_w, r.ReplayStreamId); err != nil {
 		return err
 	}
+	if err := _m.WriteInt32(_w, r.FileIoMaxLength); err != nil {
+		return err
+	}
+	if err := _m.WriteInt64(_w, r.ReplayToken); err != nil {
+		return err
+	}
 	if err := _m.WriteUint32(_w, uint32(len(r.ReplayChannel))); err != nil {
 		return err
 	}
@@ -95,6 +103,20 @@ func (r *ReplayRequest) Decode(_m *SbeGoMarshaller, _r io.Reader, actingVersion
 			return err
 		}
 	}
+	if !r.FileIoMaxLengthInActingVersion(actingVersion) {
+		r.FileIoMaxLength = r.FileIoMaxLengthNullValue()
+	} else {
+		if err := _m.ReadInt32(_r, &r.FileIoMaxLength); err != nil {
+			return err
+		}
+	}
+	if !r.ReplayTokenInActingVersion(actingVersion) {
+		r.ReplayToken = r.ReplayTokenNullValue()
+	} else {
+		if err := _m.ReadInt64(_r, &r.ReplayToken); err != nil {
+			return err
+		}
+	}
 	if actingVersion > r.SbeSchemaVersion() && blockLength > r.SbeBlockLength() {
 		io.CopyN(ioutil.Discard, _r, int64(blockLength-r.SbeBlockLength()))
 	}
@@ -151,15 +173,27 @@ func (r *ReplayRequest) RangeCheck(actingVersion uint16, schemaVersion uint16) e
 			return fmt.Errorf("Range check failed on r.ReplayStreamId (%v < %v > %v)", r.ReplayStreamIdMinValue(), r.ReplayStreamId, r.ReplayStreamIdMaxValue())
 		}
 	}
+	if r.FileIoMaxLengthInActingVersion(actingVersion) {
+		if r.FileIoMaxLength != r.FileIoMaxLengthNullValue() && (r.FileIoMaxLength < r.FileIoMaxLengthMinValue() || r.FileIoMaxLength > r.FileIoMaxLengthMaxValue()) {
+			return fmt.Errorf("Range check failed on r.FileIoMaxLength (%v < %v > %v)", r.FileIoMaxLengthMinValue(), r.FileIoMaxLength, r.FileIoMaxLengthMaxValue())
+		}
+	}
+	if r.ReplayTokenInActingVersion(actingVersion) {
+		if r.ReplayToken != r.ReplayTokenNullValue() && (r.ReplayToken < r.ReplayTokenMinValue() || r.ReplayToken > r.ReplayTokenMaxValue()) {
+			return fmt.Errorf("Range check failed on r.ReplayToken (%v < %v > %v)", r.ReplayTokenMinValue(), r.ReplayToken, r.ReplayTokenMaxValue())
+		}
+	}
 	return nil
 }
 
 func ReplayRequestInit(r *ReplayRequest) {
+	r.FileIoMaxLength = math.MinInt32
+	r.ReplayToken = math.MinInt64
 	return
 }
 
 func (*ReplayRequest) SbeBlockLength() (blockLength uint16) {
-	return 44
+	return 56
 }
 
 func (*ReplayRequest) SbeTemplateId() (templateId uint16) {
@@ -171,7 +205,7 @@ func (*ReplayRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*ReplayRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*ReplayRequest) SbeSemanticType() (semanticType []byte) {
@@ -430,6 +464,90 @@ func (*ReplayRequest) ReplayStreamIdNullValue() int32 {
 	return math.MinInt32
 }
 
+func (*ReplayRequest) FileIoMaxLengthId() uint16 {
+	return 8
+}
+
+func (*ReplayRequest) FileIoMaxLengthSinceVersion() uint16 {
+	return 7
+}
+
+func (r *ReplayRequest) FileIoMaxLengthInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= r.FileIoMaxLengthSinceVersion()
+}
+
+func (*ReplayRequest) FileIoMaxLengthDeprecated() uint16 {
+	return 0
+}
+
+func (*ReplayRequest) FileIoMaxLengthMetaAttribute(meta int) string {
+	switch meta {
+	case 1:
+		return ""
+	case 2:
+		return ""
+	case 3:
+		return ""
+	case 4:
+		return "optional"
+	}
+	return ""
+}
+
+func (*ReplayRequest) FileIoMaxLengthMinValue() int32 {
+	return math.MinInt32 + 1
+}
+
+func (*ReplayRequest) FileIoMaxLengthMaxValue() int32 {
+	return math.MaxInt32
+}
+
+func (*ReplayRequest) FileIoMaxLengthNullValue() int32 {
+	return math.MinInt32
+}
+
+func (*ReplayRequest) ReplayTokenId() uint16 {
+	return 9
+}
+
+func (*ReplayRequest) ReplayTokenSinceVersion() uint16 {
+	return 10
+}
+
+func (r *ReplayRequest) ReplayTokenInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= r.ReplayTokenSinceVersion()
+}
+
+func (*ReplayRequest) ReplayTokenDeprecated() uint16 {
+	return 0
+}
+
+func (*ReplayRequest) ReplayTokenMetaAttribute(meta int) string {
+	switch meta {
+	case 1:
+		return ""
+	case 2:
+		return ""
+	case 3:
+		return ""
+	case 4:
+		return "optional"
+	}
+	return ""
+}
+
+func (*ReplayRequest) ReplayTokenMinValue() int64 {
+	return math.MinInt64 + 1
+}
+
+func (*ReplayRequest) ReplayTokenMaxValue() int64 {
+	return math.MaxInt64
+}
+
+func (*ReplayRequest) ReplayTokenNullValue() int64 {
+	return math.MinInt64
+}
+
 func (*ReplayRequest) ReplayChannelMetaAttribute(meta int) string {
 	switch meta {
 	case 1:
@@ -10,17 +10,21 @@ import (
 )
 
 type ReplicateRequest2 struct {
-	ControlSessionId   int64
-	CorrelationId      int64
-	SrcRecordingId     int64
-	DstRecordingId     int64
-	StopPosition       int64
-	ChannelTagId       int64
-	SubscriptionTagId  int64
-	SrcControlStreamId int32
-	SrcControlChannel  []uint8
-	LiveDestination    []uint8
-	ReplicationChannel []uint8
+	ControlSessionId     int64
+	CorrelationId        int64
+	SrcRecordingId       int64
+	DstRecordingId       int64
+	StopPosition         int64
+	ChannelTagId         int64
+	SubscriptionTagId    int64
+	SrcControlStreamId   int32
+	FileIoMaxLength      int32
+	ReplicationSessionId int32
+	SrcControlChannel    []uint8
+	LiveDestination      []uint8
+	ReplicationChannel   []uint8
+	EncodedCredentials   []uint8
+	SrcResponseChannel   []uint8
 }
 
 func (r *ReplicateRequest2) Encode(_m *SbeGoMarshaller, _w io.Writer, doRangeCheck bool) error {
@@ -53,6 +57,12 @@ func (r *ReplicateRequest2) Encode(_m *SbeGoMarshaller, _w io.Writer, doRangeChe
 	if err := _m.WriteInt32(_w, r.SrcControlStreamId); err != nil {
 		return err
 	}
+	if err := _m.WriteInt32(_w, r.FileIoMaxLength); err != nil {
+		return err
+	}
+	if err := _m.WriteInt32(_w, r.ReplicationSessionId); err != nil {
+		return err
+	}
 	if err := _m.WriteUint32(_w, uint32(len(r.SrcControlChannel))); err != nil {
 		return err
 	}
@@ -71,6 +81,18 @@ func (r *ReplicateRequest2) Encode(_m *SbeGoMarshaller, _w io.Writer, doRangeChe
 	if err := _m.WriteBytes(_w, r.ReplicationChannel); err != nil {
 		return err
 	}
+	if err := _m.WriteUint32(_w, uint32(len(r.EncodedCredentials))); err != nil {
+		return err
+	}
+	if err := _m.WriteBytes(_w, r.EncodedCredentials); err != nil {
+		return err
+	}
+	if err := _m.WriteUint32(_w, uint32(len(r.SrcResponseChannel))); err != nil {
+		return err
+	}
+	if err := _m.WriteBytes(_w, r.SrcResponseChannel); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -131,6 +153,20 @@ func (r *ReplicateRequest2) Decode(_m *SbeGoMarshaller, _r io.Reader, actingVers
 			return err
 		}
 	}
+	if !r.FileIoMaxLengthInActingVersion(actingVersion) {
+		r.FileIoMaxLength = r.FileIoMaxLengthNullValue()
+	} else {
+		if err := _m.ReadInt32(_r, &r.FileIoMaxLength); err != nil {
+			return err
+		}
+	}
+	if !r.ReplicationSessionIdInActingVersion(actingVersion) {
+		r.ReplicationSessionId = r.ReplicationSessionIdNullValue()
+	} else {
+		if err := _m.ReadInt32(_r, &r.ReplicationSessionId); err != nil {
+			return err
+		}
+	}
 	if actingVersion > r.SbeSchemaVersion() && blockLength > r.SbeBlockLength() {
 		io.CopyN(ioutil.Discard, _r, int64(blockLength-r.SbeBlockLength()))
 	}
@@ -176,6 +212,34 @@ func (r *ReplicateRequest2) Decode(_m *SbeGoMarshaller, _r io.Reader, actingVers
 			return err
 		}
 	}
+
+	if r.EncodedCredentialsInActingVersion(actingVersion) {
+		var EncodedCredentialsLength uint32
+		if err := _m.ReadUint32(_r, &EncodedCredentialsLength); err != nil {
+			return err
+		}
+		if cap(r.EncodedCredentials) < int(EncodedCredentialsLength) {
+			r.EncodedCredentials = make([]uint8, EncodedCredentialsLength)
+		}
+		r.EncodedCredentials = r.EncodedCredentials[:EncodedCredentialsLength]
+		if err := _m.ReadBytes(_r, r.EncodedCredentials); err != nil {
+			return err
+		}
+	}
+
+	if r.SrcResponseChannelInActingVersion(actingVersion) {
+		var SrcResponseChannelLength uint32
+		if err := _m.ReadUint32(_r, &SrcResponseChannelLength); err != nil {
+			return err
+		}
+		if cap(r.SrcResponseChannel) < int(SrcResponseChannelLength) {
+			r.SrcResponseChannel = make([]uint8, SrcResponseChannelLength)
+		}
+		r.SrcResponseChannel = r.SrcResponseChannel[:SrcResponseChannelLength]
+		if err := _m.ReadBytes(_r, r.SrcResponseChannel); err != nil {
+			return err
+		}
+	}
 	if doRangeCheck {
 		if err := r.RangeCheck(actingVersion, r.SbeSchemaVersion()); err != nil {
 			return err
@@ -225,15 +289,27 @@ func (r *ReplicateRequest2) RangeCheck(actingVersion uint16, schemaVersion uint1
 			return fmt.Errorf("Range check failed on r.SrcControlStreamId (%v < %v > %v)", r.SrcControlStreamIdMinValue(), r.SrcControlStreamId, r.SrcControlStreamIdMaxValue())
 		}
 	}
+	if r.FileIoMaxLengthInActingVersion(actingVersion) {
+		if r.FileIoMaxLength != r.FileIoMaxLengthNullValue() && (r.FileIoMaxLength < r.FileIoMaxLengthMinValue() || r.FileIoMaxLength > r.FileIoMaxLengthMaxValue()) {
+			return fmt.Errorf("Range check failed on r.FileIoMaxLength (%v < %v > %v)", r.FileIoMaxLengthMinValue(), r.FileIoMaxLength, r.FileIoMaxLengthMaxValue())
+		}
+	}
+	if r.ReplicationSessionIdInActingVersion(actingVersion) {
+		if r.ReplicationSessionId != r.ReplicationSessionIdNullValue() && (r.ReplicationSessionId < r.ReplicationSessionIdMinValue() || r.ReplicationSessionId > r.ReplicationSessionIdMaxValue()) {
+			return fmt.Errorf("Range check failed on r.ReplicationSessionId (%v < %v > %v)", r.ReplicationSessionIdMinValue(), r.ReplicationSessionId, r.ReplicationSessionIdMaxValue())
+		}
+	}
 	return nil
 }
 
 func ReplicateRequest2Init(r *ReplicateRequest2) {
+	r.FileIoMaxLength = math.MinInt32
+	r.ReplicationSessionId = math.MinInt32
 	return
 }
 
 func (*ReplicateRequest2) SbeBlockLength() (blockLength uint16) {
-	return 60
+	return 68
 }
 
 func (*ReplicateRequest2) SbeTemplateId() (templateId uint16) {
@@ -245,7 +321,7 @@ func (*ReplicateRequest2) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*ReplicateRequest2) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*ReplicateRequest2) SbeSemanticType() (semanticType []byte) {
@@ -588,6 +664,90 @@ func (*ReplicateRequest2) SrcControlStreamIdNullValue() int32 {
 	return math.MinInt32
 }
 
+func (*ReplicateRequest2) FileIoMaxLengthId() uint16 {
+	return 12
+}
+
+func (*ReplicateRequest2) FileIoMaxLengthSinceVersion() uint16 {
+	return 7
+}
+
+func (r *ReplicateRequest2) FileIoMaxLengthInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= r.FileIoMaxLengthSinceVersion()
+}
+
+func (*ReplicateRequest2) FileIoMaxLengthDeprecated() uint16 {
+	return 0
+}
+
+func (*ReplicateRequest2) FileIoMaxLengthMetaAttribute(meta int) string {
+	switch meta {
+	case 1:
+		return ""
+	case 2:
+		return ""
+	case 3:
+		return ""
+	case 4:
+		return "optional"
+	}
+	return ""
+}
+
+func (*ReplicateRequest2) FileIoMaxLengthMinValue() int32 {
+	return math.MinInt32 + 1
+}
+
+func (*ReplicateRequest2) FileIoMaxLengthMaxValue() int32 {
+	return math.MaxInt32
+}
+
+func (*ReplicateRequest2) FileIoMaxLengthNullValue() int32 {
+	return math.MinInt32
+}
+
+func (*ReplicateRequest2) ReplicationSessionIdId() uint16 {
+	return 13
+}
+
+func (*ReplicateRequest2) ReplicationSessionIdSinceVersion() uint16 {
+	return 8
+}
+
+func (r *ReplicateRequest2) ReplicationSessionIdInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= r.ReplicationSessionIdSinceVersion()
+}
+
+func (*ReplicateRequest2) ReplicationSessionIdDeprecated() uint16 {
+	return 0
+}
+
+func (*ReplicateRequest2) ReplicationSessionIdMetaAttribute(meta int) string {
+	switch meta {
+	case 1:
+		return ""
+	case 2:
+		return ""
+	case 3:
+		return ""
+	case 4:
+		return "optional"
+	}
+	return ""
+}
+
+func (*ReplicateRequest2) ReplicationSessionIdMinValue() int32 {
+	return math.MinInt32 + 1
+}
+
+func (*ReplicateRequest2) ReplicationSessionIdMaxValue() int32 {
+	return math.MaxInt32
+}
+
+func (*ReplicateRequest2) ReplicationSessionIdNullValue() int32 {
+	return math.MinInt32
+}
+
 func (*ReplicateRequest2) SrcControlChannelMetaAttribute(meta int) string {
 	switch meta {
 	case 1:
@@ -689,3 +849,71 @@ func (ReplicateRequest2) ReplicationChannelCharacterEncoding() string {
 func (ReplicateRequest2) ReplicationChannelHeaderLength() uint64 {
 	return 4
 }
+
+func (*ReplicateRequest2) EncodedCredentialsMetaAttribute(meta int) string {
+	switch meta {
+	case 1:
+		return ""
+	case 2:
+		return ""
+	case 3:
+		return ""
+	case 4:
+		return "required"
+	}
+	return ""
+}
+
+func (*ReplicateRequest2) EncodedCredentialsSinceVersion() uint16 {
+	return 8
+}
+
+func (r *ReplicateRequest2) EncodedCredentialsInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= r.EncodedCredentialsSinceVersion()
+}
+
+func (*ReplicateRequest2) EncodedCredentialsDeprecated() uint16 {
+	return 0
+}
+
+func (ReplicateRequest2) EncodedCredentialsCharacterEncoding() string {
+	return "null"
+}
+
+func (ReplicateRequest2) EncodedCredentialsHeaderLength() uint64 {
+	return 4
+}
+
+func (*ReplicateRequest2) SrcResponseChannelMetaAttribute(meta int) string {
+	switch meta {
+	case 1:
+		return ""
+	case 2:
+		return ""
+	case 3:
+		return ""
+	case 4:
+		return "required"
+	}
+	return ""
+}
+
+func (*ReplicateRequest2) SrcResponseChannelSinceVersion() uint16 {
+	return 10
+}
+
+func (r *ReplicateRequest2) SrcResponseChannelInActingVersion(actingVersion uint16) bool {
+	return actingVersion >= r.SrcResponseChannelSinceVersion()
+}
+
+func (*ReplicateRequest2) SrcResponseChannelDeprecated() uint16 {
+	return 0
+}
+
+func (ReplicateRequest2) SrcResponseChannelCharacterEncoding() string {
+	return "US-ASCII"
+}
+
+func (ReplicateRequest2) SrcResponseChannelHeaderLength() uint64 {
+	return 4
+}
@@ -102,7 +102,7 @@ func (*StartPositionRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*StartPositionRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*StartPositionRequest) SbeSemanticType() (semanticType []byte) {
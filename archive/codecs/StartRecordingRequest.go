@@ -135,7 +135,7 @@ func (*StartRecordingRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*StartRecordingRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*StartRecordingRequest) SbeSemanticType() (semanticType []byte) {
@@ -102,7 +102,7 @@ func (*StopAllReplaysRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*StopAllReplaysRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*StopAllReplaysRequest) SbeSemanticType() (semanticType []byte) {
@@ -102,7 +102,7 @@ func (*StopPositionRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*StopPositionRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*StopPositionRequest) SbeSemanticType() (semanticType []byte) {
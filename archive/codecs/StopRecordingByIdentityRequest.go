@@ -102,7 +102,7 @@ func (*StopRecordingByIdentityRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*StopRecordingByIdentityRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*StopRecordingByIdentityRequest) SbeSemanticType() (semanticType []byte) {
@@ -102,7 +102,7 @@ func (*StopRecordingSubscriptionRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*StopRecordingSubscriptionRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*StopRecordingSubscriptionRequest) SbeSemanticType() (semanticType []byte) {
@@ -102,7 +102,7 @@ func (*StopReplayRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*StopReplayRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*StopReplayRequest) SbeSemanticType() (semanticType []byte) {
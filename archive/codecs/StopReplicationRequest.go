@@ -102,7 +102,7 @@ func (*StopReplicationRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*StopReplicationRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*StopReplicationRequest) SbeSemanticType() (semanticType []byte) {
@@ -118,7 +118,7 @@ func (*TruncateRecordingRequest) SbeSchemaId() (schemaId uint16) {
 }
 
 func (*TruncateRecordingRequest) SbeSchemaVersion() (schemaVersion uint16) {
-	return 6
+	return 10
 }
 
 func (*TruncateRecordingRequest) SbeSemanticType() (semanticType []byte) {
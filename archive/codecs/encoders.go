@@ -125,13 +125,15 @@ func StopRecordingRequestPacket(marshaller *SbeGoMarshaller, rangeChecking bool,
 	return buffer.Bytes(), nil
 }
 
-func ReplayRequestPacket(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64, recordingId int64, position int64, length int64, replayStream int32, replayChannel string) ([]byte, error) {
+func ReplayRequestPacket(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64, recordingId int64, position int64, length int64, fileIoMaxLength int32, replayToken int64, replayStream int32, replayChannel string) ([]byte, error) {
 	var request ReplayRequest
 	request.ControlSessionId = controlSessionId
 	request.CorrelationId = correlationId
 	request.RecordingId = recordingId
 	request.Position = position
 	request.Length = length
+	request.FileIoMaxLength = fileIoMaxLength
+	request.ReplayToken = replayToken
 	request.ReplayStreamId = replayStream
 	request.ReplayChannel = []uint8(replayChannel)
 
@@ -401,7 +403,7 @@ func ListRecordingSubscriptionsPacket(marshaller *SbeGoMarshaller, rangeChecking
 	return buffer.Bytes(), nil
 }
 
-func BoundedReplayPacket(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64, recordingId int64, position int64, length int64, limitCounterId int32, replayStream int32, replayChannel string) ([]byte, error) {
+func BoundedReplayPacket(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64, recordingId int64, position int64, length int64, limitCounterId int32, fileIoMaxLength int32, replayToken int64, replayStream int32, replayChannel string) ([]byte, error) {
 	var request BoundedReplayRequest
 	request.ControlSessionId = controlSessionId
 	request.CorrelationId = correlationId
@@ -409,6 +411,8 @@ func BoundedReplayPacket(marshaller *SbeGoMarshaller, rangeChecking bool, contro
 	request.Position = position
 	request.Length = length
 	request.LimitCounterId = limitCounterId
+	request.FileIoMaxLength = fileIoMaxLength
+	request.ReplayToken = replayToken
 	request.ReplayStreamId = replayStream
 	request.ReplayChannel = []uint8(replayChannel)
 
@@ -708,6 +712,12 @@ func TaggedReplicateRequestPacket(marshaller *SbeGoMarshaller, rangeChecking boo
 }
 
 func ReplicateRequest2Packet(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64, srcRecordingId int64, dstRecordingId int64, stopPosition int64, channelTagId int64, srcControlStreamId int32, srcControlChannel string, liveDestination string, replicationChannel string) ([]byte, error) {
+	return ReplicateRequest2WithParamsPacket(marshaller, rangeChecking, controlSessionId, correlationId, srcRecordingId, dstRecordingId, stopPosition, channelTagId, 0, srcControlStreamId, -1, -1, srcControlChannel, liveDestination, replicationChannel, nil, "")
+}
+
+// ReplicateRequest2WithParamsPacket carries the fields added to ReplicateRequest2 in later schema
+// versions: fileIoMaxLength, replicationSessionId, encodedCredentials and srcResponseChannel
+func ReplicateRequest2WithParamsPacket(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64, srcRecordingId int64, dstRecordingId int64, stopPosition int64, channelTagId int64, subscriptionTagId int64, srcControlStreamId int32, fileIoMaxLength int32, replicationSessionId int32, srcControlChannel string, liveDestination string, replicationChannel string, encodedCredentials []uint8, srcResponseChannel string) ([]byte, error) {
 	var request ReplicateRequest2
 	request.ControlSessionId = controlSessionId
 	request.CorrelationId = correlationId
@@ -715,10 +725,15 @@ func ReplicateRequest2Packet(marshaller *SbeGoMarshaller, rangeChecking bool, co
 	request.DstRecordingId = dstRecordingId
 	request.StopPosition = stopPosition
 	request.ChannelTagId = channelTagId
+	request.SubscriptionTagId = subscriptionTagId
 	request.SrcControlStreamId = srcControlStreamId
+	request.FileIoMaxLength = fileIoMaxLength
+	request.ReplicationSessionId = replicationSessionId
 	request.SrcControlChannel = []uint8(srcControlChannel)
 	request.LiveDestination = []uint8(liveDestination)
 	request.ReplicationChannel = []uint8(replicationChannel)
+	request.EncodedCredentials = encodedCredentials
+	request.SrcResponseChannel = []uint8(srcResponseChannel)
 
 	// Marshal it
 	header := MessageHeader{BlockLength: request.SbeBlockLength(), TemplateId: request.SbeTemplateId(), SchemaId: request.SbeSchemaId(), Version: request.SbeSchemaVersion()}
@@ -751,3 +766,59 @@ func PurgeRecordingRequestPacket(marshaller *SbeGoMarshaller, rangeChecking bool
 
 	return buffer.Bytes(), nil
 }
+
+func MaxRecordedPositionRequestPacket(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64, recordingId int64) ([]byte, error) {
+	var request MaxRecordedPositionRequest
+	request.ControlSessionId = controlSessionId
+	request.CorrelationId = correlationId
+	request.RecordingId = recordingId
+
+	// Marshal it
+	header := MessageHeader{BlockLength: request.SbeBlockLength(), TemplateId: request.SbeTemplateId(), SchemaId: request.SbeSchemaId(), Version: request.SbeSchemaVersion()}
+	buffer := new(bytes.Buffer)
+	if err := header.Encode(marshaller, buffer); err != nil {
+		return nil, err
+	}
+	if err := request.Encode(marshaller, buffer, rangeChecking); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func ArchiveIdRequestPacket(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64) ([]byte, error) {
+	var request ArchiveIdRequest
+	request.ControlSessionId = controlSessionId
+	request.CorrelationId = correlationId
+
+	// Marshal it
+	header := MessageHeader{BlockLength: request.SbeBlockLength(), TemplateId: request.SbeTemplateId(), SchemaId: request.SbeSchemaId(), Version: request.SbeSchemaVersion()}
+	buffer := new(bytes.Buffer)
+	if err := header.Encode(marshaller, buffer); err != nil {
+		return nil, err
+	}
+	if err := request.Encode(marshaller, buffer, rangeChecking); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func ReplayTokenRequestPacket(marshaller *SbeGoMarshaller, rangeChecking bool, controlSessionId int64, correlationId int64, recordingId int64) ([]byte, error) {
+	var request ReplayTokenRequest
+	request.ControlSessionId = controlSessionId
+	request.CorrelationId = correlationId
+	request.RecordingId = recordingId
+
+	// Marshal it
+	header := MessageHeader{BlockLength: request.SbeBlockLength(), TemplateId: request.SbeTemplateId(), SchemaId: request.SbeSchemaId(), Version: request.SbeSchemaVersion()}
+	buffer := new(bytes.Buffer)
+	if err := header.Encode(marshaller, buffer); err != nil {
+		return nil, err
+	}
+	if err := request.Encode(marshaller, buffer, rangeChecking); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -44,4 +44,22 @@ func TestEncoders(t *testing.T) {
 		t.Logf("StartRecordingRequestPacket() failed length check: %d", len(packet))
 		t.Fail()
 	}
+	packet, err = ReplayRequestPacket(marshaller, rangeChecking, 1234, 5678, 9, -1, -1, -1, -1, stream, channel)
+	if err != nil {
+		t.Log("ReplayRequestPacket() failed")
+		t.Fail()
+	}
+	if len(packet) != 102 { // Look for unexpected change
+		t.Logf("ReplayRequestPacket() failed length check: %d", len(packet))
+		t.Fail()
+	}
+	packet, err = ArchiveIdRequestPacket(marshaller, rangeChecking, 1234, 5678)
+	if err != nil {
+		t.Log("ArchiveIdRequestPacket() failed")
+		t.Fail()
+	}
+	if len(packet) != 24 { // Look for unexpected change
+		t.Logf("ArchiveIdRequestPacket() failed length check: %d", len(packet))
+		t.Fail()
+	}
 }
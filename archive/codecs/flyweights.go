@@ -0,0 +1,317 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+)
+
+// Flyweight decoders
+//
+// These read the archive's responses in place on an atomic.Buffer, so the
+// control response poller can decode a message's header and correlation
+// fields without copying it, and only build the generated struct for the
+// messages it keeps. They cover the control responses, recording signals and
+// recording descriptors; the generated codecs remain for everything else.
+//
+// A decoder wraps the message body following the MessageHeader with the
+// block length and version from that header. Fields added after the acting
+// version read as their null value.
+
+// MessageHeaderLength is the encoded length of a MessageHeader
+const MessageHeaderLength = 8
+
+// flyweight holds the position of a wrapped message body
+type flyweight struct {
+	buffer            *atomic.Buffer
+	offset            int32
+	actingBlockLength uint16
+	actingVersion     uint16
+}
+
+func (f *flyweight) wrap(buffer *atomic.Buffer, offset int32, actingBlockLength uint16, actingVersion uint16) {
+	f.buffer = buffer
+	f.offset = offset
+	f.actingBlockLength = actingBlockLength
+	f.actingVersion = actingVersion
+}
+
+// Buffer returns the wrapped buffer
+func (f *flyweight) Buffer() *atomic.Buffer {
+	return f.buffer
+}
+
+// Offset returns the offset of the message body in the buffer
+func (f *flyweight) Offset() int32 {
+	return f.offset
+}
+
+// ActingBlockLength returns the block length the message was encoded with
+func (f *flyweight) ActingBlockLength() uint16 {
+	return f.actingBlockLength
+}
+
+// ActingVersion returns the schema version the message was encoded with
+func (f *flyweight) ActingVersion() uint16 {
+	return f.actingVersion
+}
+
+// getVarData copies the variable length field at *limit into dst, reusing its
+// capacity, and advances *limit past it. It fails if the field's length is
+// negative or runs past the end of the buffer.
+func (f *flyweight) getVarData(limit *int32, dst []uint8) ([]uint8, error) {
+	if *limit+4 > f.buffer.Capacity() {
+		return dst[:0], fmt.Errorf("var data header at %d beyond buffer capacity %d", *limit, f.buffer.Capacity())
+	}
+	length := f.buffer.GetInt32(*limit)
+	if length < 0 || length > f.buffer.Capacity()-*limit-4 {
+		return dst[:0], fmt.Errorf("invalid var data length %d at %d, buffer capacity %d", length, *limit, f.buffer.Capacity())
+	}
+	if cap(dst) < int(length) {
+		dst = make([]uint8, length)
+	}
+	dst = dst[:length]
+	f.buffer.GetBytes(*limit+4, dst)
+	*limit += 4 + length
+	return dst, nil
+}
+
+// MessageHeaderDecoder reads the MessageHeader preceding each message
+type MessageHeaderDecoder struct {
+	buffer *atomic.Buffer
+	offset int32
+}
+
+func (d *MessageHeaderDecoder) Wrap(buffer *atomic.Buffer, offset int32) *MessageHeaderDecoder {
+	d.buffer = buffer
+	d.offset = offset
+	return d
+}
+
+func (d *MessageHeaderDecoder) BlockLength() uint16 {
+	return d.buffer.GetUInt16(d.offset)
+}
+
+func (d *MessageHeaderDecoder) TemplateId() uint16 {
+	return d.buffer.GetUInt16(d.offset + 2)
+}
+
+func (d *MessageHeaderDecoder) SchemaId() uint16 {
+	return d.buffer.GetUInt16(d.offset + 4)
+}
+
+func (d *MessageHeaderDecoder) Version() uint16 {
+	return d.buffer.GetUInt16(d.offset + 6)
+}
+
+// ControlResponseDecoder reads a ControlResponse
+type ControlResponseDecoder struct {
+	flyweight
+}
+
+func (d *ControlResponseDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *ControlResponseDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *ControlResponseDecoder) ControlSessionId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *ControlResponseDecoder) CorrelationId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *ControlResponseDecoder) RelevantId() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *ControlResponseDecoder) Code() ControlResponseCodeEnum {
+	return ControlResponseCodeEnum(d.buffer.GetInt32(d.offset + 24))
+}
+
+func (d *ControlResponseDecoder) Version() int32 {
+	var c ControlResponse
+	if !c.VersionInActingVersion(d.actingVersion) {
+		return c.VersionNullValue()
+	}
+	return d.buffer.GetInt32(d.offset + 28)
+}
+
+// GetErrorMessage copies the error message into dst, reusing its capacity
+func (d *ControlResponseDecoder) GetErrorMessage(dst []uint8) ([]uint8, error) {
+	limit := d.offset + int32(d.actingBlockLength)
+	return d.getVarData(&limit, dst)
+}
+
+// CopyTo fills c with the message, range checking it if asked
+func (d *ControlResponseDecoder) CopyTo(c *ControlResponse, doRangeCheck bool) error {
+	c.ControlSessionId = d.ControlSessionId()
+	c.CorrelationId = d.CorrelationId()
+	c.RelevantId = d.RelevantId()
+	c.Code = d.Code()
+	c.Version = d.Version()
+	var err error
+	if c.ErrorMessage, err = d.GetErrorMessage(c.ErrorMessage); err != nil {
+		return err
+	}
+	if doRangeCheck {
+		return c.RangeCheck(d.actingVersion, c.SbeSchemaVersion())
+	}
+	return nil
+}
+
+// RecordingSignalEventDecoder reads a RecordingSignalEvent
+type RecordingSignalEventDecoder struct {
+	flyweight
+}
+
+func (d *RecordingSignalEventDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *RecordingSignalEventDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *RecordingSignalEventDecoder) ControlSessionId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *RecordingSignalEventDecoder) CorrelationId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *RecordingSignalEventDecoder) RecordingId() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *RecordingSignalEventDecoder) SubscriptionId() int64 {
+	return d.buffer.GetInt64(d.offset + 24)
+}
+
+func (d *RecordingSignalEventDecoder) Position() int64 {
+	return d.buffer.GetInt64(d.offset + 32)
+}
+
+func (d *RecordingSignalEventDecoder) Signal() RecordingSignalEnum {
+	return RecordingSignalEnum(d.buffer.GetInt32(d.offset + 40))
+}
+
+// CopyTo fills r with the message, range checking it if asked
+func (d *RecordingSignalEventDecoder) CopyTo(r *RecordingSignalEvent, doRangeCheck bool) error {
+	r.ControlSessionId = d.ControlSessionId()
+	r.CorrelationId = d.CorrelationId()
+	r.RecordingId = d.RecordingId()
+	r.SubscriptionId = d.SubscriptionId()
+	r.Position = d.Position()
+	r.Signal = d.Signal()
+	if doRangeCheck {
+		return r.RangeCheck(d.actingVersion, r.SbeSchemaVersion())
+	}
+	return nil
+}
+
+// RecordingDescriptorDecoder reads a RecordingDescriptor
+type RecordingDescriptorDecoder struct {
+	flyweight
+}
+
+func (d *RecordingDescriptorDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *RecordingDescriptorDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *RecordingDescriptorDecoder) ControlSessionId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *RecordingDescriptorDecoder) CorrelationId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *RecordingDescriptorDecoder) RecordingId() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *RecordingDescriptorDecoder) StartTimestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 24)
+}
+
+func (d *RecordingDescriptorDecoder) StopTimestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 32)
+}
+
+func (d *RecordingDescriptorDecoder) StartPosition() int64 {
+	return d.buffer.GetInt64(d.offset + 40)
+}
+
+func (d *RecordingDescriptorDecoder) StopPosition() int64 {
+	return d.buffer.GetInt64(d.offset + 48)
+}
+
+func (d *RecordingDescriptorDecoder) InitialTermId() int32 {
+	return d.buffer.GetInt32(d.offset + 56)
+}
+
+func (d *RecordingDescriptorDecoder) SegmentFileLength() int32 {
+	return d.buffer.GetInt32(d.offset + 60)
+}
+
+func (d *RecordingDescriptorDecoder) TermBufferLength() int32 {
+	return d.buffer.GetInt32(d.offset + 64)
+}
+
+func (d *RecordingDescriptorDecoder) MtuLength() int32 {
+	return d.buffer.GetInt32(d.offset + 68)
+}
+
+func (d *RecordingDescriptorDecoder) SessionId() int32 {
+	return d.buffer.GetInt32(d.offset + 72)
+}
+
+func (d *RecordingDescriptorDecoder) StreamId() int32 {
+	return d.buffer.GetInt32(d.offset + 76)
+}
+
+// CopyTo fills r with the message, including its channels and source
+// identity, range checking it if asked
+func (d *RecordingDescriptorDecoder) CopyTo(r *RecordingDescriptor, doRangeCheck bool) error {
+	r.ControlSessionId = d.ControlSessionId()
+	r.CorrelationId = d.CorrelationId()
+	r.RecordingId = d.RecordingId()
+	r.StartTimestamp = d.StartTimestamp()
+	r.StopTimestamp = d.StopTimestamp()
+	r.StartPosition = d.StartPosition()
+	r.StopPosition = d.StopPosition()
+	r.InitialTermId = d.InitialTermId()
+	r.SegmentFileLength = d.SegmentFileLength()
+	r.TermBufferLength = d.TermBufferLength()
+	r.MtuLength = d.MtuLength()
+	r.SessionId = d.SessionId()
+	r.StreamId = d.StreamId()
+	limit := d.offset + int32(d.actingBlockLength)
+	var err error
+	if r.StrippedChannel, err = d.getVarData(&limit, r.StrippedChannel); err != nil {
+		return err
+	}
+	if r.OriginalChannel, err = d.getVarData(&limit, r.OriginalChannel); err != nil {
+		return err
+	}
+	if r.SourceIdentity, err = d.getVarData(&limit, r.SourceIdentity); err != nil {
+		return err
+	}
+	if doRangeCheck {
+		return r.RangeCheck(d.actingVersion, r.SbeSchemaVersion())
+	}
+	return nil
+}
@@ -0,0 +1,168 @@
+package codecs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encodeMessage(t *testing.T, blockLength, templateId, version uint16, body func(m *SbeGoMarshaller, w *bytes.Buffer) error) *atomic.Buffer {
+	m := NewSbeGoMarshaller()
+	w := new(bytes.Buffer)
+	header := MessageHeader{BlockLength: blockLength, TemplateId: templateId, SchemaId: 101, Version: version}
+	require.NoError(t, header.Encode(m, w))
+	require.NoError(t, body(m, w))
+	return atomic.NewBufferSlice(w.Bytes())
+}
+
+func TestControlResponseDecoder(t *testing.T) {
+	expected := ControlResponse{
+		ControlSessionId: 1,
+		CorrelationId:    2,
+		RelevantId:       3,
+		Code:             ControlResponseCode.ERROR,
+		Version:          4,
+		ErrorMessage:     []uint8("unknown recording"),
+	}
+	buf := encodeMessage(t, expected.SbeBlockLength(), expected.SbeTemplateId(), expected.SbeSchemaVersion(),
+		func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+			return expected.Encode(m, w, true)
+		})
+
+	var hdr MessageHeaderDecoder
+	hdr.Wrap(buf, 0)
+	assert.Equal(t, expected.SbeTemplateId(), hdr.TemplateId())
+
+	var d ControlResponseDecoder
+	d.Wrap(buf, MessageHeaderLength, hdr.BlockLength(), hdr.Version())
+	assert.Equal(t, int64(1), d.ControlSessionId())
+	assert.Equal(t, int64(2), d.CorrelationId())
+	assert.Equal(t, ControlResponseCode.ERROR, d.Code())
+
+	var decoded ControlResponse
+	require.NoError(t, d.CopyTo(&decoded, true))
+	assert.Equal(t, expected, decoded)
+}
+
+func TestControlResponseDecoder_OlderVersion(t *testing.T) {
+	// Version 3 of the schema precedes the ControlResponse's Version field
+	var c ControlResponse
+	buf := encodeMessage(t, 28, c.SbeTemplateId(), 3, func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+		for _, v := range []int64{1, 2, 3} {
+			if err := m.WriteInt64(w, v); err != nil {
+				return err
+			}
+		}
+		if err := m.WriteInt32(w, int32(ControlResponseCode.OK)); err != nil {
+			return err
+		}
+		return m.WriteUint32(w, 0)
+	})
+
+	var d ControlResponseDecoder
+	d.Wrap(buf, MessageHeaderLength, 28, 3)
+	require.NoError(t, d.CopyTo(&c, true))
+	assert.Equal(t, ControlResponseCode.OK, c.Code)
+	assert.Equal(t, c.VersionNullValue(), c.Version)
+	assert.Empty(t, c.ErrorMessage)
+}
+
+func TestRecordingDescriptorDecoder(t *testing.T) {
+	expected := RecordingDescriptor{
+		ControlSessionId:  1,
+		CorrelationId:     2,
+		RecordingId:       3,
+		StartTimestamp:    4,
+		StopTimestamp:     5,
+		StartPosition:     6,
+		StopPosition:      7,
+		InitialTermId:     8,
+		SegmentFileLength: 9,
+		TermBufferLength:  10,
+		MtuLength:         11,
+		SessionId:         12,
+		StreamId:          13,
+		StrippedChannel:   []uint8("aeron:ipc"),
+		OriginalChannel:   []uint8("aeron:ipc?term-length=64k"),
+		SourceIdentity:    []uint8("aeron:ipc"),
+	}
+	buf := encodeMessage(t, expected.SbeBlockLength(), expected.SbeTemplateId(), expected.SbeSchemaVersion(),
+		func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+			return expected.Encode(m, w, true)
+		})
+
+	var d RecordingDescriptorDecoder
+	d.Wrap(buf, MessageHeaderLength, expected.SbeBlockLength(), expected.SbeSchemaVersion())
+	var decoded RecordingDescriptor
+	require.NoError(t, d.CopyTo(&decoded, true))
+	assert.Equal(t, expected, decoded)
+}
+
+func TestRecordingSignalEventDecoder(t *testing.T) {
+	expected := RecordingSignalEvent{
+		ControlSessionId: 1,
+		CorrelationId:    2,
+		RecordingId:      3,
+		SubscriptionId:   4,
+		Position:         5,
+		Signal:           RecordingSignal.STOP,
+	}
+	buf := encodeMessage(t, expected.SbeBlockLength(), expected.SbeTemplateId(), expected.SbeSchemaVersion(),
+		func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+			return expected.Encode(m, w, true)
+		})
+
+	var d RecordingSignalEventDecoder
+	d.Wrap(buf, MessageHeaderLength, expected.SbeBlockLength(), expected.SbeSchemaVersion())
+	var decoded RecordingSignalEvent
+	require.NoError(t, d.CopyTo(&decoded, true))
+	assert.Equal(t, expected, decoded)
+}
+
+func TestControlResponseDecoder_FiltersWithoutAllocating(t *testing.T) {
+	response := ControlResponse{ControlSessionId: 1, CorrelationId: 2, Code: ControlResponseCode.OK}
+	buf := encodeMessage(t, response.SbeBlockLength(), response.SbeTemplateId(), response.SbeSchemaVersion(),
+		func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+			return response.Encode(m, w, true)
+		})
+
+	var hdr MessageHeaderDecoder
+	var d ControlResponseDecoder
+	matched := 0
+	allocs := testing.AllocsPerRun(100, func() {
+		hdr.Wrap(buf, 0)
+		d.Wrap(buf, MessageHeaderLength, hdr.BlockLength(), hdr.Version())
+		if d.ControlSessionId() == 1 && d.CorrelationId() == 2 && d.Code() == ControlResponseCode.OK {
+			matched++
+		}
+	})
+	assert.Zero(t, allocs)
+	assert.NotZero(t, matched)
+}
+
+func TestControlResponseDecoder_CorruptErrorMessageLength(t *testing.T) {
+	response := ControlResponse{ControlSessionId: 1, CorrelationId: 2, Code: ControlResponseCode.ERROR, ErrorMessage: []uint8("b0rk")}
+	buf := encodeMessage(t, response.SbeBlockLength(), response.SbeTemplateId(), response.SbeSchemaVersion(),
+		func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+			return response.Encode(m, w, true)
+		})
+	lengthOffset := MessageHeaderLength + int32(response.SbeBlockLength())
+
+	for _, length := range []int32{-1, buf.Capacity()} {
+		buf.PutInt32(lengthOffset, length)
+		var d ControlResponseDecoder
+		d.Wrap(buf, MessageHeaderLength, response.SbeBlockLength(), response.SbeSchemaVersion())
+		decoded := ControlResponse{ErrorMessage: []uint8("stale")}
+		assert.ErrorContains(t, d.CopyTo(&decoded, true), "invalid var data length")
+		assert.Empty(t, decoded.ErrorMessage)
+	}
+
+	// A block length running past the end leaves no room for the length itself
+	var d ControlResponseDecoder
+	d.Wrap(buf, MessageHeaderLength, uint16(buf.Capacity()), response.SbeSchemaVersion())
+	_, err := d.GetErrorMessage(nil)
+	assert.ErrorContains(t, err, "beyond buffer capacity")
+}
@@ -17,6 +17,7 @@ package archive
 import (
 	"bytes"
 	"fmt"
+	"io"
 	"time"
 
 	"github.com/lirm/aeron-go/aeron"
@@ -40,6 +41,16 @@ type Control struct {
 	fragmentAssembler *aeron.ControlledFragmentAssembler
 
 	errorFragmentHandler term.ControlledFragmentHandler
+
+	// Flyweights reused to decode responses in place
+	headerDecoder          codecs.MessageHeaderDecoder
+	controlResponseDecoder codecs.ControlResponseDecoder
+	signalDecoder          codecs.RecordingSignalEventDecoder
+	descriptorDecoder      codecs.RecordingDescriptorDecoder
+
+	// Decoded messages reused by each poll, so are only valid until the next
+	controlResponse codecs.ControlResponse
+	recordingSignal codecs.RecordingSignalEvent
 }
 
 // ControlResults for holding state over a Control request/response
@@ -47,7 +58,7 @@ type Control struct {
 // These pieces are filled out by various ResponsePollers which will set IsPollComplete to true
 type ControlResults struct {
 	CorrelationId                    int64
-	ControlResponse                  *codecs.ControlResponse
+	ControlResponse                  *codecs.ControlResponse // Reused by the next poll
 	RecordingDescriptors             []*codecs.RecordingDescriptor
 	RecordingSubscriptionDescriptors []*codecs.RecordingSubscriptionDescriptor
 	IsPollComplete                   bool
@@ -131,13 +142,8 @@ func controlFragmentHandler(context interface{}, buffer *atomic.Buffer, offset i
 	}
 
 	logger.Debugf("controlFragmentHandler: correlationID:%d offset:%d length:%d header:%#v", pollContext.correlationID, offset, length, header)
-	var hdr codecs.SbeGoMessageHeader
-
-	buf := new(bytes.Buffer)
-	buffer.WriteBytes(buf, offset, length)
-
-	marshaller := codecs.NewSbeGoMarshaller()
-	if err := hdr.Decode(marshaller, buf); err != nil {
+	hdr, err := pollContext.control.wrapHeader(buffer, offset, length)
+	if err != nil {
 		// Not much to be done here as we can't really tell what went wrong
 		err2 := fmt.Errorf("controlFragmentHandler() failed to decode control message header: %w", err)
 		// Call the global error handler, ugly but it's all we've got
@@ -159,22 +165,24 @@ func controlFragmentHandler(context interface{}, buffer *atomic.Buffer, offset i
 	}
 	control := c.(*Control)
 
-	switch hdr.TemplateId {
+	switch hdr.TemplateId() {
 	case codecIds.controlResponse:
-		var controlResponse = new(codecs.ControlResponse)
-		logger.Debugf("controlFragmentHandler/controlResponse: Received controlResponse: length %d", buf.Len())
-		if err := controlResponse.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
-			// Not much to be done here as we can't see what's gone wrong
-			err2 := fmt.Errorf("controlFragmentHandler failed to decode control response:%w", err)
-			// Call the global error handler, ugly but it's all we've got
-			if pollContext.control.archive.Listeners.ErrorListener != nil {
-				pollContext.control.archive.Listeners.ErrorListener(err2)
+		logger.Debugf("controlFragmentHandler/controlResponse: Received controlResponse: length %d", length)
+		decoder := control.wrapControlResponse(buffer, offset, hdr)
+
+		// Check this was for us, or an error we may need to route, before building the response
+		if decoder.ControlSessionId() == control.archive.SessionID && decoder.CorrelationId() == pollContext.correlationID {
+			controlResponse := &control.controlResponse
+			if err := decoder.CopyTo(controlResponse, rangeChecking); err != nil {
+				// Not much to be done here as we can't see what's gone wrong
+				err2 := fmt.Errorf("controlFragmentHandler failed to decode control response:%w", err)
+				// Call the global error handler, ugly but it's all we've got
+				if pollContext.control.archive.Listeners.ErrorListener != nil {
+					pollContext.control.archive.Listeners.ErrorListener(err2)
+				}
+				return
 			}
-			return
-		}
 
-		// Check this was for us
-		if controlResponse.ControlSessionId == control.archive.SessionID && controlResponse.CorrelationId == pollContext.correlationID {
 			// Set our state to let the caller of Poll() which triggered this know they have something
 			// We're basically finished so prepare our OOB return values and log some info if we can
 			logger.Debugf("controlFragmentHandler/controlResponse: received for sessionID:%d, correlationID:%d", controlResponse.ControlSessionId, controlResponse.CorrelationId)
@@ -182,37 +190,90 @@ func controlFragmentHandler(context interface{}, buffer *atomic.Buffer, offset i
 			control.Results.IsPollComplete = true
 
 			return term.ControlledPollActionBreak
-		} else {
-			logger.Debugf("controlFragmentHandler/controlResponse ignoring sessionID:%d, correlationID:%d", controlResponse.ControlSessionId, controlResponse.CorrelationId)
 		}
+		logger.Debugf("controlFragmentHandler/controlResponse ignoring sessionID:%d, correlationID:%d", decoder.ControlSessionId(), decoder.CorrelationId())
+		control.onAsyncControlResponse(decoder)
 
 	case codecIds.recordingSignalEvent:
-		var recordingSignalEvent = new(codecs.RecordingSignalEvent)
-
-		if err := recordingSignalEvent.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
+		recordingSignalEvent, err := control.decodeRecordingSignal(buffer, offset, hdr)
+		if err != nil {
 			// Not much to be done here as we can't really tell what went wrong
 			err2 := fmt.Errorf("ControlFragmentHandler failed to decode recording signal: %w", err)
 			if pollContext.control.archive.Listeners.ErrorListener != nil {
 				pollContext.control.archive.Listeners.ErrorListener(err2)
 			}
+			return
 		}
-		if pollContext.control.archive.Listeners.RecordingSignalListener != nil {
-			pollContext.control.archive.Listeners.RecordingSignalListener(recordingSignalEvent)
-		}
+		pollContext.control.archive.onRecordingSignal(recordingSignalEvent)
 
 	// These can happen when testing/reconnecting or if multiple clients are on the same channel/stream
 	case codecIds.recordingDescriptor:
-		logger.Debugf("controlFragmentHandler: ignoring RecordingDescriptor type %d", hdr.TemplateId)
+		logger.Debugf("controlFragmentHandler: ignoring RecordingDescriptor type %d", hdr.TemplateId())
 	case codecIds.recordingSubscriptionDescriptor:
-		logger.Debugf("controlFragmentHandler: ignoring RecordingSubscriptionDescriptor type %d", hdr.TemplateId)
+		logger.Debugf("controlFragmentHandler: ignoring RecordingSubscriptionDescriptor type %d", hdr.TemplateId())
 
 	default:
 		// This can happen when testing/adding new functionality
-		fmt.Printf("controlFragmentHandler: Unexpected message type %d\n", hdr.TemplateId)
+		fmt.Printf("controlFragmentHandler: Unexpected message type %d\n", hdr.TemplateId())
 	}
 	return
 }
 
+// wrapHeader wraps the header decoder over the message, failing if the message
+// is too short for its header and block
+func (control *Control) wrapHeader(buffer *atomic.Buffer, offset int32, length int32) (*codecs.MessageHeaderDecoder, error) {
+	if length < codecs.MessageHeaderLength {
+		return nil, fmt.Errorf("message too short for header: length=%d", length)
+	}
+	hdr := control.headerDecoder.Wrap(buffer, offset)
+	if length < codecs.MessageHeaderLength+int32(hdr.BlockLength()) {
+		return nil, fmt.Errorf("message truncated: templateId=%d length=%d blockLength=%d",
+			hdr.TemplateId(), length, hdr.BlockLength())
+	}
+	return hdr, nil
+}
+
+// wrapControlResponse wraps the control response decoder over the body of the message at offset
+func (control *Control) wrapControlResponse(buffer *atomic.Buffer, offset int32, hdr *codecs.MessageHeaderDecoder) *codecs.ControlResponseDecoder {
+	return control.controlResponseDecoder.Wrap(buffer, offset+codecs.MessageHeaderLength, hdr.BlockLength(), hdr.Version())
+}
+
+// onAsyncControlResponse passes an uncorrelated response to the archive if it
+// is an error on our session, only then building the response
+func (control *Control) onAsyncControlResponse(decoder *codecs.ControlResponseDecoder) {
+	if decoder.ControlSessionId() != control.archive.SessionID || decoder.Code() != codecs.ControlResponseCode.ERROR {
+		return
+	}
+	controlResponse := &control.controlResponse
+	if err := decoder.CopyTo(controlResponse, rangeChecking); err != nil {
+		if control.archive.Listeners.ErrorListener != nil {
+			control.archive.Listeners.ErrorListener(fmt.Errorf("failed to decode control response: %w", err))
+		}
+		return
+	}
+	control.archive.onAsyncErrorResponse(controlResponse)
+}
+
+// decodeRecordingSignal decodes the recording signal in the message at offset
+// into the control's reused event
+func (control *Control) decodeRecordingSignal(buffer *atomic.Buffer, offset int32, hdr *codecs.MessageHeaderDecoder) (*codecs.RecordingSignalEvent, error) {
+	decoder := control.signalDecoder.Wrap(buffer, offset+codecs.MessageHeaderLength, hdr.BlockLength(), hdr.Version())
+	return &control.recordingSignal, decoder.CopyTo(&control.recordingSignal, rangeChecking)
+}
+
+// decodeMessage decodes the body of the message at offset with a generated codec,
+// for the messages without a flyweight
+func decodeMessage(message sbeDecoder, buffer *atomic.Buffer, offset int32, length int32, hdr *codecs.MessageHeaderDecoder) error {
+	buf := new(bytes.Buffer)
+	buffer.WriteBytes(buf, offset+codecs.MessageHeaderLength, length-codecs.MessageHeaderLength)
+	return message.Decode(codecs.NewSbeGoMarshaller(), buf, hdr.Version(), hdr.BlockLength(), rangeChecking)
+}
+
+// sbeDecoder is implemented by the generated codecs
+type sbeDecoder interface {
+	Decode(m *codecs.SbeGoMarshaller, r io.Reader, actingVersion uint16, blockLength uint16, doRangeCheck bool) error
+}
+
 // ConnectionControlFragmentHandler is the connection handling specific fragment handler.
 // This mechanism only alows us to pass results back via global state which we do in control.State
 func ConnectionControlFragmentHandler(context *PollContext, buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
@@ -235,7 +296,7 @@ func ConnectionControlFragmentHandler(context *PollContext, buffer *atomic.Buffe
 
 	switch hdr.TemplateId {
 	case codecIds.controlResponse:
-		var controlResponse = new(codecs.ControlResponse)
+		controlResponse := &context.control.controlResponse
 		logger.Debugf("Received controlResponse: length %d", buf.Len())
 		if err := controlResponse.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
 			// Not much to be done here as we can't correlate
@@ -280,6 +341,7 @@ func ConnectionControlFragmentHandler(context *PollContext, buffer *atomic.Buffe
 			control.State.state = ControlStateConnected
 			control.State.err = nil
 			control.archive.SessionID = controlResponse.ControlSessionId
+			control.archive.protocolVersion = controlResponse.Version
 		} else {
 			// It's conceivable if the same application is making concurrent connection attempts using
 			// the same channel/stream that we can reach here which is our parent's problem
@@ -310,8 +372,9 @@ func ConnectionControlFragmentHandler(context *PollContext, buffer *atomic.Buffe
 		// Check this was for us
 		if challenge.CorrelationId == context.correlationID {
 
-			// Check the challenge is expected iff our option for this is not nil
-			if control.archive.Options.AuthChallenge != nil {
+			// Check the challenge is expected iff our option for this is not nil, unless
+			// a CredentialsSupplier is set as it alone decides how to answer a challenge
+			if control.archive.Options.CredentialsSupplier == nil && control.archive.Options.AuthChallenge != nil {
 				if !bytes.Equal(control.archive.Options.AuthChallenge, challenge.EncodedChallenge) {
 					control.State.err = fmt.Errorf("ChallengeResponse Unexpected: expected:%v received:%v", control.archive.Options.AuthChallenge, challenge.EncodedChallenge)
 					return
@@ -323,7 +386,11 @@ func ConnectionControlFragmentHandler(context *PollContext, buffer *atomic.Buffe
 			control.State.state = ControlStateChallenged
 			control.State.err = nil
 			control.archive.SessionID = challenge.ControlSessionId
-			control.archive.Proxy.ChallengeResponse(challenge.CorrelationId, control.archive.Options.AuthResponse)
+			response := control.archive.Options.credentialsSupplier().OnChallenge(challenge.EncodedChallenge)
+			if err := control.archive.Proxy.ChallengeResponse(challenge.CorrelationId, response); err != nil {
+				control.State.state = ControlStateError
+				control.State.err = err
+			}
 		} else {
 			// It's conceivable if the same application is making concurrent connection attempts using
 			// the same channel/stream that we can reach here which is our parent's problem
@@ -400,48 +467,44 @@ func (control *Control) errorResponseFragmentHandler(buffer *atomic.Buffer, offs
 
 	logger.Debugf("errorResponseFragmentHandler: offset:%d length: %d", offset, length)
 
-	var hdr codecs.SbeGoMessageHeader
-
-	buf := new(bytes.Buffer)
-	buffer.WriteBytes(buf, offset, length)
-
-	marshaller := codecs.NewSbeGoMarshaller()
-	if err := hdr.Decode(marshaller, buf); err != nil {
+	hdr, err := control.wrapHeader(buffer, offset, length)
+	if err != nil {
 		// Not much to be done here as we can't correlate
 		err2 := fmt.Errorf("ConnectionControlFragmentHandler() failed to decode control message header: %w", err)
 		// Call the global error handler, ugly, but it's all we've got
 		if pollContext.control.archive.Listeners.ErrorListener != nil {
 			pollContext.control.archive.Listeners.ErrorListener(err2)
 		}
+		return
 	}
 
-	switch hdr.TemplateId {
+	switch hdr.TemplateId() {
 	case codecIds.controlResponse:
-		var controlResponse = new(codecs.ControlResponse)
 		logger.Debugf("controlFragmentHandler/controlResponse: Received controlResponse")
-		if err := controlResponse.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
-			// Not much to be done here as we can't see what's gone wrong
-			err2 := fmt.Errorf("errorResponseFragmentHandler failed to decode control response:%w", err)
-			// Call the global error handler, ugly, but it's all we've got
-			if pollContext.control.archive.Listeners.ErrorListener != nil {
-				pollContext.control.archive.Listeners.ErrorListener(err2)
-			}
-			return
-		}
+		decoder := control.wrapControlResponse(buffer, offset, hdr)
 
 		// If this was for us then check for errors
-		if controlResponse.ControlSessionId == pollContext.control.archive.SessionID {
-			if controlResponse.Code == codecs.ControlResponseCode.ERROR {
-				pollContext.control.Results.ErrorResponse = fmt.Errorf("PollForErrorResponse received a ControlResponse (correlationId:%d Code:ERROR error=\"%s\"", controlResponse.CorrelationId, controlResponse.ErrorMessage)
-				return term.ControlledPollActionBreak
+		if decoder.ControlSessionId() == pollContext.control.archive.SessionID && decoder.Code() == codecs.ControlResponseCode.ERROR {
+			controlResponse := &control.controlResponse
+			if err := decoder.CopyTo(controlResponse, rangeChecking); err != nil {
+				// Not much to be done here as we can't see what's gone wrong
+				err2 := fmt.Errorf("errorResponseFragmentHandler failed to decode control response:%w", err)
+				// Call the global error handler, ugly, but it's all we've got
+				if pollContext.control.archive.Listeners.ErrorListener != nil {
+					pollContext.control.archive.Listeners.ErrorListener(err2)
+				}
+				return
 			}
+			pollContext.control.archive.onAsyncErrorResponse(controlResponse)
+			pollContext.control.Results.ErrorResponse = fmt.Errorf("PollForErrorResponse received a ControlResponse (correlationId:%d Code:ERROR error=\"%s\"", controlResponse.CorrelationId, controlResponse.ErrorMessage)
+			return term.ControlledPollActionBreak
 		}
 		return
 
 	case codecIds.challenge:
 		var challenge = new(codecs.Challenge)
 
-		if err := challenge.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
+		if err := decodeMessage(challenge, buffer, offset, length, hdr); err != nil {
 			// Not much to be done here as we can't correlate
 			err2 := fmt.Errorf("errorResponseFragmentHandler failed to decode challenge: %w", err)
 			if pollContext.control.archive.Listeners.ErrorListener != nil {
@@ -457,19 +520,11 @@ func (control *Control) errorResponseFragmentHandler(buffer *atomic.Buffer, offs
 		}
 
 	case codecIds.recordingDescriptor:
-		var rd = new(codecs.RecordingDescriptor)
-
-		if err := rd.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
-			// Not much to be done here as we can't correlate
-			err2 := fmt.Errorf("errorResponseFragmentHandler failed to decode recordingSubscription: %w", err)
-			if pollContext.control.archive.Listeners.ErrorListener != nil {
-				pollContext.control.archive.Listeners.ErrorListener(err2)
-			}
-		}
+		decoder := control.descriptorDecoder.Wrap(buffer, offset+codecs.MessageHeaderLength, hdr.BlockLength(), hdr.Version())
 
 		// If this was for us then that's bad
-		if rd.ControlSessionId == pollContext.control.archive.SessionID {
-			pollContext.control.Results.ErrorResponse = fmt.Errorf("Received and ignoring recordingDescriptor (correlationID:%d). ErrorResponse should not be called on in parallel with sync operations", rd.CorrelationId)
+		if decoder.ControlSessionId() == pollContext.control.archive.SessionID {
+			pollContext.control.Results.ErrorResponse = fmt.Errorf("Received and ignoring recordingDescriptor (correlationID:%d). ErrorResponse should not be called on in parallel with sync operations", decoder.CorrelationId())
 			logger.Warning(pollContext.control.Results.ErrorResponse)
 			return
 		}
@@ -477,7 +532,7 @@ func (control *Control) errorResponseFragmentHandler(buffer *atomic.Buffer, offs
 	case codecIds.recordingSubscriptionDescriptor:
 		var rsd = new(codecs.RecordingSubscriptionDescriptor)
 
-		if err := rsd.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
+		if err := decodeMessage(rsd, buffer, offset, length, hdr); err != nil {
 			// Not much to be done here as we can't correlate
 			err2 := fmt.Errorf("errorResponseFragmentHandler failed to decode recordingSubscription: %w", err)
 			if pollContext.control.archive.Listeners.ErrorListener != nil {
@@ -492,18 +547,15 @@ func (control *Control) errorResponseFragmentHandler(buffer *atomic.Buffer, offs
 		}
 
 	case codecIds.recordingSignalEvent:
-		var rse = new(codecs.RecordingSignalEvent)
-
-		if err := rse.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
+		rse, err := control.decodeRecordingSignal(buffer, offset, hdr)
+		if err != nil {
 			// Not much to be done here as we can't really tell what went wrong
 			err2 := fmt.Errorf("errorResponseFragmentHandler failed to decode recording signal: %w", err)
 			if pollContext.control.archive.Listeners.ErrorListener != nil {
 				pollContext.control.archive.Listeners.ErrorListener(err2)
 			}
 		}
-		if pollContext.control.archive.Listeners.RecordingSignalListener != nil {
-			pollContext.control.archive.Listeners.RecordingSignalListener(rse)
-		}
+		pollContext.control.archive.onRecordingSignal(rse)
 
 		if rse.ControlSessionId == pollContext.control.archive.SessionID {
 			// We can call the async callback if it exists
@@ -555,13 +607,8 @@ func (control *Control) onFragment(
 		return term.ControlledPollActionAbort
 	}
 
-	var hdr codecs.SbeGoMessageHeader
-
-	buf := new(bytes.Buffer)
-	buffer.WriteBytes(buf, offset, length)
-
-	marshaller := codecs.NewSbeGoMarshaller()
-	if err := hdr.Decode(marshaller, buf); err != nil {
+	hdr, err := control.wrapHeader(buffer, offset, length)
+	if err != nil {
 		// Not much to be done here as we can't correlate
 		err = fmt.Errorf("DescriptorFragmentHandler() failed to decode control message header: %w", err)
 		if control.archive.Listeners.ErrorListener != nil {
@@ -570,11 +617,11 @@ func (control *Control) onFragment(
 		return term.ControlledPollActionContinue
 	}
 
-	switch hdr.TemplateId {
+	switch hdr.TemplateId() {
 	case codecIds.controlResponse:
-		var controlResponse = new(codecs.ControlResponse)
-		logger.Debugf("Received controlResponse: length %d", buf.Len())
-		if err := controlResponse.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
+		controlResponse := &control.controlResponse
+		logger.Debugf("Received controlResponse: length %d", length)
+		if err := control.wrapControlResponse(buffer, offset, hdr).CopyTo(controlResponse, rangeChecking); err != nil {
 			// Not much to be done here as we can't correlate
 			err = fmt.Errorf("failed to decode control response: %w", err)
 			if control.archive.Listeners.ErrorListener != nil {
@@ -588,8 +635,8 @@ func (control *Control) onFragment(
 		return term.ControlledPollActionBreak
 
 	case codecIds.recordingSignalEvent:
-		var recordingSignalEvent = new(codecs.RecordingSignalEvent)
-		if err := recordingSignalEvent.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
+		recordingSignalEvent, err := control.decodeRecordingSignal(buffer, offset, hdr)
+		if err != nil {
 			// Not much to be done here as we can't correlate
 			err = fmt.Errorf("failed to decode recording signal: %w", err)
 			if control.archive.Listeners.ErrorListener != nil {
@@ -597,9 +644,10 @@ func (control *Control) onFragment(
 			}
 			return term.ControlledPollActionContinue
 		}
+		control.archive.onRecordingSignal(recordingSignalEvent)
 
 	default:
-		logger.Debug("descriptorFragmentHandler: Insert decoder for type: %d", hdr.TemplateId)
+		logger.Debug("descriptorFragmentHandler: Insert decoder for type: %d", hdr.TemplateId())
 	}
 	return term.ControlledPollActionContinue
 }
@@ -670,13 +718,8 @@ func DescriptorFragmentHandler(context interface{}, buffer *atomic.Buffer, offse
 
 	// logger.Debugf("DescriptorFragmentHandler: correlationID:%d offset:%d length: %d header: %#v\n", pollContext.correlationID, offset, length, header)
 
-	var hdr codecs.SbeGoMessageHeader
-
-	buf := new(bytes.Buffer)
-	buffer.WriteBytes(buf, offset, length)
-
-	marshaller := codecs.NewSbeGoMarshaller()
-	if err := hdr.Decode(marshaller, buf); err != nil {
+	hdr, err := pollContext.control.wrapHeader(buffer, offset, length)
+	if err != nil {
 		// Not much to be done here as we can't correlate
 		err2 := fmt.Errorf("DescriptorFragmentHandler() failed to decode control message header: %w", err)
 		// Call the global error handler, ugly but it's all we've got
@@ -698,33 +741,35 @@ func DescriptorFragmentHandler(context interface{}, buffer *atomic.Buffer, offse
 	}
 	control := c.(*Control)
 
-	switch hdr.TemplateId {
+	switch hdr.TemplateId() {
 	case codecIds.recordingDescriptor:
-		var recordingDescriptor = new(codecs.RecordingDescriptor)
-		logger.Debugf("Received RecordingDescriptor: length %d", buf.Len())
-		if err := recordingDescriptor.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
-			// Not much to be done here as we can't correlate
-			err2 := fmt.Errorf("failed to decode RecordingDescriptor: %w", err)
-			if pollContext.control.archive.Listeners.ErrorListener != nil {
-				pollContext.control.archive.Listeners.ErrorListener(err2)
-			}
-			return
-		}
-		logger.Debugf("RecordingDescriptor: %#v", recordingDescriptor)
+		logger.Debugf("Received RecordingDescriptor: length %d", length)
+		decoder := control.descriptorDecoder.Wrap(buffer, offset+codecs.MessageHeaderLength, hdr.BlockLength(), hdr.Version())
 
 		// Check this was for us
-		if recordingDescriptor.ControlSessionId == control.archive.SessionID && recordingDescriptor.CorrelationId == pollContext.correlationID {
+		if decoder.ControlSessionId() == control.archive.SessionID && decoder.CorrelationId() == pollContext.correlationID {
+			var recordingDescriptor = new(codecs.RecordingDescriptor)
+			if err := decoder.CopyTo(recordingDescriptor, rangeChecking); err != nil {
+				// Not much to be done here as we can't correlate
+				err2 := fmt.Errorf("failed to decode RecordingDescriptor: %w", err)
+				if pollContext.control.archive.Listeners.ErrorListener != nil {
+					pollContext.control.archive.Listeners.ErrorListener(err2)
+				}
+				return
+			}
+			logger.Debugf("RecordingDescriptor: %#v", recordingDescriptor)
+
 			// Set our state to let the caller of Poll() which triggered this know they have something
 			control.Results.RecordingDescriptors = append(control.Results.RecordingDescriptors, recordingDescriptor)
 			control.Results.FragmentsReceived++
 		} else {
-			logger.Debugf("descriptorFragmentHandler/recordingDescriptor ignoring sessionID:%d, pollContext.correlationID:%d", recordingDescriptor.ControlSessionId, recordingDescriptor.CorrelationId)
+			logger.Debugf("descriptorFragmentHandler/recordingDescriptor ignoring sessionID:%d, pollContext.correlationID:%d", decoder.ControlSessionId(), decoder.CorrelationId())
 		}
 
 	case codecIds.recordingSubscriptionDescriptor:
-		logger.Debugf("Received RecordingSubscriptionDescriptor: length %d", buf.Len())
+		logger.Debugf("Received RecordingSubscriptionDescriptor: length %d", length)
 		var recordingSubscriptionDescriptor = new(codecs.RecordingSubscriptionDescriptor)
-		if err := recordingSubscriptionDescriptor.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
+		if err := decodeMessage(recordingSubscriptionDescriptor, buffer, offset, length, hdr); err != nil {
 			// Not much to be done here as we can't correlate
 			err2 := fmt.Errorf("failed to decode RecordingSubscriptioDescriptor: %w", err)
 			if pollContext.control.archive.Listeners.ErrorListener != nil {
@@ -743,41 +788,42 @@ func DescriptorFragmentHandler(context interface{}, buffer *atomic.Buffer, offse
 		}
 
 	case codecIds.controlResponse:
-		var controlResponse = new(codecs.ControlResponse)
-		logger.Debugf("Received controlResponse: length %d", buf.Len())
-		if err := controlResponse.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
-			// Not much to be done here as we can't correlate
-			err2 := fmt.Errorf("failed to decode control response: %w", err)
-			if pollContext.control.archive.Listeners.ErrorListener != nil {
-				pollContext.control.archive.Listeners.ErrorListener(err2)
-			}
-			return
-		}
+		logger.Debugf("Received controlResponse: length %d", length)
+		decoder := control.wrapControlResponse(buffer, offset, hdr)
 
 		// Check this was for us
-		if controlResponse.ControlSessionId == control.archive.SessionID {
+		if decoder.ControlSessionId() == control.archive.SessionID {
+			code := decoder.Code()
 			// RECORDING_UNKNOWN expected if there are no more results
-			if controlResponse.CorrelationId == pollContext.correlationID && controlResponse.Code == codecs.ControlResponseCode.RECORDING_UNKNOWN {
-				// Set our state to let the caller of Poll() which triggered this know they have something
-				// We're basically finished so prepare our OOB return values and log some info if we can
-				logger.Debugf("descriptorFragmentHandler/controlResponse: received for sessionID:%d, correlationID:%d", controlResponse.ControlSessionId, controlResponse.CorrelationId)
-				control.Results.ControlResponse = controlResponse
-				control.Results.IsPollComplete = true
-				return
-			} else if controlResponse.Code == codecs.ControlResponseCode.ERROR {
-				// Unexpected so log but we deal with it in the parent
-				logger.Debugf("ControlResponse error ERROR: %s\n%#v", controlResponse.ErrorMessage, controlResponse)
+			if (decoder.CorrelationId() == pollContext.correlationID && code == codecs.ControlResponseCode.RECORDING_UNKNOWN) ||
+				code == codecs.ControlResponseCode.ERROR {
+				controlResponse := &control.controlResponse
+				if err := decoder.CopyTo(controlResponse, rangeChecking); err != nil {
+					// Not much to be done here as we can't correlate
+					err2 := fmt.Errorf("failed to decode control response: %w", err)
+					if pollContext.control.archive.Listeners.ErrorListener != nil {
+						pollContext.control.archive.Listeners.ErrorListener(err2)
+					}
+					return
+				}
+				if code == codecs.ControlResponseCode.ERROR {
+					// Unexpected so log but we deal with it in the parent
+					logger.Debugf("ControlResponse error ERROR: %s\n%#v", controlResponse.ErrorMessage, controlResponse)
+				} else {
+					// Set our state to let the caller of Poll() which triggered this know they have something
+					// We're basically finished so prepare our OOB return values and log some info if we can
+					logger.Debugf("descriptorFragmentHandler/controlResponse: received for sessionID:%d, correlationID:%d", controlResponse.ControlSessionId, controlResponse.CorrelationId)
+				}
 				control.Results.ControlResponse = controlResponse
 				control.Results.IsPollComplete = true
 				return
-			} else {
-				logger.Debugf("descriptorFragmentHandler/controlResponse ignoring sessionID:%d, correlationID:%d", controlResponse.ControlSessionId, controlResponse.CorrelationId)
 			}
+			logger.Debugf("descriptorFragmentHandler/controlResponse ignoring sessionID:%d, correlationID:%d", decoder.ControlSessionId(), decoder.CorrelationId())
 		}
 
 	case codecIds.recordingSignalEvent:
-		var recordingSignalEvent = new(codecs.RecordingSignalEvent)
-		if err := recordingSignalEvent.Decode(marshaller, buf, hdr.Version, hdr.BlockLength, rangeChecking); err != nil {
+		recordingSignalEvent, err := control.decodeRecordingSignal(buffer, offset, hdr)
+		if err != nil {
 			// Not much to be done here as we can't correlate
 			err2 := fmt.Errorf("failed to decode recording signal: %w", err)
 			if pollContext.control.archive.Listeners.ErrorListener != nil {
@@ -786,12 +832,10 @@ func DescriptorFragmentHandler(context interface{}, buffer *atomic.Buffer, offse
 			return
 
 		}
-		if pollContext.control.archive.Listeners.RecordingSignalListener != nil {
-			pollContext.control.archive.Listeners.RecordingSignalListener(recordingSignalEvent)
-		}
+		pollContext.control.archive.onRecordingSignal(recordingSignalEvent)
 
 	default:
-		logger.Debug("descriptorFragmentHandler: Insert decoder for type: %d", hdr.TemplateId)
+		logger.Debug("descriptorFragmentHandler: Insert decoder for type: %d", hdr.TemplateId())
 	}
 }
 
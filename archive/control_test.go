@@ -134,6 +134,7 @@ func TestControl_PollForErrorResponse(t *testing.T) {
 }
 
 func mockPollResponses(t *testing.T, image *aeron.MockImage, responses ...encodable) {
+	image.On("IsClosed").Return(false).Maybe()
 	poll := image.On("Poll", mock.Anything, mock.Anything)
 	poll.Maybe()
 	poll.Run(func(args mock.Arguments) {
@@ -209,6 +210,7 @@ func newTestControl(t *testing.T) (*Control, *aeron.MockImage) {
 	c.fragmentAssembler = aeron.NewControlledFragmentAssembler(
 		c.onFragment, aeron.DefaultFragmentAssemblyBufferLength,
 	)
+	c.errorFragmentHandler = c.errorResponseFragmentHandler
 	return c, image
 }
 
@@ -228,3 +230,92 @@ func newTestHeader() *logbuffer.Header {
 	buffer.PutUInt8(logbuffer.DataFrameHeader_FlagsFieldOffset, 0xc0) // unfragmented
 	return new(logbuffer.Header).Wrap(buffer.Ptr(), buffer.Capacity())
 }
+
+type testCredentialsSupplier struct {
+	challenges [][]byte
+}
+
+func (s *testCredentialsSupplier) EncodedCredentials() []byte {
+	return []byte("credentials")
+}
+
+func (s *testCredentialsSupplier) OnChallenge(encodedChallenge []byte) []byte {
+	s.challenges = append(s.challenges, encodedChallenge)
+	return append([]byte("response to "), encodedChallenge...)
+}
+
+func TestConnectionControlFragmentHandler_ChallengeWithCredentialsSupplier(t *testing.T) {
+	control, _ := newTestControl(t)
+	supplier := &testCredentialsSupplier{}
+	control.archive.Options.CredentialsSupplier = supplier
+	control.archive.Options.AuthChallenge = []byte("static challenge") // Not checked with a supplier
+	control.archive.Options.RangeChecking = true
+	pub, image := aeron.NewInMemoryStream("aeron:ipc", 10, 1, logbuffer.TermMinLength)
+	control.archive.Proxy = &Proxy{Publication: pub, archive: control.archive, marshaller: codecs.NewSbeGoMarshaller()}
+	correlations.Store(int64(7), control)
+	defer correlations.Delete(int64(7))
+
+	buffer := encode(t, &codecs.Challenge{
+		ControlSessionId: 3,
+		CorrelationId:    7,
+		EncodedChallenge: []byte("dynamic challenge"),
+	})
+	ConnectionControlFragmentHandler(&PollContext{control, 7}, buffer, 0, buffer.Capacity(), newTestHeader())
+
+	assert.NoError(t, control.State.err)
+	assert.Equal(t, ControlStateChallenged, control.State.state)
+	assert.EqualValues(t, 3, control.archive.SessionID)
+	assert.Equal(t, [][]byte{[]byte("dynamic challenge")}, supplier.challenges)
+
+	var responses []codecs.ChallengeResponse
+	image.Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+		m := codecs.NewSbeGoMarshaller()
+		reader := bytes.NewReader(buffer.GetBytesArray(offset, length))
+		var hdr codecs.MessageHeader
+		assert.NoError(t, hdr.Decode(m, reader, 0))
+		var response codecs.ChallengeResponse
+		assert.NoError(t, response.Decode(m, reader, hdr.Version, hdr.BlockLength, true))
+		responses = append(responses, response)
+	}, 10)
+	if assert.Len(t, responses, 1) {
+		assert.EqualValues(t, 3, responses[0].ControlSessionId)
+		assert.EqualValues(t, 7, responses[0].CorrelationId)
+		assert.Equal(t, "response to dynamic challenge", string(responses[0].EncodedCredentials))
+	}
+}
+
+func TestConnectionControlFragmentHandler_UnexpectedStaticChallenge(t *testing.T) {
+	control, _ := newTestControl(t)
+	control.archive.Options.AuthChallenge = []byte("static challenge")
+	correlations.Store(int64(7), control)
+	defer correlations.Delete(int64(7))
+
+	buffer := encode(t, &codecs.Challenge{ControlSessionId: 3, CorrelationId: 7, EncodedChallenge: []byte("dynamic challenge")})
+	ConnectionControlFragmentHandler(&PollContext{control, 7}, buffer, 0, buffer.Capacity(), newTestHeader())
+	assert.ErrorContains(t, control.State.err, "ChallengeResponse Unexpected")
+	assert.NotEqual(t, ControlStateChallenged, control.State.state)
+}
+
+func TestControl_ReusesDecodedMessages(t *testing.T) {
+	control, image := newTestControl(t)
+	var signals []*codecs.RecordingSignalEvent
+	var positions []int64
+	control.archive.Listeners.RecordingSignalListener = func(rse *codecs.RecordingSignalEvent) {
+		signals = append(signals, rse)
+		positions = append(positions, rse.Position)
+	}
+	mockPollResponses(t, image,
+		&codecs.RecordingSignalEvent{CorrelationId: 1, Position: 10, Signal: codecs.RecordingSignal.START},
+		&codecs.RecordingSignalEvent{CorrelationId: 1, Position: 20, Signal: codecs.RecordingSignal.STOP},
+		&codecs.ControlResponse{Code: codecs.ControlResponseCode.OK, CorrelationId: 1, RelevantId: 3},
+	)
+	correlations.Store(int64(1), control)
+	id, err := control.PollForResponse(1, 0)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, id)
+	assert.Equal(t, []int64{10, 20}, positions)
+	if assert.Len(t, signals, 2) {
+		assert.Same(t, signals[0], signals[1])
+	}
+	assert.Same(t, &control.controlResponse, control.Results.ControlResponse)
+}
@@ -15,9 +15,12 @@
 package archive
 
 import (
+	"errors"
 	"time"
 
+	"github.com/lirm/aeron-go/aeron"
 	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/properties"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -29,21 +32,22 @@ import (
 // Those attributes marked [runtime] may be changed at any time
 // Those attributes marked [enable] may be changed when the feature is not enabled
 type Options struct {
-	RequestChannel         string             // [init] Control request publication channel
-	RequestStream          int32              // [init] and stream
-	ResponseChannel        string             // [init] Control response subscription channel
-	ResponseStream         int32              // [init] and stream
-	RecordingEventsChannel string             // [enable] Recording progress events
-	RecordingEventsStream  int32              // [enable] and stream
-	ArchiveLoglevel        zapcore.Level      // [runtime] via logging.SetLevel()
-	AeronLoglevel          zapcore.Level      // [runtime] via logging.SetLevel()
-	Timeout                time.Duration      // [runtime] How long to try sending/receiving control messages
-	IdleStrategy           idlestrategy.Idler // [runtime] Idlestrategy for sending/receiving control messages
-	RangeChecking          bool               // [runtime] archive protocol marshalling checks
-	AuthEnabled            bool               // [init] enable to require AuthConnect() over Connect()
-	AuthCredentials        []uint8            // [init] The credentials to be provided to AuthConnect()
-	AuthChallenge          []uint8            // [init] The challenge string we are to expect (checked iff not nil)
-	AuthResponse           []uint8            // [init] The challengeResponse we should provide
+	RequestChannel         string                    // [init] Control request publication channel
+	RequestStream          int32                     // [init] and stream
+	ResponseChannel        string                    // [init] Control response subscription channel
+	ResponseStream         int32                     // [init] and stream
+	RecordingEventsChannel string                    // [enable] Recording progress events
+	RecordingEventsStream  int32                     // [enable] and stream
+	ArchiveLoglevel        zapcore.Level             // [runtime] via logging.SetLevel()
+	AeronLoglevel          zapcore.Level             // [runtime] via logging.SetLevel()
+	Timeout                time.Duration             // [runtime] How long to try sending/receiving control messages
+	IdleStrategy           idlestrategy.Idler        // [runtime] Idlestrategy for sending/receiving control messages
+	RangeChecking          bool                      // [runtime] archive protocol marshalling checks
+	AuthEnabled            bool                      // [init] enable to require AuthConnect() over Connect()
+	AuthCredentials        []uint8                   // [init] The credentials to be provided to AuthConnect()
+	AuthChallenge          []uint8                   // [init] The challenge string we are to expect (checked iff not nil and there is no CredentialsSupplier)
+	AuthResponse           []uint8                   // [init] The challengeResponse we should provide
+	CredentialsSupplier    aeron.CredentialsSupplier // [init] If set, supplies credentials and challenge responses in place of the above
 }
 
 // These are the Options used by default for an Archive object
@@ -63,6 +67,16 @@ var defaultOptions = Options{
 	AuthCredentials:        nil,
 	AuthChallenge:          nil,
 	AuthResponse:           nil,
+	CredentialsSupplier:    nil,
+}
+
+// credentialsSupplier returns the CredentialsSupplier if set, otherwise one
+// built from the static AuthCredentials and AuthResponse
+func (options *Options) credentialsSupplier() aeron.CredentialsSupplier {
+	if options.CredentialsSupplier != nil {
+		return options.CredentialsSupplier
+	}
+	return &aeron.StaticCredentialsSupplier{Credentials: options.AuthCredentials, ChallengeResponse: options.AuthResponse}
 }
 
 // DefaultOptions creates and returns a new Options from the defaults.
@@ -70,3 +84,47 @@ func DefaultOptions() *Options {
 	options := defaultOptions
 	return &options
 }
+
+// Property names read by OptionsFromEnv() and ApplyProperties(), shared with
+// the Java archive client. Bare numbers for the timeout are nanoseconds.
+const (
+	ControlChannelPropName          = "aeron.archive.control.channel"            // RequestChannel
+	ControlStreamIdPropName         = "aeron.archive.control.stream.id"          // RequestStream
+	ControlResponseChannelPropName  = "aeron.archive.control.response.channel"   // ResponseChannel
+	ControlResponseStreamIdPropName = "aeron.archive.control.response.stream.id" // ResponseStream
+	RecordingEventsChannelPropName  = "aeron.archive.recording.events.channel"   // RecordingEventsChannel
+	RecordingEventsStreamIdPropName = "aeron.archive.recording.events.stream.id" // RecordingEventsStream
+	MessageTimeoutPropName          = "aeron.archive.message.timeout"            // Timeout
+)
+
+// OptionsFromEnv creates Options from the defaults overridden by the AERON_*
+// environment variables, e.g. AERON_ARCHIVE_CONTROL_CHANNEL. See package
+// properties for the naming and precedence.
+func OptionsFromEnv() (*Options, error) {
+	return OptionsFromProperties(properties.FromEnv())
+}
+
+// OptionsFromProperties creates Options from the defaults overridden by props,
+// which may be merged from files and the environment with properties.Load()
+func OptionsFromProperties(props properties.Properties) (*Options, error) {
+	options := DefaultOptions()
+	if err := options.ApplyProperties(props); err != nil {
+		return nil, err
+	}
+	return options, nil
+}
+
+// ApplyProperties overrides the options with those set in props. Channels must
+// be valid Aeron URIs, and the error lists each invalid property, leaving the
+// option unchanged for those.
+func (options *Options) ApplyProperties(props properties.Properties) error {
+	return errors.Join(
+		props.ChannelVar(&options.RequestChannel, ControlChannelPropName, aeron.ValidateChannelUri),
+		props.Int32Var(&options.RequestStream, ControlStreamIdPropName),
+		props.ChannelVar(&options.ResponseChannel, ControlResponseChannelPropName, aeron.ValidateChannelUri),
+		props.Int32Var(&options.ResponseStream, ControlResponseStreamIdPropName),
+		props.ChannelVar(&options.RecordingEventsChannel, RecordingEventsChannelPropName, aeron.ValidateChannelUri),
+		props.Int32Var(&options.RecordingEventsStream, RecordingEventsStreamIdPropName),
+		props.TimeoutVar(&options.Timeout, MessageTimeoutPropName, time.Nanosecond),
+	)
+}
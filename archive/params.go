@@ -0,0 +1,96 @@
+// Copyright (C) 2021-2022 Talos, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"fmt"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/util"
+)
+
+// Archive protocol versions from which the newer control requests are understood.
+// The version in use is reported by the archive in the ControlResponse to our connect
+// request and available via Archive.ProtocolVersion()
+var (
+	ProtocolVersionWithArchiveId    = int32(util.SemanticVersionCompose(1, 10, 0))
+	ProtocolVersionWithReplayTokens = int32(util.SemanticVersionCompose(1, 11, 0))
+)
+
+// ReplayParams contains the optional parameters for StartReplayWithParams. Use
+// NewReplayParams() to get a set with all values defaulted.
+type ReplayParams struct {
+	Position               int64 // Position to start the replay from, RecordingPositionNull for the start
+	Length                 int64 // Length to replay, RecordingLengthNull or RecordingLengthMax to follow a live recording
+	BoundingLimitCounterId int32 // Counter bounding the replay (see BoundedReplay), aeron.NullValue if unbounded
+	FileIoMaxLength        int32 // Maximum size of a file read by the archive, aeron.NullValue for the archive default
+	ReplayToken            int64 // Token from RequestReplayToken() when replaying on a response channel, otherwise aeron.NullValue
+}
+
+// NewReplayParams creates and returns a new ReplayParams which will replay the whole recording
+func NewReplayParams() *ReplayParams {
+	return &ReplayParams{
+		Position:               RecordingPositionNull,
+		Length:                 RecordingLengthNull,
+		BoundingLimitCounterId: aeron.NullValue,
+		FileIoMaxLength:        aeron.NullValue,
+		ReplayToken:            aeron.NullValue,
+	}
+}
+
+// IsBounded is true if the replay is to be bounded by a position counter
+func (params *ReplayParams) IsBounded() bool {
+	return params.BoundingLimitCounterId != aeron.NullValue
+}
+
+// ReplicationParams contains the optional parameters for ReplicateWithParams. Use
+// NewReplicationParams() to get a set with all values defaulted.
+type ReplicationParams struct {
+	DstRecordingId       int64   // Recording to extend in the destination, otherwise RecordingIdNullValue
+	StopPosition         int64   // Position to stop the replication at, RecordingPositionNull for the end of the recording
+	LiveDestination      string  // Destination for the live stream if merge is required, empty for no merge
+	ReplicationChannel   string  // Channel over which the replication will occur, empty for the archive default
+	SrcResponseChannel   string  // Response channel for the source archive when using response channels
+	ChannelTagId         int64   // Used to tag the replication subscription, aeron.NullValue if not tagged
+	SubscriptionTagId    int64   // Used to tag the replication subscription, aeron.NullValue if not tagged
+	FileIoMaxLength      int32   // Maximum size of a file read by the source archive, aeron.NullValue for the default
+	ReplicationSessionId int32   // Session id for the replicated recording, aeron.NullValue to let the archive choose
+	EncodedCredentials   []uint8 // Credentials for authenticating with the source archive
+}
+
+// NewReplicationParams creates and returns a new ReplicationParams which will
+// replicate the whole source recording into a new destination recording
+func NewReplicationParams() *ReplicationParams {
+	return &ReplicationParams{
+		DstRecordingId:       int64(RecordingIdNullValue),
+		StopPosition:         RecordingPositionNull,
+		ChannelTagId:         aeron.NullValue,
+		SubscriptionTagId:    aeron.NullValue,
+		FileIoMaxLength:      aeron.NullValue,
+		ReplicationSessionId: aeron.NullValue,
+	}
+}
+
+// checkProtocolVersion returns an error if the connected archive predates the protocol
+// version required for an operation
+func (archive *Archive) checkProtocolVersion(required int32, operation string) error {
+	if archive.protocolVersion < required {
+		return fmt.Errorf("%s requires archive protocol version %s or later, archive is %s",
+			operation,
+			util.SemanticVersionToString(uint32(required)),
+			util.SemanticVersionToString(uint32(archive.protocolVersion)))
+	}
+	return nil
+}
@@ -0,0 +1,206 @@
+package archive
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
+	"github.com/lirm/aeron-go/aeron/util"
+	"github.com/lirm/aeron-go/archive/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// testArchiveServer answers each request on the archive's in-memory request
+// stream with a control response carrying relevantId, or the error message,
+// followed by any events queued for the control session
+type testArchiveServer struct {
+	t          *testing.T
+	requests   aeron.Image
+	templates  []uint16
+	bodies     [][]byte
+	relevantId int64
+	errMessage string
+	events     []encodable
+}
+
+func newTestArchive(t *testing.T, protocolVersion int32) (*Archive, *testArchiveServer) {
+	control, image := newTestControl(t)
+	archive := control.archive
+	archive.Control = control
+	archive.SessionID = 7
+	archive.protocolVersion = protocolVersion
+	archive.Options.RangeChecking = true
+	pub, requests := aeron.NewInMemoryStream("aeron:ipc", 10, 1, logbuffer.TermMinLength)
+	archive.Proxy = &Proxy{Publication: pub, archive: archive, marshaller: codecs.NewSbeGoMarshaller()}
+
+	server := &testArchiveServer{t: t, requests: requests}
+	image.On("IsClosed").Return(false).Maybe()
+	controlledPoll := image.On("ControlledPoll", mock.Anything, mock.Anything).Maybe()
+	controlledPoll.Run(func(args mock.Arguments) {
+		handler := args.Get(0).(term.ControlledFragmentHandler)
+		controlledPoll.Return(server.respond(handler))
+	})
+	return archive, server
+}
+
+func (server *testArchiveServer) respond(handler term.ControlledFragmentHandler) int {
+	var responses []*codecs.ControlResponse
+	server.requests.Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+		server.templates = append(server.templates, buffer.GetUInt16(offset+2))
+		server.bodies = append(server.bodies, buffer.GetBytesArray(offset+codecs.MessageHeaderLength, length-codecs.MessageHeaderLength))
+		response := &codecs.ControlResponse{
+			ControlSessionId: buffer.GetInt64(offset + codecs.MessageHeaderLength),
+			CorrelationId:    buffer.GetInt64(offset + codecs.MessageHeaderLength + 8),
+			RelevantId:       server.relevantId,
+			Code:             codecs.ControlResponseCode.OK,
+		}
+		if server.errMessage != "" {
+			response.Code = codecs.ControlResponseCode.ERROR
+			response.ErrorMessage = []byte(server.errMessage)
+		}
+		responses = append(responses, response)
+	}, 10)
+	for _, response := range responses {
+		buffer := encode(server.t, response)
+		handler(buffer, 0, buffer.Capacity(), newTestHeader())
+	}
+	if len(responses) > 0 {
+		return len(responses)
+	}
+	count := 0
+	for len(server.events) > 0 {
+		buffer := encode(server.t, server.events[0])
+		action := handler(buffer, 0, buffer.Capacity(), newTestHeader())
+		if action == term.ControlledPollActionAbort {
+			break
+		}
+		server.events = server.events[1:]
+		count++
+		if action == term.ControlledPollActionBreak {
+			break
+		}
+	}
+	return count
+}
+
+// decode the body of the only request received
+func (server *testArchiveServer) decode(request sbeDecoder, version uint16, blockLength uint16) {
+	require.Len(server.t, server.bodies, 1)
+	require.NoError(server.t, request.Decode(codecs.NewSbeGoMarshaller(), bytes.NewReader(server.bodies[0]), version, blockLength, true))
+}
+
+var olderProtocolVersion = int32(util.SemanticVersionCompose(1, 9, 0))
+
+func TestCheckProtocolVersion(t *testing.T) {
+	archive := &Archive{protocolVersion: ProtocolVersionWithArchiveId}
+	assert.NoError(t, archive.checkProtocolVersion(ProtocolVersionWithArchiveId, "ArchiveId"))
+	err := archive.checkProtocolVersion(ProtocolVersionWithReplayTokens, "ReplayToken")
+	assert.EqualError(t, err, "ReplayToken requires archive protocol version 1.11.0 or later, archive is 1.10.0")
+}
+
+func TestArchive_OlderProtocolVersionRejected(t *testing.T) {
+	archive, server := newTestArchive(t, olderProtocolVersion)
+
+	_, err := archive.ArchiveId()
+	assert.EqualError(t, err, "ArchiveId requires archive protocol version 1.10.0 or later, archive is 1.9.0")
+	_, err = archive.GetMaxRecordedPosition(5)
+	assert.EqualError(t, err, "GetMaxRecordedPosition requires archive protocol version 1.10.0 or later, archive is 1.9.0")
+	_, err = archive.RequestReplayToken(5)
+	assert.EqualError(t, err, "RequestReplayToken requires archive protocol version 1.11.0 or later, archive is 1.9.0")
+
+	params := NewReplayParams()
+	params.ReplayToken = 99
+	_, err = archive.StartReplayWithParams(5, "aeron:ipc", 11, params)
+	assert.EqualError(t, err, "ReplayToken requires archive protocol version 1.11.0 or later, archive is 1.9.0")
+
+	server.respond(nil)
+	assert.Empty(t, server.templates, "no requests are sent")
+}
+
+func TestArchive_StartReplayWithParams_OlderProtocolVersion(t *testing.T) {
+	// Replays without a token are still allowed
+	archive, server := newTestArchive(t, olderProtocolVersion)
+	server.relevantId = 1234
+	replaySessionId, err := archive.StartReplayWithParams(5, "aeron:ipc", 11, NewReplayParams())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1234, replaySessionId)
+
+	var request codecs.ReplayRequest
+	server.decode(&request, request.SbeSchemaVersion(), request.SbeBlockLength())
+	assert.EqualValues(t, 5, request.RecordingId)
+	assert.EqualValues(t, aeron.NullValue, request.ReplayToken)
+}
+
+func TestArchive_StartReplayWithParams(t *testing.T) {
+	archive, server := newTestArchive(t, ProtocolVersionWithReplayTokens)
+	server.relevantId = 1234
+	params := NewReplayParams()
+	params.Position = 1024
+	params.FileIoMaxLength = 4096
+	params.ReplayToken = 99
+	replaySessionId, err := archive.StartReplayWithParams(5, "aeron:ipc", 11, params)
+	require.NoError(t, err)
+	assert.EqualValues(t, 1234, replaySessionId)
+
+	var request codecs.ReplayRequest
+	require.Equal(t, []uint16{request.SbeTemplateId()}, server.templates)
+	server.decode(&request, request.SbeSchemaVersion(), request.SbeBlockLength())
+	assert.EqualValues(t, 7, request.ControlSessionId)
+	assert.EqualValues(t, 5, request.RecordingId)
+	assert.EqualValues(t, 1024, request.Position)
+	assert.EqualValues(t, 11, request.ReplayStreamId)
+	assert.EqualValues(t, 4096, request.FileIoMaxLength)
+	assert.EqualValues(t, 99, request.ReplayToken)
+	assert.Equal(t, "aeron:ipc", string(request.ReplayChannel))
+}
+
+func TestArchive_RequestReplayToken(t *testing.T) {
+	archive, server := newTestArchive(t, ProtocolVersionWithReplayTokens)
+	server.relevantId = 99
+	token, err := archive.RequestReplayToken(5)
+	require.NoError(t, err)
+	assert.EqualValues(t, 99, token)
+
+	var request codecs.ReplayTokenRequest
+	require.Equal(t, []uint16{request.SbeTemplateId()}, server.templates)
+	server.decode(&request, request.SbeSchemaVersion(), request.SbeBlockLength())
+	assert.EqualValues(t, 5, request.RecordingId)
+}
+
+func TestArchive_GetMaxRecordedPosition(t *testing.T) {
+	archive, server := newTestArchive(t, ProtocolVersionWithArchiveId)
+	server.relevantId = 8192
+	position, err := archive.GetMaxRecordedPosition(5)
+	require.NoError(t, err)
+	assert.EqualValues(t, 8192, position)
+
+	var request codecs.MaxRecordedPositionRequest
+	require.Equal(t, []uint16{request.SbeTemplateId()}, server.templates)
+	server.decode(&request, request.SbeSchemaVersion(), request.SbeBlockLength())
+	assert.EqualValues(t, 5, request.RecordingId)
+}
+
+func TestArchive_ArchiveId(t *testing.T) {
+	archive, server := newTestArchive(t, ProtocolVersionWithArchiveId)
+	server.relevantId = 42
+	id, err := archive.ArchiveId()
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, id)
+
+	var request codecs.ArchiveIdRequest
+	require.Equal(t, []uint16{request.SbeTemplateId()}, server.templates)
+	server.decode(&request, request.SbeSchemaVersion(), request.SbeBlockLength())
+	assert.EqualValues(t, 7, request.ControlSessionId)
+}
+
+func TestArchive_ArchiveId_ErrorResponse(t *testing.T) {
+	archive, server := newTestArchive(t, ProtocolVersionWithArchiveId)
+	server.errMessage = "unknown request"
+	_, err := archive.ArchiveId()
+	assert.EqualError(t, err, "Control Response failure: unknown request")
+}
@@ -123,7 +123,30 @@ func (proxy *Proxy) StopRecordingRequest(correlationID int64, stream int32, chan
 func (proxy *Proxy) ReplayRequest(correlationID int64, recordingID int64, position int64, length int64, replayChannel string, replayStream int32) error {
 
 	// Create a packet and send it
-	bytes, err := codecs.ReplayRequestPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, recordingID, position, length, replayStream, replayChannel)
+	bytes, err := codecs.ReplayRequestPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, recordingID, position, length, aeron.NullValue, aeron.NullValue, replayStream, replayChannel)
+	if err != nil {
+		return err
+	}
+
+	if ret := proxy.Offer(atomic.NewBufferSlice(bytes), 0, int32(len(bytes)), nil); ret < 0 {
+		return fmt.Errorf("Offer failed: %d", ret)
+	}
+
+	return nil
+}
+
+// ReplayRequestWithParams packet and offer
+// Uses a BoundedReplayRequest if the params specify a limit counter
+func (proxy *Proxy) ReplayRequestWithParams(correlationID int64, recordingID int64, replayChannel string, replayStream int32, params *ReplayParams) error {
+
+	// Create a packet and send it
+	var bytes []byte
+	var err error
+	if params.IsBounded() {
+		bytes, err = codecs.BoundedReplayPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, recordingID, params.Position, params.Length, params.BoundingLimitCounterId, params.FileIoMaxLength, params.ReplayToken, replayStream, replayChannel)
+	} else {
+		bytes, err = codecs.ReplayRequestPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, recordingID, params.Position, params.Length, params.FileIoMaxLength, params.ReplayToken, replayStream, replayChannel)
+	}
 	if err != nil {
 		return err
 	}
@@ -326,7 +349,7 @@ func (proxy *Proxy) ListRecordingSubscriptionsRequest(correlationID int64, pseud
 func (proxy *Proxy) BoundedReplayRequest(correlationID int64, recordingID int64, position int64, length int64, limitCounterID int32, replayStream int32, replayChannel string) error {
 
 	// Create a packet and send it
-	bytes, err := codecs.BoundedReplayPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, recordingID, position, length, limitCounterID, replayStream, replayChannel)
+	bytes, err := codecs.BoundedReplayPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, recordingID, position, length, limitCounterID, aeron.NullValue, aeron.NullValue, replayStream, replayChannel)
 	if err != nil {
 		return err
 	}
@@ -400,6 +423,22 @@ func (proxy *Proxy) ReplicateRequest2(correlationID int64, srcRecordingID int64,
 	return nil
 }
 
+// ReplicateRequestWithParams packet and offer
+// Uses ReplicateRequest2 with the fields added in later protocol versions
+func (proxy *Proxy) ReplicateRequestWithParams(correlationID int64, srcRecordingID int64, srcControlStreamID int32, srcControlChannel string, params *ReplicationParams) error {
+	// Create a packet and send it
+	bytes, err := codecs.ReplicateRequest2WithParamsPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, srcRecordingID, params.DstRecordingId, params.StopPosition, params.ChannelTagId, params.SubscriptionTagId, srcControlStreamID, params.FileIoMaxLength, params.ReplicationSessionId, srcControlChannel, params.LiveDestination, params.ReplicationChannel, params.EncodedCredentials, params.SrcResponseChannel)
+	if err != nil {
+		return err
+	}
+
+	if ret := proxy.Offer(atomic.NewBufferSlice(bytes), 0, int32(len(bytes)), nil); ret < 0 {
+		return fmt.Errorf("Offer failed: %d", ret)
+	}
+
+	return nil
+}
+
 // StopReplicationRequest packet and offer
 func (proxy *Proxy) StopReplicationRequest(correlationID int64, replicationID int64) error {
 	// Create a packet and send it
@@ -580,3 +619,48 @@ func (proxy *Proxy) PurgeRecordingRequest(correlationID int64, replaySessionID i
 
 	return nil
 }
+
+// MaxRecordedPositionRequest packet and offer
+func (proxy *Proxy) MaxRecordedPositionRequest(correlationID int64, recordingID int64) error {
+	// Create a packet and send it
+	bytes, err := codecs.MaxRecordedPositionRequestPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, recordingID)
+	if err != nil {
+		return err
+	}
+
+	if ret := proxy.Offer(atomic.NewBufferSlice(bytes), 0, int32(len(bytes)), nil); ret < 0 {
+		return fmt.Errorf("Offer failed: %d", ret)
+	}
+
+	return nil
+}
+
+// ArchiveIdRequest packet and offer
+func (proxy *Proxy) ArchiveIdRequest(correlationID int64) error {
+	// Create a packet and send it
+	bytes, err := codecs.ArchiveIdRequestPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID)
+	if err != nil {
+		return err
+	}
+
+	if ret := proxy.Offer(atomic.NewBufferSlice(bytes), 0, int32(len(bytes)), nil); ret < 0 {
+		return fmt.Errorf("Offer failed: %d", ret)
+	}
+
+	return nil
+}
+
+// ReplayTokenRequest packet and offer
+func (proxy *Proxy) ReplayTokenRequest(correlationID int64, recordingID int64) error {
+	// Create a packet and send it
+	bytes, err := codecs.ReplayTokenRequestPacket(proxy.marshaller, proxy.archive.Options.RangeChecking, proxy.archive.SessionID, correlationID, recordingID)
+	if err != nil {
+		return err
+	}
+
+	if ret := proxy.Offer(atomic.NewBufferSlice(bytes), 0, int32(len(bytes)), nil); ret < 0 {
+		return fmt.Errorf("Offer failed: %d", ret)
+	}
+
+	return nil
+}
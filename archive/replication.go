@@ -0,0 +1,331 @@
+// Copyright (C) 2021-2022 Talos, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/lirm/aeron-go/archive/codecs"
+)
+
+// ReplicationState is the progress of a ReplicationSession as
+// determined by the recording signals received for it
+type ReplicationState int
+
+// The states of a ReplicationSession
+const (
+	ReplicationPending     ReplicationState = iota // Requested, no signal yet received
+	ReplicationReplicating                         // Destination recording has started or been extended
+	ReplicationSynced                              // Destination has caught up with the source recording
+	ReplicationMerged                              // Replay has merged with the live stream
+	ReplicationEnded                               // Replication has finished
+	ReplicationStopped                             // Replication was stopped by Stop()
+	ReplicationFailed                              // The archive reported an error for the replication
+)
+
+func (state ReplicationState) String() string {
+	switch state {
+	case ReplicationPending:
+		return "Pending"
+	case ReplicationReplicating:
+		return "Replicating"
+	case ReplicationSynced:
+		return "Synced"
+	case ReplicationMerged:
+		return "Merged"
+	case ReplicationEnded:
+		return "Ended"
+	case ReplicationStopped:
+		return "Stopped"
+	case ReplicationFailed:
+		return "Failed"
+	}
+	return fmt.Sprintf("ReplicationState(%d)", int(state))
+}
+
+// IsTerminal is true if no further signals are expected for the replication
+func (state ReplicationState) IsTerminal() bool {
+	return state == ReplicationEnded || state == ReplicationStopped || state == ReplicationFailed
+}
+
+// replicationArchive is the part of the Archive used by a ReplicationSession
+type replicationArchive interface {
+	PollForErrorResponse() (int, error)
+	StopReplication(replicationID int64) error
+	onReplicationError(err error)
+	idle()
+	untrackReplication(replicationId int64)
+}
+
+// ReplicationSession tracks the RecordingSignalEvents for a
+// replication. The signals are delivered whenever the archive's
+// control responses are polled, which the Await and TryAwait methods
+// do via PollForErrorResponse().
+type ReplicationSession struct {
+	archive        replicationArchive
+	replicationId  int64
+	mtx            sync.Mutex
+	state          ReplicationState
+	dstRecordingId int64
+	position       int64
+	signals        map[codecs.RecordingSignalEnum]bool
+	err            error
+}
+
+// NewReplicationSession creates and returns a ReplicationSession
+// tracking a replication id as returned by Replicate(), Replicate2(),
+// TaggedReplicate() or ReplicateWithParams().
+//
+// Signals received before the session is created are not seen, so
+// prefer ReplicateSession() which creates the session on request.
+func NewReplicationSession(archive *Archive, replicationId int64) *ReplicationSession {
+	session := newReplicationSession(archive, replicationId)
+	archive.replicationSessions.Store(replicationId, session)
+	return session
+}
+
+func newReplicationSession(archive replicationArchive, replicationId int64) *ReplicationSession {
+	return &ReplicationSession{
+		archive:        archive,
+		replicationId:  replicationId,
+		state:          ReplicationPending,
+		dstRecordingId: int64(RecordingIdNullValue),
+		position:       RecordingPositionNull,
+		signals:        make(map[codecs.RecordingSignalEnum]bool),
+	}
+}
+
+// ReplicateSession requests a replication as per ReplicateWithParams()
+// and returns a ReplicationSession tracking it
+func (archive *Archive) ReplicateSession(srcRecordingID int64, srcControlStreamID int32, srcControlChannel string, params *ReplicationParams) (*ReplicationSession, error) {
+	replicationId, err := archive.ReplicateWithParams(srcRecordingID, srcControlStreamID, srcControlChannel, params)
+	if err != nil {
+		return nil, err
+	}
+	session := NewReplicationSession(archive, replicationId)
+	if params.DstRecordingId != int64(RecordingIdNullValue) {
+		session.dstRecordingId = params.DstRecordingId
+	}
+	return session, nil
+}
+
+// ReplicationId of the replication being tracked
+func (session *ReplicationSession) ReplicationId() int64 {
+	return session.replicationId
+}
+
+// State of the replication
+func (session *ReplicationSession) State() ReplicationState {
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+	return session.state
+}
+
+// DstRecordingId is the recording id in the destination archive, or
+// RecordingIdNullValue until the replication has started
+func (session *ReplicationSession) DstRecordingId() int64 {
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+	return session.dstRecordingId
+}
+
+// Position of the destination recording as of the last signal
+func (session *ReplicationSession) Position() int64 {
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+	return session.position
+}
+
+// LastError reported by the archive for this replication, if any
+func (session *ReplicationSession) LastError() error {
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+	return session.err
+}
+
+// HasSignalled is true if the signal has been received for this replication
+func (session *ReplicationSession) HasSignalled(signal codecs.RecordingSignalEnum) bool {
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+	return session.signals[signal]
+}
+
+// TryAwaitSignal polls the archive once without blocking and reports
+// whether the signal has been received. Typically used with
+// RecordingSignal.SYNC, RecordingSignal.MERGE or RecordingSignal.REPLICATE_END
+//
+// Returns an error if the replication has failed, or has finished
+// without the signal being received.
+func (session *ReplicationSession) TryAwaitSignal(signal codecs.RecordingSignalEnum) (bool, error) {
+	if done, err := session.checkSignal(signal); done || err != nil {
+		return done, err
+	}
+	if _, err := session.archive.PollForErrorResponse(); err != nil {
+		if session.LastError() == nil {
+			if err == ErrNotConnected {
+				return false, err
+			}
+			session.archive.onReplicationError(err)
+		}
+	}
+	return session.checkSignal(signal)
+}
+
+// AwaitSignal polls the archive until the signal has been received for
+// this replication or the timeout expires. The archive's IdleStrategy
+// is used between polls.
+//
+// Returns an error on timeout, if the replication has failed, or has
+// finished without the signal being received.
+func (session *ReplicationSession) AwaitSignal(signal codecs.RecordingSignalEnum, timeout time.Duration) error {
+	start := time.Now()
+	for {
+		done, err := session.TryAwaitSignal(signal)
+		if done || err != nil {
+			return err
+		}
+		if time.Since(start) > timeout {
+			return fmt.Errorf("timeout waiting for %s on replicationId %d", recordingSignalName(signal), session.replicationId)
+		}
+		session.archive.idle()
+	}
+}
+
+// Stop the replication via StopReplication() and stop tracking it
+func (session *ReplicationSession) Stop() error {
+	if session.State().IsTerminal() {
+		return nil
+	}
+	if err := session.archive.StopReplication(session.replicationId); err != nil {
+		return err
+	}
+	session.setTerminal(ReplicationStopped, nil)
+	return nil
+}
+
+// Close stops tracking the replication without stopping it
+func (session *ReplicationSession) Close() {
+	session.archive.untrackReplication(session.replicationId)
+}
+
+func (session *ReplicationSession) checkSignal(signal codecs.RecordingSignalEnum) (bool, error) {
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+	if session.signals[signal] {
+		return true, nil
+	}
+	if session.err != nil {
+		return false, session.err
+	}
+	if session.state.IsTerminal() {
+		return false, fmt.Errorf("replicationId %d is %s without receiving %s", session.replicationId, session.state, recordingSignalName(signal))
+	}
+	return false, nil
+}
+
+func (session *ReplicationSession) onSignal(rse *codecs.RecordingSignalEvent) {
+	session.mtx.Lock()
+	defer session.mtx.Unlock()
+
+	session.signals[rse.Signal] = true
+	if rse.RecordingId != int64(RecordingIdNullValue) {
+		session.dstRecordingId = rse.RecordingId
+	}
+	if rse.Position != RecordingPositionNull {
+		session.position = rse.Position
+	}
+
+	var state ReplicationState
+	switch rse.Signal {
+	case codecs.RecordingSignal.START, codecs.RecordingSignal.EXTEND, codecs.RecordingSignal.REPLICATE:
+		state = ReplicationReplicating
+	case codecs.RecordingSignal.SYNC:
+		state = ReplicationSynced
+	case codecs.RecordingSignal.MERGE:
+		state = ReplicationMerged
+	case codecs.RecordingSignal.REPLICATE_END:
+		state = ReplicationEnded
+	default:
+		return
+	}
+	if state > session.state && !session.state.IsTerminal() {
+		session.state = state
+	}
+	if state.IsTerminal() {
+		session.archive.untrackReplication(session.replicationId)
+	}
+}
+
+func (session *ReplicationSession) setTerminal(state ReplicationState, err error) {
+	session.mtx.Lock()
+	if !session.state.IsTerminal() {
+		session.state = state
+		session.err = err
+	}
+	session.mtx.Unlock()
+	session.archive.untrackReplication(session.replicationId)
+}
+
+// onRecordingSignal routes a signal to its replication session, if
+// any, and then to the RecordingSignalListener
+func (archive *Archive) onRecordingSignal(rse *codecs.RecordingSignalEvent) {
+	if rse.ControlSessionId == archive.SessionID {
+		if session, ok := archive.replicationSessions.Load(rse.CorrelationId); ok {
+			session.(*ReplicationSession).onSignal(rse)
+		}
+	}
+	if archive.Listeners.RecordingSignalListener != nil {
+		archive.Listeners.RecordingSignalListener(rse)
+	}
+}
+
+func (archive *Archive) onReplicationError(err error) {
+	if archive.Listeners.ErrorListener != nil {
+		archive.Listeners.ErrorListener(err)
+	}
+}
+
+func (archive *Archive) idle() {
+	archive.Options.IdleStrategy.Idle(0)
+}
+
+func (archive *Archive) untrackReplication(replicationId int64) {
+	archive.replicationSessions.Delete(replicationId)
+}
+
+// onAsyncErrorResponse routes an uncorrelated error response to its replication session, if any
+func (archive *Archive) onAsyncErrorResponse(controlResponse *codecs.ControlResponse) {
+	if controlResponse.ControlSessionId != archive.SessionID || controlResponse.Code != codecs.ControlResponseCode.ERROR {
+		return
+	}
+	if session, ok := archive.replicationSessions.Load(controlResponse.CorrelationId); ok {
+		err := fmt.Errorf("replicationId %d failed: %s", controlResponse.CorrelationId, controlResponse.ErrorMessage)
+		session.(*ReplicationSession).setTerminal(ReplicationFailed, err)
+	}
+}
+
+// recordingSignalName for use in errors and logging
+func recordingSignalName(signal codecs.RecordingSignalEnum) string {
+	value := reflect.ValueOf(codecs.RecordingSignal)
+	for idx := 0; idx < value.NumField(); idx++ {
+		if signal == value.Field(idx).Interface() {
+			return value.Type().Field(idx).Name
+		}
+	}
+	return fmt.Sprintf("RecordingSignal(%d)", int32(signal))
+}
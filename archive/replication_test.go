@@ -0,0 +1,277 @@
+package archive
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/archive/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func signalEvent(correlationId int64, signal codecs.RecordingSignalEnum, position int64) *codecs.RecordingSignalEvent {
+	return &codecs.RecordingSignalEvent{
+		ControlSessionId: 7,
+		CorrelationId:    correlationId,
+		RecordingId:      5,
+		SubscriptionId:   1,
+		Position:         position,
+		Signal:           signal,
+	}
+}
+
+func TestArchive_ReplicateSession(t *testing.T) {
+	archive, server := newTestArchive(t, ProtocolVersionWithReplayTokens)
+	var listened []codecs.RecordingSignalEnum
+	archive.Listeners.RecordingSignalListener = func(rse *codecs.RecordingSignalEvent) {
+		listened = append(listened, rse.Signal)
+	}
+	server.relevantId = 42
+	params := NewReplicationParams()
+	params.ReplicationChannel = "aeron:udp?endpoint=localhost:0"
+	session, err := archive.ReplicateSession(3, 10, "aeron:udp?endpoint=localhost:8010", params)
+	require.NoError(t, err)
+	assert.EqualValues(t, 42, session.ReplicationId())
+	assert.Equal(t, ReplicationPending, session.State())
+	assert.EqualValues(t, RecordingIdNullValue, session.DstRecordingId())
+
+	var request codecs.ReplicateRequest2
+	require.Equal(t, []uint16{request.SbeTemplateId()}, server.templates)
+	server.decode(&request, request.SbeSchemaVersion(), request.SbeBlockLength())
+	assert.EqualValues(t, 3, request.SrcRecordingId)
+	assert.EqualValues(t, 10, request.SrcControlStreamId)
+	assert.Equal(t, "aeron:udp?endpoint=localhost:8010", string(request.SrcControlChannel))
+	assert.Equal(t, "aeron:udp?endpoint=localhost:0", string(request.ReplicationChannel))
+
+	server.events = []encodable{
+		signalEvent(42, codecs.RecordingSignal.REPLICATE, 0),
+		signalEvent(43, codecs.RecordingSignal.SYNC, 2048), // Another replication
+		signalEvent(42, codecs.RecordingSignal.SYNC, 1024),
+	}
+	require.NoError(t, session.AwaitSignal(codecs.RecordingSignal.SYNC, time.Second))
+	assert.Equal(t, ReplicationSynced, session.State())
+	assert.EqualValues(t, 5, session.DstRecordingId())
+	assert.EqualValues(t, 1024, session.Position())
+	assert.Empty(t, server.events)
+
+	server.events = []encodable{
+		signalEvent(42, codecs.RecordingSignal.STOP, 4096),
+		signalEvent(42, codecs.RecordingSignal.REPLICATE_END, 4096),
+	}
+	require.NoError(t, session.AwaitSignal(codecs.RecordingSignal.REPLICATE_END, time.Second))
+	assert.Equal(t, ReplicationEnded, session.State())
+	assert.EqualValues(t, 4096, session.Position())
+	_, tracked := archive.replicationSessions.Load(int64(42))
+	assert.False(t, tracked)
+
+	done, err := session.TryAwaitSignal(codecs.RecordingSignal.MERGE)
+	assert.False(t, done)
+	assert.EqualError(t, err, "replicationId 42 is Ended without receiving MERGE")
+	require.NoError(t, session.Stop())
+	assert.Len(t, server.templates, 1, "an ended replication is not stopped")
+	assert.NotEmpty(t, listened)
+}
+
+func TestArchive_ReplicateSession_Error(t *testing.T) {
+	archive, server := newTestArchive(t, ProtocolVersionWithReplayTokens)
+	var errs []error
+	archive.Listeners.ErrorListener = func(err error) { errs = append(errs, err) }
+	server.relevantId = 42
+	session, err := archive.ReplicateSession(3, 10, "aeron:udp?endpoint=localhost:8010", NewReplicationParams())
+	require.NoError(t, err)
+
+	server.events = []encodable{
+		signalEvent(42, codecs.RecordingSignal.REPLICATE, 0),
+		&codecs.ControlResponse{
+			ControlSessionId: 7,
+			CorrelationId:    42,
+			RelevantId:       int64(aeron.NullValue),
+			Code:             codecs.ControlResponseCode.ERROR,
+			ErrorMessage:     []byte("b0rk"),
+		},
+	}
+	err = session.AwaitSignal(codecs.RecordingSignal.SYNC, time.Second)
+	assert.EqualError(t, err, "replicationId 42 failed: b0rk")
+	assert.Equal(t, ReplicationFailed, session.State())
+	assert.Equal(t, err, session.LastError())
+	assert.Empty(t, errs, "the replication's own error is not reported to the listener")
+	_, tracked := archive.replicationSessions.Load(int64(42))
+	assert.False(t, tracked)
+}
+
+func TestArchive_ReplicateSession_Stop(t *testing.T) {
+	archive, server := newTestArchive(t, ProtocolVersionWithReplayTokens)
+	server.relevantId = 42
+	params := NewReplicationParams()
+	params.DstRecordingId = 9
+	session, err := archive.ReplicateSession(3, 10, "aeron:udp?endpoint=localhost:8010", params)
+	require.NoError(t, err)
+	assert.EqualValues(t, 9, session.DstRecordingId(), "extends the given recording")
+
+	server.templates, server.bodies = nil, nil
+	require.NoError(t, session.Stop())
+	assert.Equal(t, ReplicationStopped, session.State())
+	var request codecs.StopReplicationRequest
+	require.Equal(t, []uint16{request.SbeTemplateId()}, server.templates)
+	server.decode(&request, request.SbeSchemaVersion(), request.SbeBlockLength())
+	assert.EqualValues(t, 42, request.ReplicationId)
+	_, tracked := archive.replicationSessions.Load(int64(42))
+	assert.False(t, tracked)
+}
+
+// fakeReplicationArchive delivers signals to a session as it is polled
+type fakeReplicationArchive struct {
+	session   *ReplicationSession
+	polls     int
+	onPoll    func(polls int) error
+	errs      []error
+	stopped   []int64
+	untracked []int64
+}
+
+func newFakeReplicationSession(onPoll func(polls int) error) (*ReplicationSession, *fakeReplicationArchive) {
+	archive := &fakeReplicationArchive{onPoll: onPoll}
+	archive.session = newReplicationSession(archive, 42)
+	return archive.session, archive
+}
+
+func (archive *fakeReplicationArchive) signal(signal codecs.RecordingSignalEnum) {
+	archive.session.onSignal(&codecs.RecordingSignalEvent{CorrelationId: 42, RecordingId: 5, Position: 1024, Signal: signal})
+}
+
+func (archive *fakeReplicationArchive) PollForErrorResponse() (int, error) {
+	archive.polls++
+	if archive.onPoll == nil {
+		return 0, nil
+	}
+	return 1, archive.onPoll(archive.polls)
+}
+
+func (archive *fakeReplicationArchive) StopReplication(replicationID int64) error {
+	archive.stopped = append(archive.stopped, replicationID)
+	return nil
+}
+
+func (archive *fakeReplicationArchive) onReplicationError(err error) {
+	archive.errs = append(archive.errs, err)
+}
+
+func (archive *fakeReplicationArchive) idle() {}
+
+func (archive *fakeReplicationArchive) untrackReplication(replicationId int64) {
+	archive.untracked = append(archive.untracked, replicationId)
+}
+
+func TestReplicationSession_TryAwaitSignal(t *testing.T) {
+	var archive *fakeReplicationArchive
+	session, archive := newFakeReplicationSession(func(polls int) error {
+		if polls == 2 {
+			archive.signal(codecs.RecordingSignal.SYNC)
+		}
+		return nil
+	})
+
+	done, err := session.TryAwaitSignal(codecs.RecordingSignal.SYNC)
+	assert.False(t, done)
+	assert.NoError(t, err)
+	done, err = session.TryAwaitSignal(codecs.RecordingSignal.SYNC)
+	assert.True(t, done)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, archive.polls)
+
+	// Already signalled, so does not poll
+	done, err = session.TryAwaitSignal(codecs.RecordingSignal.SYNC)
+	assert.True(t, done)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, archive.polls)
+	assert.Equal(t, ReplicationSynced, session.State())
+	assert.EqualValues(t, 5, session.DstRecordingId())
+}
+
+func TestReplicationSession_TryAwaitSignal_PollErrors(t *testing.T) {
+	pollErr := errors.New("unexpected descriptor")
+	session, archive := newFakeReplicationSession(func(polls int) error {
+		if polls == 1 {
+			return pollErr
+		}
+		return ErrNotConnected
+	})
+
+	// Errors not for the replication are reported and polling continues
+	done, err := session.TryAwaitSignal(codecs.RecordingSignal.SYNC)
+	assert.False(t, done)
+	assert.NoError(t, err)
+	assert.Equal(t, []error{pollErr}, archive.errs)
+
+	done, err = session.TryAwaitSignal(codecs.RecordingSignal.SYNC)
+	assert.False(t, done)
+	assert.Equal(t, ErrNotConnected, err)
+	assert.Len(t, archive.errs, 1)
+}
+
+func TestReplicationSession_AwaitSignal(t *testing.T) {
+	var archive *fakeReplicationArchive
+	session, archive := newFakeReplicationSession(func(polls int) error {
+		switch polls {
+		case 2:
+			archive.signal(codecs.RecordingSignal.REPLICATE)
+		case 3:
+			archive.signal(codecs.RecordingSignal.MERGE)
+		}
+		return nil
+	})
+	require.NoError(t, session.AwaitSignal(codecs.RecordingSignal.MERGE, time.Second))
+	assert.Equal(t, 3, archive.polls)
+	assert.Equal(t, ReplicationMerged, session.State())
+	assert.Empty(t, archive.errs)
+}
+
+func TestReplicationSession_AwaitSignal_Timeout(t *testing.T) {
+	session, archive := newFakeReplicationSession(nil)
+	timeout := 20 * time.Millisecond
+	start := time.Now()
+	err := session.AwaitSignal(codecs.RecordingSignal.SYNC, timeout)
+	assert.EqualError(t, err, "timeout waiting for SYNC on replicationId 42")
+	assert.GreaterOrEqual(t, time.Since(start), timeout)
+	assert.Greater(t, archive.polls, 1)
+	assert.Equal(t, ReplicationPending, session.State())
+}
+
+func TestReplicationSession_AwaitSignal_Failed(t *testing.T) {
+	var archive *fakeReplicationArchive
+	failure := errors.New("replicationId 42 failed: b0rk")
+	session, archive := newFakeReplicationSession(func(polls int) error {
+		archive.session.setTerminal(ReplicationFailed, failure)
+		return errors.New("PollForErrorResponse received a ControlResponse")
+	})
+	assert.Equal(t, failure, session.AwaitSignal(codecs.RecordingSignal.SYNC, time.Second))
+	assert.Equal(t, 1, archive.polls)
+	assert.Empty(t, archive.errs, "the replication's own error is not reported again")
+	assert.Equal(t, []int64{42}, archive.untracked)
+}
+
+func TestReplicationSession_AwaitSignal_EndedWithoutSignal(t *testing.T) {
+	var archive *fakeReplicationArchive
+	session, archive := newFakeReplicationSession(func(polls int) error {
+		archive.signal(codecs.RecordingSignal.REPLICATE_END)
+		return nil
+	})
+	err := session.AwaitSignal(codecs.RecordingSignal.SYNC, time.Second)
+	assert.EqualError(t, err, "replicationId 42 is Ended without receiving SYNC")
+	assert.Equal(t, []int64{42}, archive.untracked)
+}
+
+func TestReplicationSession_Stop(t *testing.T) {
+	session, archive := newFakeReplicationSession(nil)
+	require.NoError(t, session.Stop())
+	assert.Equal(t, ReplicationStopped, session.State())
+	assert.Equal(t, []int64{42}, archive.stopped)
+	assert.Equal(t, []int64{42}, archive.untracked)
+
+	require.NoError(t, session.Stop())
+	assert.Len(t, archive.stopped, 1, "already stopped")
+	err := session.AwaitSignal(codecs.RecordingSignal.SYNC, time.Second)
+	assert.EqualError(t, err, "replicationId 42 is Stopped without receiving SYNC")
+}
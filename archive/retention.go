@@ -0,0 +1,383 @@
+// Copyright (C) 2021-2022 Talos, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package archive
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/archive/codecs"
+)
+
+// RetentionPolicy describes how much of the recordings matching a
+// channel and stream should be kept. A zero value for any limit
+// disables that limit.
+type RetentionPolicy struct {
+	Channel             string        // Channel fragment matched against recordings as per ListRecordingsForUri
+	StreamId            int32         // Stream matched against recordings
+	MaxAge              time.Duration // Stopped recordings which stopped longer ago than this are removed
+	MaxBytes            int64         // Total recorded bytes to keep, the oldest data is removed first
+	KeepLast            int           // Number of most recent recordings to keep, older stopped recordings are removed
+	MinRetainedPosition int64         // Data at or beyond this position in a recording is never removed, aeron.NullValue to disable
+}
+
+// NewRetentionPolicy creates and returns a new RetentionPolicy for
+// the channel and stream with no limits set
+func NewRetentionPolicy(channel string, streamId int32) *RetentionPolicy {
+	return &RetentionPolicy{
+		Channel:             channel,
+		StreamId:            streamId,
+		MinRetainedPosition: aeron.NullValue,
+	}
+}
+
+func (policy *RetentionPolicy) String() string {
+	return fmt.Sprintf("%s:%d", policy.Channel, policy.StreamId)
+}
+
+// RetentionActionType is the operation a RetentionAction applies to a recording
+type RetentionActionType int
+
+// The operations the RetentionManager may apply to a recording
+const (
+	RetentionPurgeRecording RetentionActionType = iota // The whole recording is removed with PurgeRecording()
+	RetentionPurgeSegments                             // Segments before NewStartPosition are removed with PurgeSegments()
+	RetentionDetachSegments                            // Segments before NewStartPosition are detached with DetachSegments()
+)
+
+func (actionType RetentionActionType) String() string {
+	switch actionType {
+	case RetentionPurgeRecording:
+		return "PurgeRecording"
+	case RetentionPurgeSegments:
+		return "PurgeSegments"
+	case RetentionDetachSegments:
+		return "DetachSegments"
+	}
+	return fmt.Sprintf("RetentionActionType(%d)", int(actionType))
+}
+
+// RetentionAction is a single operation on a recording that a
+// RetentionPolicy requires
+type RetentionAction struct {
+	Policy           *RetentionPolicy
+	RecordingId      int64
+	Type             RetentionActionType
+	NewStartPosition int64  // For segment operations, the new start position of the recording
+	Bytes            int64  // Number of recorded bytes the action removes
+	Reason           string // Which limit of the policy caused the action
+	Applied          bool   // True once the action has been successfully applied
+	Err              error  // Set if the action failed
+}
+
+func (action *RetentionAction) String() string {
+	var result string
+	switch action.Type {
+	case RetentionPurgeRecording:
+		result = fmt.Sprintf("%s recordingId=%d bytes=%d (%s)", action.Type, action.RecordingId, action.Bytes, action.Reason)
+	default:
+		result = fmt.Sprintf("%s recordingId=%d newStartPosition=%d bytes=%d (%s)", action.Type, action.RecordingId, action.NewStartPosition, action.Bytes, action.Reason)
+	}
+	if action.Err != nil {
+		result += fmt.Sprintf(" failed: %s", action.Err)
+	}
+	return result
+}
+
+// RetentionReport is returned by RetentionManager.Apply() and lists
+// the actions that were applied, or with DryRun, which would be.
+type RetentionReport struct {
+	DryRun  bool
+	Actions []*RetentionAction
+}
+
+// BytesReclaimed is the total number of recorded bytes removed by the
+// applied actions, or with DryRun, which would be removed
+func (report *RetentionReport) BytesReclaimed() int64 {
+	var total int64
+	for _, action := range report.Actions {
+		if action.Applied || (report.DryRun && action.Err == nil) {
+			total += action.Bytes
+		}
+	}
+	return total
+}
+
+// Errors returns the errors of any actions which failed
+func (report *RetentionReport) Errors() []error {
+	var errs []error
+	for _, action := range report.Actions {
+		if action.Err != nil {
+			errs = append(errs, action.Err)
+		}
+	}
+	return errs
+}
+
+func (report *RetentionReport) String() string {
+	var sb strings.Builder
+	if report.DryRun {
+		sb.WriteString("Retention report (dry run)\n")
+	} else {
+		sb.WriteString("Retention report\n")
+	}
+	for _, action := range report.Actions {
+		fmt.Fprintf(&sb, "  %s: %s\n", action.Policy, action)
+	}
+	fmt.Fprintf(&sb, "  %d action(s), %d bytes reclaimed\n", len(report.Actions), report.BytesReclaimed())
+	return sb.String()
+}
+
+// RetentionManager applies RetentionPolicies to the recordings in an
+// archive. It is intended to be called periodically.
+type RetentionManager struct {
+	archive    ArchiveClient
+	Policies   []*RetentionPolicy
+	DryRun     bool // Report what would be done without modifying any recordings
+	DetachOnly bool // Detach segments rather than deleting them, leaving the files for external handling
+	PageSize   int32
+}
+
+// NewRetentionManager creates and returns a new RetentionManager for
+// an archive with the given policies
+func NewRetentionManager(archive ArchiveClient, policies ...*RetentionPolicy) *RetentionManager {
+	return &RetentionManager{
+		archive:  archive,
+		Policies: policies,
+		PageSize: 100,
+	}
+}
+
+// Apply each of the policies to the recordings in the archive.
+//
+// Returns a report of the actions applied, or with DryRun, the
+// actions that would be applied. Failing actions are recorded in the
+// report and do not stop the remaining actions. An error is returned
+// if the recordings could not be listed.
+func (rm *RetentionManager) Apply() (*RetentionReport, error) {
+	report := &RetentionReport{DryRun: rm.DryRun}
+	now := time.Now()
+
+	for _, policy := range rm.Policies {
+		recordings, err := rm.listRecordings(policy)
+		if err != nil {
+			return report, err
+		}
+
+		actions := planRetention(policy, recordings, now, rm.DetachOnly)
+		if !rm.DryRun {
+			for _, action := range actions {
+				rm.applyAction(action)
+			}
+		}
+		report.Actions = append(report.Actions, actions...)
+	}
+
+	return report, nil
+}
+
+func (rm *RetentionManager) applyAction(action *RetentionAction) {
+	logger.Debugf("RetentionManager applying %s", action)
+	var err error
+	switch action.Type {
+	case RetentionPurgeRecording:
+		err = rm.archive.PurgeRecording(action.RecordingId)
+	case RetentionPurgeSegments:
+		_, err = rm.archive.PurgeSegments(action.RecordingId, action.NewStartPosition)
+	case RetentionDetachSegments:
+		err = rm.archive.DetachSegments(action.RecordingId, action.NewStartPosition)
+	}
+	if err != nil {
+		action.Err = fmt.Errorf("%s of recordingId %d failed: %w", action.Type, action.RecordingId, err)
+		logger.Warning(action.Err)
+		return
+	}
+	action.Applied = true
+}
+
+// listRecordings matching the policy, resolving the current position of active recordings
+func (rm *RetentionManager) listRecordings(policy *RetentionPolicy) ([]*retentionRecording, error) {
+	var recordings []*retentionRecording
+	fromRecordingID := int64(0)
+	for {
+		descriptors, err := rm.archive.ListRecordingsForUri(fromRecordingID, rm.PageSize, policy.Channel, policy.StreamId)
+		if err != nil {
+			return nil, err
+		}
+		for _, descriptor := range descriptors {
+			recording := &retentionRecording{
+				descriptor:    descriptor,
+				startPosition: descriptor.StartPosition,
+				position:      descriptor.StopPosition,
+			}
+			if descriptor.StopPosition == RecordingPositionNull {
+				recording.active = true
+				if recording.position, err = rm.archive.GetRecordingPosition(descriptor.RecordingId); err != nil {
+					return nil, err
+				}
+				// The recording may have stopped between the calls
+				if recording.position == RecordingPositionNull {
+					recording.position = descriptor.StartPosition
+				}
+			}
+			recordings = append(recordings, recording)
+			fromRecordingID = descriptor.RecordingId + 1
+		}
+		if len(descriptors) < int(rm.PageSize) {
+			return recordings, nil
+		}
+	}
+}
+
+// retentionRecording is a recording descriptor with its current extent
+type retentionRecording struct {
+	descriptor    *codecs.RecordingDescriptor
+	startPosition int64 // The start position allowing for any actions already planned
+	position      int64 // The stop position or for an active recording the recording position
+	active        bool
+}
+
+func (recording *retentionRecording) length() int64 {
+	return recording.position - recording.startPosition
+}
+
+// planRetention determines the actions required to bring the recordings within the policy
+func planRetention(policy *RetentionPolicy, recordings []*retentionRecording, now time.Time, detachOnly bool) []*RetentionAction {
+	var actions []*RetentionAction
+
+	// Most recent first
+	sort.Slice(recordings, func(i, j int) bool {
+		return recordings[i].descriptor.RecordingId > recordings[j].descriptor.RecordingId
+	})
+
+	// Whole recordings first by count and age
+	var retained []*retentionRecording
+	for idx, recording := range recordings {
+		var reason string
+		if !recording.active {
+			if policy.KeepLast > 0 && idx >= policy.KeepLast {
+				reason = fmt.Sprintf("keep last %d", policy.KeepLast)
+			} else if policy.MaxAge > 0 && now.Sub(time.UnixMilli(recording.descriptor.StopTimestamp)) > policy.MaxAge {
+				reason = fmt.Sprintf("max age %s", policy.MaxAge)
+			}
+		}
+		if reason == "" {
+			retained = append(retained, recording)
+			continue
+		}
+		if action := removeRecording(policy, recording, reason, detachOnly); action != nil {
+			actions = append(actions, action)
+			recording.startPosition += action.Bytes
+		}
+		if recording.length() > 0 {
+			retained = append(retained, recording)
+		}
+	}
+
+	// Then trim the oldest data until we're within the byte limit
+	if policy.MaxBytes > 0 {
+		var total int64
+		for _, recording := range retained {
+			total += recording.length()
+		}
+		reason := fmt.Sprintf("max bytes %d", policy.MaxBytes)
+		for idx := len(retained) - 1; idx >= 0 && total > policy.MaxBytes; idx-- {
+			recording := retained[idx]
+			var action *RetentionAction
+			if !recording.active && recording.length() <= total-policy.MaxBytes {
+				action = removeRecording(policy, recording, reason, detachOnly)
+			} else {
+				action = trimRecording(policy, recording, recording.startPosition+total-policy.MaxBytes, reason, detachOnly)
+			}
+			if action != nil {
+				actions = append(actions, action)
+				total -= action.Bytes
+			}
+		}
+	}
+
+	return actions
+}
+
+// removeRecording entirely if the policy allows, otherwise as much as can be trimmed
+func removeRecording(policy *RetentionPolicy, recording *retentionRecording, reason string, detachOnly bool) *RetentionAction {
+	protected := policy.MinRetainedPosition != aeron.NullValue && recording.position > policy.MinRetainedPosition
+	if protected || detachOnly {
+		return trimRecording(policy, recording, recording.position, reason, detachOnly)
+	}
+	return &RetentionAction{
+		Policy:           policy,
+		RecordingId:      recording.descriptor.RecordingId,
+		Type:             RetentionPurgeRecording,
+		NewStartPosition: recording.position,
+		Bytes:            recording.length(),
+		Reason:           reason,
+	}
+}
+
+// trimRecording segments up to the target position, rounding up to a whole segment
+func trimRecording(policy *RetentionPolicy, recording *retentionRecording, target int64, reason string, detachOnly bool) *RetentionAction {
+	descriptor := recording.descriptor
+	newStartPosition := SegmentFileBasePosition(descriptor.StartPosition, target, descriptor.TermBufferLength, descriptor.SegmentFileLength)
+	if newStartPosition < target {
+		newStartPosition += int64(descriptor.SegmentFileLength)
+	}
+
+	// Segments holding retained data can't be removed
+	if policy.MinRetainedPosition != aeron.NullValue {
+		retained := SegmentFileBasePosition(descriptor.StartPosition, policy.MinRetainedPosition, descriptor.TermBufferLength, descriptor.SegmentFileLength)
+		if newStartPosition > retained {
+			newStartPosition = retained
+		}
+	}
+
+	// Nor can the segment holding the current position
+	limit := SegmentFileBasePosition(descriptor.StartPosition, recording.position, descriptor.TermBufferLength, descriptor.SegmentFileLength)
+	if newStartPosition > limit {
+		newStartPosition = limit
+	}
+	if newStartPosition <= recording.startPosition {
+		return nil
+	}
+
+	actionType := RetentionPurgeSegments
+	if detachOnly {
+		actionType = RetentionDetachSegments
+	}
+	return &RetentionAction{
+		Policy:           policy,
+		RecordingId:      descriptor.RecordingId,
+		Type:             actionType,
+		NewStartPosition: newStartPosition,
+		Bytes:            newStartPosition - recording.startPosition,
+		Reason:           reason,
+	}
+}
+
+// SegmentFileBasePosition returns the base position of the segment
+// file holding a position in a recording. This is a valid position to
+// pass to DetachSegments() and PurgeSegments().
+func SegmentFileBasePosition(startPosition int64, position int64, termBufferLength int32, segmentFileLength int32) int64 {
+	if position == RecordingPositionNull {
+		return startPosition
+	}
+	startTermBasePosition := startPosition - (startPosition & int64(termBufferLength-1))
+	lengthFromBasePosition := position - startTermBasePosition
+	segments := lengthFromBasePosition - (lengthFromBasePosition & int64(segmentFileLength-1))
+	return startTermBasePosition + segments
+}
@@ -0,0 +1,154 @@
+package archive
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/archive/codecs"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testTermLength    = 64 * 1024
+	testSegmentLength = 4 * testTermLength
+)
+
+func newTestRecording(recordingID int64, startPosition int64, stopPosition int64, stopTimestamp time.Time) *retentionRecording {
+	descriptor := &codecs.RecordingDescriptor{
+		RecordingId:       recordingID,
+		StartPosition:     startPosition,
+		StopPosition:      stopPosition,
+		StopTimestamp:     stopTimestamp.UnixMilli(),
+		TermBufferLength:  testTermLength,
+		SegmentFileLength: testSegmentLength,
+	}
+	return &retentionRecording{descriptor: descriptor, startPosition: startPosition, position: stopPosition}
+}
+
+func TestSegmentFileBasePosition(t *testing.T) {
+	assert.EqualValues(t, 0, SegmentFileBasePosition(0, 0, testTermLength, testSegmentLength))
+	assert.EqualValues(t, 0, SegmentFileBasePosition(0, testSegmentLength-1, testTermLength, testSegmentLength))
+	assert.EqualValues(t, testSegmentLength, SegmentFileBasePosition(0, testSegmentLength, testTermLength, testSegmentLength))
+	assert.EqualValues(t, testTermLength, SegmentFileBasePosition(testTermLength+32, testSegmentLength, testTermLength, testSegmentLength))
+	assert.EqualValues(t, 1024, SegmentFileBasePosition(1024, RecordingPositionNull, testTermLength, testSegmentLength))
+}
+
+func TestPlanRetention(t *testing.T) {
+	now := time.Now()
+
+	t.Run("keeps everything with no limits", func(t *testing.T) {
+		policy := NewRetentionPolicy("aeron:ipc", 1001)
+		recordings := []*retentionRecording{
+			newTestRecording(1, 0, testSegmentLength, now.Add(-time.Hour)),
+			newTestRecording(2, 0, testSegmentLength, now),
+		}
+		assert.Empty(t, planRetention(policy, recordings, now, false))
+	})
+
+	t.Run("purges all but the last N stopped recordings", func(t *testing.T) {
+		policy := NewRetentionPolicy("aeron:ipc", 1001)
+		policy.KeepLast = 2
+		recordings := []*retentionRecording{
+			newTestRecording(1, 0, 100, now),
+			newTestRecording(3, 0, 300, now),
+			newTestRecording(2, 0, 200, now),
+		}
+		actions := planRetention(policy, recordings, now, false)
+		if assert.Len(t, actions, 1) {
+			assert.EqualValues(t, 1, actions[0].RecordingId)
+			assert.Equal(t, RetentionPurgeRecording, actions[0].Type)
+			assert.EqualValues(t, 100, actions[0].Bytes)
+		}
+	})
+
+	t.Run("purges stopped recordings older than the max age", func(t *testing.T) {
+		policy := NewRetentionPolicy("aeron:ipc", 1001)
+		policy.MaxAge = time.Hour
+		recordings := []*retentionRecording{
+			newTestRecording(1, 0, 100, now.Add(-2*time.Hour)),
+			newTestRecording(2, 0, 200, now.Add(-time.Minute)),
+		}
+		actions := planRetention(policy, recordings, now, false)
+		if assert.Len(t, actions, 1) {
+			assert.EqualValues(t, 1, actions[0].RecordingId)
+			assert.Equal(t, RetentionPurgeRecording, actions[0].Type)
+		}
+	})
+
+	t.Run("trims the oldest segments to fit the max bytes", func(t *testing.T) {
+		policy := NewRetentionPolicy("aeron:ipc", 1001)
+		policy.MaxBytes = 3 * testSegmentLength
+		recordings := []*retentionRecording{
+			newTestRecording(1, 0, 2*testSegmentLength+100, now),
+			newTestRecording(2, 0, 2*testSegmentLength, now),
+		}
+		actions := planRetention(policy, recordings, now, false)
+		if assert.Len(t, actions, 1) {
+			assert.EqualValues(t, 1, actions[0].RecordingId)
+			assert.Equal(t, RetentionPurgeSegments, actions[0].Type)
+			assert.EqualValues(t, 2*testSegmentLength, actions[0].NewStartPosition)
+			assert.EqualValues(t, 2*testSegmentLength, actions[0].Bytes)
+		}
+	})
+
+	t.Run("purges whole recordings that exceed the max bytes", func(t *testing.T) {
+		policy := NewRetentionPolicy("aeron:ipc", 1001)
+		policy.MaxBytes = testSegmentLength
+		recordings := []*retentionRecording{
+			newTestRecording(1, 0, testSegmentLength, now),
+			newTestRecording(2, 0, testSegmentLength, now),
+		}
+		actions := planRetention(policy, recordings, now, false)
+		if assert.Len(t, actions, 1) {
+			assert.EqualValues(t, 1, actions[0].RecordingId)
+			assert.Equal(t, RetentionPurgeRecording, actions[0].Type)
+		}
+	})
+
+	t.Run("does not remove data beyond the minimum retained position", func(t *testing.T) {
+		policy := NewRetentionPolicy("aeron:ipc", 1001)
+		policy.KeepLast = 1
+		policy.MinRetainedPosition = testSegmentLength
+		recordings := []*retentionRecording{
+			newTestRecording(1, 0, 3*testSegmentLength, now),
+			newTestRecording(2, 0, 100, now),
+		}
+		actions := planRetention(policy, recordings, now, false)
+		if assert.Len(t, actions, 1) {
+			assert.EqualValues(t, 1, actions[0].RecordingId)
+			assert.Equal(t, RetentionPurgeSegments, actions[0].Type)
+			assert.EqualValues(t, testSegmentLength, actions[0].NewStartPosition)
+		}
+	})
+
+	t.Run("detaches rather than purges when detach only", func(t *testing.T) {
+		policy := NewRetentionPolicy("aeron:ipc", 1001)
+		policy.KeepLast = 1
+		recordings := []*retentionRecording{
+			newTestRecording(1, 0, 2*testSegmentLength+100, now),
+			newTestRecording(2, 0, 100, now),
+		}
+		actions := planRetention(policy, recordings, now, true)
+		if assert.Len(t, actions, 1) {
+			assert.Equal(t, RetentionDetachSegments, actions[0].Type)
+			assert.EqualValues(t, 2*testSegmentLength, actions[0].NewStartPosition)
+		}
+	})
+}
+
+func TestRetentionReport(t *testing.T) {
+	policy := NewRetentionPolicy("aeron:ipc", 1001)
+	report := &RetentionReport{
+		DryRun: true,
+		Actions: []*RetentionAction{
+			{Policy: policy, RecordingId: 1, Type: RetentionPurgeRecording, Bytes: 100, Reason: "keep last 1"},
+			{Policy: policy, RecordingId: 2, Type: RetentionPurgeSegments, NewStartPosition: 200, Bytes: 200, Reason: "max bytes 1"},
+		},
+	}
+	assert.EqualValues(t, 300, report.BytesReclaimed())
+	assert.Empty(t, report.Errors())
+	assert.Contains(t, report.String(), "PurgeRecording recordingId=1 bytes=100 (keep last 1)")
+	assert.Contains(t, report.String(), "dry run")
+	assert.Equal(t, int32(aeron.NullValue), int32(NewRetentionPolicy("", 0).MinRetainedPosition))
+}
@@ -0,0 +1,770 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package backup implements ClusterBackup, which continuously backs up a
+// cluster into a local archive. It queries a member for the cluster's latest
+// snapshots and log recording, replicates them from the leader's archive,
+// keeps replicating the log as it grows, and writes a recording log of the
+// replicated terms and snapshots so the cluster dir can seed a new member.
+package backup
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
+	"github.com/lirm/aeron-go/aeron/logging"
+	"github.com/lirm/aeron-go/archive"
+	archivecodecs "github.com/lirm/aeron-go/archive/codecs"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+)
+
+var logger = logging.MustGetLogger("cluster-backup")
+var marshaller = codecs.NewSbeGoMarshaller()
+
+// State of a ClusterBackup
+type State int8
+
+const (
+	StateBackupQuery        State = iota // Querying a member for the cluster's snapshots and log
+	StateSnapshotRetrieve                // Replicating the snapshots not yet backed up
+	StateLiveLogReplay                   // Starting replication of the log
+	StateUpdateRecordingLog              // Adding the replicated terms and snapshots to the recording log
+	StateBackingUp                       // Replicating the log until the next query
+	StateReset                           // Cooling down after a failure
+	StateClosed
+)
+
+func (state State) String() string {
+	switch state {
+	case StateBackupQuery:
+		return "BackupQuery"
+	case StateSnapshotRetrieve:
+		return "SnapshotRetrieve"
+	case StateLiveLogReplay:
+		return "LiveLogReplay"
+	case StateUpdateRecordingLog:
+		return "UpdateRecordingLog"
+	case StateBackingUp:
+		return "BackingUp"
+	case StateReset:
+		return "Reset"
+	case StateClosed:
+		return "Closed"
+	}
+	return fmt.Sprintf("State(%d)", int(state))
+}
+
+// backupProtocolVersion sent in the backup query, the version of the cluster protocol
+const backupProtocolVersion = 0<<16 | 2<<8 | 0
+
+// backupClient is the part of the aeron client used by the backup
+type backupClient interface {
+	AddPublication(channel string, streamID int32) (*aeron.Publication, error)
+	NextCorrelationID() int64
+	Close() error
+}
+
+// backupArchive is the part of the local archive used by the backup
+type backupArchive interface {
+	replicate(srcRecordingId int64, srcControlStreamId int32, srcControlChannel string, params *archive.ReplicationParams) (replication, error)
+	GetRecordingPosition(recordingId int64) (int64, error)
+	Close() error
+}
+
+// replication is the part of an archive.ReplicationSession used by the backup
+type replication interface {
+	ReplicationId() int64
+	State() archive.ReplicationState
+	DstRecordingId() int64
+	Position() int64
+	TryAwaitSignal(signal archivecodecs.RecordingSignalEnum) (bool, error)
+	Stop() error
+	Close()
+}
+
+// consensusSubscription is the part of the subscription for consensus
+// responses used by the backup
+type consensusSubscription interface {
+	Poll(handler term.FragmentHandler, fragmentLimit int) int
+	TryResolveChannelEndpointPort() string
+	Close() error
+}
+
+// localArchive replicates into the local archive with ReplicationSessions
+type localArchive struct {
+	*archive.Archive
+}
+
+func (a localArchive) replicate(
+	srcRecordingId int64,
+	srcControlStreamId int32,
+	srcControlChannel string,
+	params *archive.ReplicationParams,
+) (replication, error) {
+	session, err := a.ReplicateSession(srcRecordingId, srcControlStreamId, srcControlChannel, params)
+	if err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// ClusterBackup backs up a cluster into a local archive and recording log.
+// It is driven by calling DoWork(), and is not safe for concurrent use.
+type ClusterBackup struct {
+	opts                 *Options
+	listener             EventsListener
+	aeronClient          backupClient
+	archive              backupArchive
+	consensusSub         consensusSubscription
+	consensusPub         *aeron.Publication
+	consensusChannel     *aeron.ChannelUri
+	fragmentAssembler    *aeron.FragmentAssembler
+	endpoints            []string
+	endpointIdx          int
+	state                State
+	correlationId        int64
+	deadlineMs           int64 // For the response, or the end of a cool down
+	nextQueryMs          int64
+	nextProgressCheckMs  int64
+	err                  error // Set by a fragment handler, to reset the backup
+	recordingLog         []cluster.RecordingLogEntry
+	response             *codecs.BackupResponse
+	logSource            ClusterMember
+	sourceArchiveChannel string
+	snapshotsToRetrieve  []codecs.BackupResponseSnapshots
+	snapshotsRetrieved   []cluster.RecordingLogEntry
+	snapshotReplication  replication
+	replicationPosition  int64
+	replicationProgress  int64 // Time of the last progress of the snapshot replication
+	logReplication       replication
+	logSourceRecordingId int64
+	liveLogRecordingId   int64
+	liveLogPosition      int64
+}
+
+// NewClusterBackup creates a ClusterBackup, connecting to the media driver
+// and the local archive, and reading any recording log left in the cluster
+// dir by a previous backup so that it continues from where that left off
+func NewClusterBackup(
+	aeronCtx *aeron.Context,
+	options *Options,
+	listener EventsListener,
+) (*ClusterBackup, error) {
+	if listener == nil {
+		return nil, errors.New("listener is nil")
+	}
+	if options.ClusterConsensusEndpoints == "" {
+		return nil, errors.New("ClusterConsensusEndpoints must be set")
+	}
+	consensusChannel, err := aeron.ParseChannelUri(options.ConsensusChannel)
+	if err != nil {
+		return nil, err
+	}
+	if !consensusChannel.IsUdp() {
+		return nil, fmt.Errorf("consensus channel must be UDP: %s", options.ConsensusChannel)
+	}
+	if err := os.MkdirAll(options.ClusterDir, 0755); err != nil {
+		return nil, err
+	}
+	recordingLog, err := cluster.ReadRecordingLog(options.ClusterDir)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+	logging.SetLevel(options.Loglevel, "cluster-backup")
+
+	aeronClient, err := aeron.Connect(aeronCtx)
+	if err != nil {
+		return nil, err
+	}
+	arch, err := archive.NewArchive(options.ArchiveOptions, aeronCtx)
+	if err != nil {
+		aeronClient.Close()
+		return nil, err
+	}
+	consensusSub, err := aeronClient.AddSubscription(options.ConsensusChannel, options.ConsensusStreamId)
+	if err != nil {
+		arch.Close()
+		aeronClient.Close()
+		return nil, err
+	}
+
+	backup := newClusterBackup(options, listener, recordingLog)
+	backup.aeronClient = aeronClient
+	backup.archive = localArchive{arch}
+	backup.consensusSub = consensusSub
+	backup.consensusChannel = &consensusChannel
+	return backup, nil
+}
+
+func newClusterBackup(options *Options, listener EventsListener, recordingLog []cluster.RecordingLogEntry) *ClusterBackup {
+	backup := &ClusterBackup{
+		opts:                 options,
+		listener:             listener,
+		endpoints:            strings.Split(options.ClusterConsensusEndpoints, ","),
+		state:                StateBackupQuery,
+		correlationId:        cluster.NullValue,
+		recordingLog:         recordingLog,
+		logSourceRecordingId: cluster.NullValue,
+		liveLogRecordingId:   cluster.NullValue,
+		liveLogPosition:      cluster.NullPosition,
+	}
+	backup.fragmentAssembler = aeron.NewFragmentAssembler(backup.onFragment, 0)
+	return backup
+}
+
+// State the backup is in
+func (b *ClusterBackup) State() State {
+	return b.state
+}
+
+// RecordingLog returns the entries of the local recording log
+func (b *ClusterBackup) RecordingLog() []cluster.RecordingLogEntry {
+	return append([]cluster.RecordingLogEntry(nil), b.recordingLog...)
+}
+
+// LiveLogRecordingId is the local recording the log is replicated into, or
+// NullValue until log replication has started
+func (b *ClusterBackup) LiveLogRecordingId() int64 {
+	return b.liveLogRecordingId
+}
+
+// LiveLogPosition of the local log recording as of the last progress check
+func (b *ClusterBackup) LiveLogPosition() int64 {
+	return b.liveLogPosition
+}
+
+// DoWork performs the next step of the backup, returning the amount of work done
+func (b *ClusterBackup) DoWork() int {
+	if b.state == StateClosed {
+		return 0
+	}
+	nowMs := time.Now().UnixMilli()
+	work, err := b.doWork(nowMs)
+	if err == nil {
+		err = b.err
+	}
+	b.err = nil
+	if err != nil {
+		b.reset(nowMs, err)
+	}
+	return work
+}
+
+func (b *ClusterBackup) doWork(nowMs int64) (int, error) {
+	work := b.consensusSub.Poll(b.fragmentAssembler.OnFragment, 10)
+	var stateWork int
+	var err error
+	switch b.state {
+	case StateBackupQuery:
+		stateWork, err = b.backupQuery(nowMs)
+	case StateSnapshotRetrieve:
+		stateWork, err = b.snapshotRetrieve(nowMs)
+	case StateLiveLogReplay:
+		stateWork, err = b.liveLogReplay(nowMs)
+	case StateUpdateRecordingLog:
+		stateWork, err = b.updateRecordingLog(nowMs)
+	case StateBackingUp:
+		stateWork, err = b.backingUp(nowMs)
+	case StateReset:
+		if nowMs >= b.deadlineMs {
+			b.state = StateBackupQuery
+			stateWork = 1
+		}
+	}
+	return work + stateWork, err
+}
+
+// Run calls DoWork() until stop is closed, idling with Options.IdleStrategy
+func (b *ClusterBackup) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+			b.opts.IdleStrategy.Idle(b.DoWork())
+		}
+	}
+}
+
+// Close stops the replications and releases the connections. The local
+// recordings and recording log remain for a later backup to continue from.
+func (b *ClusterBackup) Close() error {
+	if b.state == StateClosed {
+		return nil
+	}
+	b.stopReplications()
+	b.closeConsensusPublication()
+	var errs []error
+	if err := b.consensusSub.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := b.archive.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := b.aeronClient.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	b.state = StateClosed
+	return errors.Join(errs...)
+}
+
+func (b *ClusterBackup) backupQuery(nowMs int64) (int, error) {
+	endpoint := b.endpoints[b.endpointIdx]
+	if b.consensusPub == nil {
+		b.consensusChannel.Set("endpoint", endpoint)
+		pub, err := b.aeronClient.AddPublication(b.consensusChannel.String(), b.opts.ConsensusStreamId)
+		if err != nil {
+			return 0, err
+		}
+		b.consensusPub = pub
+		b.correlationId = cluster.NullValue
+		b.deadlineMs = nowMs + b.opts.ResponseTimeout.Milliseconds()
+		return 1, nil
+	}
+	if b.correlationId == cluster.NullValue && b.consensusPub.IsConnected() {
+		responseChannel := b.consensusSub.TryResolveChannelEndpointPort()
+		if responseChannel == "" {
+			return 0, nil
+		}
+		correlationId := b.aeronClient.NextCorrelationID()
+		buffer, err := b.encodeBackupQuery(correlationId, responseChannel)
+		if err != nil {
+			return 0, err
+		}
+		if b.consensusPub.Offer(buffer, 0, buffer.Capacity(), nil) >= 0 {
+			logger.Debugf("sent backup query - endpoint=%s correlationId=%d", endpoint, correlationId)
+			b.correlationId = correlationId
+			b.deadlineMs = nowMs + b.opts.ResponseTimeout.Milliseconds()
+			b.listener.OnBackupQuery(endpoint)
+			return 1, nil
+		}
+	}
+	if nowMs > b.deadlineMs {
+		return 0, fmt.Errorf("no backup response from %s", endpoint)
+	}
+	return 0, nil
+}
+
+func (b *ClusterBackup) encodeBackupQuery(correlationId int64, responseChannel string) (*atomic.Buffer, error) {
+	req := codecs.BackupQuery{
+		CorrelationId:      correlationId,
+		ResponseStreamId:   b.opts.ConsensusStreamId,
+		Version:            backupProtocolVersion,
+		ResponseChannel:    []byte(responseChannel),
+		EncodedCredentials: b.credentialsSupplier().EncodedCredentials(),
+	}
+	header := codecs.MessageHeader{
+		BlockLength: req.SbeBlockLength(),
+		TemplateId:  req.SbeTemplateId(),
+		SchemaId:    req.SbeSchemaId(),
+		Version:     req.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	if err := header.Encode(marshaller, writer); err != nil {
+		return nil, err
+	}
+	if err := req.Encode(marshaller, writer, b.opts.RangeChecking); err != nil {
+		return nil, err
+	}
+	return atomic.NewBufferSlice(writer.Bytes()), nil
+}
+
+func (b *ClusterBackup) encodeChallengeResponse(
+	correlationId, clusterSessionId int64,
+	encodedCredentials []byte,
+) (*atomic.Buffer, error) {
+	req := codecs.ChallengeResponse{
+		CorrelationId:      correlationId,
+		ClusterSessionId:   clusterSessionId,
+		EncodedCredentials: encodedCredentials,
+	}
+	header := codecs.MessageHeader{
+		BlockLength: req.SbeBlockLength(),
+		TemplateId:  req.SbeTemplateId(),
+		SchemaId:    req.SbeSchemaId(),
+		Version:     req.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	if err := header.Encode(marshaller, writer); err != nil {
+		return nil, err
+	}
+	if err := req.Encode(marshaller, writer, b.opts.RangeChecking); err != nil {
+		return nil, err
+	}
+	return atomic.NewBufferSlice(writer.Bytes()), nil
+}
+
+func (b *ClusterBackup) credentialsSupplier() aeron.CredentialsSupplier {
+	if b.opts.CredentialsSupplier == nil {
+		return aeron.NullCredentialsSupplier{}
+	}
+	return b.opts.CredentialsSupplier
+}
+
+func (b *ClusterBackup) onFragment(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+	if length < cluster.SBEHeaderLength {
+		return
+	}
+	blockLength := buffer.GetUInt16(offset)
+	templateId := buffer.GetUInt16(offset + 2)
+	schemaId := buffer.GetUInt16(offset + 4)
+	version := buffer.GetUInt16(offset + 6)
+	if schemaId != cluster.ClusterSchemaId {
+		logger.Errorf("unexpected schemaId=%d templateId=%d blockLen=%d version=%d",
+			schemaId, templateId, blockLength, version)
+		return
+	}
+	offset += cluster.SBEHeaderLength
+	length -= cluster.SBEHeaderLength
+
+	switch templateId {
+	case cluster.BackupResponseTemplateId:
+		b.onBackupResponse(buffer, offset, length, version, blockLength)
+	case cluster.ChallengeTemplateId:
+		b.onChallenge(buffer, offset, length, version, blockLength)
+	}
+}
+
+func (b *ClusterBackup) onChallenge(buffer *atomic.Buffer, offset, length int32, version, blockLength uint16) {
+	e := codecs.Challenge{}
+	buf := bytes.Buffer{}
+	buffer.WriteBytes(&buf, offset, length)
+	if err := e.Decode(marshaller, &buf, version, blockLength, true); err != nil {
+		logger.Errorf("challenge decode error: %v", err)
+	} else if b.state == StateBackupQuery && e.CorrelationId == b.correlationId && b.correlationId != cluster.NullValue {
+		response := b.credentialsSupplier().OnChallenge(e.EncodedChallenge)
+		reply, err := b.encodeChallengeResponse(e.CorrelationId, e.ClusterSessionId, response)
+		if err == nil && b.consensusPub.Offer(reply, 0, reply.Capacity(), nil) < 0 {
+			err = errors.New("failed to send challenge response")
+		}
+		if err != nil {
+			b.err = err
+		}
+	} else {
+		logger.Debugf("ignored challenge - state=%v corrId=%d", b.state, e.CorrelationId)
+	}
+}
+
+func (b *ClusterBackup) onBackupResponse(buffer *atomic.Buffer, offset, length int32, version, blockLength uint16) {
+	e := &codecs.BackupResponse{}
+	buf := bytes.Buffer{}
+	buffer.WriteBytes(&buf, offset, length)
+	if err := e.Decode(marshaller, &buf, version, blockLength, true); err != nil {
+		logger.Errorf("backup response decode error: %v", err)
+	} else if b.state == StateBackupQuery && e.CorrelationId == b.correlationId && b.correlationId != cluster.NullValue {
+		if err := b.processBackupResponse(e); err != nil {
+			b.err = err
+		}
+	} else {
+		logger.Debugf("ignored backup response - state=%v corrId=%d", b.state, e.CorrelationId)
+	}
+}
+
+// processBackupResponse determines what is to be replicated from the leader
+func (b *ClusterBackup) processBackupResponse(response *codecs.BackupResponse) error {
+	members, err := ParseClusterMembers(string(response.ClusterMembers))
+	if err != nil {
+		return err
+	}
+	var leader *ClusterMember
+	for idx := range members {
+		if members[idx].Id == response.LeaderMemberId {
+			leader = &members[idx]
+		}
+	}
+	if leader == nil {
+		return fmt.Errorf("leader memberId=%d not in cluster members: %s", response.LeaderMemberId, response.ClusterMembers)
+	}
+	sourceArchiveChannel, err := aeron.ParseChannelUri(b.opts.ClusterArchiveControlChannel)
+	if err != nil {
+		return err
+	}
+	sourceArchiveChannel.Set("endpoint", leader.ArchiveEndpoint)
+
+	if b.logReplication != nil && (leader.Id != b.logSource.Id || response.LogRecordingId != b.logSourceRecordingId) {
+		logger.Infof("log source changed - memberId=%d recordingId=%d", leader.Id, response.LogRecordingId)
+		b.stopReplication(&b.logReplication)
+	}
+
+	b.response = response
+	b.logSource = *leader
+	b.sourceArchiveChannel = sourceArchiveChannel.String()
+	b.snapshotsToRetrieve = b.missingSnapshots(response.Snapshots)
+	b.snapshotsRetrieved = nil
+	b.correlationId = cluster.NullValue
+	b.state = StateSnapshotRetrieve
+	b.listener.OnBackupResponse(members, *leader, b.snapshotsToRetrieve)
+	return nil
+}
+
+// missingSnapshots returns the snapshots which are not in the recording log
+func (b *ClusterBackup) missingSnapshots(snapshots []codecs.BackupResponseSnapshots) []codecs.BackupResponseSnapshots {
+	var missing []codecs.BackupResponseSnapshots
+	for _, snapshot := range snapshots {
+		found := false
+		for idx := range b.recordingLog {
+			entry := &b.recordingLog[idx]
+			if entry.IsValid() && entry.IsSnapshot() && entry.LeadershipTermId == snapshot.LeadershipTermId &&
+				entry.LogPosition == snapshot.LogPosition && entry.ServiceId == snapshot.ServiceId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, snapshot)
+		}
+	}
+	return missing
+}
+
+func (b *ClusterBackup) snapshotRetrieve(nowMs int64) (int, error) {
+	if b.snapshotReplication == nil {
+		if len(b.snapshotsRetrieved) == len(b.snapshotsToRetrieve) {
+			b.state = StateLiveLogReplay
+			return 1, nil
+		}
+		snapshot := b.snapshotsToRetrieve[len(b.snapshotsRetrieved)]
+		params := archive.NewReplicationParams()
+		params.ReplicationChannel = b.opts.ReplicationChannel
+		session, err := b.archive.replicate(
+			snapshot.RecordingId, b.opts.ClusterArchiveControlStreamId, b.sourceArchiveChannel, params)
+		if err != nil {
+			return 0, err
+		}
+		logger.Debugf("replicating snapshot - srcRecordingId=%d serviceId=%d logPosition=%d",
+			snapshot.RecordingId, snapshot.ServiceId, snapshot.LogPosition)
+		b.snapshotReplication = session
+		b.replicationPosition = cluster.NullPosition
+		b.replicationProgress = nowMs
+		return 1, nil
+	}
+
+	done, err := b.snapshotReplication.TryAwaitSignal(archivecodecs.RecordingSignal.REPLICATE_END)
+	if err != nil {
+		return 0, err
+	}
+	if done {
+		snapshot := b.snapshotsToRetrieve[len(b.snapshotsRetrieved)]
+		b.snapshotsRetrieved = append(b.snapshotsRetrieved, cluster.RecordingLogEntry{
+			RecordingId:         b.snapshotReplication.DstRecordingId(),
+			LeadershipTermId:    snapshot.LeadershipTermId,
+			TermBaseLogPosition: snapshot.TermBaseLogPosition,
+			LogPosition:         snapshot.LogPosition,
+			Timestamp:           snapshot.Timestamp,
+			ServiceId:           snapshot.ServiceId,
+			EntryType:           cluster.RecordingLogEntryTypeSnapshot,
+		})
+		b.snapshotReplication = nil
+		return 1, nil
+	}
+	if position := b.snapshotReplication.Position(); position != b.replicationPosition {
+		b.replicationPosition = position
+		b.replicationProgress = nowMs
+	} else if nowMs-b.replicationProgress > b.opts.ProgressTimeout.Milliseconds() {
+		return 0, fmt.Errorf("snapshot replication made no progress - replicationId=%d state=%v",
+			b.snapshotReplication.ReplicationId(), b.snapshotReplication.State())
+	}
+	return 0, nil
+}
+
+func (b *ClusterBackup) liveLogReplay(nowMs int64) (int, error) {
+	if b.logReplication == nil {
+		params := archive.NewReplicationParams()
+		params.ReplicationChannel = b.opts.ReplicationChannel
+		if recordingId := b.localLogRecordingId(); recordingId != cluster.NullValue {
+			params.DstRecordingId = recordingId
+		}
+		session, err := b.archive.replicate(
+			b.response.LogRecordingId, b.opts.ClusterArchiveControlStreamId, b.sourceArchiveChannel, params)
+		if err != nil {
+			return 0, err
+		}
+		logger.Debugf("replicating log - srcRecordingId=%d dstRecordingId=%d memberId=%d",
+			b.response.LogRecordingId, params.DstRecordingId, b.logSource.Id)
+		b.logReplication = session
+		b.logSourceRecordingId = b.response.LogRecordingId
+		b.deadlineMs = nowMs + b.opts.ProgressTimeout.Milliseconds()
+		return 1, nil
+	}
+
+	if err := b.checkLogReplication(); err != nil {
+		return 0, err
+	}
+	if b.logReplication.State() >= archive.ReplicationReplicating {
+		b.liveLogRecordingId = b.logReplication.DstRecordingId()
+		b.state = StateUpdateRecordingLog
+		return 1, nil
+	}
+	if nowMs > b.deadlineMs {
+		return 0, fmt.Errorf("log replication did not start - replicationId=%d", b.logReplication.ReplicationId())
+	}
+	return 0, nil
+}
+
+// checkLogReplication polls for the log replication's signals, failing if it has ended
+func (b *ClusterBackup) checkLogReplication() error {
+	ended, err := b.logReplication.TryAwaitSignal(archivecodecs.RecordingSignal.REPLICATE_END)
+	if err != nil {
+		return err
+	}
+	if ended {
+		return fmt.Errorf("log replication ended - replicationId=%d", b.logReplication.ReplicationId())
+	}
+	return nil
+}
+
+// localLogRecordingId is the recording of the latest term in the recording log, or NullValue
+func (b *ClusterBackup) localLogRecordingId() int64 {
+	for idx := len(b.recordingLog) - 1; idx >= 0; idx-- {
+		if entry := &b.recordingLog[idx]; entry.IsValid() && !entry.IsSnapshot() {
+			return entry.RecordingId
+		}
+	}
+	return cluster.NullValue
+}
+
+func (b *ClusterBackup) hasTerm(leadershipTermId int64) bool {
+	for idx := range b.recordingLog {
+		if entry := &b.recordingLog[idx]; entry.IsValid() && !entry.IsSnapshot() && entry.LeadershipTermId == leadershipTermId {
+			return true
+		}
+	}
+	return false
+}
+
+// updateRecordingLog appends the terms replicated and snapshots retrieved.
+// Terms come first, and the snapshots for each log position are ordered with
+// the consensus module's last, as written by the consensus module.
+func (b *ClusterBackup) updateRecordingLog(nowMs int64) (int, error) {
+	response := b.response
+	var entries []cluster.RecordingLogEntry
+	appendTerm := func(leadershipTermId, termBaseLogPosition int64) {
+		if !b.hasTerm(leadershipTermId) {
+			entries = append(entries, cluster.RecordingLogEntry{
+				RecordingId:         b.liveLogRecordingId,
+				LeadershipTermId:    leadershipTermId,
+				TermBaseLogPosition: termBaseLogPosition,
+				LogPosition:         cluster.NullPosition,
+				Timestamp:           nowMs,
+				ServiceId:           cluster.NullValue,
+				EntryType:           cluster.RecordingLogEntryTypeTerm,
+			})
+		}
+	}
+	appendTerm(response.LogLeadershipTermId, response.LogTermBaseLogPosition)
+	if response.LastLeadershipTermId != response.LogLeadershipTermId {
+		appendTerm(response.LastLeadershipTermId, response.LastTermBaseLogPosition)
+	}
+
+	snapshots := b.snapshotsRetrieved
+	sort.SliceStable(snapshots, func(i, j int) bool {
+		if snapshots[i].LogPosition != snapshots[j].LogPosition {
+			return snapshots[i].LogPosition < snapshots[j].LogPosition
+		}
+		return snapshots[i].ServiceId > snapshots[j].ServiceId
+	})
+	entries = append(entries, snapshots...)
+
+	if len(entries) > 0 {
+		for idx := range entries {
+			entries[idx].Index = len(b.recordingLog) + idx
+		}
+		if err := cluster.AppendRecordingLog(b.opts.ClusterDir, entries...); err != nil {
+			return 0, err
+		}
+		b.recordingLog = append(b.recordingLog, entries...)
+		b.listener.OnUpdatedRecordingLog(entries)
+	}
+	b.snapshotsToRetrieve = nil
+	b.snapshotsRetrieved = nil
+	b.state = StateBackingUp
+	b.nextQueryMs = nowMs + b.opts.BackupInterval.Milliseconds()
+	b.nextProgressCheckMs = nowMs
+	return 1, nil
+}
+
+func (b *ClusterBackup) backingUp(nowMs int64) (int, error) {
+	if err := b.checkLogReplication(); err != nil {
+		return 0, err
+	}
+	work := 0
+	if nowMs >= b.nextProgressCheckMs {
+		position, err := b.archive.GetRecordingPosition(b.liveLogRecordingId)
+		if err != nil {
+			return 0, err
+		}
+		b.liveLogPosition = position
+		b.nextProgressCheckMs = nowMs + b.opts.ProgressCheckInterval.Milliseconds()
+		b.listener.OnLiveLogProgress(b.liveLogRecordingId, position)
+		work++
+	}
+	if nowMs >= b.nextQueryMs {
+		b.state = StateBackupQuery
+		b.correlationId = cluster.NullValue
+		b.deadlineMs = nowMs + b.opts.ResponseTimeout.Milliseconds()
+		work++
+	}
+	return work, nil
+}
+
+// reset after a failure, stopping replication and moving on to the next
+// member once cooled down
+func (b *ClusterBackup) reset(nowMs int64, err error) {
+	logger.Warningf("cluster backup reset in state %v: %v", b.state, err)
+	b.listener.OnPossibleFailure(err)
+	b.stopReplications()
+	b.closeConsensusPublication()
+	b.endpointIdx = (b.endpointIdx + 1) % len(b.endpoints)
+	b.correlationId = cluster.NullValue
+	b.response = nil
+	b.snapshotsToRetrieve = nil
+	b.snapshotsRetrieved = nil
+	b.fragmentAssembler.Clear()
+	b.state = StateReset
+	b.deadlineMs = nowMs + b.opts.CoolDownInterval.Milliseconds()
+}
+
+func (b *ClusterBackup) stopReplications() {
+	b.stopReplication(&b.snapshotReplication)
+	b.stopReplication(&b.logReplication)
+	b.logSourceRecordingId = cluster.NullValue
+}
+
+func (b *ClusterBackup) stopReplication(session *replication) {
+	if *session != nil {
+		if err := (*session).Stop(); err != nil {
+			logger.Debugf("error stopping replicationId=%d: %v", (*session).ReplicationId(), err)
+		}
+		(*session).Close()
+		*session = nil
+	}
+}
+
+func (b *ClusterBackup) closeConsensusPublication() {
+	if b.consensusPub != nil {
+		if err := b.consensusPub.Close(); err != nil {
+			logger.Debugf("error closing consensus publication: %v", err)
+		}
+		b.consensusPub = nil
+	}
+}
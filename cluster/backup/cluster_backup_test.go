@@ -0,0 +1,481 @@
+package backup
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
+	"github.com/lirm/aeron-go/archive"
+	archivecodecs "github.com/lirm/aeron-go/archive/codecs"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testClusterMembers = "0,localhost:20110,localhost:20111,localhost:20113,localhost:0,localhost:8010|" +
+	"1,localhost:20210,localhost:20211,localhost:20213,localhost:0,localhost:8020"
+
+type testEventsListener struct {
+	queries     []string
+	failures    []error
+	members     []ClusterMember
+	logSource   ClusterMember
+	toRetrieve  []codecs.BackupResponseSnapshots
+	logUpdates  [][]cluster.RecordingLogEntry
+	logProgress []int64
+}
+
+func (l *testEventsListener) OnBackupQuery(consensusEndpoint string) {
+	l.queries = append(l.queries, consensusEndpoint)
+}
+
+func (l *testEventsListener) OnPossibleFailure(err error) {
+	l.failures = append(l.failures, err)
+}
+
+func (l *testEventsListener) OnBackupResponse(
+	clusterMembers []ClusterMember,
+	logSource ClusterMember,
+	snapshotsToRetrieve []codecs.BackupResponseSnapshots,
+) {
+	l.members = clusterMembers
+	l.logSource = logSource
+	l.toRetrieve = snapshotsToRetrieve
+}
+
+func (l *testEventsListener) OnUpdatedRecordingLog(entries []cluster.RecordingLogEntry) {
+	l.logUpdates = append(l.logUpdates, entries)
+}
+
+func (l *testEventsListener) OnLiveLogProgress(recordingId int64, position int64) {
+	l.logProgress = append(l.logProgress, position)
+}
+
+func newTestClusterBackup(t *testing.T, recordingLog []cluster.RecordingLogEntry) (*ClusterBackup, *testEventsListener) {
+	opts := NewOptions()
+	opts.ClusterDir = t.TempDir()
+	opts.ClusterConsensusEndpoints = "localhost:20111,localhost:20211"
+	listener := &testEventsListener{}
+	return newClusterBackup(opts, listener, recordingLog), listener
+}
+
+func encodeBackupResponse(t *testing.T, response *codecs.BackupResponse) *atomic.Buffer {
+	header := codecs.MessageHeader{
+		BlockLength: response.SbeBlockLength(),
+		TemplateId:  response.SbeTemplateId(),
+		SchemaId:    response.SbeSchemaId(),
+		Version:     response.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	require.NoError(t, header.Encode(marshaller, writer))
+	require.NoError(t, response.Encode(marshaller, writer, true))
+	return atomic.NewBufferSlice(writer.Bytes())
+}
+
+func testBackupResponse(correlationId int64) *codecs.BackupResponse {
+	return &codecs.BackupResponse{
+		CorrelationId:           correlationId,
+		LogRecordingId:          3,
+		LogLeadershipTermId:     0,
+		LogTermBaseLogPosition:  0,
+		LastLeadershipTermId:    2,
+		LastTermBaseLogPosition: 4096,
+		CommitPositionCounterId: 7,
+		LeaderMemberId:          1,
+		Snapshots: []codecs.BackupResponseSnapshots{
+			{RecordingId: 10, LeadershipTermId: 1, TermBaseLogPosition: 0, LogPosition: 1024, Timestamp: 100, ServiceId: -1},
+			{RecordingId: 11, LeadershipTermId: 1, TermBaseLogPosition: 0, LogPosition: 1024, Timestamp: 100, ServiceId: 0},
+			{RecordingId: 20, LeadershipTermId: 2, TermBaseLogPosition: 4096, LogPosition: 8192, Timestamp: 200, ServiceId: -1},
+			{RecordingId: 21, LeadershipTermId: 2, TermBaseLogPosition: 4096, LogPosition: 8192, Timestamp: 200, ServiceId: 0},
+		},
+		ClusterMembers: []byte(testClusterMembers),
+	}
+}
+
+func TestParseClusterMembers(t *testing.T) {
+	members, err := ParseClusterMembers(testClusterMembers)
+	require.NoError(t, err)
+	require.Len(t, members, 2)
+	assert.Equal(t, ClusterMember{
+		Id:                1,
+		IngressEndpoint:   "localhost:20210",
+		ConsensusEndpoint: "localhost:20211",
+		LogEndpoint:       "localhost:20213",
+		CatchupEndpoint:   "localhost:0",
+		ArchiveEndpoint:   "localhost:8020",
+	}, members[1])
+
+	_, err = ParseClusterMembers("0,localhost:20110")
+	assert.Error(t, err)
+	_, err = ParseClusterMembers("x,a,b,c,d,e")
+	assert.Error(t, err)
+}
+
+func TestEncodeBackupQuery(t *testing.T) {
+	backup, _ := newTestClusterBackup(t, nil)
+	buffer, err := backup.encodeBackupQuery(42, "aeron:udp?endpoint=localhost:9876")
+	require.NoError(t, err)
+
+	assert.Equal(t, uint16(cluster.BackupQueryTemplateId), buffer.GetUInt16(2))
+	query := codecs.BackupQuery{}
+	buf := bytes.Buffer{}
+	buffer.WriteBytes(&buf, cluster.SBEHeaderLength, buffer.Capacity()-cluster.SBEHeaderLength)
+	require.NoError(t, query.Decode(marshaller, &buf, buffer.GetUInt16(6), buffer.GetUInt16(0), true))
+	assert.Equal(t, int64(42), query.CorrelationId)
+	assert.Equal(t, backup.opts.ConsensusStreamId, query.ResponseStreamId)
+	assert.Equal(t, "aeron:udp?endpoint=localhost:9876", string(query.ResponseChannel))
+}
+
+func TestBackupResponse_SnapshotsToRetrieve(t *testing.T) {
+	recordingLog := []cluster.RecordingLogEntry{
+		{Index: 0, RecordingId: 1, LeadershipTermId: 0, LogPosition: cluster.NullPosition, ServiceId: -1, EntryType: cluster.RecordingLogEntryTypeTerm},
+		{Index: 1, RecordingId: 5, LeadershipTermId: 1, LogPosition: 1024, ServiceId: 0, EntryType: cluster.RecordingLogEntryTypeSnapshot},
+		{Index: 2, RecordingId: 4, LeadershipTermId: 1, LogPosition: 1024, ServiceId: -1, EntryType: cluster.RecordingLogEntryTypeSnapshot},
+	}
+	backup, listener := newTestClusterBackup(t, recordingLog)
+	backup.correlationId = 42
+
+	buffer := encodeBackupResponse(t, testBackupResponse(42))
+	backup.onFragment(buffer, 0, buffer.Capacity(), nil)
+	require.NoError(t, backup.err)
+
+	assert.Equal(t, StateSnapshotRetrieve, backup.State())
+	assert.Equal(t, int64(cluster.NullValue), backup.correlationId)
+	assert.Equal(t, int32(1), listener.logSource.Id)
+	assert.Len(t, listener.members, 2)
+	assert.Contains(t, backup.sourceArchiveChannel, "endpoint=localhost:8020")
+	require.Len(t, listener.toRetrieve, 2)
+	assert.Equal(t, int64(20), listener.toRetrieve[0].RecordingId)
+	assert.Equal(t, int64(21), listener.toRetrieve[1].RecordingId)
+	assert.Equal(t, int64(1), backup.localLogRecordingId())
+}
+
+func TestBackupResponse_Ignored(t *testing.T) {
+	backup, listener := newTestClusterBackup(t, nil)
+	backup.correlationId = 42
+
+	buffer := encodeBackupResponse(t, testBackupResponse(41))
+	backup.onFragment(buffer, 0, buffer.Capacity(), nil)
+	assert.Equal(t, StateBackupQuery, backup.State())
+	assert.Nil(t, listener.toRetrieve)
+	assert.Nil(t, backup.err)
+}
+
+func TestBackupResponse_UnknownLeader(t *testing.T) {
+	backup, _ := newTestClusterBackup(t, nil)
+	backup.correlationId = 42
+
+	response := testBackupResponse(42)
+	response.LeaderMemberId = 5
+	buffer := encodeBackupResponse(t, response)
+	backup.onFragment(buffer, 0, buffer.Capacity(), nil)
+	assert.Error(t, backup.err)
+	assert.Equal(t, StateBackupQuery, backup.State())
+}
+
+func TestUpdateRecordingLog(t *testing.T) {
+	backup, listener := newTestClusterBackup(t, nil)
+	backup.response = testBackupResponse(42)
+	backup.liveLogRecordingId = 30
+	backup.snapshotsRetrieved = []cluster.RecordingLogEntry{
+		{RecordingId: 41, LeadershipTermId: 2, LogPosition: 8192, ServiceId: 0, EntryType: cluster.RecordingLogEntryTypeSnapshot},
+		{RecordingId: 40, LeadershipTermId: 2, LogPosition: 8192, ServiceId: -1, EntryType: cluster.RecordingLogEntryTypeSnapshot},
+		{RecordingId: 31, LeadershipTermId: 1, LogPosition: 1024, ServiceId: 0, EntryType: cluster.RecordingLogEntryTypeSnapshot},
+	}
+	backup.state = StateUpdateRecordingLog
+
+	backup.updateRecordingLog(1000)
+	assert.Equal(t, StateBackingUp, backup.State())
+	entries, err := cluster.ReadRecordingLog(backup.opts.ClusterDir)
+	require.NoError(t, err)
+	assert.Equal(t, entries, backup.RecordingLog())
+	require.Len(t, listener.logUpdates, 1)
+	assert.Equal(t, entries, listener.logUpdates[0])
+
+	var recordingIds []int64
+	for _, entry := range entries {
+		recordingIds = append(recordingIds, entry.RecordingId)
+	}
+	assert.Equal(t, []int64{30, 30, 31, 41, 40}, recordingIds)
+	assert.Equal(t, int64(0), entries[0].LeadershipTermId)
+	assert.Equal(t, int64(2), entries[1].LeadershipTermId)
+	assert.Equal(t, int64(4096), entries[1].TermBaseLogPosition)
+	assert.Equal(t, int64(cluster.NullPosition), entries[1].LogPosition)
+	assert.Equal(t, 4, entries[4].Index)
+
+	// A later update adds only what is new
+	backup.snapshotsRetrieved = nil
+	backup.updateRecordingLog(2000)
+	assert.Len(t, listener.logUpdates, 1)
+	entries, err = cluster.ReadRecordingLog(backup.opts.ClusterDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 5)
+	assert.Equal(t, int64(30), backup.localLogRecordingId())
+}
+
+// testConsensusModule answers the backup queries sent to it, reading them
+// from the publications the backup adds and replying on the consensus stream
+type testConsensusModule struct {
+	t           *testing.T
+	channels    []string
+	pubs        []*aeron.Publication
+	images      []aeron.Image
+	queries     []codecs.BackupQuery
+	responsePub *aeron.Publication
+	silent      bool
+	closes      int
+	correlation int64
+}
+
+func (cm *testConsensusModule) AddPublication(channel string, streamID int32) (*aeron.Publication, error) {
+	pub, image := aeron.NewInMemoryStream(channel, streamID, int32(len(cm.pubs)+1), logbuffer.TermMinLength)
+	cm.channels = append(cm.channels, channel)
+	cm.pubs = append(cm.pubs, pub)
+	cm.images = append(cm.images, image)
+	return pub, nil
+}
+
+func (cm *testConsensusModule) NextCorrelationID() int64 {
+	cm.correlation++
+	return cm.correlation
+}
+
+func (cm *testConsensusModule) Close() error {
+	cm.closes++
+	return nil
+}
+
+func (cm *testConsensusModule) doWork() {
+	if len(cm.images) == 0 {
+		return
+	}
+	cm.images[len(cm.images)-1].Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+		var msgHeader codecs.MessageHeader
+		var query codecs.BackupQuery
+		reader := bytes.NewReader(buffer.GetBytesArray(offset, length))
+		require.NoError(cm.t, msgHeader.Decode(marshaller, reader, 0))
+		require.Equal(cm.t, query.SbeTemplateId(), msgHeader.TemplateId)
+		require.NoError(cm.t, query.Decode(marshaller, reader, msgHeader.Version, msgHeader.BlockLength, true))
+		cm.queries = append(cm.queries, query)
+		if !cm.silent {
+			response := encodeBackupResponse(cm.t, testBackupResponse(query.CorrelationId))
+			require.Positive(cm.t, cm.responsePub.Offer(response, 0, response.Capacity(), nil))
+		}
+	}, 10)
+}
+
+// consensusStream is the backup's consensus subscription, which runs a duty
+// cycle of the consensus module before each poll
+type consensusStream struct {
+	consensusModule *testConsensusModule
+	image           aeron.Image
+	closes          int
+}
+
+func (s *consensusStream) Poll(handler term.FragmentHandler, fragmentLimit int) int {
+	s.consensusModule.doWork()
+	return s.image.Poll(handler, fragmentLimit)
+}
+
+func (s *consensusStream) TryResolveChannelEndpointPort() string {
+	return "aeron:udp?endpoint=localhost:20001"
+}
+
+func (s *consensusStream) Close() error {
+	s.closes++
+	return nil
+}
+
+type testReplication struct {
+	id             int64
+	srcRecordingId int64
+	params         archive.ReplicationParams
+	state          archive.ReplicationState
+	dstRecordingId int64
+	stops          int
+	closes         int
+}
+
+func (r *testReplication) ReplicationId() int64            { return r.id }
+func (r *testReplication) State() archive.ReplicationState { return r.state }
+func (r *testReplication) DstRecordingId() int64           { return r.dstRecordingId }
+func (r *testReplication) Position() int64                 { return 0 }
+func (r *testReplication) Close()                          { r.closes++ }
+
+func (r *testReplication) TryAwaitSignal(signal archivecodecs.RecordingSignalEnum) (bool, error) {
+	return signal == archivecodecs.RecordingSignal.REPLICATE_END && r.state == archive.ReplicationEnded, nil
+}
+
+func (r *testReplication) Stop() error {
+	r.stops++
+	return nil
+}
+
+// testArchive replicates the log recording 3 into recording 30, and
+// completes the replication of each snapshot as soon as it is requested
+type testArchive struct {
+	replications []*testReplication
+	positions    map[int64]int64
+	closes       int
+}
+
+func (a *testArchive) replicate(
+	srcRecordingId int64,
+	srcControlStreamId int32,
+	srcControlChannel string,
+	params *archive.ReplicationParams,
+) (replication, error) {
+	r := &testReplication{
+		id:             int64(len(a.replications) + 100),
+		srcRecordingId: srcRecordingId,
+		params:         *params,
+		state:          archive.ReplicationEnded,
+		dstRecordingId: srcRecordingId + 30,
+	}
+	if srcRecordingId == 3 {
+		r.state = archive.ReplicationReplicating
+		r.dstRecordingId = 30
+	}
+	a.replications = append(a.replications, r)
+	return r, nil
+}
+
+func (a *testArchive) GetRecordingPosition(recordingId int64) (int64, error) {
+	return a.positions[recordingId], nil
+}
+
+func (a *testArchive) Close() error {
+	a.closes++
+	return nil
+}
+
+type testBackup struct {
+	*ClusterBackup
+	listener        *testEventsListener
+	consensusModule *testConsensusModule
+	consensusSub    *consensusStream
+	archive         *testArchive
+}
+
+// newTestBackup returns a backup of a cluster whose members are reached
+// through in-memory streams
+func newTestBackup(t *testing.T) *testBackup {
+	backup, listener := newTestClusterBackup(t, nil)
+	backup.opts.CoolDownInterval = 0
+	consensusChannel, err := aeron.ParseChannelUri(backup.opts.ConsensusChannel)
+	require.NoError(t, err)
+	backup.consensusChannel = &consensusChannel
+
+	responsePub, responseImage := aeron.NewInMemoryStream("aeron:ipc", backup.opts.ConsensusStreamId, 99, logbuffer.TermMinLength)
+	tb := &testBackup{
+		ClusterBackup:   backup,
+		listener:        listener,
+		consensusModule: &testConsensusModule{t: t, responsePub: responsePub},
+		archive:         &testArchive{positions: map[int64]int64{30: 8192}},
+	}
+	tb.consensusSub = &consensusStream{consensusModule: tb.consensusModule, image: responseImage}
+	backup.aeronClient = tb.consensusModule
+	backup.consensusSub = tb.consensusSub
+	backup.archive = tb.archive
+	return tb
+}
+
+// doWorkUntil the condition holds, failing after a second
+func (tb *testBackup) doWorkUntil(t *testing.T, condition func() bool) {
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		require.True(t, time.Now().Before(deadline), "in state %v, failures: %v", tb.State(), tb.listener.failures)
+		tb.DoWork()
+	}
+}
+
+func (tb *testBackup) inState(state State) func() bool {
+	return func() bool { return tb.State() == state }
+}
+
+func TestDoWork(t *testing.T) {
+	tb := newTestBackup(t)
+	tb.doWorkUntil(t, tb.inState(StateBackingUp))
+
+	assert.Equal(t, []string{"localhost:20111"}, tb.listener.queries)
+	require.Len(t, tb.consensusModule.channels, 1)
+	assert.Contains(t, tb.consensusModule.channels[0], "endpoint=localhost:20111")
+	require.Len(t, tb.consensusModule.queries, 1)
+	query := tb.consensusModule.queries[0]
+	assert.Equal(t, "aeron:udp?endpoint=localhost:20001", string(query.ResponseChannel))
+	assert.Equal(t, tb.opts.ConsensusStreamId, query.ResponseStreamId)
+	assert.EqualValues(t, backupProtocolVersion, query.Version)
+	assert.EqualValues(t, 1, tb.listener.logSource.Id)
+
+	var srcRecordingIds []int64
+	for _, r := range tb.archive.replications {
+		srcRecordingIds = append(srcRecordingIds, r.srcRecordingId)
+		assert.Equal(t, tb.opts.ReplicationChannel, r.params.ReplicationChannel)
+	}
+	assert.Equal(t, []int64{10, 11, 20, 21, 3}, srcRecordingIds)
+	logReplication := tb.archive.replications[4]
+	assert.EqualValues(t, archive.RecordingIdNullValue, logReplication.params.DstRecordingId)
+	assert.EqualValues(t, 30, tb.LiveLogRecordingId())
+
+	entries, err := cluster.ReadRecordingLog(tb.opts.ClusterDir)
+	require.NoError(t, err)
+	assert.Equal(t, entries, tb.RecordingLog())
+	var recordingIds []int64
+	for _, entry := range entries {
+		recordingIds = append(recordingIds, entry.RecordingId)
+	}
+	assert.Equal(t, []int64{30, 30, 41, 40, 51, 50}, recordingIds)
+
+	tb.DoWork()
+	assert.Equal(t, []int64{8192}, tb.listener.logProgress)
+	assert.EqualValues(t, 8192, tb.LiveLogPosition())
+	assert.Equal(t, StateBackingUp, tb.State())
+
+	// Losing the log replication resets the backup, which then queries the
+	// next member and extends the local log recording
+	logReplication.state = archive.ReplicationEnded
+	tb.DoWork()
+	assert.Equal(t, StateReset, tb.State())
+	require.Len(t, tb.listener.failures, 1)
+	assert.ErrorContains(t, tb.listener.failures[0], "log replication ended")
+	assert.Equal(t, 1, logReplication.stops)
+	assert.Equal(t, 1, logReplication.closes)
+	assert.True(t, tb.consensusModule.pubs[0].IsClosed())
+
+	tb.doWorkUntil(t, tb.inState(StateBackingUp))
+	assert.Equal(t, []string{"localhost:20111", "localhost:20211"}, tb.listener.queries)
+	assert.Contains(t, tb.consensusModule.channels[1], "endpoint=localhost:20211")
+	require.Len(t, tb.archive.replications, 6, "snapshots already backed up are not replicated")
+	assert.EqualValues(t, 30, tb.archive.replications[5].params.DstRecordingId)
+	assert.Len(t, tb.listener.logUpdates, 1)
+
+	require.NoError(t, tb.Close())
+	assert.Equal(t, StateClosed, tb.State())
+	assert.Equal(t, 1, tb.archive.replications[5].stops)
+	assert.True(t, tb.consensusModule.pubs[1].IsClosed())
+	assert.Equal(t, 1, tb.consensusSub.closes)
+	assert.Equal(t, 1, tb.archive.closes)
+	assert.Equal(t, 1, tb.consensusModule.closes)
+	assert.Zero(t, tb.DoWork())
+}
+
+func TestDoWork_NoBackupResponse(t *testing.T) {
+	tb := newTestBackup(t)
+	tb.consensusModule.silent = true
+	tb.opts.ResponseTimeout = 10 * time.Millisecond
+	tb.doWorkUntil(t, tb.inState(StateReset))
+
+	require.Len(t, tb.listener.failures, 1)
+	assert.EqualError(t, tb.listener.failures[0], "no backup response from localhost:20111")
+	assert.Len(t, tb.consensusModule.queries, 1)
+	assert.True(t, tb.consensusModule.pubs[0].IsClosed())
+
+	tb.doWorkUntil(t, func() bool { return len(tb.consensusModule.queries) == 2 })
+	assert.Contains(t, tb.consensusModule.channels[1], "endpoint=localhost:20211")
+	assert.Empty(t, tb.archive.replications)
+}
@@ -0,0 +1,57 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ClusterMember is a member of the cluster as described in a BackupResponse
+type ClusterMember struct {
+	Id                int32
+	IngressEndpoint   string
+	ConsensusEndpoint string
+	LogEndpoint       string
+	CatchupEndpoint   string
+	ArchiveEndpoint   string
+}
+
+// ParseClusterMembers parses members in the consensus module's format of
+// id,ingress,consensus,log,catchup,archive endpoints separated by '|'
+func ParseClusterMembers(members string) ([]ClusterMember, error) {
+	var result []ClusterMember
+	for _, member := range strings.Split(members, "|") {
+		if member == "" {
+			continue
+		}
+		parts := strings.Split(member, ",")
+		if len(parts) != 6 {
+			return nil, fmt.Errorf("invalid cluster member: %s", member)
+		}
+		id, err := strconv.ParseInt(parts[0], 10, 32)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cluster member id: %s", member)
+		}
+		result = append(result, ClusterMember{
+			Id:                int32(id),
+			IngressEndpoint:   parts[1],
+			ConsensusEndpoint: parts[2],
+			LogEndpoint:       parts[3],
+			CatchupEndpoint:   parts[4],
+			ArchiveEndpoint:   parts[5],
+		})
+	}
+	return result, nil
+}
@@ -0,0 +1,97 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command clusterbackup continuously backs up a cluster into the archive
+// running alongside the local media driver.
+//
+//	clusterbackup -endpoints host1:20111,host2:20211 -consensus-channel aeron:udp?endpoint=backuphost:20311 \
+//		-replication-channel aeron:udp?endpoint=backuphost:20312 -dir /var/backup/cluster
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/backup"
+	"github.com/lirm/aeron-go/cluster/codecs"
+)
+
+// logListener logs the progress of the backup
+type logListener struct{}
+
+func (logListener) OnBackupQuery(consensusEndpoint string) {
+	log.Printf("backup query sent to %s", consensusEndpoint)
+}
+
+func (logListener) OnPossibleFailure(err error) {
+	log.Printf("possible failure: %v", err)
+}
+
+func (logListener) OnBackupResponse(
+	clusterMembers []backup.ClusterMember,
+	logSource backup.ClusterMember,
+	snapshotsToRetrieve []codecs.BackupResponseSnapshots,
+) {
+	log.Printf("backup response - members=%d logSourceMemberId=%d snapshotsToRetrieve=%d",
+		len(clusterMembers), logSource.Id, len(snapshotsToRetrieve))
+}
+
+func (logListener) OnUpdatedRecordingLog(entries []cluster.RecordingLogEntry) {
+	for idx := range entries {
+		log.Printf("recording log: %v", &entries[idx])
+	}
+}
+
+func (logListener) OnLiveLogProgress(recordingId int64, position int64) {}
+
+func main() {
+	opts := backup.NewOptions()
+	aeronDir := flag.String("aeron-dir", aeron.DefaultAeronDir+"/aeron-"+aeron.UserName, "media driver directory")
+	flag.StringVar(&opts.ClusterDir, "dir", opts.ClusterDir, "directory for the recording log")
+	flag.StringVar(&opts.ClusterConsensusEndpoints, "endpoints", "", "consensus endpoints of the cluster members, comma separated")
+	flag.StringVar(&opts.ConsensusChannel, "consensus-channel", opts.ConsensusChannel, "channel for responses from the cluster")
+	flag.StringVar(&opts.ReplicationChannel, "replication-channel", opts.ReplicationChannel, "channel for replicating recordings")
+	flag.DurationVar(&opts.BackupInterval, "interval", opts.BackupInterval, "interval between queries for new snapshots")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [options]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if opts.ClusterConsensusEndpoints == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := aeron.NewContext().AeronDir(*aeronDir)
+	clusterBackup, err := backup.NewClusterBackup(ctx, opts, logListener{})
+	if err != nil {
+		log.Fatalf("failed to start cluster backup: %v", err)
+	}
+
+	stop := make(chan struct{})
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-interrupt
+		close(stop)
+	}()
+	clusterBackup.Run(stop)
+	if err := clusterBackup.Close(); err != nil {
+		log.Printf("error closing cluster backup: %v", err)
+	}
+}
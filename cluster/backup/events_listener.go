@@ -0,0 +1,32 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+)
+
+// EventsListener is notified of the progress of a ClusterBackup
+type EventsListener interface {
+	// OnBackupQuery is called when a backup query is sent to a member
+	OnBackupQuery(consensusEndpoint string)
+	// OnPossibleFailure is called when the backup is reset after an error or timeout
+	OnPossibleFailure(err error)
+	// OnBackupResponse is called with the snapshots which will be replicated, after a response from the cluster
+	OnBackupResponse(clusterMembers []ClusterMember, logSource ClusterMember, snapshotsToRetrieve []codecs.BackupResponseSnapshots)
+	// OnUpdatedRecordingLog is called with the entries added to the recording log
+	OnUpdatedRecordingLog(entries []cluster.RecordingLogEntry)
+	// OnLiveLogProgress is called with the position of the replicated log each ProgressCheckInterval
+	OnLiveLogProgress(recordingId int64, position int64)
+}
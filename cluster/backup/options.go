@@ -0,0 +1,83 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package backup
+
+import (
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/archive"
+	"go.uber.org/zap/zapcore"
+)
+
+type Options struct {
+	RangeChecking bool          // [runtime] cluster protocol marshalling checks
+	Loglevel      zapcore.Level // [runtime] via logging.SetLevel()
+	IdleStrategy  idlestrategy.Idler
+	// ClusterDir is where the recording log of the backed up terms and snapshots is written
+	ClusterDir string
+	// ClusterConsensusEndpoints are the consensus endpoints of the cluster members, comma separated,
+	// which are queried in turn until one responds
+	ClusterConsensusEndpoints string
+	// ConsensusChannel is the channel the backup receives responses on, and with its endpoint
+	// replaced by a member's consensus endpoint, the channel queries are sent on
+	ConsensusChannel  string
+	ConsensusStreamId int32
+	// ClusterArchiveControlChannel, with the endpoint replaced by the archive endpoint of the
+	// member being backed up, is used by the local archive to replicate recordings from it
+	ClusterArchiveControlChannel  string
+	ClusterArchiveControlStreamId int32
+	// ReplicationChannel is the channel the local archive receives replicated recordings on
+	ReplicationChannel string
+	// ArchiveOptions for the local archive the recordings are replicated into
+	ArchiveOptions *archive.Options
+	// CredentialsSupplier provides the credentials sent with the backup query and answers
+	// any challenge from the cluster
+	CredentialsSupplier aeron.CredentialsSupplier
+	// ResponseTimeout is how long to wait for a member to respond before trying the next one
+	ResponseTimeout time.Duration
+	// BackupInterval is how often the cluster is queried for new snapshots
+	BackupInterval time.Duration
+	// ProgressTimeout is how long a snapshot replication may make no progress before the
+	// backup is reset and the cluster queried again
+	ProgressTimeout time.Duration
+	// ProgressCheckInterval is how often the position of the live log replication is checked
+	ProgressCheckInterval time.Duration
+	// CoolDownInterval is how long to wait after a failure before querying the cluster again
+	CoolDownInterval time.Duration
+}
+
+func NewOptions() *Options {
+	archiveOpts := archive.DefaultOptions()
+	archiveOpts.RequestChannel = "aeron:ipc?alias=cluster-backup-archive-ctrl-req|term-length=128k"
+	archiveOpts.ResponseChannel = "aeron:ipc?alias=cluster-backup-archive-ctrl-resp|term-length=128k"
+	return &Options{
+		RangeChecking:                 true,
+		Loglevel:                      zapcore.WarnLevel,
+		IdleStrategy:                  idlestrategy.NewDefaultBackoffIdleStrategy(),
+		ClusterDir:                    "/tmp/aeron-cluster-backup",
+		ConsensusChannel:              "aeron:udp?alias=cluster-backup|term-length=64k|endpoint=localhost:0",
+		ConsensusStreamId:             108,
+		ClusterArchiveControlChannel:  "aeron:udp?alias=cluster-backup-src-archive|term-length=64k",
+		ClusterArchiveControlStreamId: 10,
+		ReplicationChannel:            "aeron:udp?alias=cluster-backup-replication|endpoint=localhost:0",
+		ArchiveOptions:                archiveOpts,
+		CredentialsSupplier:           aeron.NullCredentialsSupplier{},
+		ResponseTimeout:               5 * time.Second,
+		BackupInterval:                time.Hour,
+		ProgressTimeout:               10 * time.Second,
+		ProgressCheckInterval:         time.Second,
+		CoolDownInterval:              time.Second,
+	}
+}
@@ -14,6 +14,7 @@ package cluster
 
 import (
 	"bytes"
+	"unsafe"
 
 	"github.com/lirm/aeron-go/aeron"
 	"github.com/lirm/aeron-go/aeron/atomic"
@@ -28,12 +29,22 @@ const (
 )
 
 type boundedLogAdapter struct {
-	marshaller     *codecs.SbeGoMarshaller
 	options        *Options
 	agent          *ClusteredServiceAgent
 	image          aeron.Image
 	builder        *bytes.Buffer
+	builderBuffer  atomic.Buffer // wraps the builder's bytes without allocating
 	maxLogPosition int64
+
+	// Flyweights which decode log messages in place
+	messageHeader     codecs.MessageHeaderDecoder
+	sessionMessage    codecs.SessionMessageHeaderDecoder
+	timerEvent        codecs.TimerEventDecoder
+	sessionOpenEvent  codecs.SessionOpenEventDecoder
+	sessionCloseEvent codecs.SessionCloseEventDecoder
+	clusterAction     codecs.ClusterActionRequestDecoder
+	newLeadershipTerm codecs.NewLeadershipTermEventDecoder
+	membershipChange  codecs.MembershipChangeEventDecoder
 }
 
 func (adapter *boundedLogAdapter) isDone() bool {
@@ -64,12 +75,9 @@ func (adapter *boundedLogAdapter) onFragment(
 	} else if adapter.builder != nil && adapter.builder.Len() != 0 {
 		buffer.WriteBytes(adapter.builder, offset, length)
 		if (flags & endFrag) == endFrag {
-			msgLength := adapter.builder.Len()
-			adapter.onMessage(
-				atomic.NewBufferSlice(adapter.builder.Bytes()),
-				int32(0),
-				int32(msgLength),
-				header)
+			msg := adapter.builder.Bytes()
+			adapter.builderBuffer.Wrap(unsafe.Pointer(&msg[0]), int32(len(msg)))
+			adapter.onMessage(&adapter.builderBuffer, 0, int32(len(msg)), header)
 			adapter.builder.Reset()
 		}
 	}
@@ -84,10 +92,11 @@ func (adapter *boundedLogAdapter) onMessage(
 	if length < SBEHeaderLength {
 		return
 	}
-	blockLength := buffer.GetUInt16(offset)
-	templateId := buffer.GetUInt16(offset + 2)
-	schemaId := buffer.GetUInt16(offset + 4)
-	version := buffer.GetUInt16(offset + 6)
+	hdr := adapter.messageHeader.Wrap(buffer, offset)
+	blockLength := hdr.BlockLength()
+	templateId := hdr.TemplateId()
+	schemaId := hdr.SchemaId()
+	version := hdr.Version()
 	if schemaId != ClusterSchemaId {
 		logger.Errorf("BoundedLogAdaptor - unexpected schemaId=%d templateId=%d blockLen=%d version=%d",
 			schemaId, templateId, blockLength, version)
@@ -95,79 +104,64 @@ func (adapter *boundedLogAdapter) onMessage(
 	}
 	offset += SBEHeaderLength
 	length -= SBEHeaderLength
+	if templateId != SessionMessageHeaderTemplateId && length < int32(blockLength) {
+		logger.Errorf("BoundedLogAdaptor - truncated message templateId=%d blockLen=%d length=%d",
+			templateId, blockLength, length)
+		return
+	}
 
 	switch templateId {
 	case timerEventTemplateId:
-		correlationId := buffer.GetInt64(offset + 8)
-		timestamp := buffer.GetInt64(offset + 16)
-		adapter.agent.onTimerEvent(header.Position(), correlationId, timestamp)
+		e := adapter.timerEvent.Wrap(buffer, offset, blockLength, version)
+		adapter.agent.onTimerEvent(header.Position(), e.CorrelationId(), e.Timestamp())
 	case sessionOpenTemplateId:
-		event := &codecs.SessionOpenEvent{}
-		if err := event.Decode(
-			adapter.marshaller,
-			toByteBuffer(buffer, offset, length),
-			version,
-			blockLength,
-			adapter.options.RangeChecking,
-		); err != nil {
-			logger.Errorf("boundedLogAdapter: session open decode error: %v", err)
+		e := adapter.sessionOpenEvent.Wrap(buffer, offset, blockLength, version)
+		e.SetMessageLength(length)
+		responseChannel, err := e.GetResponseChannel(nil)
+		if err != nil {
+			logger.Errorf("BoundedLogAdaptor - session open event decode error: %v", err)
 			return
 		}
-
-		err := adapter.agent.onSessionOpen(
-			event.LeadershipTermId,
+		encodedPrincipal, err := e.GetEncodedPrincipal(nil)
+		if err != nil {
+			logger.Errorf("BoundedLogAdaptor - session open event decode error: %v", err)
+			return
+		}
+		err = adapter.agent.onSessionOpen(
+			e.LeadershipTermId(),
 			header.Position(),
-			event.ClusterSessionId,
-			event.Timestamp,
-			event.ResponseStreamId,
-			string(event.ResponseChannel),
-			event.EncodedPrincipal,
+			e.ClusterSessionId(),
+			e.Timestamp(),
+			e.ResponseStreamId(),
+			string(responseChannel),
+			encodedPrincipal,
 		)
 		if err != nil {
 			panic("boundedLogAdapter: session open error: " + err.Error())
 		}
 	case sessionCloseTemplateId:
-		leadershipTermId := buffer.GetInt64(offset)
-		clusterSessionId := buffer.GetInt64(offset + 8)
-		timestamp := buffer.GetInt64(offset + 16)
-		closeReason := codecs.CloseReasonEnum(buffer.GetInt32(offset + 24))
-		adapter.agent.onSessionClose(leadershipTermId, header.Position(), clusterSessionId, timestamp, closeReason)
+		e := adapter.sessionCloseEvent.Wrap(buffer, offset, blockLength, version)
+		adapter.agent.onSessionClose(e.LeadershipTermId(), header.Position(), e.ClusterSessionId(), e.Timestamp(), e.CloseReason())
 	case clusterActionReqTemplateId:
-		e := codecs.ClusterActionRequest{}
-		buf := toByteBuffer(buffer, offset, length)
-		if err := e.Decode(adapter.marshaller, buf, version, blockLength, adapter.options.RangeChecking); err != nil {
-			logger.Errorf("boundedLogAdapter: cluster action request decode error: %v", err)
-		} else {
-			adapter.agent.onServiceAction(e.LeadershipTermId, e.LogPosition, e.Timestamp, e.Action)
-		}
+		e := adapter.clusterAction.Wrap(buffer, offset, blockLength, version)
+		adapter.agent.onServiceAction(e.LeadershipTermId(), e.LogPosition(), e.Timestamp(), e.Action(), e.Flags())
 	case newLeadershipTermTemplateId:
-		e := codecs.NewLeadershipTermEvent{}
-		buf := toByteBuffer(buffer, offset, length)
-		if err := e.Decode(adapter.marshaller, buf, version, blockLength, adapter.options.RangeChecking); err != nil {
-			logger.Errorf("boundedLogAdapter: new leadership term decode error: %v", err)
-		} else {
-			adapter.agent.onNewLeadershipTermEvent(e.LeadershipTermId, e.LogPosition, e.Timestamp,
-				e.TermBaseLogPosition, e.LeaderMemberId, e.LogSessionId, e.TimeUnit, e.AppVersion)
-		}
+		e := adapter.newLeadershipTerm.Wrap(buffer, offset, blockLength, version)
+		adapter.agent.onNewLeadershipTermEvent(e.LeadershipTermId(), e.LogPosition(), e.Timestamp(),
+			e.TermBaseLogPosition(), e.LeaderMemberId(), e.LogSessionId(), e.TimeUnit(), e.AppVersion())
 	case membershipChangeTemplateId:
-		e := codecs.MembershipChangeEvent{}
-		buf := toByteBuffer(buffer, offset, length)
-		if err := e.Decode(adapter.marshaller, buf, version, blockLength, adapter.options.RangeChecking); err != nil {
-			logger.Errorf("boundedLogAdapter: membership change event decode error: %v", err)
-		} else {
-			adapter.agent.onMembershipChange(e.LogPosition, e.Timestamp, e.ChangeType, e.MemberId)
-		}
+		e := adapter.membershipChange.Wrap(buffer, offset, blockLength, version)
+		adapter.agent.onMembershipChange(e.LogPosition(), e.Timestamp(), e.ChangeType(), e.MemberId())
 	case SessionMessageHeaderTemplateId:
 		if length < SessionMessageHeaderLength {
 			logger.Errorf("received invalid session message - length: %d", length)
 			return
 		}
-		clusterSessionId := buffer.GetInt64(offset + 8)
-		timestamp := buffer.GetInt64(offset + 16)
+		e := adapter.sessionMessage.Wrap(buffer, offset, blockLength, version)
 		adapter.agent.onSessionMessage(
 			header.Position(),
-			clusterSessionId,
-			timestamp,
+			e.ClusterSessionId(),
+			e.Timestamp(),
 			buffer,
 			offset+SessionMessageHeaderLength,
 			length-SessionMessageHeaderLength,
@@ -178,12 +172,6 @@ func (adapter *boundedLogAdapter) onMessage(
 	}
 }
 
-func toByteBuffer(buffer *atomic.Buffer, offset int32, length int32) *bytes.Buffer {
-	buf := &bytes.Buffer{}
-	buffer.WriteBytes(buf, offset, length)
-	return buf
-}
-
 func (adapter *boundedLogAdapter) Close() error {
 	var err error
 	if adapter.image != nil {
@@ -25,6 +25,7 @@ import (
 	"github.com/lirm/aeron-go/aeron"
 	"github.com/lirm/aeron-go/aeron/atomic"
 	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
 	"github.com/lirm/aeron-go/aeron/logging"
 	"github.com/lirm/aeron-go/aeron/util"
 	"github.com/lirm/aeron-go/cluster"
@@ -36,10 +37,25 @@ var marshaller = codecs.NewSbeGoMarshaller()
 
 var TemporaryError = errors.New("temporary error")
 
+// clusterClient is the part of the aeron client used by AeronCluster
+type clusterClient interface {
+	AddPublication(channel string, streamID int32) (*aeron.Publication, error)
+	AddExclusivePublication(channel string, streamID int32) (*aeron.Publication, error)
+	NextCorrelationID() int64
+	Close() error
+}
+
+// egressSubscription is the part of the egress subscription used by the client
+type egressSubscription interface {
+	Poll(handler term.FragmentHandler, fragmentLimit int) int
+	TryResolveChannelEndpointPort() string
+	Close() error
+}
+
 type AeronCluster struct {
 	opts                 *Options
-	aeronClient          *aeron.Aeron
-	egressSub            *aeron.Subscription
+	aeronClient          clusterClient
+	egressSub            egressSubscription
 	ingressChannel       *aeron.ChannelUri
 	ingressPub           *aeron.Publication
 	clusterSessionId     int64
@@ -54,6 +70,12 @@ type AeronCluster struct {
 	correlationId        int64
 	nextRetryConnectTime int64
 	awaitTimeoutTime     int64
+	reconnect            *reconnectState       // Set while reconnecting after session loss
+	pendingOffers        [][]byte              // Offers buffered while reconnecting yet to be sent
+	offerVectors         []atomic.BufferVector // Reused by OfferV to prepend the session message header
+	sessionEventDecoder  codecs.SessionEventDecoder
+	newLeaderDecoder     codecs.NewLeaderEventDecoder
+	detail               []byte // Reused for the variable length field of egress events
 }
 
 type memberIngress struct {
@@ -108,13 +130,23 @@ func NewAeronCluster(
 		return nil, err
 	}
 
+	return newAeronCluster(options, egressListener, aeronClient, egressSub, &ingressChannel), nil
+}
+
+func newAeronCluster(
+	options *Options,
+	egressListener EgressListener,
+	aeronClient clusterClient,
+	egressSub egressSubscription,
+	ingressChannel *aeron.ChannelUri,
+) *AeronCluster {
 	sessionMsgHdrBuf := codecs.MakeClusterMessageBuffer(cluster.SessionMessageHeaderTemplateId, cluster.SessionMessageHdrBlockLength)
 
 	client := &AeronCluster{
 		opts:                options,
 		aeronClient:         aeronClient,
 		egressSub:           egressSub,
-		ingressChannel:      &ingressChannel,
+		ingressChannel:      ingressChannel,
 		clusterSessionId:    cluster.NullValue,
 		leadershipTermId:    cluster.NullValue,
 		leaderMemberId:      cluster.NullValue,
@@ -126,8 +158,7 @@ func NewAeronCluster(
 	}
 	client.fragmentAssembler = aeron.NewFragmentAssembler(client.onFragment, 0)
 	client.updateMemberEndpoints(options.IngressEndpoints)
-
-	return client, nil
+	return client
 }
 
 func (ac *AeronCluster) ClusterSessionId() int64 {
@@ -174,13 +205,18 @@ func (ac *AeronCluster) Poll() int {
 			return ac.pollEgress(1)
 		} else {
 			logger.Warningf("timed out waiting for session connect reply")
-			ac.state = clientDisconnected
-			ac.nextRetryConnectTime = now + (30 * time.Second).Milliseconds()
+			ac.scheduleRetry(30 * time.Second)
 		}
 	case clientConnected:
 		if ac.ingressPub.IsConnected() {
-			return ac.pollEgress(10)
+			work := 0
+			if len(ac.pendingOffers) > 0 {
+				work += ac.sendPendingOffers()
+			}
+			return work + ac.pollEgress(10)
 			// TODO: check if state == closed
+		} else if ac.opts.ReconnectPolicy != nil {
+			ac.startReconnect("ingress publication disconnected")
 		} else {
 			ac.egressListener.OnDisconnect(ac, "ingress publication disconnected")
 			ac.state = clientCreatePublications
@@ -189,15 +225,60 @@ func (ac *AeronCluster) Poll() int {
 	return 0
 }
 
+// Offer a message to the cluster.
+//
+// While reconnecting with a ReconnectPolicy which buffers offers, the
+// message is buffered and 0 is returned.
 func (ac *AeronCluster) Offer(buffer *atomic.Buffer, offset, length int32) int64 {
 	if ac.state != clientConnected {
-		return aeron.NotConnected
+		return ac.bufferOffer(buffer, offset, length)
+	} else if ac.hasUnsentPendingOffers() {
+		return aeron.BackPressured
 	} else {
 		hdrBuf := ac.sessionMsgHdrBuffer
 		return ac.ingressPub.Offer2(hdrBuf, 0, hdrBuf.Capacity(), buffer, offset, length, nil)
 	}
 }
 
+// OfferV offers a message gathered, in order, from the vectors to the cluster,
+// without first copying the parts into one buffer.
+//
+// While reconnecting with a ReconnectPolicy which buffers offers, the
+// message is buffered and 0 is returned.
+func (ac *AeronCluster) OfferV(vectors []atomic.BufferVector) int64 {
+	if ac.state != clientConnected {
+		return ac.bufferOfferV(vectors)
+	} else if ac.hasUnsentPendingOffers() {
+		return aeron.BackPressured
+	} else {
+		hdrBuf := ac.sessionMsgHdrBuffer
+		ac.offerVectors = append(ac.offerVectors[:0], atomic.BufferVector{Buffer: hdrBuf, Length: hdrBuf.Capacity()})
+		ac.offerVectors = append(ac.offerVectors, vectors...)
+		return ac.ingressPub.OfferV(ac.offerVectors, nil)
+	}
+}
+
+// TryClaim claims length bytes of the ingress publication for a message,
+// after the session message header which is written at the start of the claim.
+// Encode the message at bufferClaim.Offset() + SBEHeaderLength + SessionMessageHeaderLength
+// and then Commit() or Abort() the claim. The length must not exceed the max
+// payload length of the publication less the session message header.
+//
+// Claims can not be buffered, so NotConnected is returned while reconnecting.
+func (ac *AeronCluster) TryClaim(length int32, bufferClaim *logbuffer.Claim) int64 {
+	if ac.state != clientConnected {
+		return aeron.NotConnected
+	} else if ac.hasUnsentPendingOffers() {
+		return aeron.BackPressured
+	}
+	hdrBuf := ac.sessionMsgHdrBuffer
+	result := ac.ingressPub.TryClaim(hdrBuf.Capacity()+length, bufferClaim)
+	if result > 0 {
+		bufferClaim.Buffer().PutBytes(bufferClaim.Offset(), hdrBuf, 0, hdrBuf.Capacity())
+	}
+	return result
+}
+
 func (ac *AeronCluster) SendKeepAlive() bool {
 	if !ac.IsConnected() {
 		return false
@@ -212,6 +293,21 @@ func (ac *AeronCluster) SendKeepAlive() bool {
 	return false
 }
 
+// SendAdminRequestToTakeASnapshot requests that the cluster takes a snapshot.
+// The response is delivered to the EgressListener if it implements
+// AdminResponseListener. Returns true if the request was sent.
+func (ac *AeronCluster) SendAdminRequestToTakeASnapshot(correlationId int64) bool {
+	if !ac.IsConnected() {
+		return false
+	}
+	buffer, err := ac.encodeAdminRequest(correlationId, codecs.AdminRequestType.SNAPSHOT, nil)
+	if err != nil {
+		logger.Errorf("admin request encode error: %v", err)
+		return false
+	}
+	return ac.offerIngress(buffer)
+}
+
 func (ac *AeronCluster) Close() {
 	if ac.IsConnected() && ac.ingressPub.IsConnected() {
 		ac.sendCloseSession()
@@ -222,15 +318,12 @@ func (ac *AeronCluster) Close() {
 		}
 		ac.ingressPub = nil
 	}
-	if ac.egressSub != nil {
-		if err := ac.egressSub.Close(); err != nil {
-			logger.Debugf("error closing egress subscription: %v", err)
-		}
-		ac.egressSub = nil
-	}
+	ac.closeEgressSubscription()
 	if err := ac.aeronClient.Close(); err != nil {
 		logger.Debugf("error closing aeron client: %v", err)
 	}
+	ac.reconnect = nil
+	ac.pendingOffers = nil
 	ac.state = clientClosed
 }
 
@@ -323,13 +416,12 @@ func (ac *AeronCluster) awaitPublicationConnected() (int, error) {
 	}
 	now := time.Now().UnixMilli()
 	if now > ac.awaitTimeoutTime {
-		ac.state = clientDisconnected
 		// close publications? shouldn't be necessary unless we've hit some bug
-		ac.nextRetryConnectTime = now + (30 * time.Second).Milliseconds()
+		ac.scheduleRetry(30 * time.Second)
 		return 0, errors.New("timed out waiting for connected publication")
 	}
 	if len(ac.memberByIdMap) > 0 {
-		for _, member := range ac.memberByIdMap {
+		for _, member := range ac.membersLeaderFirst() {
 			if member.publication != nil && member.publication.IsConnected() {
 				ac.ingressPub = member.publication
 				ac.fragmentAssembler.Clear()
@@ -353,14 +445,43 @@ func (ac *AeronCluster) awaitPublicationConnected() (int, error) {
 	return 0, nil
 }
 
+// membersLeaderFirst returns the members starting with the last known leader, if any
+func (ac *AeronCluster) membersLeaderFirst() []*memberIngress {
+	members := make([]*memberIngress, 0, len(ac.memberByIdMap))
+	if leader, ok := ac.memberByIdMap[ac.leaderMemberId]; ok {
+		members = append(members, leader)
+	}
+	for _, member := range ac.memberByIdMap {
+		if member.memberId != ac.leaderMemberId {
+			members = append(members, member)
+		}
+	}
+	return members
+}
+
 // Returns nil on success, TemporaryError, or any other error is a permanent error.
 func (ac *AeronCluster) sendConnectRequest(responseChannel string) error {
 	ac.correlationId = ac.aeronClient.NextCorrelationID()
+	buffer, err := ac.encodeConnectRequest(ac.correlationId, responseChannel)
+	if err != nil {
+		return err
+	}
+	result := ac.ingressPub.Offer(buffer, 0, buffer.Capacity(), nil)
+	if result >= 0 {
+		return nil
+	} else {
+		return fmt.Errorf("%w, failed to send connect request, channel=%s result=%d",
+			TemporaryError, ac.ingressPub.Channel(), result)
+	}
+}
+
+func (ac *AeronCluster) encodeConnectRequest(correlationId int64, responseChannel string) (*atomic.Buffer, error) {
 	req := codecs.SessionConnectRequest{
-		CorrelationId:    ac.correlationId,
-		ResponseStreamId: ac.opts.EgressStreamId,
-		Version:          int32(protocolSemanticVersion),
-		ResponseChannel:  []byte(responseChannel),
+		CorrelationId:      correlationId,
+		ResponseStreamId:   ac.opts.EgressStreamId,
+		Version:            int32(protocolSemanticVersion),
+		ResponseChannel:    []byte(responseChannel),
+		EncodedCredentials: ac.credentialsSupplier().EncodedCredentials(),
 	}
 	header := codecs.MessageHeader{
 		BlockLength: req.SbeBlockLength(),
@@ -370,19 +491,102 @@ func (ac *AeronCluster) sendConnectRequest(responseChannel string) error {
 	}
 	writer := new(bytes.Buffer)
 	if err := header.Encode(marshaller, writer); err != nil {
-		return err
+		return nil, err
+	}
+	if err := req.Encode(marshaller, writer, ac.opts.RangeChecking); err != nil {
+		return nil, err
+	}
+	return atomic.NewBufferSlice(writer.Bytes()), nil
+}
+
+// Returns nil on success, TemporaryError, or any other error is a permanent error.
+func (ac *AeronCluster) sendChallengeResponse(correlationId, clusterSessionId int64, encodedCredentials []byte) error {
+	if ac.ingressPub == nil {
+		return errors.New("no ingress publication for challenge response")
 	}
-	if err := req.Encode(marshaller, writer, true); err != nil {
+	buffer, err := ac.encodeChallengeResponse(correlationId, clusterSessionId, encodedCredentials)
+	if err != nil {
 		return err
 	}
-	buffer := atomic.NewBufferSlice(writer.Bytes())
-	result := ac.ingressPub.Offer(buffer, 0, buffer.Capacity(), nil)
-	if result >= 0 {
-		return nil
-	} else {
-		return fmt.Errorf("%w, failed to send connect request, channel=%s result=%d",
-			TemporaryError, ac.ingressPub.Channel(), result)
+	for i := 0; i < 3; i++ {
+		if result := ac.ingressPub.Offer(buffer, 0, buffer.Capacity(), nil); result >= 0 {
+			return nil
+		}
+		ac.opts.IdleStrategy.Idle(0)
 	}
+	return fmt.Errorf("%w, failed to send challenge response, channel=%s",
+		TemporaryError, ac.ingressPub.Channel())
+}
+
+func (ac *AeronCluster) encodeChallengeResponse(
+	correlationId, clusterSessionId int64,
+	encodedCredentials []byte,
+) (*atomic.Buffer, error) {
+	req := codecs.ChallengeResponse{
+		CorrelationId:      correlationId,
+		ClusterSessionId:   clusterSessionId,
+		EncodedCredentials: encodedCredentials,
+	}
+	header := codecs.MessageHeader{
+		BlockLength: req.SbeBlockLength(),
+		TemplateId:  req.SbeTemplateId(),
+		SchemaId:    req.SbeSchemaId(),
+		Version:     req.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	if err := header.Encode(marshaller, writer); err != nil {
+		return nil, err
+	}
+	if err := req.Encode(marshaller, writer, ac.opts.RangeChecking); err != nil {
+		return nil, err
+	}
+	return atomic.NewBufferSlice(writer.Bytes()), nil
+}
+
+func (ac *AeronCluster) encodeAdminRequest(
+	correlationId int64,
+	requestType codecs.AdminRequestTypeEnum,
+	payload []byte,
+) (*atomic.Buffer, error) {
+	req := codecs.AdminRequest{
+		LeadershipTermId: ac.leadershipTermId,
+		ClusterSessionId: ac.clusterSessionId,
+		CorrelationId:    correlationId,
+		RequestType:      requestType,
+		Payload:          payload,
+	}
+	header := codecs.MessageHeader{
+		BlockLength: req.SbeBlockLength(),
+		TemplateId:  req.SbeTemplateId(),
+		SchemaId:    req.SbeSchemaId(),
+		Version:     req.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	if err := header.Encode(marshaller, writer); err != nil {
+		return nil, err
+	}
+	if err := req.Encode(marshaller, writer, ac.opts.RangeChecking); err != nil {
+		return nil, err
+	}
+	return atomic.NewBufferSlice(writer.Bytes()), nil
+}
+
+// offerIngress retries a few times if back pressured
+func (ac *AeronCluster) offerIngress(buffer *atomic.Buffer) bool {
+	for i := 0; i < 3; i++ {
+		if result := ac.ingressPub.Offer(buffer, 0, buffer.Capacity(), nil); result >= 0 {
+			return true
+		}
+		ac.opts.IdleStrategy.Idle(0)
+	}
+	return false
+}
+
+func (ac *AeronCluster) credentialsSupplier() aeron.CredentialsSupplier {
+	if ac.opts.CredentialsSupplier == nil {
+		return aeron.NullCredentialsSupplier{}
+	}
+	return ac.opts.CredentialsSupplier
 }
 
 func (ac *AeronCluster) pollEgress(fragmentLimit int) int {
@@ -413,89 +617,142 @@ func (ac *AeronCluster) onFragment(buffer *atomic.Buffer, offset, length int32,
 	case cluster.NewLeaderEventTemlateId:
 		ac.onNewLeaderEvent(buffer, offset, length, version, blockLength)
 	case cluster.ChallengeTemplateId:
-		e := codecs.Challenge{}
-		buf := bytes.Buffer{}
-		buffer.WriteBytes(&buf, offset, length)
-		if err := e.Decode(marshaller, &buf, version, blockLength, true); err != nil {
-			logger.Errorf("new leader event decode error: %v", err)
-		} else {
-			logger.Warningf("received challenge, corrId=%d clusterSessionId=%d", e.CorrelationId, e.ClusterSessionId)
-		}
+		ac.onChallenge(buffer, offset, length, version, blockLength)
+	case cluster.AdminResponseTemplateId:
+		ac.onAdminResponse(buffer, offset, length, version, blockLength)
 	}
 }
 
-func (ac *AeronCluster) onNewLeaderEvent(buffer *atomic.Buffer, offset, length int32, version, blockLength uint16) {
-	e := codecs.NewLeaderEvent{}
+func (ac *AeronCluster) onAdminResponse(buffer *atomic.Buffer, offset, length int32, version, blockLength uint16) {
+	e := codecs.AdminResponse{}
+	buf := bytes.Buffer{}
+	buffer.WriteBytes(&buf, offset, length)
+	if err := e.Decode(marshaller, &buf, version, blockLength, true); err != nil {
+		logger.Errorf("admin response decode error: %v", err)
+	} else if e.ClusterSessionId != ac.clusterSessionId {
+		logger.Debugf("ignored admin response - thisSessionId=%d targetSessionId=%d corrId=%d",
+			ac.clusterSessionId, e.ClusterSessionId, e.CorrelationId)
+	} else if listener, ok := ac.egressListener.(AdminResponseListener); ok {
+		listener.OnAdminResponse(ac, e.CorrelationId, e.RequestType, e.ResponseCode, string(e.Message), e.Payload)
+	} else {
+		logger.Debugf("admin response with no listener - corrId=%d code=%v (%s)",
+			e.CorrelationId, e.ResponseCode, string(e.Message))
+	}
+}
+
+func (ac *AeronCluster) onChallenge(buffer *atomic.Buffer, offset, length int32, version, blockLength uint16) {
+	e := codecs.Challenge{}
 	buf := bytes.Buffer{}
 	buffer.WriteBytes(&buf, offset, length)
 	if err := e.Decode(marshaller, &buf, version, blockLength, true); err != nil {
+		logger.Errorf("challenge decode error: %v", err)
+	} else if ac.state == clientAwaitConnectReply && e.CorrelationId == ac.correlationId {
+		logger.Debugf("received challenge, corrId=%d clusterSessionId=%d", e.CorrelationId, e.ClusterSessionId)
+		response := ac.credentialsSupplier().OnChallenge(e.EncodedChallenge)
+		if err := ac.sendChallengeResponse(e.CorrelationId, e.ClusterSessionId, response); err != nil {
+			logger.Warningf("error sending challenge response: %v", err)
+			ac.egressListener.OnError(ac, fmt.Sprintf("failed to send challenge response: %v", err))
+			ac.scheduleRetry(5 * time.Second)
+		}
+	} else {
+		logger.Debugf("ignored challenge - state=%v corrId=%d clusterSessionId=%d",
+			ac.state, e.CorrelationId, e.ClusterSessionId)
+	}
+}
+
+func (ac *AeronCluster) onNewLeaderEvent(buffer *atomic.Buffer, offset, length int32, version, blockLength uint16) {
+	if length < int32(blockLength) {
+		logger.Errorf("received truncated new leader event - length=%d blockLength=%d", length, blockLength)
+		return
+	}
+	e := ac.newLeaderDecoder.Wrap(buffer, offset, blockLength, version)
+	e.SetMessageLength(length)
+	leadershipTermId := e.LeadershipTermId()
+	clusterSessionId := e.ClusterSessionId()
+	leaderMemberId := e.LeaderMemberId()
+	detail, err := e.GetIngressEndpoints(ac.detail[:0])
+	if err != nil {
 		logger.Errorf("new leader event decode error: %v", err)
-	} else if ac.state == clientConnected && e.ClusterSessionId == ac.clusterSessionId {
-		ac.leadershipTermId = e.LeadershipTermId
-		ac.leaderMemberId = e.LeaderMemberId
-		ac.sessionMsgHdrBuffer.PutInt64(cluster.SBEHeaderLength, e.LeadershipTermId)
-		ac.keepAliveBuffer.PutInt64(cluster.SBEHeaderLength, e.LeadershipTermId)
+		return
+	}
+	ac.detail = detail
+	if ac.state == clientConnected && clusterSessionId == ac.clusterSessionId {
+		ac.leadershipTermId = leadershipTermId
+		ac.leaderMemberId = leaderMemberId
+		ac.sessionMsgHdrBuffer.PutInt64(cluster.SBEHeaderLength, leadershipTermId)
+		ac.keepAliveBuffer.PutInt64(cluster.SBEHeaderLength, leadershipTermId)
 		if ac.opts.IngressEndpoints != "" {
 			if err := ac.ingressPub.Close(); err != nil {
 				logger.Warningf("error closing ingress publication: %v", err)
 			}
 			ac.ingressPub = nil
-			ac.updateMemberEndpoints(string(e.IngressEndpoints))
+			ac.updateMemberEndpoints(string(ac.detail))
 		}
 		ac.fragmentAssembler.Clear()
-		ac.egressListener.OnNewLeader(ac, e.LeadershipTermId, e.LeaderMemberId)
+		ac.egressListener.OnNewLeader(ac, leadershipTermId, leaderMemberId)
 	} else {
 		logger.Debugf("ignored new leader event - state=%v thisSessionId=%d targetSessionId=%d leaderMemberId=%d leaderTermId=%d",
-			ac.state, ac.clusterSessionId, e.ClusterSessionId, e.LeaderMemberId, e.LeadershipTermId)
+			ac.state, ac.clusterSessionId, clusterSessionId, leaderMemberId, leadershipTermId)
 	}
 }
 
 func (ac *AeronCluster) onSessionEvent(buffer *atomic.Buffer, offset, length int32, version, blockLength uint16) {
-	e := codecs.SessionEvent{}
-	buf := bytes.Buffer{}
-	buffer.WriteBytes(&buf, offset, length)
-	if err := e.Decode(marshaller, &buf, version, blockLength, true); err != nil {
+	if length < int32(blockLength) {
+		logger.Errorf("received truncated session event - length=%d blockLength=%d", length, blockLength)
+		return
+	}
+	e := ac.sessionEventDecoder.Wrap(buffer, offset, blockLength, version)
+	e.SetMessageLength(length)
+	clusterSessionId := e.ClusterSessionId()
+	leadershipTermId := e.LeadershipTermId()
+	leaderMemberId := e.LeaderMemberId()
+	code := e.Code()
+	detail, err := e.GetDetail(ac.detail[:0])
+	if err != nil {
 		logger.Errorf("session event decode error: %v", err)
-	} else if ac.state == clientAwaitConnectReply && e.CorrelationId == ac.correlationId {
-		switch e.Code {
+		return
+	}
+	ac.detail = detail
+	if ac.state == clientAwaitConnectReply && e.CorrelationId() == ac.correlationId {
+		switch code {
 		case codecs.EventCode.OK:
-			ac.leadershipTermId = e.LeadershipTermId
-			ac.leaderMemberId = e.LeaderMemberId
-			ac.clusterSessionId = e.ClusterSessionId
-			ac.sessionMsgHdrBuffer.PutInt64(cluster.SBEHeaderLength, e.LeadershipTermId)
-			ac.sessionMsgHdrBuffer.PutInt64(cluster.SBEHeaderLength+8, e.ClusterSessionId)
-			ac.keepAliveBuffer.PutInt64(cluster.SBEHeaderLength, e.LeadershipTermId)
-			ac.keepAliveBuffer.PutInt64(cluster.SBEHeaderLength+8, e.ClusterSessionId)
+			ac.leadershipTermId = leadershipTermId
+			ac.leaderMemberId = leaderMemberId
+			ac.clusterSessionId = clusterSessionId
+			ac.sessionMsgHdrBuffer.PutInt64(cluster.SBEHeaderLength, leadershipTermId)
+			ac.sessionMsgHdrBuffer.PutInt64(cluster.SBEHeaderLength+8, clusterSessionId)
+			ac.keepAliveBuffer.PutInt64(cluster.SBEHeaderLength, leadershipTermId)
+			ac.keepAliveBuffer.PutInt64(cluster.SBEHeaderLength+8, clusterSessionId)
 			ac.state = clientConnected
 			ac.closeNonLeaderPublications()
+			ac.onReconnected()
 			ac.egressListener.OnConnect(ac)
 		case codecs.EventCode.REDIRECT:
-			logger.Infof("got redirect - leaderTermId=%d leaderMemberId=%d", e.LeadershipTermId, e.LeaderMemberId)
-			ac.leaderMemberId = e.LeaderMemberId
-			ac.updateMemberEndpoints(string(e.Detail))
+			logger.Infof("got redirect - leaderTermId=%d leaderMemberId=%d", leadershipTermId, leaderMemberId)
+			ac.leaderMemberId = leaderMemberId
+			ac.updateMemberEndpoints(string(ac.detail))
 			ac.closeNonLeaderPublications()
 			ac.state = clientAwaitPublicationConnected
 		case codecs.EventCode.ERROR:
-			ac.egressListener.OnError(ac, string(e.Detail))
-			ac.state = clientDisconnected
-			ac.nextRetryConnectTime = time.Now().UnixMilli() + (5 * time.Second).Milliseconds()
+			ac.egressListener.OnError(ac, string(ac.detail))
+			ac.scheduleRetry(5 * time.Second)
 		case codecs.EventCode.AUTHENTICATION_REJECTED:
-			ac.egressListener.OnError(ac, fmt.Sprintf("authentication rejected (%s)", string(e.Detail)))
-			ac.state = clientDisconnected
-			ac.nextRetryConnectTime = time.Now().UnixMilli() + time.Minute.Milliseconds()
+			details := fmt.Sprintf("authentication rejected (%s)", string(ac.detail))
+			ac.egressListener.OnError(ac, details)
+			ac.egressListener.OnDisconnect(ac, details)
+			ac.scheduleRetry(time.Minute)
 		}
-	} else if ac.state == clientConnected && e.ClusterSessionId == ac.clusterSessionId {
-		if e.Code == codecs.EventCode.CLOSED {
-			ac.egressListener.OnDisconnect(ac, string(e.Detail))
-			ac.state = clientClosed
-		} else if e.Code == codecs.EventCode.ERROR {
-			ac.egressListener.OnError(ac, string(e.Detail))
+	} else if ac.state == clientConnected && clusterSessionId == ac.clusterSessionId {
+		if code == codecs.EventCode.CLOSED {
+			ac.startReconnect(string(ac.detail))
+		} else if code == codecs.EventCode.ERROR {
+			ac.egressListener.OnError(ac, string(ac.detail))
 		} else {
-			logger.Infof("onSessionEvent - code=%v (%s)", e.Code, string(e.Detail))
+			logger.Infof("onSessionEvent - code=%v (%s)", code, string(ac.detail))
 		}
 	} else {
 		logger.Debugf("ignored session event - state=%v thisSessionId=%d targetSessionId=%d code=%d (%s)",
-			ac.state, ac.clusterSessionId, e.ClusterSessionId, e.Code, string(e.Detail))
+			ac.state, ac.clusterSessionId, clusterSessionId, code, ac.detail)
 	}
 }
 
@@ -0,0 +1,755 @@
+package client
+
+import (
+	"bytes"
+	"io"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEgressListener struct {
+	connects    int
+	disconnects []string
+	errors      []string
+	messages    []string
+}
+
+func (l *testEgressListener) OnConnect(cluster *AeronCluster) {
+	l.connects++
+}
+
+func (l *testEgressListener) OnDisconnect(cluster *AeronCluster, details string) {
+	l.disconnects = append(l.disconnects, details)
+}
+
+func (l *testEgressListener) OnMessage(cluster *AeronCluster, timestamp int64, buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+	l.messages = append(l.messages, string(buffer.GetBytesArray(offset, length)))
+}
+
+func (l *testEgressListener) OnNewLeader(cluster *AeronCluster, leadershipTermId int64, leaderMemberId int32) {
+}
+
+func (l *testEgressListener) OnError(cluster *AeronCluster, details string) {
+	l.errors = append(l.errors, details)
+}
+
+type testCredentialsSupplier struct {
+	challenges [][]byte
+}
+
+func (s *testCredentialsSupplier) EncodedCredentials() []byte {
+	return []byte("user:pass")
+}
+
+func (s *testCredentialsSupplier) OnChallenge(encodedChallenge []byte) []byte {
+	s.challenges = append(s.challenges, encodedChallenge)
+	return []byte("response")
+}
+
+// newTestAeronCluster returns a client awaiting the reply to a connect request with no aeron connection
+func newTestAeronCluster(listener EgressListener, supplier aeron.CredentialsSupplier) *AeronCluster {
+	opts := NewOptions()
+	if supplier != nil {
+		opts.CredentialsSupplier = supplier
+	}
+	ac := &AeronCluster{
+		opts:                opts,
+		clusterSessionId:    cluster.NullValue,
+		leadershipTermId:    cluster.NullValue,
+		leaderMemberId:      cluster.NullValue,
+		memberByIdMap:       make(map[int32]*memberIngress),
+		egressListener:      listener,
+		state:               clientAwaitConnectReply,
+		correlationId:       42,
+		sessionMsgHdrBuffer: codecs.MakeClusterMessageBuffer(cluster.SessionMessageHeaderTemplateId, cluster.SessionMessageHdrBlockLength),
+		keepAliveBuffer:     codecs.MakeClusterMessageBuffer(cluster.SessionKeepAliveTemplateId, 16),
+	}
+	ac.fragmentAssembler = aeron.NewFragmentAssembler(ac.onFragment, 0)
+	return ac
+}
+
+type sbeMessage interface {
+	Encode(_m *codecs.SbeGoMarshaller, _w io.Writer, doRangeCheck bool) error
+	SbeBlockLength() uint16
+	SbeTemplateId() uint16
+	SbeSchemaId() uint16
+	SbeSchemaVersion() uint16
+}
+
+func encodeFrame(t *testing.T, msg sbeMessage) *atomic.Buffer {
+	header := codecs.MessageHeader{
+		BlockLength: msg.SbeBlockLength(),
+		TemplateId:  msg.SbeTemplateId(),
+		SchemaId:    msg.SbeSchemaId(),
+		Version:     msg.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	require.NoError(t, header.Encode(marshaller, writer))
+	require.NoError(t, msg.Encode(marshaller, writer, true))
+	return atomic.NewBufferSlice(writer.Bytes())
+}
+
+func deliver(ac *AeronCluster, buffer *atomic.Buffer) {
+	ac.onFragment(buffer, 0, buffer.Capacity(), nil)
+}
+
+func TestSessionEvent_Ok(t *testing.T) {
+	listener := &testEgressListener{}
+	ac := newTestAeronCluster(listener, nil)
+
+	deliver(ac, encodeFrame(t, &codecs.SessionEvent{
+		ClusterSessionId: 7,
+		CorrelationId:    42,
+		LeadershipTermId: 3,
+		LeaderMemberId:   1,
+		Code:             codecs.EventCode.OK,
+	}))
+	assert.True(t, ac.IsConnected())
+	assert.Equal(t, 1, listener.connects)
+	assert.EqualValues(t, 7, ac.ClusterSessionId())
+	assert.EqualValues(t, 3, ac.sessionMsgHdrBuffer.GetInt64(cluster.SBEHeaderLength))
+	assert.EqualValues(t, 7, ac.sessionMsgHdrBuffer.GetInt64(cluster.SBEHeaderLength+8))
+}
+
+func TestSessionEvent_Truncated(t *testing.T) {
+	listener := &testEgressListener{}
+	ac := newTestAeronCluster(listener, nil)
+	buffer := encodeFrame(t, &codecs.SessionEvent{
+		ClusterSessionId: 7,
+		CorrelationId:    42,
+		LeadershipTermId: 3,
+		LeaderMemberId:   1,
+		Code:             codecs.EventCode.OK,
+		Detail:           []byte("detail"),
+	})
+
+	// The fragment ends within the detail, which is dropped with the event
+	ac.onFragment(buffer, 0, buffer.Capacity()-1, nil)
+	assert.Equal(t, clientAwaitConnectReply, ac.state)
+	assert.Zero(t, listener.connects)
+
+	detailOffset := buffer.Capacity() - int32(len("detail")) - 4
+	buffer.PutInt32(detailOffset, -1)
+	deliver(ac, buffer)
+	buffer.PutInt32(detailOffset, 1<<30)
+	deliver(ac, buffer)
+	assert.Equal(t, clientAwaitConnectReply, ac.state)
+
+	buffer.PutInt32(detailOffset, int32(len("detail")))
+	deliver(ac, buffer)
+	assert.True(t, ac.IsConnected())
+}
+
+func TestSessionEvent_AuthenticationRejected(t *testing.T) {
+	listener := &testEgressListener{}
+	ac := newTestAeronCluster(listener, nil)
+
+	// Another client's rejection is ignored
+	rejection := &codecs.SessionEvent{
+		ClusterSessionId: 7,
+		CorrelationId:    41,
+		LeadershipTermId: 3,
+		LeaderMemberId:   1,
+		Code:             codecs.EventCode.AUTHENTICATION_REJECTED,
+		Detail:           []byte("bad password"),
+	}
+	deliver(ac, encodeFrame(t, rejection))
+	assert.Empty(t, listener.errors)
+	assert.Equal(t, clientAwaitConnectReply, ac.state)
+
+	rejection.CorrelationId = 42
+	deliver(ac, encodeFrame(t, rejection))
+	assert.Equal(t, []string{"authentication rejected (bad password)"}, listener.errors)
+	assert.Equal(t, []string{"authentication rejected (bad password)"}, listener.disconnects)
+	assert.Equal(t, clientDisconnected, ac.state)
+	assert.Zero(t, listener.connects)
+}
+
+func TestChallenge(t *testing.T) {
+	listener := &testEgressListener{}
+	supplier := &testCredentialsSupplier{}
+	ac := newTestAeronCluster(listener, supplier)
+
+	challenge := &codecs.Challenge{
+		CorrelationId:    41,
+		ClusterSessionId: 7,
+		EncodedChallenge: []byte("nonce"),
+	}
+	deliver(ac, encodeFrame(t, challenge))
+	assert.Empty(t, supplier.challenges)
+
+	// There is no ingress publication so sending the response fails
+	challenge.CorrelationId = 42
+	deliver(ac, encodeFrame(t, challenge))
+	assert.Equal(t, [][]byte{[]byte("nonce")}, supplier.challenges)
+	assert.Len(t, listener.errors, 1)
+	assert.Contains(t, listener.errors[0], "failed to send challenge response")
+	assert.Equal(t, clientDisconnected, ac.state)
+}
+
+func TestChallenge_Connected(t *testing.T) {
+	listener := &testEgressListener{}
+	supplier := &testCredentialsSupplier{}
+	ac := newTestAeronCluster(listener, supplier)
+	pub, image := aeron.NewInMemoryStream("aeron:ipc", 10, 1, logbuffer.TermMinLength)
+	ac.ingressPub = pub
+
+	deliver(ac, encodeFrame(t, &codecs.Challenge{
+		CorrelationId:    42,
+		ClusterSessionId: 7,
+		EncodedChallenge: []byte("nonce"),
+	}))
+	assert.Equal(t, [][]byte{[]byte("nonce")}, supplier.challenges)
+	assert.Empty(t, listener.errors)
+	assert.Equal(t, clientAwaitConnectReply, ac.state)
+
+	messages := pollIngress(image)
+	require.Len(t, messages, 1)
+	var header codecs.MessageHeader
+	var response codecs.ChallengeResponse
+	reader := bytes.NewReader(messages[0])
+	require.NoError(t, header.Decode(marshaller, reader, 0))
+	require.Equal(t, response.SbeTemplateId(), header.TemplateId)
+	require.NoError(t, response.Decode(marshaller, reader, header.Version, header.BlockLength, true))
+	assert.EqualValues(t, 42, response.CorrelationId)
+	assert.EqualValues(t, 7, response.ClusterSessionId)
+	assert.Equal(t, []byte("response"), response.EncodedCredentials)
+
+	deliver(ac, encodeFrame(t, &codecs.SessionEvent{
+		ClusterSessionId: 7,
+		CorrelationId:    42,
+		LeadershipTermId: 3,
+		LeaderMemberId:   1,
+		Code:             codecs.EventCode.OK,
+	}))
+	assert.True(t, ac.IsConnected())
+	assert.Equal(t, 1, listener.connects)
+	assert.EqualValues(t, 7, ac.ClusterSessionId())
+	assert.Empty(t, listener.errors)
+}
+
+func TestEncodeChallengeResponse(t *testing.T) {
+	ac := newTestAeronCluster(&testEgressListener{}, nil)
+	buffer, err := ac.encodeChallengeResponse(42, 7, []byte("response"))
+	require.NoError(t, err)
+
+	var header codecs.MessageHeader
+	var response codecs.ChallengeResponse
+	reader := bytes.NewReader(buffer.GetBytesArray(0, buffer.Capacity()))
+	require.NoError(t, header.Decode(marshaller, reader, 0))
+	assert.Equal(t, response.SbeTemplateId(), header.TemplateId)
+	require.NoError(t, response.Decode(marshaller, reader, header.Version, header.BlockLength, true))
+	assert.EqualValues(t, 42, response.CorrelationId)
+	assert.EqualValues(t, 7, response.ClusterSessionId)
+	assert.Equal(t, []byte("response"), response.EncodedCredentials)
+}
+
+func TestEncodeConnectRequest(t *testing.T) {
+	ac := newTestAeronCluster(&testEgressListener{}, &testCredentialsSupplier{})
+	buffer, err := ac.encodeConnectRequest(42, "aeron:udp?endpoint=localhost:1234")
+	require.NoError(t, err)
+
+	var header codecs.MessageHeader
+	var request codecs.SessionConnectRequest
+	reader := bytes.NewReader(buffer.GetBytesArray(0, buffer.Capacity()))
+	require.NoError(t, header.Decode(marshaller, reader, 0))
+	assert.Equal(t, request.SbeTemplateId(), header.TemplateId)
+	require.NoError(t, request.Decode(marshaller, reader, header.Version, header.BlockLength, true))
+	assert.EqualValues(t, 42, request.CorrelationId)
+	assert.Equal(t, ac.opts.EgressStreamId, request.ResponseStreamId)
+	assert.Equal(t, "aeron:udp?endpoint=localhost:1234", string(request.ResponseChannel))
+	assert.Equal(t, []byte("user:pass"), request.EncodedCredentials)
+}
+
+// connectTestAeronCluster delivers the OK session event for clusterSessionId
+func connectTestAeronCluster(t *testing.T, ac *AeronCluster, clusterSessionId int64) {
+	deliver(ac, encodeFrame(t, &codecs.SessionEvent{
+		ClusterSessionId: clusterSessionId,
+		CorrelationId:    ac.correlationId,
+		LeadershipTermId: 3,
+		LeaderMemberId:   1,
+		Code:             codecs.EventCode.OK,
+	}))
+	require.True(t, ac.IsConnected())
+}
+
+func sessionClosedEvent(clusterSessionId int64) *codecs.SessionEvent {
+	return &codecs.SessionEvent{
+		ClusterSessionId: clusterSessionId,
+		CorrelationId:    cluster.NullValue,
+		LeadershipTermId: 3,
+		LeaderMemberId:   1,
+		Code:             codecs.EventCode.CLOSED,
+		Detail:           []byte("session timeout"),
+	}
+}
+
+func TestSessionClosed_NoReconnectPolicy(t *testing.T) {
+	listener := &testEgressListener{}
+	ac := newTestAeronCluster(listener, nil)
+	connectTestAeronCluster(t, ac, 7)
+
+	deliver(ac, encodeFrame(t, sessionClosedEvent(7)))
+	assert.Equal(t, []string{"session timeout"}, listener.disconnects)
+	assert.Equal(t, clientClosed, ac.state)
+	assert.False(t, ac.IsReconnecting())
+
+	buffer := atomic.MakeBuffer(make([]byte, 8))
+	assert.EqualValues(t, aeron.NotConnected, ac.Offer(buffer, 0, 8))
+}
+
+func TestSessionClosed_Reconnect(t *testing.T) {
+	listener := &testEgressListener{}
+	ac := newTestAeronCluster(listener, nil)
+	var reconnects [][2]int64
+	ac.opts.ReconnectPolicy = NewReconnectPolicy()
+	ac.opts.ReconnectPolicy.BufferOffers = true
+	ac.opts.ReconnectPolicy.MaxBufferedBytes = 16
+	ac.opts.ReconnectPolicy.OnReconnect = func(cluster *AeronCluster, previousClusterSessionId, clusterSessionId int64) {
+		reconnects = append(reconnects, [2]int64{previousClusterSessionId, clusterSessionId})
+	}
+	connectTestAeronCluster(t, ac, 7)
+
+	deliver(ac, encodeFrame(t, sessionClosedEvent(7)))
+	assert.Equal(t, []string{"session timeout"}, listener.disconnects)
+	assert.Equal(t, clientDisconnected, ac.state)
+	assert.True(t, ac.IsReconnecting())
+	assert.EqualValues(t, cluster.NullValue, ac.ClusterSessionId())
+
+	buffer := atomic.MakeBuffer([]byte("0123456789abcdef"))
+	assert.EqualValues(t, 0, ac.Offer(buffer, 0, 10))
+	assert.EqualValues(t, aeron.BackPressured, ac.Offer(buffer, 0, 10))
+	assert.EqualValues(t, 0, ac.Offer(buffer, 10, 6))
+
+	ac.state = clientAwaitConnectReply
+	ac.correlationId = 43
+	connectTestAeronCluster(t, ac, 8)
+	assert.False(t, ac.IsReconnecting())
+	assert.Equal(t, [][2]int64{{7, 8}}, reconnects)
+	assert.Equal(t, 2, listener.connects)
+	assert.Equal(t, [][]byte{[]byte("0123456789"), []byte("abcdef")}, ac.pendingOffers)
+}
+
+func TestReconnect_MaxAttempts(t *testing.T) {
+	listener := &testEgressListener{}
+	ac := newTestAeronCluster(listener, nil)
+	ac.opts.ReconnectPolicy = NewReconnectPolicy()
+	ac.opts.ReconnectPolicy.MaxAttempts = 2
+	connectTestAeronCluster(t, ac, 7)
+	deliver(ac, encodeFrame(t, sessionClosedEvent(7)))
+
+	buffer := atomic.MakeBuffer(make([]byte, 8))
+	assert.EqualValues(t, aeron.NotConnected, ac.Offer(buffer, 0, 8))
+
+	ac.scheduleRetry(time.Second)
+	assert.Equal(t, clientDisconnected, ac.state)
+	assert.Equal(t, 200*time.Millisecond, ac.reconnect.backoff)
+	ac.scheduleRetry(time.Second)
+	assert.Equal(t, clientClosed, ac.state)
+	assert.False(t, ac.IsReconnecting())
+	assert.Equal(t, []string{"reconnect failed after 2 attempts"}, listener.errors)
+}
+
+type testEgressSubscription struct {
+	closes int
+}
+
+func (s *testEgressSubscription) Poll(handler term.FragmentHandler, fragmentLimit int) int {
+	return 0
+}
+
+func (s *testEgressSubscription) TryResolveChannelEndpointPort() string {
+	return "aeron:udp?endpoint=localhost:9020"
+}
+
+func (s *testEgressSubscription) Close() error {
+	s.closes++
+	return nil
+}
+
+// newTestReconnect returns a client which lost its session and will give up on the next failed attempt
+func newTestReconnect(t *testing.T, listener *testEgressListener) (*AeronCluster, *testEgressSubscription) {
+	ac := newTestAeronCluster(listener, nil)
+	egressSub := &testEgressSubscription{}
+	ac.egressSub = egressSub
+	ac.opts.ReconnectPolicy = NewReconnectPolicy()
+	ac.opts.ReconnectPolicy.MaxAttempts = 1
+	connectTestAeronCluster(t, ac, 7)
+	deliver(ac, encodeFrame(t, sessionClosedEvent(7)))
+	require.True(t, ac.IsReconnecting())
+	ac.state = clientAwaitConnectReply
+	ac.correlationId = 43
+	return ac, egressSub
+}
+
+func TestReconnect_MaxAttempts_ClosesMemberPublications(t *testing.T) {
+	listener := &testEgressListener{}
+	ac, egressSub := newTestReconnect(t, listener)
+	var pubs []*aeron.Publication
+	for memberId := int32(0); memberId < 2; memberId++ {
+		pub, _ := aeron.NewInMemoryStream("aeron:ipc", 10, memberId, logbuffer.TermMinLength)
+		ac.memberByIdMap[memberId] = &memberIngress{memberId: memberId, endpoint: "in:" + strconv.Itoa(int(memberId)), publication: pub}
+		pubs = append(pubs, pub)
+	}
+	ac.ingressPub = pubs[1]
+
+	deliver(ac, encodeFrame(t, &codecs.SessionEvent{
+		ClusterSessionId: cluster.NullValue,
+		CorrelationId:    43,
+		LeadershipTermId: 3,
+		LeaderMemberId:   1,
+		Code:             codecs.EventCode.ERROR,
+		Detail:           []byte("no leader"),
+	}))
+	assert.True(t, ac.IsClosed())
+	assert.Equal(t, []string{"no leader", "reconnect failed after 1 attempts"}, listener.errors)
+	assert.Nil(t, ac.ingressPub)
+	for memberId, pub := range pubs {
+		assert.True(t, pub.IsClosed(), "memberId=%d", memberId)
+		assert.Nil(t, ac.memberByIdMap[int32(memberId)].publication)
+	}
+	assert.Nil(t, ac.egressSub)
+	assert.Equal(t, 1, egressSub.closes)
+}
+
+func TestReconnect_MaxAttempts_ClosesIngressPublication(t *testing.T) {
+	listener := &testEgressListener{}
+	ac, egressSub := newTestReconnect(t, listener)
+	pub, _ := aeron.NewInMemoryStream("aeron:ipc", 10, 1, logbuffer.TermMinLength)
+	ac.ingressPub = pub
+
+	// The connect reply times out
+	ac.awaitTimeoutTime = 0
+	assert.Zero(t, ac.Poll())
+	assert.True(t, ac.IsClosed())
+	assert.Equal(t, []string{"reconnect failed after 1 attempts"}, listener.errors)
+	assert.Nil(t, ac.ingressPub)
+	assert.True(t, pub.IsClosed())
+	assert.Nil(t, ac.egressSub)
+	assert.Equal(t, 1, egressSub.closes)
+	assert.Zero(t, ac.Poll(), "a closed client does no work")
+}
+
+// pollIngress returns the messages offered to the in memory ingress stream of the client
+func pollIngress(image aeron.Image) [][]byte {
+	var messages [][]byte
+	assembler := aeron.NewFragmentAssembler(func(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+		messages = append(messages, buffer.GetBytesArray(offset, length))
+	}, aeron.DefaultFragmentAssemblyBufferLength)
+	for image.Poll(assembler.OnFragment, 10) > 0 {
+	}
+	return messages
+}
+
+func TestTryClaimAndOfferV(t *testing.T) {
+	ac := newTestAeronCluster(&testEgressListener{}, nil)
+	var claim logbuffer.Claim
+	assert.EqualValues(t, aeron.NotConnected, ac.TryClaim(8, &claim))
+	connectTestAeronCluster(t, ac, 7)
+	pub, image := aeron.NewInMemoryStream("aeron:ipc", 10, 1, logbuffer.TermMinLength)
+	ac.ingressPub = pub
+
+	require.Greater(t, ac.TryClaim(8, &claim), int64(0))
+	claim.Buffer().PutInt64(claim.Offset()+cluster.SBEHeaderLength+cluster.SessionMessageHeaderLength, 99)
+	claim.Commit()
+
+	vectors := []atomic.BufferVector{
+		{Buffer: atomic.MakeBuffer([]byte("xxhead")), Offset: 2, Length: 4},
+		{Buffer: atomic.MakeBuffer([]byte("body")), Offset: 0, Length: 4},
+	}
+	require.Greater(t, ac.OfferV(vectors), int64(0))
+
+	messages := pollIngress(image)
+	require.Len(t, messages, 2)
+	for _, msg := range messages {
+		hdr := atomic.MakeBuffer(msg)
+		assert.EqualValues(t, cluster.SessionMessageHeaderTemplateId, hdr.GetUInt16(2))
+		assert.EqualValues(t, 7, hdr.GetInt64(cluster.SBEHeaderLength+8))
+	}
+	assert.EqualValues(t, 99, atomic.MakeBuffer(messages[0]).GetInt64(cluster.SBEHeaderLength+cluster.SessionMessageHeaderLength))
+	assert.Equal(t, "headbody", string(messages[1][cluster.SBEHeaderLength+cluster.SessionMessageHeaderLength:]))
+}
+
+func TestOfferV_BufferedWhileReconnecting(t *testing.T) {
+	ac := newTestAeronCluster(&testEgressListener{}, nil)
+	ac.opts.ReconnectPolicy = NewReconnectPolicy()
+	ac.opts.ReconnectPolicy.BufferOffers = true
+	connectTestAeronCluster(t, ac, 7)
+	deliver(ac, encodeFrame(t, sessionClosedEvent(7)))
+
+	vectors := []atomic.BufferVector{
+		{Buffer: atomic.MakeBuffer([]byte("ab")), Offset: 0, Length: 2},
+		{Buffer: atomic.MakeBuffer([]byte("xcd")), Offset: 1, Length: 2},
+	}
+	assert.EqualValues(t, 0, ac.OfferV(vectors))
+	var claim logbuffer.Claim
+	assert.EqualValues(t, aeron.NotConnected, ac.TryClaim(8, &claim))
+	assert.Equal(t, [][]byte{[]byte("abcd")}, ac.reconnect.pendingOffers)
+}
+
+func TestOffer_EmptyBufferedWhileReconnecting(t *testing.T) {
+	ac := newTestAeronCluster(&testEgressListener{}, nil)
+	ac.opts.ReconnectPolicy = NewReconnectPolicy()
+	ac.opts.ReconnectPolicy.BufferOffers = true
+	connectTestAeronCluster(t, ac, 7)
+	deliver(ac, encodeFrame(t, sessionClosedEvent(7)))
+
+	buffer := atomic.MakeBuffer([]byte("ab"))
+	assert.EqualValues(t, 0, ac.Offer(buffer, 0, 0))
+	assert.EqualValues(t, 0, ac.Offer(buffer, 0, 2))
+
+	ac.state = clientAwaitConnectReply
+	ac.correlationId = 43
+	connectTestAeronCluster(t, ac, 8)
+	pub, image := aeron.NewInMemoryStream("aeron:ipc", 10, 1, logbuffer.TermMinLength)
+	ac.ingressPub = pub
+
+	// The buffered offers are sent ahead of the next one
+	require.Positive(t, ac.Offer(buffer, 1, 1))
+	assert.Empty(t, ac.pendingOffers)
+	messages := pollIngress(image)
+	require.Len(t, messages, 3)
+	headerLength := cluster.SBEHeaderLength + cluster.SessionMessageHeaderLength
+	assert.Len(t, messages[0], headerLength)
+	assert.EqualValues(t, 8, atomic.MakeBuffer(messages[0]).GetInt64(cluster.SBEHeaderLength+8))
+	assert.Equal(t, "ab", string(messages[1][headerLength:]))
+	assert.Equal(t, "b", string(messages[2][headerLength:]))
+}
+
+type testAdminListener struct {
+	testEgressListener
+	adminResponses []codecs.AdminResponse
+}
+
+func (l *testAdminListener) OnAdminResponse(
+	cluster *AeronCluster,
+	correlationId int64,
+	requestType codecs.AdminRequestTypeEnum,
+	responseCode codecs.AdminResponseCodeEnum,
+	message string,
+	payload []byte,
+) {
+	l.adminResponses = append(l.adminResponses, codecs.AdminResponse{
+		CorrelationId: correlationId,
+		RequestType:   requestType,
+		ResponseCode:  responseCode,
+		Message:       []byte(message),
+		Payload:       payload,
+	})
+}
+
+func TestEncodeAdminRequest(t *testing.T) {
+	ac := newTestAeronCluster(&testEgressListener{}, nil)
+	connectTestAeronCluster(t, ac, 7)
+	buffer, err := ac.encodeAdminRequest(99, codecs.AdminRequestType.SNAPSHOT, nil)
+	require.NoError(t, err)
+
+	var header codecs.MessageHeader
+	var request codecs.AdminRequest
+	reader := bytes.NewReader(buffer.GetBytesArray(0, buffer.Capacity()))
+	require.NoError(t, header.Decode(marshaller, reader, 0))
+	assert.EqualValues(t, cluster.AdminRequestTemplateId, header.TemplateId)
+	require.NoError(t, request.Decode(marshaller, reader, header.Version, header.BlockLength, true))
+	assert.EqualValues(t, 3, request.LeadershipTermId)
+	assert.EqualValues(t, 7, request.ClusterSessionId)
+	assert.EqualValues(t, 99, request.CorrelationId)
+	assert.Equal(t, codecs.AdminRequestType.SNAPSHOT, request.RequestType)
+}
+
+func TestAdminRequests_NotConnected(t *testing.T) {
+	ac := newTestAeronCluster(&testEgressListener{}, nil)
+	assert.False(t, ac.SendAdminRequestToTakeASnapshot(99))
+}
+
+func TestAdminResponse(t *testing.T) {
+	listener := &testAdminListener{}
+	ac := newTestAeronCluster(listener, nil)
+	connectTestAeronCluster(t, ac, 7)
+
+	response := &codecs.AdminResponse{
+		ClusterSessionId: 8,
+		CorrelationId:    99,
+		RequestType:      codecs.AdminRequestType.SNAPSHOT,
+		ResponseCode:     codecs.AdminResponseCode.OK,
+		Message:          []byte("done"),
+	}
+	deliver(ac, encodeFrame(t, response))
+	assert.Empty(t, listener.adminResponses)
+
+	response.ClusterSessionId = 7
+	deliver(ac, encodeFrame(t, response))
+	require.Len(t, listener.adminResponses, 1)
+	assert.EqualValues(t, 99, listener.adminResponses[0].CorrelationId)
+	assert.Equal(t, codecs.AdminResponseCode.OK, listener.adminResponses[0].ResponseCode)
+	assert.Equal(t, "done", string(listener.adminResponses[0].Message))
+
+	// Ignored without the optional interface
+	plain := newTestAeronCluster(&testEgressListener{}, nil)
+	connectTestAeronCluster(t, plain, 7)
+	deliver(plain, encodeFrame(t, response))
+}
+
+// testCluster answers on the egress stream the requests its members receive
+// on the ingress publications the client adds. A connect request to member 0
+// is redirected to the leader, member 1, which challenges the client before
+// opening session 7 and then echoes the session's messages.
+type testCluster struct {
+	t         *testing.T
+	channels  []string
+	pubs      []*aeron.Publication
+	images    []aeron.Image
+	egressPub *aeron.Publication
+	received  []string // Template and channel of each request
+	closes    int
+	nextId    int64
+}
+
+func (c *testCluster) AddPublication(channel string, streamID int32) (*aeron.Publication, error) {
+	pub, image := aeron.NewInMemoryStream(channel, streamID, int32(len(c.pubs)+1), logbuffer.TermMinLength)
+	c.channels = append(c.channels, channel)
+	c.pubs = append(c.pubs, pub)
+	c.images = append(c.images, image)
+	return pub, nil
+}
+
+func (c *testCluster) AddExclusivePublication(channel string, streamID int32) (*aeron.Publication, error) {
+	return c.AddPublication(channel, streamID)
+}
+
+func (c *testCluster) NextCorrelationID() int64 {
+	c.nextId++
+	return c.nextId
+}
+
+func (c *testCluster) Close() error {
+	c.closes++
+	return nil
+}
+
+func (c *testCluster) reply(msg sbeMessage) {
+	buffer := encodeFrame(c.t, msg)
+	require.Positive(c.t, c.egressPub.Offer(buffer, 0, buffer.Capacity(), nil))
+}
+
+func (c *testCluster) doWork() {
+	for idx, image := range c.images {
+		channel := c.channels[idx]
+		image.Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+			var msgHeader codecs.MessageHeader
+			reader := bytes.NewReader(buffer.GetBytesArray(offset, length))
+			require.NoError(c.t, msgHeader.Decode(marshaller, reader, 0))
+			c.received = append(c.received, strconv.Itoa(int(msgHeader.TemplateId))+" "+channel)
+			switch msgHeader.TemplateId {
+			case (&codecs.SessionConnectRequest{}).SbeTemplateId():
+				var request codecs.SessionConnectRequest
+				require.NoError(c.t, request.Decode(marshaller, reader, msgHeader.Version, msgHeader.BlockLength, true))
+				require.Equal(c.t, "aeron:udp?endpoint=localhost:9020", string(request.ResponseChannel))
+				if strings.Contains(channel, "localhost:20000") {
+					c.reply(&codecs.SessionEvent{
+						ClusterSessionId: cluster.NullValue,
+						CorrelationId:    request.CorrelationId,
+						LeadershipTermId: 3,
+						LeaderMemberId:   1,
+						Code:             codecs.EventCode.REDIRECT,
+						Detail:           []byte("0=localhost:20000,1=localhost:20001"),
+					})
+				} else {
+					c.reply(&codecs.Challenge{CorrelationId: request.CorrelationId, ClusterSessionId: 7, EncodedChallenge: []byte("nonce")})
+				}
+			case cluster.ChallengeResponseTemplateId:
+				var response codecs.ChallengeResponse
+				require.NoError(c.t, response.Decode(marshaller, reader, msgHeader.Version, msgHeader.BlockLength, true))
+				require.Equal(c.t, "response", string(response.EncodedCredentials))
+				c.reply(&codecs.SessionEvent{
+					ClusterSessionId: response.ClusterSessionId,
+					CorrelationId:    response.CorrelationId,
+					LeadershipTermId: 3,
+					LeaderMemberId:   1,
+					Code:             codecs.EventCode.OK,
+				})
+			case cluster.SessionMessageHeaderTemplateId:
+				// The egress session message header has the same layout
+				msg := atomic.NewBufferSlice(buffer.GetBytesArray(offset, length))
+				require.Positive(c.t, c.egressPub.Offer(msg, 0, msg.Capacity(), nil))
+			}
+		}, 10)
+	}
+}
+
+// egressStream is the client's egress subscription, which runs a duty cycle
+// of the cluster before each poll
+type egressStream struct {
+	cluster *testCluster
+	image   aeron.Image
+}
+
+func (s *egressStream) Poll(handler term.FragmentHandler, fragmentLimit int) int {
+	s.cluster.doWork()
+	return s.image.Poll(handler, fragmentLimit)
+}
+
+func (s *egressStream) TryResolveChannelEndpointPort() string {
+	return "aeron:udp?endpoint=localhost:9020"
+}
+
+func (s *egressStream) Close() error {
+	return nil
+}
+
+func TestPoll_ConnectAndExchangeMessages(t *testing.T) {
+	listener := &testEgressListener{}
+	opts := NewOptions()
+	opts.IngressChannel = "aeron:udp"
+	opts.IngressEndpoints = "0=localhost:20000"
+	opts.CredentialsSupplier = &testCredentialsSupplier{}
+	ingressChannel, err := aeron.ParseChannelUri(opts.IngressChannel)
+	require.NoError(t, err)
+	egressPub, egressImage := aeron.NewInMemoryStream(opts.EgressChannel, opts.EgressStreamId, 9, logbuffer.TermMinLength)
+	c := &testCluster{t: t, egressPub: egressPub}
+	ac := newAeronCluster(opts, listener, c, &egressStream{cluster: c, image: egressImage}, &ingressChannel)
+
+	for i := 0; !ac.IsConnected(); i++ {
+		require.Less(t, i, 100, "in state %v, errors: %v", ac.state, listener.errors)
+		ac.Poll()
+	}
+	assert.Equal(t, 1, listener.connects)
+	assert.Empty(t, listener.errors)
+	assert.EqualValues(t, 7, ac.ClusterSessionId())
+	assert.EqualValues(t, 1, ac.LeaderMemberId())
+	assert.EqualValues(t, 3, ac.LeadershipTermId())
+	assert.Equal(t, []string{"aeron:udp?endpoint=localhost:20000", "aeron:udp?endpoint=localhost:20001"}, c.channels)
+	assert.Equal(t, []string{
+		"3 aeron:udp?endpoint=localhost:20000",
+		"3 aeron:udp?endpoint=localhost:20001",
+		"8 aeron:udp?endpoint=localhost:20001",
+	}, c.received)
+	assert.True(t, c.pubs[0].IsClosed(), "the redirected member's publication is closed")
+	assert.Same(t, c.pubs[1], ac.ingressPub)
+
+	buffer := atomic.MakeBuffer([]byte("hello"))
+	require.Positive(t, ac.Offer(buffer, 0, buffer.Capacity()))
+	for i := 0; len(listener.messages) == 0; i++ {
+		require.Less(t, i, 100)
+		ac.Poll()
+	}
+	assert.Equal(t, []string{"hello"}, listener.messages)
+
+	ac.Close()
+	assert.True(t, ac.IsClosed())
+	assert.True(t, c.pubs[1].IsClosed())
+	assert.Equal(t, 1, c.closes)
+	c.doWork()
+	closeRequest := strconv.Itoa(int((&codecs.SessionCloseRequest{}).SbeTemplateId())) + " aeron:udp?endpoint=localhost:20001"
+	assert.Equal(t, closeRequest, c.received[len(c.received)-1])
+}
@@ -3,6 +3,7 @@ package client
 import (
 	"github.com/lirm/aeron-go/aeron/atomic"
 	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/cluster/codecs"
 )
 
 type EgressListener interface {
@@ -27,3 +28,16 @@ type EgressListener interface {
 
 	OnError(cluster *AeronCluster, details string)
 }
+
+// AdminResponseListener may be implemented by an EgressListener to receive
+// responses to admin requests such as AeronCluster.SendAdminRequestToTakeASnapshot()
+type AdminResponseListener interface {
+	OnAdminResponse(
+		cluster *AeronCluster,
+		correlationId int64,
+		requestType codecs.AdminRequestTypeEnum,
+		responseCode codecs.AdminResponseCodeEnum,
+		message string,
+		payload []byte,
+	)
+}
@@ -1,6 +1,7 @@
 package client
 
 import (
+	"github.com/lirm/aeron-go/aeron"
 	"github.com/lirm/aeron-go/aeron/idlestrategy"
 	"go.uber.org/zap/zapcore"
 )
@@ -15,6 +16,11 @@ type Options struct {
 	EgressStreamId     int32
 	IdleStrategy       idlestrategy.Idler
 	IsIngressExclusive bool
+	// CredentialsSupplier provides the credentials sent with the session connect request and
+	// answers any challenge from the cluster
+	CredentialsSupplier aeron.CredentialsSupplier
+	// ReconnectPolicy, if set, establishes a new session when the session is lost rather than closing
+	ReconnectPolicy *ReconnectPolicy
 }
 
 func NewOptions() *Options {
@@ -25,5 +31,7 @@ func NewOptions() *Options {
 		EgressChannel:   "aeron:udp?alias=cluster-egress|endpoint=localhost:0",
 		EgressStreamId:  102,
 		IdleStrategy:    idlestrategy.NewDefaultBackoffIdleStrategy(),
+
+		CredentialsSupplier: aeron.NullCredentialsSupplier{},
 	}
 }
@@ -0,0 +1,205 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/cluster"
+)
+
+// ReconnectPolicy enables an AeronCluster to establish a new session
+// when its session is lost, rather than becoming closed. The session is
+// lost when the cluster closes it, for example on session timeout
+// during a long election, or when the ingress publication disconnects.
+//
+// Reconnection attempts back off exponentially from InitialBackoff to
+// MaxBackoff. The leader last known is tried first and redirects are
+// followed as for the initial connection.
+type ReconnectPolicy struct {
+	InitialBackoff   time.Duration // Delay before the first reconnection attempt
+	MaxBackoff       time.Duration // Limit on the delay between attempts
+	MaxAttempts      int           // Attempts before giving up and closing, 0 for no limit
+	BufferOffers     bool          // Buffer messages offered while reconnecting and send them on the new session, otherwise Offer() returns NotConnected
+	MaxBufferedBytes int           // Limit on the buffered messages, further offers return BackPressured
+
+	// OnReconnect is called, if set, once a new session is established
+	// following the loss of the previous one, before EgressListener.OnConnect.
+	OnReconnect func(cluster *AeronCluster, previousClusterSessionId, clusterSessionId int64)
+}
+
+// NewReconnectPolicy returns a ReconnectPolicy with default values which
+// retries indefinitely and does not buffer offers
+func NewReconnectPolicy() *ReconnectPolicy {
+	return &ReconnectPolicy{
+		InitialBackoff:   100 * time.Millisecond,
+		MaxBackoff:       10 * time.Second,
+		MaxBufferedBytes: 1024 * 1024,
+	}
+}
+
+// reconnectState tracks a reconnection in progress
+type reconnectState struct {
+	previousClusterSessionId int64
+	attempts                 int
+	backoff                  time.Duration
+	pendingOffers            [][]byte
+	pendingBytes             int
+}
+
+// IsReconnecting is true while a new session is being established after session loss
+func (ac *AeronCluster) IsReconnecting() bool {
+	return ac.reconnect != nil
+}
+
+// startReconnect on session loss, if there is a ReconnectPolicy, otherwise the client is closed
+func (ac *AeronCluster) startReconnect(details string) {
+	ac.egressListener.OnDisconnect(ac, details)
+	policy := ac.opts.ReconnectPolicy
+	if policy == nil {
+		ac.state = clientClosed
+		return
+	}
+	logger.Infof("session lost, reconnecting - clusterSessionId=%d (%s)", ac.clusterSessionId, details)
+	ac.reconnect = &reconnectState{
+		previousClusterSessionId: ac.clusterSessionId,
+		backoff:                  policy.InitialBackoff,
+	}
+	ac.clusterSessionId = cluster.NullValue
+	ac.closeIngressPublications()
+	ac.fragmentAssembler.Clear()
+	ac.state = clientDisconnected
+	ac.nextRetryConnectTime = time.Now().Add(policy.InitialBackoff).UnixMilli()
+}
+
+// scheduleRetry of the connection after a failed attempt, backing off if reconnecting
+func (ac *AeronCluster) scheduleRetry(delay time.Duration) {
+	ac.state = clientDisconnected
+	if reconnect := ac.reconnect; reconnect != nil {
+		policy := ac.opts.ReconnectPolicy
+		reconnect.attempts++
+		if policy.MaxAttempts > 0 && reconnect.attempts >= policy.MaxAttempts {
+			ac.reconnect = nil
+			ac.state = clientClosed
+			ac.closeIngressPublications()
+			ac.closeEgressSubscription()
+			ac.egressListener.OnError(ac, fmt.Sprintf("reconnect failed after %d attempts", reconnect.attempts))
+			return
+		}
+		reconnect.backoff *= 2
+		if reconnect.backoff > policy.MaxBackoff {
+			reconnect.backoff = policy.MaxBackoff
+		}
+		delay = reconnect.backoff
+	}
+	ac.nextRetryConnectTime = time.Now().Add(delay).UnixMilli()
+}
+
+// onReconnected completes a reconnection once the new session is established
+func (ac *AeronCluster) onReconnected() {
+	reconnect := ac.reconnect
+	if reconnect == nil {
+		return
+	}
+	ac.reconnect = nil
+	ac.pendingOffers = reconnect.pendingOffers
+	logger.Infof("reconnected - previousClusterSessionId=%d clusterSessionId=%d",
+		reconnect.previousClusterSessionId, ac.clusterSessionId)
+	if policy := ac.opts.ReconnectPolicy; policy.OnReconnect != nil {
+		policy.OnReconnect(ac, reconnect.previousClusterSessionId, ac.clusterSessionId)
+	}
+}
+
+// bufferOffer while reconnecting if the policy allows
+func (ac *AeronCluster) bufferOffer(buffer *atomic.Buffer, offset, length int32) int64 {
+	return ac.bufferOfferV([]atomic.BufferVector{{Buffer: buffer, Offset: offset, Length: length}})
+}
+
+// bufferOfferV gathers the vectors into one buffered offer while reconnecting if the policy allows
+func (ac *AeronCluster) bufferOfferV(vectors []atomic.BufferVector) int64 {
+	reconnect := ac.reconnect
+	if reconnect == nil || !ac.opts.ReconnectPolicy.BufferOffers {
+		return aeron.NotConnected
+	}
+	length := atomic.VectorsLength(vectors)
+	if reconnect.pendingBytes+int(length) > ac.opts.ReconnectPolicy.MaxBufferedBytes {
+		return aeron.BackPressured
+	}
+	msg := make([]byte, 0, length)
+	for _, vector := range vectors {
+		msg = append(msg, vector.Buffer.GetBytesArray(vector.Offset, vector.Length)...)
+	}
+	reconnect.pendingOffers = append(reconnect.pendingOffers, msg)
+	reconnect.pendingBytes += int(length)
+	return 0
+}
+
+// hasUnsentPendingOffers tries to send the offers buffered while reconnecting,
+// returning true if some remain, so that new messages are not sent out of order
+func (ac *AeronCluster) hasUnsentPendingOffers() bool {
+	if len(ac.pendingOffers) == 0 {
+		return false
+	}
+	ac.sendPendingOffers()
+	return len(ac.pendingOffers) > 0
+}
+
+// sendPendingOffers buffered during reconnection, stopping if back pressured
+func (ac *AeronCluster) sendPendingOffers() int {
+	sent := 0
+	hdrBuf := ac.sessionMsgHdrBuffer
+	for len(ac.pendingOffers) > 0 {
+		var result int64
+		if len(ac.pendingOffers[0]) == 0 {
+			// An empty message is sent as the session header alone
+			result = ac.ingressPub.Offer(hdrBuf, 0, hdrBuf.Capacity(), nil)
+		} else {
+			msg := atomic.NewBufferSlice(ac.pendingOffers[0])
+			result = ac.ingressPub.Offer2(hdrBuf, 0, hdrBuf.Capacity(), msg, 0, msg.Capacity(), nil)
+		}
+		if result < 0 {
+			break
+		}
+		ac.pendingOffers[0] = nil
+		ac.pendingOffers = ac.pendingOffers[1:]
+		sent++
+	}
+	if len(ac.pendingOffers) == 0 {
+		ac.pendingOffers = nil
+	}
+	return sent
+}
+
+func (ac *AeronCluster) closeIngressPublications() {
+	for _, member := range ac.memberByIdMap {
+		member.close()
+	}
+	if ac.ingressPub != nil && len(ac.memberByIdMap) == 0 {
+		if err := ac.ingressPub.Close(); err != nil {
+			logger.Debugf("error closing ingress publication: %v", err)
+		}
+	}
+	ac.ingressPub = nil
+}
+
+func (ac *AeronCluster) closeEgressSubscription() {
+	if ac.egressSub != nil {
+		if err := ac.egressSub.Close(); err != nil {
+			logger.Debugf("error closing egress subscription: %v", err)
+		}
+		ac.egressSub = nil
+	}
+}
@@ -15,6 +15,7 @@ package cluster
 import (
 	"github.com/lirm/aeron-go/aeron"
 	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
 	"github.com/lirm/aeron-go/aeron/logbuffer/term"
 )
 
@@ -27,13 +28,15 @@ type ClientSession interface {
 	ResponseStreamId() int32
 	ResponseChannel() string
 	EncodedPrincipal() []byte
+	// Close the session via the consensus module, so that all members agree on when it is closed
 	Close()
-	// TODO: the other close methods are not part of interface.
-	// I don't understand the closing bool implementation and why it is needed
-	// Leaving out for now unless it is really important
-	// IsClosing() bool
+	// IsClosing is true once a close has been requested and until the session close event arrives
+	IsClosing() bool
 	Offer(*atomic.Buffer, int32, int32, term.ReservedValueSupplier) int64
-	// TryClaim(...)
+	// TryClaim length bytes for a message to the client. The claimed range begins with the
+	// session message header, so the message must be written at
+	// bufferClaim.Offset() + SBEHeaderLength + SessionMessageHeaderLength
+	TryClaim(length int32, bufferClaim *logbuffer.Claim) int64
 }
 
 type containerClientSession struct {
@@ -43,6 +46,7 @@ type containerClientSession struct {
 	encodedPrincipal []byte
 	agent            *ClusteredServiceAgent
 	response         *aeron.Publication
+	isClosing        bool
 }
 
 func newContainerClientSession(
@@ -83,11 +87,23 @@ func (s *containerClientSession) EncodedPrincipal() []byte {
 }
 
 func (s *containerClientSession) Close() {
-	if _, ok := s.agent.getClientSession(s.id); ok {
-		s.agent.closeClientSession(s.id)
+	if _, ok := s.agent.GetClientSession(s.id); ok {
+		s.agent.CloseClientSession(s.id)
 	}
 }
 
+func (s *containerClientSession) IsClosing() bool {
+	return s.isClosing
+}
+
+func (s *containerClientSession) markClosing() {
+	s.isClosing = true
+}
+
+func (s *containerClientSession) resetClosing() {
+	s.isClosing = false
+}
+
 func (s *containerClientSession) Offer(
 	buffer *atomic.Buffer,
 	offset int32,
@@ -103,3 +119,7 @@ func (s *containerClientSession) Offer(
 		reservedValueSupplier,
 	)
 }
+
+func (s *containerClientSession) TryClaim(length int32, bufferClaim *logbuffer.Claim) int64 {
+	return s.agent.tryClaimToSession(s.id, s.response, length, bufferClaim)
+}
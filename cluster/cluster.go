@@ -1,6 +1,10 @@
 package cluster
 
-import "github.com/lirm/aeron-go/aeron/idlestrategy"
+import (
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+)
 
 type Cluster interface {
 	LogPosition() int64
@@ -12,4 +16,31 @@ type Cluster interface {
 	// ScheduleTimer schedules a timer for a given deadline
 	ScheduleTimer(correlationId int64, deadline int64) bool
 	CancelTimer(correlationId int64) bool
+
+	// Offer a message from the service to the consensus module to be appended to the log.
+	// It is delivered to the services on all members via OnSessionMessage with a nil session.
+	// Returns the new position, or a negative result such as aeron.BackPressured or
+	// aeron.NotConnected if the message was not sent
+	Offer(buffer *atomic.Buffer, offset int32, length int32) int64
+
+	// TryClaim length bytes of the log for a service message to be written directly.
+	// The claimed range begins with the session message header, so the message must
+	// be written at bufferClaim.Offset() + SBEHeaderLength + SessionMessageHeaderLength
+	// before calling Commit() or Abort()
+	TryClaim(length int32, bufferClaim *logbuffer.Claim) int64
+
+	// GetClientSession returns the open session with the given id
+	GetClientSession(id int64) (ClientSession, bool)
+	// ForEachClientSession calls fn for each open session in the order they were opened
+	ForEachClientSession(fn func(ClientSession))
+	// CloseClientSession requests that the consensus module close the session. The
+	// session remains open until OnSessionClose is called for it on all members
+	//
+	// Returns false if the session is unknown, or was not opened by the container
+	CloseClientSession(id int64) bool
+
+	// RecordError records err, with the stack of the caller, in the distinct error log
+	// of the service's mark file, where it can be read by tools such as clustertool
+	// after the service has died. It is also logged and passed to Options.ErrorHandler
+	RecordError(err error)
 }
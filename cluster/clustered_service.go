@@ -46,3 +46,10 @@ type ClusteredService interface {
 		appVersion int32,
 	)
 }
+
+// ClusterActionListener may be implemented by a ClusteredService to be told
+// when the cluster is suspended or resumed. Snapshot actions are handled by
+// the agent, which calls OnTakeSnapshot().
+type ClusterActionListener interface {
+	OnClusterAction(action codecs.ClusterActionEnum, logPosition int64)
+}
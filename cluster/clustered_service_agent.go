@@ -13,6 +13,7 @@
 package cluster
 
 import (
+	"errors"
 	"fmt"
 	"strings"
 	"time"
@@ -20,11 +21,13 @@ import (
 	"github.com/lirm/aeron-go/aeron"
 	"github.com/lirm/aeron-go/aeron/atomic"
 	"github.com/lirm/aeron-go/aeron/counters"
+	"github.com/lirm/aeron-go/aeron/errorlog"
 	"github.com/lirm/aeron-go/aeron/idlestrategy"
 	"github.com/lirm/aeron-go/aeron/logbuffer"
 	"github.com/lirm/aeron-go/aeron/logbuffer/term"
 	"github.com/lirm/aeron-go/aeron/logging"
 	"github.com/lirm/aeron-go/aeron/util"
+	"github.com/lirm/aeron-go/aeron/util/memmap"
 	"github.com/lirm/aeron-go/archive"
 	"github.com/lirm/aeron-go/cluster/codecs"
 )
@@ -41,15 +44,27 @@ const (
 
 var logger = logging.MustGetLogger("cluster")
 
+// ErrConsensusModuleNotConnected is returned by StartAndRun when the service terminated
+// because its publication to the consensus module stayed disconnected for longer than
+// Options.Timeout
+var ErrConsensusModuleNotConnected = errors.New("consensus module not connected")
+
+// ErrStartupTimeout is wrapped by the error from OnStart() when a startup phase
+// does not complete within Options.StartupTimeout
+var ErrStartupTimeout = errors.New("cluster service startup timed out")
+
 type ClusteredServiceAgent struct {
 	aeronClient              *aeron.Aeron
 	aeronCtx                 *aeron.Context
+	clientId                 int64
 	opts                     *Options
 	proxy                    *consensusModuleProxy
 	counters                 *counters.Reader
+	cncFile                  *memmap.File
 	serviceAdapter           *serviceAdapter
 	logAdapter               *boundedLogAdapter
 	markFile                 *ClusterMarkFile
+	errorLog                 *errorlog.DistinctErrorLog
 	activeLogEvent           *activeLogEvent
 	cachedTimeMs             int64
 	markFileUpdateDeadlineMs int64
@@ -60,11 +75,19 @@ type ClusteredServiceAgent struct {
 	nextAckId                int64
 	terminationPosition      int64
 	isServiceActive          bool
+	isSuspended              bool
+	isAeronClientOwner       bool
+	cmDisconnectedSinceMs    int64
+	terminationErr           error
+	startupPhase             atomic.Int
+	startupDeadline          time.Time
 	role                     Role
 	service                  ClusteredService
 	sessions                 map[int64]ClientSession
+	sessionList              []ClientSession // sessions in the order opened, for deterministic iteration
 	commitPosition           *counters.ReadableCounter
 	sessionMsgHdrBuffer      *atomic.Buffer
+	mockedClaimBuffer        *atomic.Buffer
 }
 
 func NewClusteredServiceAgent(
@@ -72,22 +95,30 @@ func NewClusteredServiceAgent(
 	options *Options,
 	service ClusteredService,
 ) (*ClusteredServiceAgent, error) {
-	if !strings.HasPrefix(options.ArchiveOptions.RequestChannel, "aeron:ipc") {
-		return nil, fmt.Errorf("archive request channel must be IPC: %s", options.ArchiveOptions.RequestChannel)
-	}
-	if !strings.HasPrefix(options.ArchiveOptions.ResponseChannel, "aeron:ipc") {
-		return nil, fmt.Errorf("archive response channel must be IPC: %s", options.ArchiveOptions.ResponseChannel)
-	}
-	if options.ServiceId < 0 || options.ServiceId > 127 {
-		return nil, fmt.Errorf("serviceId is outside allowed range (0-127): %d", options.ServiceId)
+	if err := validateOptions(options); err != nil {
+		return nil, err
 	}
 
-	logging.SetLevel(options.Loglevel, "cluster")
-
 	aeronClient, err := aeron.Connect(aeronCtx)
 	if err != nil {
 		return nil, err
 	}
+	agent, err := newClusteredServiceAgent(aeronClient, aeronCtx, options, service)
+	if err != nil {
+		aeronClient.Close()
+		return nil, err
+	}
+	agent.isAeronClientOwner = true
+	return agent, nil
+}
+
+func newClusteredServiceAgent(
+	aeronClient *aeron.Aeron,
+	aeronCtx *aeron.Context,
+	options *Options,
+	service ClusteredService,
+) (*ClusteredServiceAgent, error) {
+	logging.SetLevel(options.Loglevel, "cluster")
 
 	pub, err := aeronClient.AddPublication(options.ControlChannel, options.ConsensusModuleStreamId)
 	if err != nil {
@@ -104,30 +135,35 @@ func NewClusteredServiceAgent(
 		subscription: sub,
 	}
 	logAdapter := &boundedLogAdapter{
-		marshaller: codecs.NewSbeGoMarshaller(),
-		options:    options,
+		options: options,
 	}
 
-	counterFile, _, _ := counters.MapFile(aeronCtx.CncFileName())
+	counterFile, cncFile, err := counters.MapFile(aeronCtx.CncFileName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to map CnC file %s: %w", aeronCtx.CncFileName(), err)
+	}
 	countersReader := counters.NewReader(
 		counterFile.ValuesBuf.Get(),
 		counterFile.MetaDataBuf.Get(),
 	)
 
-	cmf, err := NewClusterMarkFile(options.ClusterDir + "/cluster-mark-service-0.dat")
+	cmf, err := NewClusterMarkFile(options.ClusterDir + "/" + MarkFileFilenameForService(options.ServiceId))
 	if err != nil {
 		return nil, err
 	}
 
 	agent := &ClusteredServiceAgent{
 		aeronClient:         aeronClient,
+		clientId:            aeronClient.ClientID(),
 		opts:                options,
 		serviceAdapter:      serviceAdapter,
 		logAdapter:          logAdapter,
 		aeronCtx:            aeronCtx,
 		proxy:               proxy,
 		counters:            countersReader,
+		cncFile:             cncFile,
 		markFile:            cmf,
+		errorLog:            errorlog.NewDistinctErrorLog(cmf.ErrorBuffer(), nil),
 		role:                Follower,
 		service:             service,
 		logPosition:         NullPosition,
@@ -153,6 +189,19 @@ func NewClusteredServiceAgent(
 	return agent, nil
 }
 
+func validateOptions(options *Options) error {
+	if !strings.HasPrefix(options.ArchiveOptions.RequestChannel, "aeron:ipc") {
+		return fmt.Errorf("archive request channel must be IPC: %s", options.ArchiveOptions.RequestChannel)
+	}
+	if !strings.HasPrefix(options.ArchiveOptions.ResponseChannel, "aeron:ipc") {
+		return fmt.Errorf("archive response channel must be IPC: %s", options.ArchiveOptions.ResponseChannel)
+	}
+	if options.ServiceId < 0 || options.ServiceId > 127 {
+		return fmt.Errorf("serviceId is outside allowed range (0-127): %d", options.ServiceId)
+	}
+	return nil
+}
+
 func (agent *ClusteredServiceAgent) StartAndRun() error {
 	if err := agent.OnStart(); err != nil {
 		return err
@@ -160,17 +209,56 @@ func (agent *ClusteredServiceAgent) StartAndRun() error {
 	for agent.isServiceActive {
 		agent.opts.IdleStrategy.Idle(agent.DoWork())
 	}
-	return nil
+	return agent.terminationErr
 }
 
+// OnStart waits for the consensus module and recovers the service's state,
+// failing if a phase does not complete within Options.StartupTimeout. On failure
+// the mark file is signalled so that the failed start is visible to tooling.
 func (agent *ClusteredServiceAgent) OnStart() error {
-	if err := agent.awaitCommitPositionCounter(); err != nil {
+	if agent.opts.StartupTimeout > 0 {
+		agent.startupDeadline = time.Now().Add(agent.opts.StartupTimeout)
+	}
+	err := agent.awaitCommitPositionCounter()
+	if err == nil {
+		err = agent.recoverState()
+	}
+	if err != nil {
+		err = fmt.Errorf("serviceId %d failed to start in phase %v: %w", agent.opts.ServiceId, agent.StartupPhase(), err)
+		agent.isServiceActive = false
+		agent.setStartupPhase(StartupFailed)
+		agent.markFile.SignalFailedStart()
+		agent.RecordError(err)
 		return err
 	}
-	return agent.recoverState()
+	agent.setStartupPhase(StartupComplete)
+	return nil
+}
+
+// StartupPhase returns the progress of OnStart(), and may be called from any goroutine
+func (agent *ClusteredServiceAgent) StartupPhase() StartupPhase {
+	return StartupPhase(agent.startupPhase.Get())
+}
+
+func (agent *ClusteredServiceAgent) setStartupPhase(phase StartupPhase) {
+	agent.startupPhase.Set(int32(phase))
+	logger.Debugf("serviceId %d startup phase: %v", agent.opts.ServiceId, phase)
+	if agent.opts.StartupPhaseHandler != nil {
+		agent.opts.StartupPhaseHandler(phase)
+	}
+}
+
+// checkStartupDeadline returns an error wrapping ErrStartupTimeout, describing what
+// was awaited, once the startup deadline has passed
+func (agent *ClusteredServiceAgent) checkStartupDeadline(awaiting string) error {
+	if !agent.startupDeadline.IsZero() && time.Now().After(agent.startupDeadline) {
+		return fmt.Errorf("%w after %v: %s", ErrStartupTimeout, agent.opts.StartupTimeout, awaiting)
+	}
+	return nil
 }
 
 func (agent *ClusteredServiceAgent) awaitCommitPositionCounter() error {
+	agent.setStartupPhase(StartupAwaitingCommitPosition)
 	for {
 		id := agent.counters.FindCounter(commitPosCounterTypeId, func(keyBuffer *atomic.Buffer) bool {
 			return keyBuffer.GetInt32(0) == agent.opts.ClusterId
@@ -181,18 +269,25 @@ func (agent *ClusteredServiceAgent) awaitCommitPositionCounter() error {
 			agent.commitPosition = commitPos
 			return err
 		}
+		if err := agent.checkStartupDeadline(fmt.Sprintf(
+			"commit position counter for clusterId=%d, is the consensus module running?", agent.opts.ClusterId)); err != nil {
+			return err
+		}
 		agent.Idle(0)
 	}
 }
 
 func (agent *ClusteredServiceAgent) recoverState() error {
-	counterId, leadershipTermId := agent.awaitRecoveryCounter()
+	counterId, leadershipTermId, err := agent.awaitRecoveryCounter()
+	if err != nil {
+		return err
+	}
 	logger.Debugf("found recovery counter - id=%d leadershipTermId=%d logPos=%d clusterTime=%d",
 		counterId, leadershipTermId, agent.logPosition, agent.clusterTime)
 	agent.sessionMsgHdrBuffer.PutInt64(SBEHeaderLength, leadershipTermId)
-	agent.isServiceActive = true
 
 	if leadershipTermId == -1 {
+		agent.isServiceActive = true
 		agent.service.OnStart(agent, nil)
 	} else {
 		serviceCount, err := agent.counters.GetKeyPartInt32(counterId, 28)
@@ -202,6 +297,9 @@ func (agent *ClusteredServiceAgent) recoverState() error {
 		if serviceCount < 1 {
 			return fmt.Errorf("invalid service count: %d", serviceCount)
 		}
+		if agent.opts.ServiceId >= serviceCount {
+			return fmt.Errorf("serviceId %d is not within the service count: %d", agent.opts.ServiceId, serviceCount)
+		}
 		snapshotRecId, err := agent.counters.GetKeyPartInt64(counterId, 32+(agent.opts.ServiceId*util.SizeOfInt64))
 		if err != nil {
 			return err
@@ -215,13 +313,14 @@ func (agent *ClusteredServiceAgent) recoverState() error {
 		agent.logPosition,
 		agent.clusterTime,
 		agent.getAndIncrementNextAckId(),
-		agent.aeronClient.ClientID(),
+		agent.clientId,
 		agent.opts.ServiceId,
 	)
 	return nil
 }
 
-func (agent *ClusteredServiceAgent) awaitRecoveryCounter() (int32, int64) {
+func (agent *ClusteredServiceAgent) awaitRecoveryCounter() (int32, int64, error) {
+	agent.setStartupPhase(StartupAwaitingRecoveryState)
 	for {
 		var leadershipTermId int64
 		id := agent.counters.FindCounter(recoveryStateCounterTypeId, func(keyBuffer *atomic.Buffer) bool {
@@ -234,13 +333,18 @@ func (agent *ClusteredServiceAgent) awaitRecoveryCounter() (int32, int64) {
 			return false
 		})
 		if id != counters.NullCounterId {
-			return id, leadershipTermId
+			return id, leadershipTermId, nil
+		}
+		if err := agent.checkStartupDeadline(fmt.Sprintf(
+			"recovery state counter for clusterId=%d", agent.opts.ClusterId)); err != nil {
+			return NullValue, NullValue, err
 		}
 		agent.Idle(0)
 	}
 }
 
 func (agent *ClusteredServiceAgent) loadSnapshot(recordingId int64) error {
+	agent.setStartupPhase(StartupLoadingSnapshot)
 	arch, err := archive.NewArchive(agent.opts.ArchiveOptions, agent.aeronCtx)
 	if err != nil {
 		return err
@@ -266,23 +370,62 @@ func (agent *ClusteredServiceAgent) loadSnapshot(recordingId int64) error {
 	}
 	defer closeSubscription(subscription)
 
-	img := agent.awaitImage(int32(replaySessionId), subscription)
-	loader := newSnapshotLoader(agent, img)
-	for !loader.isDone {
-		agent.opts.IdleStrategy.Idle(loader.poll())
+	img, err := agent.awaitImage(int32(replaySessionId), subscription)
+	if err != nil {
+		return fmt.Errorf("snapshot replay of recordingId=%d: %w", recordingId, err)
+	}
+	loader, err := agent.replaySnapshot(recordingId, img)
+	if err != nil {
+		return err
 	}
 	if util.SemanticVersionMajor(uint32(agent.opts.AppVersion)) != util.SemanticVersionMajor(uint32(loader.appVersion)) {
-		panic(fmt.Errorf("incompatible app version: %v snapshot=%v",
+		return fmt.Errorf("incompatible app version: %v snapshot=%v",
 			util.SemanticVersionToString(uint32(agent.opts.AppVersion)),
-			util.SemanticVersionToString(uint32(loader.appVersion))))
+			util.SemanticVersionToString(uint32(loader.appVersion)))
 	}
 	agent.timeUnit = loader.timeUnit
+	agent.isServiceActive = true
 	agent.service.OnStart(agent, img)
 	return nil
 }
 
+// replaySnapshot polls img until the end of the snapshot, failing if the image
+// closes first or the startup deadline passes
+func (agent *ClusteredServiceAgent) replaySnapshot(recordingId int64, img aeron.Image) (*snapshotLoader, error) {
+	loader := newSnapshotLoader(agent, img)
+	for !loader.isDone {
+		if img.IsClosed() {
+			return nil, fmt.Errorf("snapshot replay of recordingId=%d: image closed before the end of the snapshot", recordingId)
+		}
+		if err := agent.checkStartupDeadline(fmt.Sprintf(
+			"snapshot replay of recordingId=%d at position=%d", recordingId, img.Position())); err != nil {
+			return nil, err
+		}
+		agent.opts.IdleStrategy.Idle(loader.poll())
+	}
+	if loader.err != nil {
+		return nil, fmt.Errorf("snapshot replay of recordingId=%d: %w", recordingId, loader.err)
+	}
+	return loader, nil
+}
+
 func (agent *ClusteredServiceAgent) addSessionFromSnapshot(session *containerClientSession) {
-	agent.sessions[session.id] = session
+	agent.addSession(session)
+}
+
+func (agent *ClusteredServiceAgent) addSession(session ClientSession) {
+	agent.sessions[session.Id()] = session
+	agent.sessionList = append(agent.sessionList, session)
+}
+
+func (agent *ClusteredServiceAgent) removeSession(clusterSessionId int64) {
+	delete(agent.sessions, clusterSessionId)
+	for i, session := range agent.sessionList {
+		if session.Id() == clusterSessionId {
+			agent.sessionList = append(agent.sessionList[:i], agent.sessionList[i+1:]...)
+			break
+		}
+	}
 }
 
 func (agent *ClusteredServiceAgent) checkForClockTick() bool {
@@ -304,27 +447,70 @@ func (agent *ClusteredServiceAgent) pollServiceAdapter() {
 	if agent.activeLogEvent != nil && agent.logAdapter.image == nil {
 		event := agent.activeLogEvent
 		agent.activeLogEvent = nil
-		agent.joinActiveLog(event)
+		if err := agent.joinActiveLog(event); err != nil {
+			agent.RecordError(err)
+		}
 	}
 
-	if agent.terminationPosition != NullPosition && agent.logPosition >= agent.terminationPosition {
-		if agent.logPosition > agent.terminationPosition {
-			logger.Errorf("service terminate: logPos=%d > terminationPos=%d", agent.logPosition, agent.terminationPosition)
-		}
+	agent.checkForTermination()
+	agent.checkConsensusModuleConnected()
+}
+
+// checkForTermination terminates the service once the log has reached the
+// termination position given by the consensus module
+func (agent *ClusteredServiceAgent) checkForTermination() {
+	if !agent.isServiceActive || agent.terminationPosition == NullPosition ||
+		agent.logPosition < agent.terminationPosition {
+		return
+	}
+	if agent.logPosition > agent.terminationPosition {
+		agent.RecordError(fmt.Errorf("service terminate: logPos=%d > terminationPos=%d",
+			agent.logPosition, agent.terminationPosition))
+	}
+	agent.terminate()
+}
+
+// checkConsensusModuleConnected terminates the service if the consensus module
+// has been gone for longer than the timeout, so a service does not outlive a
+// consensus module which shut down without sending a termination position
+func (agent *ClusteredServiceAgent) checkConsensusModuleConnected() {
+	if !agent.isServiceActive {
+		return
+	}
+	if agent.proxy.publication.IsConnected() {
+		agent.cmDisconnectedSinceMs = 0
+		return
+	}
+	if agent.cmDisconnectedSinceMs == 0 {
+		agent.cmDisconnectedSinceMs = agent.cachedTimeMs
+	} else if agent.cachedTimeMs-agent.cmDisconnectedSinceMs > agent.opts.Timeout.Milliseconds() {
+		agent.terminationErr = ErrConsensusModuleNotConnected
+		agent.RecordError(fmt.Errorf("serviceId %d: %w for %v",
+			agent.opts.ServiceId, ErrConsensusModuleNotConnected, agent.opts.Timeout))
 		agent.terminate()
 	}
 }
 
+// terminate calls OnTerminate and then acknowledges the termination to the
+// consensus module with a service ack at the termination log position. The ack is
+// bounded by the timeout as the consensus module may already have shut down.
 func (agent *ClusteredServiceAgent) terminate() {
 	agent.isServiceActive = false
 	agent.service.OnTerminate(agent)
-	agent.proxy.serviceAckRequest(
-		agent.logPosition,
-		agent.clusterTime,
-		agent.getAndIncrementNextAckId(),
-		NullValue,
-		agent.opts.ServiceId,
-	)
+	if agent.terminationErr == nil {
+		sent := agent.proxy.serviceAckWithTimeout(
+			agent.logPosition,
+			agent.clusterTime,
+			agent.getAndIncrementNextAckId(),
+			NullValue,
+			agent.opts.ServiceId,
+			agent.opts.Timeout,
+		)
+		if !sent {
+			agent.RecordError(fmt.Errorf("serviceId %d: failed to ack termination at logPos=%d",
+				agent.opts.ServiceId, agent.logPosition))
+		}
+	}
 	agent.terminationPosition = NullPosition
 }
 
@@ -341,11 +527,29 @@ func (agent *ClusteredServiceAgent) DoWork() int {
 		if polled == 0 && agent.logAdapter.isDone() {
 			agent.closeLog()
 		}
+		agent.checkForTermination()
 	}
 
 	return work
 }
 
+// Close releases the publications, subscriptions and mark file of the agent, and
+// the Aeron client if the agent connected it
+func (agent *ClusteredServiceAgent) Close() error {
+	var errs []error
+	if agent.logAdapter.image != nil {
+		errs = append(errs, agent.logAdapter.Close())
+	}
+	errs = append(errs, agent.serviceAdapter.subscription.Close())
+	errs = append(errs, agent.proxy.publication.Close())
+	errs = append(errs, agent.markFile.Close())
+	errs = append(errs, agent.cncFile.Close())
+	if agent.isAeronClientOwner {
+		errs = append(errs, agent.aeronClient.Close())
+	}
+	return errors.Join(errs...)
+}
+
 func (agent *ClusteredServiceAgent) onJoinLog(
 	logPosition int64,
 	maxLogPosition int64,
@@ -387,7 +591,11 @@ func (agent *ClusteredServiceAgent) joinActiveLog(event *activeLogEvent) error {
 	if err != nil {
 		return err
 	}
-	img := agent.awaitImage(event.logSessionId, logSub)
+	img, err := agent.awaitImage(event.logSessionId, logSub)
+	if err != nil {
+		closeSubscription(logSub)
+		return fmt.Errorf("joinActiveLog - logSessionId=%d: %w", event.logSessionId, err)
+	}
 	if img.Position() != agent.logPosition {
 		return fmt.Errorf("joinActiveLog - image.position=%v expected=%v", img.Position(), agent.logPosition)
 	}
@@ -418,7 +626,7 @@ func (agent *ClusteredServiceAgent) closeLog() {
 		agent.logPosition = imageLogPos
 	}
 	if err := agent.logAdapter.Close(); err != nil {
-		logger.Errorf("error closing log image: %v", err)
+		agent.RecordError(fmt.Errorf("error closing log image: %w", err))
 	}
 	agent.setRole(Follower)
 }
@@ -433,10 +641,15 @@ func (agent *ClusteredServiceAgent) setRole(newRole Role) {
 func (agent *ClusteredServiceAgent) awaitImage(
 	sessionId int32,
 	subscription *aeron.Subscription,
-) aeron.Image {
+) (aeron.Image, error) {
+	deadline := time.Now().Add(agent.opts.Timeout)
 	for {
 		if img := subscription.ImageBySessionID(sessionId); img != nil {
-			return img
+			return img, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("no image for sessionId=%d streamId=%d channel=%s within %v",
+				sessionId, subscription.StreamID(), subscription.Channel(), agent.opts.Timeout)
 		}
 		agent.opts.IdleStrategy.Idle(0)
 	}
@@ -470,7 +683,7 @@ func (agent *ClusteredServiceAgent) onSessionOpen(
 		// TODO: looks like we only want to connect if this is the leader
 		// currently always connecting
 
-		agent.sessions[session.id] = session
+		agent.addSession(session)
 		agent.service.OnSessionOpen(session, timestamp)
 	}
 	return nil
@@ -487,7 +700,7 @@ func (agent *ClusteredServiceAgent) onSessionClose(
 	agent.clusterTime = timestamp
 
 	if session, ok := agent.sessions[clusterSessionId]; ok {
-		delete(agent.sessions, clusterSessionId)
+		agent.removeSession(clusterSessionId)
 		agent.service.OnSessionClose(session, timestamp, closeReason)
 	} else {
 		logger.Errorf("onSessionClose: unknown session - id=%d leaderTermId=%d logPos=%d reason=%v",
@@ -521,15 +734,24 @@ func (agent *ClusteredServiceAgent) onNewLeadershipTermEvent(
 	appVersion int32,
 ) {
 	if util.SemanticVersionMajor(uint32(agent.opts.AppVersion)) != util.SemanticVersionMajor(uint32(appVersion)) {
-		panic(fmt.Errorf("incompatible app version: %v log=%v",
+		err := fmt.Errorf("incompatible app version: %v log=%v",
 			util.SemanticVersionToString(uint32(agent.opts.AppVersion)),
-			util.SemanticVersionToString(uint32(appVersion))))
+			util.SemanticVersionToString(uint32(appVersion)))
+		agent.RecordError(err)
+		panic(err)
 	}
 	agent.sessionMsgHdrBuffer.PutInt64(SBEHeaderLength, leadershipTermId)
 	agent.logPosition = logPosition
 	agent.clusterTime = timestamp
 	agent.timeUnit = timeUnit
 
+	// Close requests sent to a previous leader may have been lost
+	for _, session := range agent.sessionList {
+		if containerSession, ok := session.(*containerClientSession); ok {
+			containerSession.resetClosing()
+		}
+	}
+
 	agent.service.OnNewLeadershipTermEvent(
 		leadershipTermId,
 		logPosition,
@@ -546,17 +768,37 @@ func (agent *ClusteredServiceAgent) onServiceAction(
 	logPos int64,
 	timestamp int64,
 	action codecs.ClusterActionEnum,
+	flags int32,
 ) {
 	agent.logPosition = logPos
 	agent.clusterTime = timestamp
-	if action == codecs.ClusterAction.SNAPSHOT {
+	switch action {
+	case codecs.ClusterAction.SNAPSHOT:
+		if !agent.shouldSnapshot(flags) {
+			logger.Debugf("onServiceAction - skipping snapshot with flags=%d logPos=%d", flags, logPos)
+			return
+		}
 		recordingId, err := agent.takeSnapshot(logPos, leadershipTermId)
 		if err != nil {
-			logger.Errorf("take snapshot failed: ", err)
+			agent.RecordError(fmt.Errorf("take snapshot failed: %w", err))
 		} else {
 			agent.proxy.serviceAckRequest(logPos, timestamp, agent.getAndIncrementNextAckId(), recordingId, agent.opts.ServiceId)
 		}
+	case codecs.ClusterAction.SUSPEND, codecs.ClusterAction.RESUME:
+		agent.isSuspended = action == codecs.ClusterAction.SUSPEND
+		if listener, ok := agent.service.(ClusterActionListener); ok {
+			listener.OnClusterAction(action, logPos)
+		}
+	default:
+		agent.RecordError(fmt.Errorf("onServiceAction - unknown action=%v flags=%d logPos=%d", action, flags, logPos))
+	}
+}
+
+func (agent *ClusteredServiceAgent) shouldSnapshot(flags int32) bool {
+	if flags == ClusterActionFlagsDefault {
+		return true
 	}
+	return flags&ClusterActionFlagsStandbySnapshot != 0 && agent.opts.StandbySnapshotEnabled
 }
 
 func (agent *ClusteredServiceAgent) onTimerEvent(
@@ -605,7 +847,7 @@ func (agent *ClusteredServiceAgent) takeSnapshot(logPos int64, leadershipTermId
 	if err := snapshotTaker.markBegin(logPos, leadershipTermId, agent.timeUnit, agent.opts.AppVersion); err != nil {
 		return 0, err
 	}
-	for _, session := range agent.sessions {
+	for _, session := range agent.sessionList {
 		if err := snapshotTaker.snapshotSession(session); err != nil {
 			return 0, err
 		}
@@ -666,18 +908,30 @@ func (agent *ClusteredServiceAgent) offerToSession(
 	return publication.Offer2(hdrBuf, 0, hdrBuf.Capacity(), buffer, offset, length, reservedValueSupplier)
 }
 
-func (agent *ClusteredServiceAgent) getClientSession(id int64) (ClientSession, bool) {
-	session, ok := agent.sessions[id]
-	return session, ok
-}
+func (agent *ClusteredServiceAgent) tryClaimToSession(
+	clusterSessionId int64,
+	publication *aeron.Publication,
+	length int32,
+	bufferClaim *logbuffer.Claim,
+) int64 {
+	hdrBuf := agent.sessionMsgHdrBuffer
+	if agent.role != Leader {
+		// Give the service somewhere to write its message that is never sent
+		claimLength := logbuffer.DataFrameHeader.Length + hdrBuf.Capacity() + length
+		if agent.mockedClaimBuffer == nil || agent.mockedClaimBuffer.Capacity() < claimLength {
+			agent.mockedClaimBuffer = atomic.NewBufferSlice(make([]byte, claimLength))
+		}
+		bufferClaim.Wrap(agent.mockedClaimBuffer, 0, claimLength)
+		return ClientSessionMockedOffer
+	}
 
-func (agent *ClusteredServiceAgent) closeClientSession(id int64) {
-	if _, ok := agent.sessions[id]; ok {
-		// TODO: check if session already closed
-		agent.proxy.closeSessionRequest(id)
-	} else {
-		logger.Errorf("closeClientSession: unknown session id=%d", id)
+	hdrBuf.PutInt64(SBEHeaderLength+8, clusterSessionId)
+	hdrBuf.PutInt64(SBEHeaderLength+16, agent.clusterTime)
+	result := publication.TryClaim(hdrBuf.Capacity()+length, bufferClaim)
+	if result > 0 {
+		bufferClaim.Buffer().PutBytes(bufferClaim.Offset(), hdrBuf, 0, hdrBuf.Capacity())
 	}
+	return result
 }
 
 func closeArchive(arch *archive.Archive) {
@@ -710,6 +964,11 @@ func (agent *ClusteredServiceAgent) Idle(workCount int) {
 
 // BEGIN CLUSTER IMPLEMENTATION
 
+// IsSuspended is true while the cluster is suspended by a ClusterAction.SUSPEND
+func (agent *ClusteredServiceAgent) IsSuspended() bool {
+	return agent.isSuspended
+}
+
 func (agent *ClusteredServiceAgent) LogPosition() int64 {
 	return agent.logPosition
 }
@@ -738,4 +997,55 @@ func (agent *ClusteredServiceAgent) CancelTimer(correlationId int64) bool {
 	return agent.proxy.cancelTimer(correlationId)
 }
 
+func (agent *ClusteredServiceAgent) Offer(buffer *atomic.Buffer, offset int32, length int32) int64 {
+	hdrBuf := agent.sessionMsgHdrBuffer
+	hdrBuf.PutInt64(SBEHeaderLength+8, int64(agent.opts.ServiceId))
+	return agent.proxy.offerServiceMessage(hdrBuf, buffer, offset, length)
+}
+
+func (agent *ClusteredServiceAgent) TryClaim(length int32, bufferClaim *logbuffer.Claim) int64 {
+	hdrBuf := agent.sessionMsgHdrBuffer
+	hdrBuf.PutInt64(SBEHeaderLength+8, int64(agent.opts.ServiceId))
+	return agent.proxy.tryClaimServiceMessage(hdrBuf.Capacity()+length, bufferClaim, hdrBuf)
+}
+
+func (agent *ClusteredServiceAgent) GetClientSession(id int64) (ClientSession, bool) {
+	session, ok := agent.sessions[id]
+	return session, ok
+}
+
+func (agent *ClusteredServiceAgent) ForEachClientSession(fn func(ClientSession)) {
+	for _, session := range agent.sessionList {
+		fn(session)
+	}
+}
+
+func (agent *ClusteredServiceAgent) CloseClientSession(id int64) bool {
+	session, ok := agent.sessions[id]
+	if !ok {
+		logger.Errorf("closeClientSession: unknown session id=%d", id)
+		return false
+	}
+	containerSession, ok := session.(*containerClientSession)
+	if !ok {
+		logger.Errorf("closeClientSession: session id=%d was not opened by the container", id)
+		return false
+	}
+	if !containerSession.IsClosing() {
+		agent.proxy.closeSessionRequest(id)
+		containerSession.markClosing()
+	}
+	return true
+}
+
+func (agent *ClusteredServiceAgent) RecordError(err error) {
+	logger.Errorf("%v", err)
+	if !agent.errorLog.Record(err) {
+		logger.Warningf("mark file error buffer full, dropped error: %v", err)
+	}
+	if agent.opts.ErrorHandler != nil {
+		agent.opts.ErrorHandler(err)
+	}
+}
+
 // END CLUSTER IMPLEMENTATION
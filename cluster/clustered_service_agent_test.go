@@ -0,0 +1,533 @@
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/counters"
+	"github.com/lirm/aeron-go/aeron/errorlog"
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/util"
+	"github.com/lirm/aeron-go/cluster/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testService struct {
+	actions     []codecs.ClusterActionEnum
+	terminated  int
+	roleChanges []Role
+	onTerminate func()
+}
+
+func (s *testService) OnStart(cluster Cluster, image aeron.Image)           {}
+func (s *testService) OnSessionOpen(session ClientSession, timestamp int64) {}
+func (s *testService) OnSessionClose(session ClientSession, timestamp int64, closeReason codecs.CloseReasonEnum) {
+}
+func (s *testService) OnSessionMessage(session ClientSession, timestamp int64, buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+}
+func (s *testService) OnTimerEvent(correlationId, timestamp int64)   {}
+func (s *testService) OnTakeSnapshot(publication *aeron.Publication) {}
+func (s *testService) OnRoleChange(role Role)                        { s.roleChanges = append(s.roleChanges, role) }
+func (s *testService) OnTerminate(cluster Cluster) {
+	s.terminated++
+	if s.onTerminate != nil {
+		s.onTerminate()
+	}
+}
+func (s *testService) OnClusterAction(action codecs.ClusterActionEnum, logPosition int64) {
+	s.actions = append(s.actions, action)
+}
+func (s *testService) OnNewLeadershipTermEvent(
+	leadershipTermId int64,
+	logPosition int64,
+	timestamp int64,
+	termBaseLogPosition int64,
+	leaderMemberId int32,
+	logSessionId int32,
+	timeUnit codecs.ClusterTimeUnitEnum,
+	appVersion int32,
+) {
+}
+
+// testAgent is an agent whose publication to the consensus module is an
+// in-memory stream, so the messages it sends can be read back from
+// consensusModule, and whose recorded errors are collected
+type testAgent struct {
+	*ClusteredServiceAgent
+	service         *testService
+	publication     *aeron.Publication
+	consensusModule aeron.Image
+	errs            []error
+}
+
+func newTestAgent(t *testing.T) *testAgent {
+	opts := NewOptions()
+	opts.ClusterDir = t.TempDir()
+	opts.ServiceId = 1
+	opts.Timeout = 50 * time.Millisecond
+	opts.IdleStrategy = idlestrategy.Busy{}
+	pub, image := aeron.NewInMemoryStream(opts.ControlChannel, opts.ConsensusModuleStreamId, 1, logbuffer.TermMinLength)
+
+	markFile, err := NewClusterMarkFile(filepath.Join(opts.ClusterDir, MarkFileFilenameForService(opts.ServiceId)))
+	require.NoError(t, err)
+	t.Cleanup(func() { markFile.Close() })
+
+	ta := &testAgent{service: &testService{}, publication: pub, consensusModule: image}
+	opts.ErrorHandler = func(err error) { ta.errs = append(ta.errs, err) }
+	ta.ClusteredServiceAgent = &ClusteredServiceAgent{
+		opts:                opts,
+		proxy:               newConsensusModuleProxy(opts, pub),
+		logAdapter:          &boundedLogAdapter{options: opts},
+		markFile:            markFile,
+		errorLog:            errorlog.NewDistinctErrorLog(markFile.ErrorBuffer(), nil),
+		role:                Follower,
+		service:             ta.service,
+		logPosition:         NullPosition,
+		terminationPosition: NullPosition,
+		sessions:            map[int64]ClientSession{},
+		sessionMsgHdrBuffer: codecs.MakeClusterMessageBuffer(SessionMessageHeaderTemplateId, SessionMessageHdrBlockLength),
+		isServiceActive:     true,
+	}
+	ta.logAdapter.agent = ta.ClusteredServiceAgent
+	ta.proxy.idleStrategy = ta.ClusteredServiceAgent
+	return ta
+}
+
+// acks returns the service acks sent to the consensus module since the last call
+func (ta *testAgent) acks(t *testing.T) []codecs.ServiceAck {
+	var acks []codecs.ServiceAck
+	marshaller := codecs.NewSbeGoMarshaller()
+	ta.consensusModule.Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+		var msgHeader codecs.MessageHeader
+		reader := bytes.NewReader(buffer.GetBytesArray(offset, length))
+		require.NoError(t, msgHeader.Decode(marshaller, reader, 0))
+		var ack codecs.ServiceAck
+		if msgHeader.TemplateId != ack.SbeTemplateId() {
+			return
+		}
+		require.NoError(t, ack.Decode(marshaller, reader, msgHeader.Version, msgHeader.BlockLength, true))
+		acks = append(acks, ack)
+	}, 100)
+	return acks
+}
+
+// fillPublication offers to the bounded in-memory stream until it is back pressured
+func (ta *testAgent) fillPublication(t *testing.T) {
+	buffer := atomic.NewBufferSlice(make([]byte, 4096))
+	for i := 0; ; i++ {
+		result := ta.publication.Offer(buffer, 0, buffer.Capacity(), nil)
+		if result == aeron.BackPressured {
+			return
+		}
+		if result == aeron.AdminAction {
+			continue
+		}
+		require.Positive(t, result)
+		require.Less(t, i, 1000)
+	}
+}
+
+func TestOnServiceAction_SuspendAndResume(t *testing.T) {
+	ta := newTestAgent(t)
+	ta.onServiceAction(2, 1024, 77, codecs.ClusterAction.SUSPEND, ClusterActionFlagsDefault)
+	assert.True(t, ta.IsSuspended())
+	assert.EqualValues(t, 1024, ta.LogPosition())
+	assert.EqualValues(t, 77, ta.Time())
+
+	ta.onServiceAction(2, 2048, 78, codecs.ClusterAction.RESUME, ClusterActionFlagsDefault)
+	assert.False(t, ta.IsSuspended())
+	assert.EqualValues(t, 2048, ta.LogPosition())
+	assert.Equal(t, []codecs.ClusterActionEnum{codecs.ClusterAction.SUSPEND, codecs.ClusterAction.RESUME}, ta.service.actions)
+	assert.Empty(t, ta.acks(t))
+	assert.Empty(t, ta.errs)
+}
+
+func TestOnServiceAction_SnapshotFlags(t *testing.T) {
+	ta := newTestAgent(t)
+	assert.True(t, ta.shouldSnapshot(ClusterActionFlagsDefault))
+	assert.False(t, ta.shouldSnapshot(ClusterActionFlagsStandbySnapshot))
+	assert.False(t, ta.shouldSnapshot(2))
+
+	// A standby snapshot is skipped, without an ack or error, unless enabled
+	ta.onServiceAction(2, 1024, 77, codecs.ClusterAction.SNAPSHOT, ClusterActionFlagsStandbySnapshot)
+	assert.EqualValues(t, 1024, ta.LogPosition())
+	assert.Empty(t, ta.acks(t))
+	assert.Empty(t, ta.errs)
+	assert.Empty(t, ta.service.actions)
+
+	ta.opts.StandbySnapshotEnabled = true
+	assert.True(t, ta.shouldSnapshot(ClusterActionFlagsStandbySnapshot))
+	assert.True(t, ta.shouldSnapshot(ClusterActionFlagsStandbySnapshot|2))
+	assert.False(t, ta.shouldSnapshot(2))
+}
+
+func TestOnServiceAction_Unknown(t *testing.T) {
+	ta := newTestAgent(t)
+	ta.onServiceAction(2, 1024, 77, codecs.ClusterActionEnum(99), ClusterActionFlagsDefault)
+	require.Len(t, ta.errs, 1)
+	assert.Contains(t, ta.errs[0].Error(), "unknown action")
+	assert.False(t, ta.IsSuspended())
+	assert.Empty(t, ta.service.actions)
+}
+
+func TestCheckForTermination_AtLogPosition(t *testing.T) {
+	ta := newTestAgent(t)
+	ta.logPosition = 1024
+	ta.clusterTime = 77
+	ta.checkForTermination()
+	assert.Zero(t, ta.service.terminated, "no termination position")
+
+	ta.onServiceTerminationPosition(2048)
+	ta.checkForTermination()
+	assert.Zero(t, ta.service.terminated, "log is before the termination position")
+
+	ta.logPosition = 2048
+	ta.checkForTermination()
+	assert.Equal(t, 1, ta.service.terminated)
+	assert.False(t, ta.isServiceActive)
+	assert.EqualValues(t, NullPosition, ta.terminationPosition)
+	assert.Empty(t, ta.errs)
+
+	acks := ta.acks(t)
+	require.Len(t, acks, 1)
+	assert.Equal(t, codecs.ServiceAck{LogPosition: 2048, Timestamp: 77, AckId: 0, RelevantId: NullValue, ServiceId: 1}, acks[0])
+
+	// Only terminates once
+	ta.onServiceTerminationPosition(2048)
+	ta.checkForTermination()
+	assert.Equal(t, 1, ta.service.terminated)
+}
+
+func TestCheckForTermination_BeyondLogPosition(t *testing.T) {
+	ta := newTestAgent(t)
+	ta.onServiceTerminationPosition(1024)
+	ta.logPosition = 2048
+	ta.checkForTermination()
+
+	assert.Equal(t, 1, ta.service.terminated)
+	require.Len(t, ta.errs, 1)
+	assert.Contains(t, ta.errs[0].Error(), "logPos=2048 > terminationPos=1024")
+	acks := ta.acks(t)
+	require.Len(t, acks, 1)
+	assert.EqualValues(t, 2048, acks[0].LogPosition)
+
+	count := errorlog.Read(ta.markFile.ErrorBuffer(), func(int32, int64, int64, string) {}, 0)
+	assert.Equal(t, 1, count)
+}
+
+func TestRecordError(t *testing.T) {
+	ta := newTestAgent(t)
+	// Errors from the agent and from the service through the Cluster
+	for i := 0; i < 2; i++ {
+		ta.onServiceAction(2, 1024, 77, codecs.ClusterActionEnum(99), ClusterActionFlagsDefault)
+	}
+	var cluster Cluster = ta.ClusteredServiceAgent
+	cluster.RecordError(errors.New("service failure"))
+
+	require.Len(t, ta.errs, 3, "the handler is called for every error")
+	assert.Contains(t, ta.errs[0].Error(), "onServiceAction - unknown action")
+	assert.Equal(t, ta.errs[0], ta.errs[1])
+	assert.EqualError(t, ta.errs[2], "service failure")
+
+	var counts []int32
+	var encodedErrors []string
+	errorlog.Read(ta.markFile.ErrorBuffer(), func(count int32, first, last int64, encodedError string) {
+		counts = append(counts, count)
+		encodedErrors = append(encodedErrors, encodedError)
+	}, 0)
+	require.Len(t, encodedErrors, 2, "errors repeated from the same place are recorded once")
+	assert.Equal(t, []int32{2, 1}, counts)
+	assert.Contains(t, encodedErrors[0], ta.errs[0].Error())
+	assert.Contains(t, encodedErrors[1], "service failure")
+
+	// Without a handler errors are still recorded
+	ta.opts.ErrorHandler = nil
+	cluster.RecordError(errors.New("unhandled failure"))
+	assert.Len(t, ta.errs, 3)
+	assert.Equal(t, 3, errorlog.Read(ta.markFile.ErrorBuffer(), func(int32, int64, int64, string) {}, 0))
+}
+
+func TestTerminate_AckNotSent(t *testing.T) {
+	ta := newTestAgent(t)
+	ta.logPosition = 1024
+	ta.fillPublication(t)
+
+	start := time.Now()
+	ta.terminate()
+	assert.GreaterOrEqual(t, time.Since(start), ta.opts.Timeout)
+	assert.Equal(t, 1, ta.service.terminated)
+	assert.False(t, ta.isServiceActive)
+	require.Len(t, ta.errs, 1)
+	assert.Contains(t, ta.errs[0].Error(), "failed to ack termination at logPos=1024")
+}
+
+func TestCheckConsensusModuleConnected(t *testing.T) {
+	ta := newTestAgent(t)
+	ta.cachedTimeMs = 1000
+	ta.checkConsensusModuleConnected()
+	assert.Zero(t, ta.cmDisconnectedSinceMs)
+
+	require.NoError(t, ta.publication.Close())
+	ta.checkConsensusModuleConnected()
+	assert.EqualValues(t, 1000, ta.cmDisconnectedSinceMs)
+	assert.True(t, ta.isServiceActive)
+
+	ta.cachedTimeMs += ta.opts.Timeout.Milliseconds()
+	ta.checkConsensusModuleConnected()
+	assert.True(t, ta.isServiceActive, "not yet beyond the timeout")
+
+	ta.cachedTimeMs++
+	ta.checkConsensusModuleConnected()
+	assert.False(t, ta.isServiceActive)
+	assert.Equal(t, 1, ta.service.terminated)
+	assert.ErrorIs(t, ta.terminationErr, ErrConsensusModuleNotConnected)
+	require.Len(t, ta.errs, 1)
+	assert.ErrorIs(t, ta.errs[0], ErrConsensusModuleNotConnected)
+	assert.Empty(t, ta.acks(t), "no termination ack to a consensus module which has gone")
+
+	ta.checkConsensusModuleConnected()
+	assert.Equal(t, 1, ta.service.terminated)
+}
+
+func TestServiceAckWithTimeout(t *testing.T) {
+	ta := newTestAgent(t)
+	assert.True(t, ta.proxy.serviceAckWithTimeout(1024, 77, 5, 6, 1, time.Second))
+	acks := ta.acks(t)
+	require.Len(t, acks, 1)
+	assert.Equal(t, codecs.ServiceAck{LogPosition: 1024, Timestamp: 77, AckId: 5, RelevantId: 6, ServiceId: 1}, acks[0])
+
+	ta.fillPublication(t)
+	start := time.Now()
+	assert.False(t, ta.proxy.serviceAckWithTimeout(1024, 77, 6, 6, 1, 20*time.Millisecond))
+	assert.GreaterOrEqual(t, time.Since(start), 20*time.Millisecond)
+}
+
+func TestServiceAckWithTimeout_Closed(t *testing.T) {
+	ta := newTestAgent(t)
+	require.NoError(t, ta.publication.Close())
+	start := time.Now()
+	assert.False(t, ta.proxy.serviceAckWithTimeout(1024, 77, 5, 6, 1, time.Minute))
+	assert.Less(t, time.Since(start), time.Second, "gives up without waiting for the timeout")
+}
+
+func TestStartupPhase_NotStarted(t *testing.T) {
+	ta := newTestAgent(t)
+	assert.Equal(t, StartupNotStarted, ta.StartupPhase())
+	assert.Equal(t, "NOT_STARTED", StartupNotStarted.String())
+	var zero StartupPhase
+	assert.Equal(t, StartupNotStarted, zero)
+}
+
+func TestOnStart_Timeout(t *testing.T) {
+	ta := newTestAgent(t)
+	ta.counters = counters.NewReader(
+		atomic.NewBufferSlice(make([]byte, counters.CounterLength)),
+		atomic.NewBufferSlice(make([]byte, counters.MetadataLength)),
+	)
+	ta.opts.StartupTimeout = 20 * time.Millisecond
+	var phases []StartupPhase
+	ta.opts.StartupPhaseHandler = func(phase StartupPhase) { phases = append(phases, phase) }
+	ta.isServiceActive = false
+	ta.markFile.SignalReady()
+
+	start := time.Now()
+	err := ta.OnStart()
+	assert.GreaterOrEqual(t, time.Since(start), ta.opts.StartupTimeout)
+	require.ErrorIs(t, err, ErrStartupTimeout)
+	assert.Contains(t, err.Error(), "failed to start in phase AWAITING_COMMIT_POSITION")
+	assert.Equal(t, []StartupPhase{StartupAwaitingCommitPosition, StartupFailed}, phases)
+	assert.Equal(t, StartupFailed, ta.StartupPhase())
+	assert.False(t, ta.isServiceActive)
+
+	assert.EqualValues(t, -1, ta.markFile.Header().Version.Get(), "mark file signals the failed start")
+	require.Len(t, ta.errs, 1)
+	assert.ErrorIs(t, ta.errs[0], ErrStartupTimeout)
+	count := errorlog.Read(ta.markFile.ErrorBuffer(), func(int32, int64, int64, string) {}, 0)
+	assert.Equal(t, 1, count)
+}
+
+func TestReplaySnapshot(t *testing.T) {
+	ta := newTestAgent(t)
+	pub, image := aeron.NewInMemoryStream(ta.opts.SnapshotChannel, ta.opts.SnapshotStreamId, 2, logbuffer.TermMinLength)
+	taker := newSnapshotTaker(ta.opts, pub)
+	appVersion := int32(util.SemanticVersionCompose(1, 2, 3))
+	require.NoError(t, taker.markBegin(1024, 2, codecs.ClusterTimeUnit.MICROS, appVersion))
+	require.NoError(t, taker.markEnd(1024, 2, codecs.ClusterTimeUnit.MICROS, appVersion))
+
+	ta.startupDeadline = time.Now().Add(time.Second)
+	loader, err := ta.replaySnapshot(5, image)
+	require.NoError(t, err)
+	assert.Equal(t, appVersion, loader.appVersion)
+	assert.Equal(t, codecs.ClusterTimeUnit.MICROS, loader.timeUnit)
+}
+
+func TestReplaySnapshot_Timeout(t *testing.T) {
+	ta := newTestAgent(t)
+	pub, image := aeron.NewInMemoryStream(ta.opts.SnapshotChannel, ta.opts.SnapshotStreamId, 2, logbuffer.TermMinLength)
+	taker := newSnapshotTaker(ta.opts, pub)
+	require.NoError(t, taker.markBegin(1024, 2, codecs.ClusterTimeUnit.MILLIS, 1))
+
+	// The snapshot never ends, as when the replay stalls
+	ta.opts.StartupTimeout = 20 * time.Millisecond
+	ta.startupDeadline = time.Now().Add(ta.opts.StartupTimeout)
+	_, err := ta.replaySnapshot(5, image)
+	require.ErrorIs(t, err, ErrStartupTimeout)
+	assert.Contains(t, err.Error(), "snapshot replay of recordingId=5")
+}
+
+// serviceMessages returns the payloads of the service messages sent to the
+// consensus module, checking each session message header
+func (ta *testAgent) serviceMessages(t *testing.T) []string {
+	var messages []string
+	ta.consensusModule.Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+		require.EqualValues(t, SessionMessageHeaderTemplateId, buffer.GetUInt16(offset+2))
+		assert.EqualValues(t, ta.opts.ServiceId, buffer.GetInt64(offset+SBEHeaderLength+8), "clusterSessionId")
+		hdrLength := SBEHeaderLength + SessionMessageHdrBlockLength
+		messages = append(messages, string(buffer.GetBytesArray(offset+int32(hdrLength), length-int32(hdrLength))))
+	}, 100)
+	return messages
+}
+
+func TestOffer(t *testing.T) {
+	ta := newTestAgent(t)
+	msg := atomic.NewBufferSlice([]byte("xxhelloxx"))
+	assert.Positive(t, ta.Offer(msg, 2, 5))
+	assert.Equal(t, []string{"hello"}, ta.serviceMessages(t))
+
+	ta.fillPublication(t)
+	assert.EqualValues(t, aeron.BackPressured, ta.Offer(msg, 2, 5))
+
+	require.NoError(t, ta.publication.Close())
+	assert.NotPanics(t, func() {
+		assert.EqualValues(t, aeron.PublicationClosed, ta.Offer(msg, 2, 5))
+	})
+}
+
+func TestTryClaim(t *testing.T) {
+	ta := newTestAgent(t)
+	hdrLength := int32(SBEHeaderLength + SessionMessageHdrBlockLength)
+	var claim logbuffer.Claim
+	require.Positive(t, ta.TryClaim(5, &claim))
+	claim.Buffer().PutBytesArray(claim.Offset()+hdrLength, &[]byte{'h', 'e', 'l', 'l', 'o'}, 0, 5)
+	claim.Commit()
+	assert.Equal(t, []string{"hello"}, ta.serviceMessages(t))
+
+	ta.fillPublication(t)
+	assert.EqualValues(t, aeron.BackPressured, ta.TryClaim(5, &claim))
+
+	require.NoError(t, ta.publication.Close())
+	assert.NotPanics(t, func() {
+		assert.EqualValues(t, aeron.PublicationClosed, ta.TryClaim(5, &claim))
+	})
+}
+
+// closeRequests returns the ids of the sessions the agent asked the consensus
+// module to close since the last call
+func (ta *testAgent) closeRequests(t *testing.T) []int64 {
+	var ids []int64
+	marshaller := codecs.NewSbeGoMarshaller()
+	ta.consensusModule.Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+		var msgHeader codecs.MessageHeader
+		reader := bytes.NewReader(buffer.GetBytesArray(offset, length))
+		require.NoError(t, msgHeader.Decode(marshaller, reader, 0))
+		var request codecs.CloseSession
+		if msgHeader.TemplateId != request.SbeTemplateId() {
+			return
+		}
+		require.NoError(t, request.Decode(marshaller, reader, msgHeader.Version, msgHeader.BlockLength, true))
+		ids = append(ids, request.ClusterSessionId)
+	}, 100)
+	return ids
+}
+
+func (ta *testAgent) openSession(id int64) *containerClientSession {
+	pub, _ := aeron.NewInMemoryStream("aeron:ipc", 102, int32(id), logbuffer.TermMinLength)
+	session := &containerClientSession{id: id, responseStreamId: 102, responseChannel: "aeron:ipc", agent: ta.ClusteredServiceAgent, response: pub}
+	ta.addSession(session)
+	return session
+}
+
+// foreignSession is a ClientSession the container did not open
+type foreignSession struct {
+	ClientSession
+	id int64
+}
+
+func (s *foreignSession) Id() int64 {
+	return s.id
+}
+
+func TestGetClientSession(t *testing.T) {
+	ta := newTestAgent(t)
+	session := ta.openSession(7)
+	found, ok := ta.GetClientSession(7)
+	assert.True(t, ok)
+	assert.Same(t, session, found)
+	_, ok = ta.GetClientSession(8)
+	assert.False(t, ok)
+
+	ta.removeSession(7)
+	_, ok = ta.GetClientSession(7)
+	assert.False(t, ok)
+}
+
+func TestForEachClientSession(t *testing.T) {
+	ta := newTestAgent(t)
+	for _, id := range []int64{9, 3, 7, 5} {
+		ta.openSession(id)
+	}
+	ta.removeSession(7)
+
+	var ids []int64
+	ta.ForEachClientSession(func(session ClientSession) { ids = append(ids, session.Id()) })
+	assert.Equal(t, []int64{9, 3, 5}, ids, "in the order opened")
+}
+
+func TestCloseClientSession(t *testing.T) {
+	ta := newTestAgent(t)
+	session := ta.openSession(7)
+	assert.False(t, session.IsClosing())
+
+	assert.True(t, ta.CloseClientSession(7))
+	assert.True(t, session.IsClosing())
+	assert.Equal(t, []int64{7}, ta.closeRequests(t))
+
+	// Requested only once until a new leader may have lost it
+	session.Close()
+	assert.True(t, ta.CloseClientSession(7))
+	assert.Empty(t, ta.closeRequests(t))
+
+	ta.onNewLeadershipTermEvent(3, 1024, 77, 0, 1, 2, codecs.ClusterTimeUnit.MILLIS, ta.opts.AppVersion)
+	assert.False(t, session.IsClosing())
+	session.Close()
+	assert.True(t, session.IsClosing())
+	assert.Equal(t, []int64{7}, ta.closeRequests(t))
+
+	// Still open until the session close event arrives
+	_, ok := ta.GetClientSession(7)
+	assert.True(t, ok)
+	ta.onSessionClose(3, 2048, 7, 78, codecs.CloseReason.CLIENT_ACTION)
+	_, ok = ta.GetClientSession(7)
+	assert.False(t, ok)
+	session.Close()
+	assert.Empty(t, ta.closeRequests(t))
+}
+
+func TestCloseClientSession_Unknown(t *testing.T) {
+	ta := newTestAgent(t)
+	assert.False(t, ta.CloseClientSession(7))
+
+	ta.addSession(&foreignSession{id: 8})
+	assert.NotPanics(t, func() { assert.False(t, ta.CloseClientSession(8)) })
+	assert.NotPanics(t, func() {
+		ta.onNewLeadershipTermEvent(3, 1024, 77, 0, 1, 2, codecs.ClusterTimeUnit.MILLIS, ta.opts.AppVersion)
+	})
+	assert.Empty(t, ta.closeRequests(t))
+}
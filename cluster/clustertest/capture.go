@@ -0,0 +1,79 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertest
+
+import (
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/cluster"
+)
+
+// messageHeaderLength is the session message header preceding each message,
+// as written by the service agent
+const messageHeaderLength = cluster.SBEHeaderLength + cluster.SessionMessageHeaderLength
+
+// messageCapture records messages offered or claimed, in order. Claims are
+// captured once committed and dropped if aborted.
+type messageCapture struct {
+	entries  []*capturedMessage
+	captured [][]byte
+}
+
+type capturedMessage struct {
+	data  []byte         // Set once captured
+	claim *atomic.Buffer // Frame of a claim which may not yet be committed
+}
+
+func (capture *messageCapture) offer(buffer *atomic.Buffer, offset int32, length int32) {
+	capture.entries = append(capture.entries, &capturedMessage{data: buffer.GetBytesArray(offset, length)})
+}
+
+// tryClaim wraps bufferClaim around a frame on the heap, laid out as it
+// would be in a term so that Commit() and Abort() work as usual
+func (capture *messageCapture) tryClaim(length int32, bufferClaim *logbuffer.Claim) {
+	frameLength := logbuffer.DataFrameHeader_Length + messageHeaderLength + length
+	frame := atomic.NewBufferSlice(make([]byte, frameLength))
+	frame.PutUInt16(logbuffer.DataFrameHeader_TypeFieldOffset, logbuffer.DataFrameHeader_TypeData)
+	bufferClaim.Wrap(frame, 0, frameLength)
+	capture.entries = append(capture.entries, &capturedMessage{claim: frame})
+}
+
+// messages returns the messages captured so far, stopping at the first
+// claim which has not yet been committed or aborted
+func (capture *messageCapture) messages() [][]byte {
+	for len(capture.entries) > 0 {
+		entry := capture.entries[0]
+		if entry.claim != nil {
+			frameLength := entry.claim.GetInt32Volatile(logbuffer.DataFrameHeader_FrameLengthFieldOffset)
+			if frameLength == 0 {
+				break
+			}
+			if entry.claim.GetUInt16(logbuffer.DataFrameHeader_TypeFieldOffset) != logbuffer.DataFrameHeader_TypePad {
+				offset := logbuffer.DataFrameHeader_Length + messageHeaderLength
+				capture.captured = append(capture.captured, entry.claim.GetBytesArray(offset, frameLength-offset))
+			}
+		} else {
+			capture.captured = append(capture.captured, entry.data)
+		}
+		capture.entries[0] = nil
+		capture.entries = capture.entries[1:]
+	}
+	return capture.captured
+}
+
+// take returns the captured messages and clears them
+func (capture *messageCapture) take() [][]byte {
+	messages := capture.messages()
+	capture.captured = nil
+	return messages
+}
@@ -0,0 +1,120 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertest
+
+import (
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
+	"github.com/lirm/aeron-go/aeron/util"
+	"github.com/lirm/aeron-go/cluster"
+)
+
+// ClientSession is a cluster.ClientSession which captures the messages the
+// service sends to the client
+type ClientSession struct {
+	id               int64
+	responseStreamId int32
+	responseChannel  string
+	encodedPrincipal []byte
+	cluster          *FakeCluster
+	isClosing        bool
+	isClosed         bool
+	position         int64
+	egress           messageCapture
+}
+
+func (s *ClientSession) Id() int64 {
+	return s.id
+}
+
+func (s *ClientSession) ResponseStreamId() int32 {
+	return s.responseStreamId
+}
+
+func (s *ClientSession) ResponseChannel() string {
+	return s.responseChannel
+}
+
+func (s *ClientSession) EncodedPrincipal() []byte {
+	return s.encodedPrincipal
+}
+
+// Close requests that the session is closed, see Harness.CompleteSessionCloses()
+func (s *ClientSession) Close() {
+	s.cluster.CloseClientSession(s.id)
+}
+
+func (s *ClientSession) IsClosing() bool {
+	return s.isClosing
+}
+
+// IsClosed is true once the service has been told the session is closed
+func (s *ClientSession) IsClosed() bool {
+	return s.isClosed
+}
+
+// Offer captures the message while the member is leader. Otherwise, as in
+// the cluster, it is dropped and ClientSessionMockedOffer returned.
+func (s *ClientSession) Offer(
+	buffer *atomic.Buffer,
+	offset int32,
+	length int32,
+	_ term.ReservedValueSupplier,
+) int64 {
+	if result := s.checkOpen(); result != 0 {
+		return result
+	}
+	s.egress.offer(buffer, offset, length)
+	return s.advance(length)
+}
+
+// TryClaim captures the message once committed while the member is leader
+func (s *ClientSession) TryClaim(length int32, bufferClaim *logbuffer.Claim) int64 {
+	if result := s.checkOpen(); result != 0 {
+		if result == cluster.ClientSessionMockedOffer {
+			s.cluster.mockClaim(length, bufferClaim)
+		}
+		return result
+	}
+	s.egress.tryClaim(length, bufferClaim)
+	return s.advance(length)
+}
+
+func (s *ClientSession) checkOpen() int64 {
+	if s.isClosed {
+		return aeron.NotConnected
+	}
+	if s.cluster.role != cluster.Leader {
+		return cluster.ClientSessionMockedOffer
+	}
+	return 0
+}
+
+// advance the session's response position as if length bytes were sent
+func (s *ClientSession) advance(length int32) int64 {
+	s.position += int64(util.AlignInt32(logbuffer.DataFrameHeader_Length+messageHeaderLength+length, logbuffer.FrameAlignment))
+	return s.position
+}
+
+// Egress returns the messages the service has sent to the client, without
+// the session message header
+func (s *ClientSession) Egress() [][]byte {
+	return s.egress.messages()
+}
+
+// TakeEgress returns the messages sent to the client since the last call
+func (s *ClientSession) TakeEgress() [][]byte {
+	return s.egress.take()
+}
@@ -0,0 +1,185 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertest
+
+import (
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/util"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+)
+
+// FakeCluster is the cluster.Cluster given to the service by a Harness. Its
+// state only changes when the Harness is driven, so time is deterministic.
+type FakeCluster struct {
+	memberId          int32
+	role              cluster.Role
+	time              int64
+	timeUnit          codecs.ClusterTimeUnitEnum
+	logPosition       int64
+	servicePosition   int64
+	timers            map[int64]*timer
+	timerOrder        int64
+	sessions          map[int64]*ClientSession
+	sessionList       []*ClientSession
+	serviceMessages   messageCapture
+	mockedClaimBuffer *atomic.Buffer
+	errors            []error
+}
+
+type timer struct {
+	correlationId int64
+	deadline      int64
+	order         int64 // Breaks ties between timers with the same deadline
+}
+
+func newFakeCluster(memberId int32, role cluster.Role, time int64, timeUnit codecs.ClusterTimeUnitEnum) *FakeCluster {
+	return &FakeCluster{
+		memberId: memberId,
+		role:     role,
+		time:     time,
+		timeUnit: timeUnit,
+		timers:   make(map[int64]*timer),
+		sessions: make(map[int64]*ClientSession),
+	}
+}
+
+func (c *FakeCluster) LogPosition() int64 {
+	return c.logPosition
+}
+
+func (c *FakeCluster) MemberId() int32 {
+	return c.memberId
+}
+
+func (c *FakeCluster) Role() cluster.Role {
+	return c.role
+}
+
+func (c *FakeCluster) Time() int64 {
+	return c.time
+}
+
+// TimeUnit of Time()
+func (c *FakeCluster) TimeUnit() codecs.ClusterTimeUnitEnum {
+	return c.timeUnit
+}
+
+func (c *FakeCluster) IdleStrategy() idlestrategy.Idler {
+	return idlestrategy.Yielding{}
+}
+
+// ScheduleTimer schedules a timer, replacing any existing timer with the same
+// correlationId. It fires when the Harness advances time to the deadline.
+func (c *FakeCluster) ScheduleTimer(correlationId int64, deadline int64) bool {
+	c.timerOrder++
+	c.timers[correlationId] = &timer{correlationId: correlationId, deadline: deadline, order: c.timerOrder}
+	return true
+}
+
+// CancelTimer cancels a scheduled timer, returning false if there is none
+func (c *FakeCluster) CancelTimer(correlationId int64) bool {
+	if _, ok := c.timers[correlationId]; !ok {
+		return false
+	}
+	delete(c.timers, correlationId)
+	return true
+}
+
+// Timers returns the deadlines of the scheduled timers by correlationId
+func (c *FakeCluster) Timers() map[int64]int64 {
+	deadlines := make(map[int64]int64, len(c.timers))
+	for id, t := range c.timers {
+		deadlines[id] = t.deadline
+	}
+	return deadlines
+}
+
+// Offer captures a service message, which is delivered to the service by
+// Harness.DeliverServiceMessages()
+func (c *FakeCluster) Offer(buffer *atomic.Buffer, offset int32, length int32) int64 {
+	c.serviceMessages.offer(buffer, offset, length)
+	return c.advanceServicePosition(length)
+}
+
+// TryClaim captures a service message once committed, see Offer()
+func (c *FakeCluster) TryClaim(length int32, bufferClaim *logbuffer.Claim) int64 {
+	c.serviceMessages.tryClaim(length, bufferClaim)
+	return c.advanceServicePosition(length)
+}
+
+func (c *FakeCluster) advanceServicePosition(length int32) int64 {
+	c.servicePosition += int64(util.AlignInt32(logbuffer.DataFrameHeader_Length+messageHeaderLength+length, logbuffer.FrameAlignment))
+	return c.servicePosition
+}
+
+func (c *FakeCluster) GetClientSession(id int64) (cluster.ClientSession, bool) {
+	session, ok := c.sessions[id]
+	if !ok {
+		return nil, false
+	}
+	return session, true
+}
+
+func (c *FakeCluster) ForEachClientSession(fn func(cluster.ClientSession)) {
+	for _, session := range c.sessionList {
+		fn(session)
+	}
+}
+
+// CloseClientSession marks the session as closing. It is closed when the
+// Harness calls CompleteSessionCloses() or CloseSession().
+func (c *FakeCluster) CloseClientSession(id int64) bool {
+	session, ok := c.sessions[id]
+	if !ok {
+		return false
+	}
+	session.isClosing = true
+	return true
+}
+
+// RecordError keeps err, see Errors()
+func (c *FakeCluster) RecordError(err error) {
+	c.errors = append(c.errors, err)
+}
+
+// Errors returns the errors recorded by the service
+func (c *FakeCluster) Errors() []error {
+	return c.errors
+}
+
+func (c *FakeCluster) addSession(session *ClientSession) {
+	c.sessions[session.id] = session
+	c.sessionList = append(c.sessionList, session)
+}
+
+func (c *FakeCluster) removeSession(id int64) {
+	delete(c.sessions, id)
+	for i, session := range c.sessionList {
+		if session.id == id {
+			c.sessionList = append(c.sessionList[:i], c.sessionList[i+1:]...)
+			break
+		}
+	}
+}
+
+// mockClaim gives the service somewhere to write a message which is never sent
+func (c *FakeCluster) mockClaim(length int32, bufferClaim *logbuffer.Claim) {
+	claimLength := logbuffer.DataFrameHeader_Length + messageHeaderLength + length
+	if c.mockedClaimBuffer == nil || c.mockedClaimBuffer.Capacity() < claimLength {
+		c.mockedClaimBuffer = atomic.NewBufferSlice(make([]byte, claimLength))
+	}
+	bufferClaim.Wrap(c.mockedClaimBuffer, 0, claimLength)
+}
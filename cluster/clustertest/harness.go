@@ -0,0 +1,418 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clustertest runs a cluster.ClusteredService in memory, without a
+// media driver, archive or consensus module, so that its behaviour can be
+// unit tested. A Harness feeds the service scripted session opens and
+// closes, messages, timers and role changes with a clock that only moves
+// when told to, captures what the service sends to each ClientSession, and
+// round trips the service's state through OnTakeSnapshot() and OnStart().
+package clustertest
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+)
+
+const (
+	logStreamId      = 100
+	snapshotStreamId = 106
+	inMemoryChannel  = "aeron:ipc?alias=clustertest"
+)
+
+// Options for a Harness
+type Options struct {
+	MemberId           int32                      // Returned by Cluster.MemberId()
+	Role               cluster.Role               // Role of the member once started
+	StartTime          int64                      // Cluster time when started
+	TimeUnit           codecs.ClusterTimeUnitEnum // Unit of cluster time
+	LogTermLength      int32                      // Term length of the in memory log, which bounds the messages sent
+	SnapshotTermLength int32                      // Term length of the in memory snapshot, which bounds its size
+}
+
+// NewOptions returns Options for the leader of a cluster, starting at time 0
+func NewOptions() *Options {
+	return &Options{
+		Role:               cluster.Leader,
+		TimeUnit:           codecs.ClusterTimeUnit.MILLIS,
+		LogTermLength:      4 * 1024 * 1024,
+		SnapshotTermLength: 4 * 1024 * 1024,
+	}
+}
+
+// Snapshot is the state taken by Harness.TakeSnapshot(), combining what the
+// consensus module and the service would have written to their snapshots
+type Snapshot struct {
+	LogPosition      int64
+	LeadershipTermId int64
+	Time             int64
+	TimeUnit         codecs.ClusterTimeUnitEnum
+	NextSessionId    int64
+	Sessions         []SnapshotSession
+	Timers           map[int64]int64 // Deadline by correlationId
+	Messages         [][]byte        // Written by the service in OnTakeSnapshot()
+}
+
+// SnapshotSession is an open client session in a Snapshot
+type SnapshotSession struct {
+	Id               int64
+	ResponseStreamId int32
+	ResponseChannel  string
+	EncodedPrincipal []byte
+	IsClosing        bool
+}
+
+// Harness drives a cluster.ClusteredService as the consensus module and
+// service container would. It is not safe for concurrent use.
+type Harness struct {
+	opts             *Options
+	service          cluster.ClusteredService
+	cluster          *FakeCluster
+	leadershipTermId int64
+	nextSessionId    int64
+	log              *aeron.Publication
+	logImage         aeron.Image
+	logAssembler     *aeron.FragmentAssembler
+	logBasePosition  int64 // Log position at which the in memory log starts
+	header           *atomic.Buffer
+	session          *ClientSession // Session of the message being polled from the log
+	isStarted        bool
+	isTerminated     bool
+}
+
+// NewHarness creates a Harness for service. Start() or StartFromSnapshot()
+// must be called before driving it.
+func NewHarness(service cluster.ClusteredService, opts *Options) *Harness {
+	if opts == nil {
+		opts = NewOptions()
+	}
+	h := &Harness{
+		opts:          opts,
+		service:       service,
+		cluster:       newFakeCluster(opts.MemberId, cluster.Follower, opts.StartTime, opts.TimeUnit),
+		nextSessionId: 1,
+		header:        codecs.MakeClusterMessageBuffer(cluster.SessionMessageHeaderTemplateId, cluster.SessionMessageHdrBlockLength),
+	}
+	h.logAssembler = aeron.NewFragmentAssembler(h.onLogMessage, aeron.DefaultFragmentAssemblyBufferLength)
+	return h
+}
+
+// Cluster returns the cluster.Cluster given to the service
+func (h *Harness) Cluster() *FakeCluster {
+	return h.cluster
+}
+
+// Start the service with no snapshot, as for a new cluster
+func (h *Harness) Start() error {
+	return h.start(nil)
+}
+
+// StartFromSnapshot starts the service from a snapshot taken by this or
+// another Harness, restoring the cluster's sessions, timers and time and
+// giving the service an image of the messages it wrote to the snapshot
+func (h *Harness) StartFromSnapshot(snapshot *Snapshot) error {
+	if snapshot == nil {
+		return errors.New("nil snapshot")
+	}
+	c := h.cluster
+	c.logPosition = snapshot.LogPosition
+	c.time = snapshot.Time
+	c.timeUnit = snapshot.TimeUnit
+	h.leadershipTermId = snapshot.LeadershipTermId
+	h.nextSessionId = snapshot.NextSessionId
+	for _, s := range snapshot.Sessions {
+		session := h.newSession(s.Id, s.ResponseStreamId, s.ResponseChannel, s.EncodedPrincipal)
+		session.isClosing = s.IsClosing
+		c.addSession(session)
+	}
+	ids := make([]int64, 0, len(snapshot.Timers))
+	for id := range snapshot.Timers {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		c.ScheduleTimer(id, snapshot.Timers[id])
+	}
+
+	pub, image := aeron.NewInMemoryStream(inMemoryChannel, snapshotStreamId, 0, h.opts.SnapshotTermLength)
+	for i, msg := range snapshot.Messages {
+		if result := pub.Offer(atomic.NewBufferSlice(msg), 0, int32(len(msg)), nil); result < 0 {
+			return fmt.Errorf("snapshot message %d of %d does not fit in the snapshot: %d", i, len(snapshot.Messages), result)
+		}
+	}
+	pub.Close()
+	return h.start(image)
+}
+
+func (h *Harness) start(image aeron.Image) error {
+	if h.isStarted {
+		return errors.New("already started")
+	}
+	h.log, h.logImage = aeron.NewInMemoryStream(inMemoryChannel, logStreamId, 0, h.opts.LogTermLength)
+	h.logBasePosition = h.cluster.logPosition
+	h.header.PutInt64(cluster.SBEHeaderLength, h.leadershipTermId)
+	h.isStarted = true
+	h.service.OnStart(h.cluster, image)
+	h.ChangeRole(h.opts.Role)
+	return nil
+}
+
+// OpenSession opens a client session, as the consensus module would once
+// the client had connected, and returns it for sending messages and
+// inspecting egress
+func (h *Harness) OpenSession(responseStreamId int32, responseChannel string, encodedPrincipal []byte) *ClientSession {
+	session := h.newSession(h.nextSessionId, responseStreamId, responseChannel, encodedPrincipal)
+	h.nextSessionId++
+	h.cluster.addSession(session)
+	h.service.OnSessionOpen(session, h.cluster.time)
+	return session
+}
+
+func (h *Harness) newSession(id int64, responseStreamId int32, responseChannel string, encodedPrincipal []byte) *ClientSession {
+	return &ClientSession{
+		id:               id,
+		responseStreamId: responseStreamId,
+		responseChannel:  responseChannel,
+		encodedPrincipal: encodedPrincipal,
+		cluster:          h.cluster,
+	}
+}
+
+// CloseSession closes a client session, for example due to a client
+// request or timeout. Returns false if the session is not open.
+func (h *Harness) CloseSession(session *ClientSession, closeReason codecs.CloseReasonEnum) bool {
+	if _, ok := h.cluster.sessions[session.id]; !ok {
+		return false
+	}
+	h.cluster.removeSession(session.id)
+	session.isClosed = true
+	h.service.OnSessionClose(session, h.cluster.time, closeReason)
+	return true
+}
+
+// CompleteSessionCloses closes the sessions the service has asked to close,
+// with a SERVICE_ACTION reason. Returns the number closed.
+func (h *Harness) CompleteSessionCloses() int {
+	var closing []*ClientSession
+	for _, session := range h.cluster.sessionList {
+		if session.isClosing {
+			closing = append(closing, session)
+		}
+	}
+	for _, session := range closing {
+		h.CloseSession(session, codecs.CloseReason.SERVICE_ACTION)
+	}
+	return len(closing)
+}
+
+// SendMessage appends a message from the client of session to the log and
+// delivers it to the service, with a header giving its position in the log
+func (h *Harness) SendMessage(session *ClientSession, msg []byte) error {
+	if _, ok := h.cluster.sessions[session.id]; !ok {
+		return fmt.Errorf("session %d is not open", session.id)
+	}
+	return h.appendToLog(session, session.id, msg)
+}
+
+// DeliverServiceMessages appends the messages the service has offered to
+// the cluster to the log and delivers them to the service, as the consensus
+// module would. Returns the number delivered.
+func (h *Harness) DeliverServiceMessages() (int, error) {
+	messages := h.cluster.serviceMessages.take()
+	for i, msg := range messages {
+		if err := h.appendToLog(nil, 0, msg); err != nil {
+			return i, err
+		}
+	}
+	return len(messages), nil
+}
+
+func (h *Harness) appendToLog(session *ClientSession, clusterSessionId int64, msg []byte) error {
+	if !h.isStarted || h.isTerminated {
+		return errors.New("not running")
+	}
+	h.header.PutInt64(cluster.SBEHeaderLength+8, clusterSessionId)
+	h.header.PutInt64(cluster.SBEHeaderLength+16, h.cluster.time)
+	result := h.log.Offer2(h.header, 0, h.header.Capacity(), atomic.NewBufferSlice(msg), 0, int32(len(msg)), nil)
+	if result < 0 {
+		return fmt.Errorf("log append failed, increase Options.LogTermLength: %d", result)
+	}
+	h.session = session
+	for h.logImage.Position() < result {
+		h.logImage.Poll(h.logAssembler.OnFragment, 1)
+	}
+	h.session = nil
+	return nil
+}
+
+func (h *Harness) onLogMessage(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+	// The header's position is within the in memory log, which starts afresh
+	// when started from a snapshot
+	h.cluster.logPosition = h.logBasePosition + header.Position()
+	timestamp := buffer.GetInt64(offset + cluster.SBEHeaderLength + 16)
+	var session cluster.ClientSession
+	if h.session != nil {
+		session = h.session
+	}
+	h.service.OnSessionMessage(session, timestamp, buffer, offset+messageHeaderLength, length-messageHeaderLength, header)
+}
+
+// AdvanceTime moves the cluster's clock forward by duration, in the cluster's
+// time unit, firing any timers which fall due. Returns the number fired.
+func (h *Harness) AdvanceTime(duration int64) int {
+	return h.SetTime(h.cluster.time + duration)
+}
+
+// SetTime moves the cluster's clock to now, firing in deadline order the
+// timers which fall due, with the clock set to each timer's deadline. Timers
+// scheduled by the service while handling a timer fire too if they are due.
+// Returns the number fired.
+func (h *Harness) SetTime(now int64) int {
+	fired := 0
+	for {
+		next := h.nextTimer(now)
+		if next == nil {
+			break
+		}
+		delete(h.cluster.timers, next.correlationId)
+		if next.deadline > h.cluster.time {
+			h.cluster.time = next.deadline
+		}
+		h.service.OnTimerEvent(next.correlationId, h.cluster.time)
+		fired++
+	}
+	if now > h.cluster.time {
+		h.cluster.time = now
+	}
+	return fired
+}
+
+// nextTimer returns the earliest timer due by now, or nil if there is none
+func (h *Harness) nextTimer(now int64) *timer {
+	var next *timer
+	for _, t := range h.cluster.timers {
+		if t.deadline > now {
+			continue
+		}
+		if next == nil || t.deadline < next.deadline || (t.deadline == next.deadline && t.order < next.order) {
+			next = t
+		}
+	}
+	return next
+}
+
+// ChangeRole changes the member's role, notifying the service if it differs
+func (h *Harness) ChangeRole(role cluster.Role) {
+	if role != h.cluster.role {
+		h.cluster.role = role
+		h.service.OnRoleChange(role)
+	}
+}
+
+// NewLeadershipTerm starts a new leadership term with leaderMemberId as
+// leader. As in the cluster, close requests made by the service in the
+// previous term are forgotten.
+func (h *Harness) NewLeadershipTerm(leaderMemberId int32, appVersion int32) {
+	h.leadershipTermId++
+	h.header.PutInt64(cluster.SBEHeaderLength, h.leadershipTermId)
+	for _, session := range h.cluster.sessionList {
+		session.isClosing = false
+	}
+	c := h.cluster
+	h.service.OnNewLeadershipTermEvent(
+		h.leadershipTermId,
+		c.logPosition,
+		c.time,
+		c.logPosition,
+		leaderMemberId,
+		0,
+		c.timeUnit,
+		appVersion,
+	)
+	if leaderMemberId == c.memberId {
+		h.ChangeRole(cluster.Leader)
+	} else {
+		h.ChangeRole(cluster.Follower)
+	}
+}
+
+// LeadershipTermId of the current term, starting at 0
+func (h *Harness) LeadershipTermId() int64 {
+	return h.leadershipTermId
+}
+
+// TakeSnapshot asks the service to take a snapshot and returns it along
+// with the cluster's state, for StartFromSnapshot()
+func (h *Harness) TakeSnapshot() (*Snapshot, error) {
+	if !h.isStarted || h.isTerminated {
+		return nil, errors.New("not running")
+	}
+	pub, image := aeron.NewInMemoryStream(inMemoryChannel, snapshotStreamId, 0, h.opts.SnapshotTermLength)
+	h.service.OnTakeSnapshot(pub)
+	pub.Close()
+
+	c := h.cluster
+	snapshot := &Snapshot{
+		LogPosition:      c.logPosition,
+		LeadershipTermId: h.leadershipTermId,
+		Time:             c.time,
+		TimeUnit:         c.timeUnit,
+		NextSessionId:    h.nextSessionId,
+		Timers:           c.Timers(),
+	}
+	for _, session := range c.sessionList {
+		snapshot.Sessions = append(snapshot.Sessions, SnapshotSession{
+			Id:               session.id,
+			ResponseStreamId: session.responseStreamId,
+			ResponseChannel:  session.responseChannel,
+			EncodedPrincipal: session.encodedPrincipal,
+			IsClosing:        session.isClosing,
+		})
+	}
+	assembler := aeron.NewFragmentAssembler(func(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+		snapshot.Messages = append(snapshot.Messages, buffer.GetBytesArray(offset, length))
+	}, aeron.DefaultFragmentAssemblyBufferLength)
+	for image.Poll(assembler.OnFragment, 10) > 0 {
+	}
+	return snapshot, nil
+}
+
+// Suspend the cluster, notifying the service if it is a cluster.ClusterActionListener
+func (h *Harness) Suspend() {
+	h.clusterAction(codecs.ClusterAction.SUSPEND)
+}
+
+// Resume the cluster, notifying the service if it is a cluster.ClusterActionListener
+func (h *Harness) Resume() {
+	h.clusterAction(codecs.ClusterAction.RESUME)
+}
+
+func (h *Harness) clusterAction(action codecs.ClusterActionEnum) {
+	if listener, ok := h.service.(cluster.ClusterActionListener); ok {
+		listener.OnClusterAction(action, h.cluster.logPosition)
+	}
+}
+
+// Terminate the service, as on cluster shutdown
+func (h *Harness) Terminate() {
+	if h.isStarted && !h.isTerminated {
+		h.isTerminated = true
+		h.service.OnTerminate(h.cluster)
+	}
+}
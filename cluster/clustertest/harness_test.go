@@ -0,0 +1,310 @@
+package clustertest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const counterSnapshotTypeId = 42
+
+// counterService counts the messages from clients, echoing each with the
+// count, and acts on a few commands
+type counterService struct {
+	cluster         cluster.Cluster
+	count           int64
+	roles           []cluster.Role
+	opened          []int64
+	closed          []codecs.CloseReasonEnum
+	timers          []int64
+	serviceMessages []string
+	positions       []int64
+	actions         []codecs.ClusterActionEnum
+	terminated      bool
+	startErr        error
+}
+
+func (s *counterService) OnStart(c cluster.Cluster, image aeron.Image) {
+	s.cluster = c
+	if image == nil {
+		return
+	}
+	reader := cluster.NewSnapshotReader(c, image, counterSnapshotTypeId)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			s.startErr = err
+			return
+		}
+		s.count = int64(binary.LittleEndian.Uint64(record.Data))
+	}
+}
+
+func (s *counterService) OnClusterAction(action codecs.ClusterActionEnum, logPosition int64) {
+	s.actions = append(s.actions, action)
+}
+
+func (s *counterService) OnSessionOpen(session cluster.ClientSession, timestamp int64) {
+	s.opened = append(s.opened, session.Id())
+}
+
+func (s *counterService) OnSessionClose(session cluster.ClientSession, timestamp int64, closeReason codecs.CloseReasonEnum) {
+	s.closed = append(s.closed, closeReason)
+}
+
+func (s *counterService) OnSessionMessage(
+	session cluster.ClientSession,
+	timestamp int64,
+	buffer *atomic.Buffer,
+	offset int32,
+	length int32,
+	header *logbuffer.Header,
+) {
+	msg := string(buffer.GetBytesArray(offset, length))
+	s.positions = append(s.positions, header.Position())
+	if session == nil {
+		s.serviceMessages = append(s.serviceMessages, msg)
+		return
+	}
+	s.count++
+	switch msg {
+	case "close":
+		session.Close()
+	case "timer":
+		s.cluster.ScheduleTimer(s.count, timestamp+100)
+	case "broadcast":
+		s.cluster.Offer(atomic.NewBufferSlice([]byte("hello")), 0, 5)
+	case "claim":
+		reply := []byte(fmt.Sprintf("claimed %d", s.count))
+		var claim logbuffer.Claim
+		if session.TryClaim(int32(len(reply)), &claim) > 0 {
+			claim.Buffer().PutBytesArray(claim.Offset()+cluster.SBEHeaderLength+cluster.SessionMessageHeaderLength, &reply, 0, int32(len(reply)))
+			claim.Commit()
+		}
+		return
+	}
+	reply := []byte(fmt.Sprintf("%d:%s", s.count, msg))
+	session.Offer(atomic.NewBufferSlice(reply), 0, int32(len(reply)), nil)
+}
+
+func (s *counterService) OnTimerEvent(correlationId, timestamp int64) {
+	s.timers = append(s.timers, correlationId)
+}
+
+func (s *counterService) OnTakeSnapshot(publication *aeron.Publication) {
+	writer := cluster.NewSnapshotWriter(s.cluster, publication, counterSnapshotTypeId, 1)
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, uint64(s.count))
+	if err := writer.Begin(); err != nil {
+		s.cluster.RecordError(err)
+	} else if err := writer.Write(1, data); err != nil {
+		s.cluster.RecordError(err)
+	} else if err := writer.End(); err != nil {
+		s.cluster.RecordError(err)
+	}
+}
+
+func (s *counterService) OnRoleChange(role cluster.Role) {
+	s.roles = append(s.roles, role)
+}
+
+func (s *counterService) OnTerminate(cluster cluster.Cluster) {
+	s.terminated = true
+}
+
+func (s *counterService) OnNewLeadershipTermEvent(
+	leadershipTermId int64,
+	logPosition int64,
+	timestamp int64,
+	termBaseLogPosition int64,
+	leaderMemberId int32,
+	logSessionId int32,
+	timeUnit codecs.ClusterTimeUnitEnum,
+	appVersion int32,
+) {
+}
+
+func startHarness(t *testing.T, service *counterService) *Harness {
+	h := NewHarness(service, nil)
+	require.NoError(t, h.Start())
+	return h
+}
+
+func asStrings(messages [][]byte) []string {
+	result := make([]string, len(messages))
+	for i, msg := range messages {
+		result[i] = string(msg)
+	}
+	return result
+}
+
+func TestHarness_MessagesAndEgress(t *testing.T) {
+	service := &counterService{}
+	h := startHarness(t, service)
+	assert.Equal(t, []cluster.Role{cluster.Leader}, service.roles)
+
+	alice := h.OpenSession(102, "aeron:udp?endpoint=localhost:20001", []byte("alice"))
+	bob := h.OpenSession(102, "aeron:udp?endpoint=localhost:20002", nil)
+	assert.Equal(t, []int64{1, 2}, service.opened)
+
+	require.NoError(t, h.SendMessage(alice, []byte("a")))
+	require.NoError(t, h.SendMessage(bob, []byte("b")))
+	require.NoError(t, h.SendMessage(alice, []byte("claim")))
+
+	assert.Equal(t, []string{"1:a", "claimed 3"}, asStrings(alice.Egress()))
+	assert.Equal(t, []string{"2:b"}, asStrings(bob.TakeEgress()))
+	assert.Empty(t, bob.Egress())
+
+	require.Len(t, service.positions, 3)
+	assert.Less(t, service.positions[0], service.positions[1])
+	assert.Equal(t, service.positions[2], h.Cluster().LogPosition())
+}
+
+func TestHarness_LargeMessage(t *testing.T) {
+	service := &counterService{}
+	h := startHarness(t, service)
+	session := h.OpenSession(102, "aeron:ipc", nil)
+
+	msg := make([]byte, 100*1024)
+	for i := range msg {
+		msg[i] = 'x'
+	}
+	require.NoError(t, h.SendMessage(session, msg))
+	egress := session.Egress()
+	require.Len(t, egress, 1)
+	assert.Equal(t, "1:"+string(msg), string(egress[0]))
+}
+
+func TestHarness_FollowerDoesNotSend(t *testing.T) {
+	service := &counterService{}
+	opts := NewOptions()
+	opts.Role = cluster.Follower
+	h := NewHarness(service, opts)
+	require.NoError(t, h.Start())
+	assert.Empty(t, service.roles)
+
+	session := h.OpenSession(102, "aeron:ipc", nil)
+	require.NoError(t, h.SendMessage(session, []byte("a")))
+	require.NoError(t, h.SendMessage(session, []byte("claim")))
+	assert.Empty(t, session.Egress())
+	assert.Equal(t, int64(2), service.count)
+
+	h.ChangeRole(cluster.Leader)
+	require.NoError(t, h.SendMessage(session, []byte("b")))
+	assert.Equal(t, []string{"3:b"}, asStrings(session.Egress()))
+}
+
+func TestHarness_Timers(t *testing.T) {
+	service := &counterService{}
+	h := startHarness(t, service)
+	session := h.OpenSession(102, "aeron:ipc", nil)
+
+	h.AdvanceTime(10)
+	require.NoError(t, h.SendMessage(session, []byte("timer"))) // id 1, due at 110
+	h.AdvanceTime(20)
+	require.NoError(t, h.SendMessage(session, []byte("timer"))) // id 2, due at 130
+	assert.Equal(t, map[int64]int64{1: 110, 2: 130}, h.Cluster().Timers())
+
+	assert.Equal(t, 0, h.AdvanceTime(79))
+	assert.Equal(t, int64(109), h.Cluster().Time())
+	assert.Equal(t, 1, h.AdvanceTime(1))
+	assert.Equal(t, []int64{1}, service.timers)
+
+	assert.True(t, h.Cluster().CancelTimer(2))
+	assert.False(t, h.Cluster().CancelTimer(2))
+	assert.Equal(t, 0, h.AdvanceTime(100))
+	assert.Equal(t, int64(210), h.Cluster().Time())
+}
+
+func TestHarness_SessionClose(t *testing.T) {
+	service := &counterService{}
+	h := startHarness(t, service)
+	session := h.OpenSession(102, "aeron:ipc", nil)
+	other := h.OpenSession(102, "aeron:ipc", nil)
+
+	require.NoError(t, h.SendMessage(session, []byte("close")))
+	assert.True(t, session.IsClosing())
+	assert.False(t, session.IsClosed())
+
+	assert.Equal(t, 1, h.CompleteSessionCloses())
+	assert.True(t, session.IsClosed())
+	assert.Equal(t, []codecs.CloseReasonEnum{codecs.CloseReason.SERVICE_ACTION}, service.closed)
+	assert.Error(t, h.SendMessage(session, []byte("a")))
+
+	assert.True(t, h.CloseSession(other, codecs.CloseReason.TIMEOUT))
+	assert.False(t, h.CloseSession(other, codecs.CloseReason.TIMEOUT))
+	_, ok := h.Cluster().GetClientSession(other.Id())
+	assert.False(t, ok)
+}
+
+func TestHarness_ServiceMessages(t *testing.T) {
+	service := &counterService{}
+	h := startHarness(t, service)
+	session := h.OpenSession(102, "aeron:ipc", nil)
+
+	require.NoError(t, h.SendMessage(session, []byte("broadcast")))
+	assert.Empty(t, service.serviceMessages)
+	n, err := h.DeliverServiceMessages()
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []string{"hello"}, service.serviceMessages)
+}
+
+func TestHarness_SuspendResume(t *testing.T) {
+	service := &counterService{}
+	h := startHarness(t, service)
+
+	h.Suspend()
+	h.Resume()
+	assert.Equal(t, []codecs.ClusterActionEnum{codecs.ClusterAction.SUSPEND, codecs.ClusterAction.RESUME}, service.actions)
+}
+
+func TestHarness_SnapshotRoundTrip(t *testing.T) {
+	service := &counterService{}
+	h := startHarness(t, service)
+	session := h.OpenSession(102, "aeron:ipc", []byte("alice"))
+	require.NoError(t, h.SendMessage(session, []byte("a")))
+	require.NoError(t, h.SendMessage(session, []byte("timer")))
+	h.AdvanceTime(50)
+
+	snapshot, err := h.TakeSnapshot()
+	require.NoError(t, err)
+	assert.Empty(t, h.Cluster().Errors())
+	assert.Equal(t, h.Cluster().LogPosition(), snapshot.LogPosition)
+	require.Len(t, snapshot.Sessions, 1)
+	assert.Equal(t, []byte("alice"), snapshot.Sessions[0].EncodedPrincipal)
+	h.Terminate()
+	assert.True(t, service.terminated)
+
+	restored := &counterService{}
+	h2 := NewHarness(restored, nil)
+	require.NoError(t, h2.StartFromSnapshot(snapshot))
+	require.NoError(t, restored.startErr)
+	assert.Equal(t, int64(2), restored.count)
+	assert.Equal(t, int64(50), h2.Cluster().Time())
+	assert.Equal(t, snapshot.LogPosition, h2.Cluster().LogPosition())
+
+	restoredSession, ok := h2.Cluster().GetClientSession(session.Id())
+	require.True(t, ok)
+	require.NoError(t, h2.SendMessage(restoredSession.(*ClientSession), []byte("b")))
+	assert.Equal(t, []string{"3:b"}, asStrings(restoredSession.(*ClientSession).Egress()))
+	assert.Less(t, snapshot.LogPosition, h2.Cluster().LogPosition())
+
+	assert.Equal(t, 1, h2.AdvanceTime(50))
+	assert.Equal(t, []int64{2}, restored.timers)
+
+	next := h2.OpenSession(102, "aeron:ipc", nil)
+	assert.Equal(t, session.Id()+1, next.Id())
+}
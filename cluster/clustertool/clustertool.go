@@ -0,0 +1,223 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package clustertool inspects cluster directories, in the manner of the Java
+// ClusterTool, by reading their mark files and recording log. Only the cluster
+// members query needs the cluster to be running.
+package clustertool
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron/errorlog"
+	"github.com/lirm/aeron-go/aeron/util"
+	"github.com/lirm/aeron-go/cluster"
+)
+
+// DefaultActivityTimeout after which a component which has not updated its mark file is considered dead
+const DefaultActivityTimeout = 10 * time.Second
+
+// ErrNoMarkFiles is returned when the cluster dir contains no mark files
+var ErrNoMarkFiles = errors.New("no mark files found")
+
+// MarkFileInfo is a copy of the header of a mark file
+type MarkFileInfo struct {
+	Filename                string
+	Version                 int32
+	ComponentType           int32
+	ActivityTimestamp       int64 // Epoch ms
+	StartTimestamp          int64 // Epoch ms
+	Pid                     int64
+	CandidateTermId         int64
+	ArchiveStreamId         int32
+	ServiceStreamId         int32
+	ConsensusModuleStreamId int32
+	IngressStreamId         int32
+	MemberId                int32
+	ServiceId               int32
+	HeaderLength            int32
+	ErrorBufferLength       int32
+	ClusterId               int32
+}
+
+// IsActive is true if the component updated its activity timestamp within timeout of now
+func (info *MarkFileInfo) IsActive(now time.Time, timeout time.Duration) bool {
+	return info.ActivityTimestamp+timeout.Milliseconds() > now.UnixMilli()
+}
+
+// VersionString is the semantic version, or the raw value if the component failed to start or is not yet ready
+func (info *MarkFileInfo) VersionString() string {
+	if info.Version <= 0 {
+		return fmt.Sprintf("%d", info.Version)
+	}
+	return util.SemanticVersionToString(uint32(info.Version))
+}
+
+func (info *MarkFileInfo) String() string {
+	return fmt.Sprintf("%s: version=%s componentType=%s memberId=%d serviceId=%d clusterId=%d pid=%d "+
+		"candidateTermId=%d startTimestamp=%s activityTimestamp=%s archiveStreamId=%d serviceStreamId=%d "+
+		"consensusModuleStreamId=%d ingressStreamId=%d headerLength=%d errorBufferLength=%d",
+		filepath.Base(info.Filename), info.VersionString(), cluster.ComponentTypeName(info.ComponentType),
+		info.MemberId, info.ServiceId, info.ClusterId, info.Pid, info.CandidateTermId,
+		formatTimestamp(info.StartTimestamp), formatTimestamp(info.ActivityTimestamp), info.ArchiveStreamId,
+		info.ServiceStreamId, info.ConsensusModuleStreamId, info.IngressStreamId, info.HeaderLength,
+		info.ErrorBufferLength)
+}
+
+// MarkFiles returns the mark files in clusterDir, the consensus module's first
+func MarkFiles(clusterDir string) ([]string, error) {
+	filenames, err := filepath.Glob(filepath.Join(clusterDir, "cluster-mark*.dat"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(filenames, func(i, j int) bool {
+		iConsensusModule := filepath.Base(filenames[i]) == cluster.MarkFileFilename
+		jConsensusModule := filepath.Base(filenames[j]) == cluster.MarkFileFilename
+		if iConsensusModule != jConsensusModule {
+			return iConsensusModule
+		}
+		return filenames[i] < filenames[j]
+	})
+	return filenames, nil
+}
+
+// ReadMarkFile copies the header of a mark file
+func ReadMarkFile(filename string) (*MarkFileInfo, error) {
+	markFile, err := cluster.MapClusterMarkFile(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer markFile.Close()
+	return readHeader(filename, markFile), nil
+}
+
+func readHeader(filename string, markFile *cluster.ClusterMarkFile) *MarkFileInfo {
+	header := markFile.Header()
+	return &MarkFileInfo{
+		Filename:                filename,
+		Version:                 header.Version.Get(),
+		ComponentType:           header.ComponentType.Get(),
+		ActivityTimestamp:       header.ActivityTimestamp.Get(),
+		StartTimestamp:          header.StartTimestamp.Get(),
+		Pid:                     header.Pid.Get(),
+		CandidateTermId:         header.CandidateTermId.Get(),
+		ArchiveStreamId:         header.ArchiveStreamId.Get(),
+		ServiceStreamId:         header.ServiceStreamId.Get(),
+		ConsensusModuleStreamId: header.ConsensusModuleStreamId.Get(),
+		IngressStreamId:         header.IngressStreamId.Get(),
+		MemberId:                header.MemberId.Get(),
+		ServiceId:               header.ServiceId.Get(),
+		HeaderLength:            header.HeaderLength.Get(),
+		ErrorBufferLength:       header.ErrorBufferLength.Get(),
+		ClusterId:               header.ClusterId.Get(),
+	}
+}
+
+// ReadMarkFiles copies the headers of all the mark files in clusterDir
+func ReadMarkFiles(clusterDir string) ([]*MarkFileInfo, error) {
+	filenames, err := MarkFiles(clusterDir)
+	if err != nil {
+		return nil, err
+	}
+	if len(filenames) == 0 {
+		return nil, fmt.Errorf("%w in %s", ErrNoMarkFiles, clusterDir)
+	}
+	infos := make([]*MarkFileInfo, 0, len(filenames))
+	for _, filename := range filenames {
+		info, err := ReadMarkFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// Describe writes the header of each mark file in clusterDir
+func Describe(w io.Writer, clusterDir string) error {
+	infos, err := ReadMarkFiles(clusterDir)
+	if err != nil {
+		return err
+	}
+	for _, info := range infos {
+		if _, err := fmt.Fprintln(w, info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Errors writes the distinct errors recorded in each mark file in clusterDir
+func Errors(w io.Writer, clusterDir string) error {
+	filenames, err := MarkFiles(clusterDir)
+	if err != nil {
+		return err
+	}
+	if len(filenames) == 0 {
+		return fmt.Errorf("%w in %s", ErrNoMarkFiles, clusterDir)
+	}
+	for _, filename := range filenames {
+		markFile, err := cluster.MapClusterMarkFile(filename)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s:\n", filepath.Base(filename))
+		count := errorlog.Read(markFile.ErrorBuffer(), func(observationCount int32, firstObservationTimestamp, lastObservationTimestamp int64, encodedError string) {
+			fmt.Fprintf(w, "***\n%d observations from %s to %s for:\n %s\n", observationCount,
+				formatTimestamp(firstObservationTimestamp), formatTimestamp(lastObservationTimestamp), encodedError)
+		}, 0)
+		fmt.Fprintf(w, "\n%d distinct errors observed.\n", count)
+		markFile.Close()
+	}
+	return nil
+}
+
+// RecordingLog writes the entries of the recording log in clusterDir
+func RecordingLog(w io.Writer, clusterDir string) error {
+	entries, err := cluster.ReadRecordingLog(clusterDir)
+	if err != nil {
+		return err
+	}
+	for idx := range entries {
+		if _, err := fmt.Fprintln(w, entries[idx].String()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsAlive is true if every mark file in clusterDir had its activity timestamp updated within timeout
+func IsAlive(clusterDir string, timeout time.Duration) (bool, error) {
+	infos, err := ReadMarkFiles(clusterDir)
+	if err != nil {
+		return false, err
+	}
+	now := time.Now()
+	for _, info := range infos {
+		if !info.IsActive(now, timeout) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func formatTimestamp(epochMs int64) string {
+	if epochMs <= 0 {
+		return fmt.Sprintf("%d", epochMs)
+	}
+	return time.UnixMilli(epochMs).UTC().Format("2006-01-02T15:04:05.000Z")
+}
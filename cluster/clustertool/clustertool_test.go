@@ -0,0 +1,120 @@
+package clustertool
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron/errorlog"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newMarkFile(t *testing.T, filename string, memberId, serviceId int32, activityTimestamp int64) *cluster.ClusterMarkFile {
+	markFile, err := cluster.NewClusterMarkFile(filename)
+	require.NoError(t, err)
+	t.Cleanup(func() { markFile.Close() })
+	markFile.Header().MemberId.Set(memberId)
+	markFile.Header().ServiceId.Set(serviceId)
+	markFile.UpdateActivityTimestamp(activityTimestamp)
+	return markFile
+}
+
+func TestDescribe(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now().UnixMilli()
+	newMarkFile(t, filepath.Join(dir, cluster.MarkFileFilenameForService(1)), 2, 1, now)
+	consensusModule := newMarkFile(t, filepath.Join(dir, cluster.MarkFileFilename), 2, -1, now)
+	consensusModule.Header().ComponentType.Set(cluster.ComponentTypeConsensusModule)
+	consensusModule.SignalReady()
+
+	infos, err := ReadMarkFiles(dir)
+	require.NoError(t, err)
+	require.Len(t, infos, 2)
+	assert.Equal(t, cluster.MarkFileFilename, filepath.Base(infos[0].Filename))
+	assert.Equal(t, cluster.ComponentTypeConsensusModule, infos[0].ComponentType)
+	assert.Equal(t, "0.3.0", infos[0].VersionString())
+	assert.Equal(t, cluster.ComponentTypeContainer, infos[1].ComponentType)
+	assert.EqualValues(t, 1, infos[1].ServiceId)
+	assert.EqualValues(t, 2, infos[1].MemberId)
+	assert.EqualValues(t, os.Getpid(), infos[1].Pid)
+	assert.EqualValues(t, now, infos[1].ActivityTimestamp)
+	assert.EqualValues(t, cluster.ErrorBufferLength, infos[1].ErrorBufferLength)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, Describe(out, dir))
+	assert.Contains(t, out.String(), "componentType=CONSENSUS_MODULE memberId=2 serviceId=-1")
+	assert.Contains(t, out.String(), "componentType=CONTAINER memberId=2 serviceId=1")
+
+	_, err = ReadMarkFiles(t.TempDir())
+	assert.ErrorIs(t, err, ErrNoMarkFiles)
+}
+
+func TestIsAlive(t *testing.T) {
+	dir := t.TempDir()
+	now := time.Now()
+	newMarkFile(t, filepath.Join(dir, cluster.MarkFileFilenameForService(0)), 0, 0, now.UnixMilli())
+	alive, err := IsAlive(dir, time.Minute)
+	require.NoError(t, err)
+	assert.True(t, alive)
+
+	newMarkFile(t, filepath.Join(dir, cluster.MarkFileFilenameForService(1)), 0, 1, now.Add(-time.Hour).UnixMilli())
+	alive, err = IsAlive(dir, time.Minute)
+	require.NoError(t, err)
+	assert.False(t, alive)
+}
+
+func TestErrors(t *testing.T) {
+	dir := t.TempDir()
+	markFile := newMarkFile(t, filepath.Join(dir, cluster.MarkFileFilenameForService(0)), 0, 0, time.Now().UnixMilli())
+	// Record as the service agent does, with a clock ticking each second
+	now := int64(0)
+	errorLog := errorlog.NewDistinctErrorLog(markFile.ErrorBuffer(), func() int64 {
+		now += 1000
+		return now
+	})
+	for _, encodedError := range []string{"take snapshot failed", "take snapshot failed", "consensus module not connected"} {
+		require.True(t, errorLog.RecordEncoded(encodedError))
+	}
+
+	out := new(bytes.Buffer)
+	require.NoError(t, Errors(out, dir))
+	assert.Contains(t, out.String(), "2 observations from 1970-01-01T00:00:01.000Z to 1970-01-01T00:00:02.000Z for:\n take snapshot failed")
+	assert.Contains(t, out.String(), "1 observations from 1970-01-01T00:00:03.000Z to 1970-01-01T00:00:03.000Z for:\n consensus module not connected")
+	assert.Contains(t, out.String(), "2 distinct errors observed.")
+}
+
+func TestRecordingLog(t *testing.T) {
+	dir := t.TempDir()
+	entries := []cluster.RecordingLogEntry{
+		{RecordingId: 10, LeadershipTermId: 0, LogPosition: 4096, Timestamp: 1000, ServiceId: -1,
+			EntryType: cluster.RecordingLogEntryTypeTerm},
+		{RecordingId: 11, LeadershipTermId: 0, LogPosition: 2048, Timestamp: 1500, ServiceId: 0,
+			EntryType: cluster.RecordingLogEntryTypeSnapshot | cluster.RecordingLogEntryTypeInvalidFlag},
+		{RecordingId: 10, LeadershipTermId: 1, TermBaseLogPosition: 4096, LogPosition: cluster.NullValue,
+			Timestamp: 2000, ServiceId: -1, EntryType: cluster.RecordingLogEntryTypeTerm},
+	}
+	var data []byte
+	for idx := range entries {
+		entries[idx].Index = idx
+		data = append(data, cluster.EncodeRecordingLogEntry(&entries[idx])...)
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(dir, cluster.RecordingLogFilename), data, 0644))
+
+	read, err := cluster.ReadRecordingLog(dir)
+	require.NoError(t, err)
+	assert.Equal(t, entries, read)
+	assert.True(t, read[1].IsSnapshot())
+	assert.False(t, read[1].IsValid())
+	assert.False(t, read[2].IsSnapshot())
+
+	out := new(bytes.Buffer)
+	require.NoError(t, RecordingLog(out, dir))
+	assert.Contains(t, out.String(), "Entry{index=1, recordingId=11, leadershipTermId=0, termBaseLogPosition=0, logPosition=2048, timestamp=1500, serviceId=0, type=SNAPSHOT, isValid=false}")
+
+	_, err = cluster.DecodeRecordingLog(data[:10])
+	assert.Error(t, err)
+}
@@ -0,0 +1,118 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command clustertool inspects a cluster directory.
+//
+//	clustertool [-timeout 10s] <cluster-dir> <command>
+//
+// list-members connects to the member's media driver, configured like the
+// member from AERON_ environment variables such as AERON_DIR and
+// AERON_CLUSTER_CONTROL_CHANNEL, and reads the stream ids from the consensus
+// module's mark file.
+//
+// Commands:
+//
+//	describe       headers of all mark files
+//	pid            pid of the first component found, the consensus module if present
+//	errors         distinct errors recorded in the mark files
+//	recording-log  terms and snapshots in the recording log
+//	is-alive       exits with status 0 if all mark files have recent activity, otherwise 1
+//	list-members   queries the running consensus module for the members of the cluster
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/clustertool"
+)
+
+func main() {
+	timeout := flag.Duration("timeout", clustertool.DefaultActivityTimeout, "activity timeout for is-alive, and reply timeout for list-members")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(),
+			"Usage: %s [options] <cluster-dir> describe|pid|errors|recording-log|is-alive|list-members\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	clusterDir, command := flag.Arg(0), flag.Arg(1)
+
+	var err error
+	switch command {
+	case "describe":
+		err = clustertool.Describe(os.Stdout, clusterDir)
+	case "pid":
+		var infos []*clustertool.MarkFileInfo
+		if infos, err = clustertool.ReadMarkFiles(clusterDir); err == nil {
+			fmt.Println(infos[0].Pid)
+		}
+	case "errors":
+		err = clustertool.Errors(os.Stdout, clusterDir)
+	case "recording-log":
+		err = clustertool.RecordingLog(os.Stdout, clusterDir)
+	case "is-alive":
+		var alive bool
+		if alive, err = clustertool.IsAlive(clusterDir, *timeout); err == nil {
+			fmt.Println(alive)
+			if !alive {
+				os.Exit(1)
+			}
+		}
+	case "list-members":
+		err = listMembers(clusterDir, *timeout)
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %v\n", command, err)
+		os.Exit(1)
+	}
+}
+
+func listMembers(clusterDir string, timeout time.Duration) error {
+	info, err := clustertool.ReadMarkFile(filepath.Join(clusterDir, cluster.MarkFileFilename))
+	if err != nil {
+		return err
+	}
+	opts, err := cluster.NewOptionsFromEnv()
+	if err != nil {
+		return err
+	}
+	opts.ConsensusModuleStreamId = info.ConsensusModuleStreamId
+	opts.ServiceStreamId = info.ServiceStreamId
+	ctx, err := aeron.NewContextFromEnv()
+	if err != nil {
+		return err
+	}
+	client, err := aeron.Connect(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	members, err := clustertool.QueryClusterMembers(client, opts, timeout)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("leaderMemberId=%d\nactiveMembers=%s\npassiveFollowers=%s\n",
+		members.LeaderMemberId, members.ActiveMembers, members.PassiveFollowers)
+	return nil
+}
@@ -0,0 +1,236 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clustertool
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+)
+
+// ErrQueryTimeout is returned when the consensus module did not reply to a
+// query in time
+var ErrQueryTimeout = errors.New("timed out waiting for the consensus module")
+
+// ClusterMembers is the consensus module's reply to a cluster members query.
+// The members are encoded as '|' separated lists of
+// "memberId,ingressEndpoint,consensusEndpoint,logEndpoint,catchupEndpoint,archiveEndpoint".
+type ClusterMembers struct {
+	CorrelationId    int64
+	LeaderMemberId   int32
+	ActiveMembers    string
+	PassiveFollowers string
+}
+
+// QueryClusterMembers asks the consensus module of a running member for the
+// membership of the cluster. As in the Java ClusterTool, the query is offered
+// on the control channel to the consensus module's stream and the reply is
+// read from the service stream, so client must be connected to the member's
+// media driver and opts must match the member's control channel and stream
+// ids. Returns ErrQueryTimeout if there is no reply within timeout.
+func QueryClusterMembers(client *aeron.Aeron, opts *cluster.Options, timeout time.Duration) (*ClusterMembers, error) {
+	query, err := newControlQuery(client, opts, false)
+	if err != nil {
+		return nil, err
+	}
+	defer query.close()
+	if err := query.run(timeout); err != nil {
+		return nil, err
+	}
+	return query.members, nil
+}
+
+// QueryClusterMembersExtended is QueryClusterMembers, replying with the
+// state of each member
+func QueryClusterMembersExtended(
+	client *aeron.Aeron,
+	opts *cluster.Options,
+	timeout time.Duration,
+) (*codecs.ClusterMembersExtendedResponse, error) {
+	query, err := newControlQuery(client, opts, true)
+	if err != nil {
+		return nil, err
+	}
+	defer query.close()
+	if err := query.run(timeout); err != nil {
+		return nil, err
+	}
+	return query.extendedMembers, nil
+}
+
+type offerer interface {
+	Offer(buffer *atomic.Buffer, offset, length int32, reservedValueSupplier term.ReservedValueSupplier) int64
+}
+
+type poller interface {
+	Poll(handler term.FragmentHandler, fragmentLimit int) int
+}
+
+// membersQuery offers a cluster members query to the consensus module and
+// polls for the reply with its correlation id
+type membersQuery struct {
+	publication     offerer
+	subscription    poller
+	closers         []func() error
+	marshaller      *codecs.SbeGoMarshaller
+	assembler       *aeron.FragmentAssembler
+	idleStrategy    idlestrategy.Idler
+	correlationId   int64
+	extended        bool
+	done            bool
+	err             error
+	members         *ClusterMembers
+	extendedMembers *codecs.ClusterMembersExtendedResponse
+}
+
+func newControlQuery(client *aeron.Aeron, opts *cluster.Options, extended bool) (*membersQuery, error) {
+	sub, err := client.AddSubscription(opts.ControlChannel, opts.ServiceStreamId)
+	if err != nil {
+		return nil, err
+	}
+	pub, err := client.AddPublication(opts.ControlChannel, opts.ConsensusModuleStreamId)
+	if err != nil {
+		sub.Close()
+		return nil, err
+	}
+	query := newMembersQuery(pub, sub, client.NextCorrelationID(), extended)
+	query.closers = []func() error{pub.Close, sub.Close}
+	return query, nil
+}
+
+func newMembersQuery(publication offerer, subscription poller, correlationId int64, extended bool) *membersQuery {
+	query := &membersQuery{
+		publication:   publication,
+		subscription:  subscription,
+		marshaller:    codecs.NewSbeGoMarshaller(),
+		idleStrategy:  idlestrategy.Sleeping{SleepFor: time.Millisecond},
+		correlationId: correlationId,
+		extended:      extended,
+	}
+	query.assembler = aeron.NewFragmentAssembler(query.onFragment, aeron.DefaultFragmentAssemblyBufferLength)
+	return query
+}
+
+func (query *membersQuery) close() {
+	for _, closer := range query.closers {
+		closer()
+	}
+}
+
+// run offers the query until it is accepted, then polls for the reply, both
+// within timeout
+func (query *membersQuery) run(timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	buffer, err := query.encode()
+	if err != nil {
+		return err
+	}
+	for {
+		result := query.publication.Offer(buffer, 0, buffer.Capacity(), nil)
+		if result >= 0 {
+			break
+		}
+		if result == aeron.PublicationClosed || result == aeron.MaxPositionExceeded {
+			return fmt.Errorf("cluster members query not sent: %d", result)
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w to accept the cluster members query: %d", ErrQueryTimeout, result)
+		}
+		query.idleStrategy.Idle(0)
+	}
+	for !query.done {
+		fragments := query.subscription.Poll(query.assembler.OnFragment, 10)
+		if query.done {
+			break
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w to reply to the cluster members query", ErrQueryTimeout)
+		}
+		query.idleStrategy.Idle(fragments)
+	}
+	return query.err
+}
+
+func (query *membersQuery) encode() (*atomic.Buffer, error) {
+	request := codecs.ClusterMembersQuery{
+		CorrelationId: query.correlationId,
+		Extended:      codecs.BooleanType.FALSE,
+	}
+	if query.extended {
+		request.Extended = codecs.BooleanType.TRUE
+	}
+	header := codecs.MessageHeader{
+		BlockLength: request.SbeBlockLength(),
+		TemplateId:  request.SbeTemplateId(),
+		SchemaId:    request.SbeSchemaId(),
+		Version:     request.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	if err := header.Encode(query.marshaller, writer); err != nil {
+		return nil, err
+	}
+	if err := request.Encode(query.marshaller, writer, true); err != nil {
+		return nil, err
+	}
+	return atomic.NewBufferSlice(writer.Bytes()), nil
+}
+
+// onFragment handles the replies on the service stream, ignoring the other
+// messages the consensus module sends its services
+func (query *membersQuery) onFragment(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+	if length < cluster.SBEHeaderLength || query.done {
+		return
+	}
+	blockLength := buffer.GetUInt16(offset)
+	templateId := buffer.GetUInt16(offset + 2)
+	schemaId := buffer.GetUInt16(offset + 4)
+	version := buffer.GetUInt16(offset + 6)
+	if schemaId != cluster.ClusterSchemaId {
+		return
+	}
+	buf := new(bytes.Buffer)
+	buffer.WriteBytes(buf, offset+cluster.SBEHeaderLength, length-cluster.SBEHeaderLength)
+
+	switch {
+	case templateId == cluster.ClusterMembersResponseTemplateId && !query.extended:
+		var response codecs.ClusterMembersResponse
+		if err := response.Decode(query.marshaller, buf, version, blockLength, true); err != nil {
+			query.done, query.err = true, fmt.Errorf("cluster members response: %w", err)
+		} else if response.CorrelationId == query.correlationId {
+			query.done = true
+			query.members = &ClusterMembers{
+				CorrelationId:    response.CorrelationId,
+				LeaderMemberId:   response.LeaderMemberId,
+				ActiveMembers:    string(response.ActiveMembers),
+				PassiveFollowers: string(response.PassiveFollowers),
+			}
+		}
+	case templateId == cluster.ClusterMembersExtendedResponseTemplateId && query.extended:
+		var response codecs.ClusterMembersExtendedResponse
+		if err := response.Decode(query.marshaller, buf, version, blockLength, true); err != nil {
+			query.done, query.err = true, fmt.Errorf("cluster members extended response: %w", err)
+		} else if response.CorrelationId == query.correlationId {
+			query.done = true
+			query.extendedMembers = &response
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package clustertool
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logbuffer/term"
+	"github.com/lirm/aeron-go/cluster"
+	"github.com/lirm/aeron-go/cluster/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testConsensusModule answers cluster members queries from its control stream
+// on the service stream, as the consensus module does
+type testConsensusModule struct {
+	t             *testing.T
+	marshaller    *codecs.SbeGoMarshaller
+	controlImage  aeron.Image
+	servicePub    *aeron.Publication
+	queries       []codecs.ClusterMembersQuery
+	templateIds   []uint16
+	replyToOthers bool
+}
+
+// serviceStream is the service stream as seen by the querying tool, which
+// runs a duty cycle of the consensus module before each poll
+type serviceStream struct {
+	consensusModule *testConsensusModule
+	image           aeron.Image
+}
+
+func (s *serviceStream) Poll(handler term.FragmentHandler, fragmentLimit int) int {
+	s.consensusModule.doWork()
+	return s.image.Poll(handler, fragmentLimit)
+}
+
+func newTestQuery(t *testing.T, correlationId int64, extended bool) (*membersQuery, *testConsensusModule) {
+	opts := cluster.NewOptions()
+	controlPub, controlImage := aeron.NewInMemoryStream(opts.ControlChannel, opts.ConsensusModuleStreamId, 1, logbuffer.TermMinLength)
+	servicePub, serviceImage := aeron.NewInMemoryStream(opts.ControlChannel, opts.ServiceStreamId, 2, logbuffer.TermMinLength)
+	consensusModule := &testConsensusModule{
+		t:            t,
+		marshaller:   codecs.NewSbeGoMarshaller(),
+		controlImage: controlImage,
+		servicePub:   servicePub,
+	}
+	query := newMembersQuery(controlPub, &serviceStream{consensusModule, serviceImage}, correlationId, extended)
+	return query, consensusModule
+}
+
+func (cm *testConsensusModule) doWork() {
+	cm.controlImage.Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+		var msgHeader codecs.MessageHeader
+		reader := bytes.NewReader(buffer.GetBytesArray(offset, length))
+		require.NoError(cm.t, msgHeader.Decode(cm.marshaller, reader, 0))
+		cm.templateIds = append(cm.templateIds, msgHeader.TemplateId)
+		if msgHeader.TemplateId != cluster.ClusterMembersQueryTemplateId {
+			return
+		}
+		var query codecs.ClusterMembersQuery
+		require.NoError(cm.t, query.Decode(cm.marshaller, reader, msgHeader.Version, msgHeader.BlockLength, true))
+		cm.queries = append(cm.queries, query)
+
+		// Services share the stream, so the tool must skip their messages
+		cm.reply(&codecs.ServiceTerminationPosition{LogPosition: 1024})
+		if cm.replyToOthers {
+			cm.reply(&codecs.ClusterMembersResponse{CorrelationId: query.CorrelationId + 1, LeaderMemberId: 9})
+		}
+		if query.Extended == codecs.BooleanType.TRUE {
+			cm.reply(&codecs.ClusterMembersExtendedResponse{
+				CorrelationId:  query.CorrelationId,
+				LeaderMemberId: 1,
+				ActiveMembers: []codecs.ClusterMembersExtendedResponseActiveMembers{{
+					LeadershipTermId: 3,
+					LogPosition:      1024,
+					MemberId:         1,
+					IngressEndpoint:  []byte("in:1"),
+				}},
+			})
+		} else {
+			cm.reply(&codecs.ClusterMembersResponse{
+				CorrelationId:  query.CorrelationId,
+				LeaderMemberId: 1,
+				ActiveMembers:  []byte("0,in:0,cons:0,log:0,catch:0,arch:0|1,in:1,cons:1,log:1,catch:1,arch:1"),
+			})
+		}
+	}, 10)
+}
+
+type encodable interface {
+	SbeBlockLength() uint16
+	SbeTemplateId() uint16
+	SbeSchemaId() uint16
+	SbeSchemaVersion() uint16
+	Encode(_m *codecs.SbeGoMarshaller, _w io.Writer, doRangeCheck bool) error
+}
+
+func (cm *testConsensusModule) reply(msg encodable) {
+	header := codecs.MessageHeader{
+		BlockLength: msg.SbeBlockLength(),
+		TemplateId:  msg.SbeTemplateId(),
+		SchemaId:    msg.SbeSchemaId(),
+		Version:     msg.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	require.NoError(cm.t, header.Encode(cm.marshaller, writer))
+	require.NoError(cm.t, msg.Encode(cm.marshaller, writer, true))
+	buffer := atomic.NewBufferSlice(writer.Bytes())
+	require.Positive(cm.t, cm.servicePub.Offer(buffer, 0, buffer.Capacity(), nil))
+}
+
+func TestQueryClusterMembers(t *testing.T) {
+	query, consensusModule := newTestQuery(t, 42, false)
+	consensusModule.replyToOthers = true
+	require.NoError(t, query.run(time.Second))
+
+	assert.Equal(t, []uint16{cluster.ClusterMembersQueryTemplateId}, consensusModule.templateIds)
+	require.Len(t, consensusModule.queries, 1)
+	assert.EqualValues(t, 42, consensusModule.queries[0].CorrelationId)
+	assert.Equal(t, codecs.BooleanType.FALSE, consensusModule.queries[0].Extended)
+	assert.Equal(t, &ClusterMembers{
+		CorrelationId:  42,
+		LeaderMemberId: 1,
+		ActiveMembers:  "0,in:0,cons:0,log:0,catch:0,arch:0|1,in:1,cons:1,log:1,catch:1,arch:1",
+	}, query.members)
+	assert.Nil(t, query.extendedMembers)
+}
+
+func TestQueryClusterMembers_Extended(t *testing.T) {
+	query, consensusModule := newTestQuery(t, 43, true)
+	require.NoError(t, query.run(time.Second))
+
+	require.Len(t, consensusModule.queries, 1)
+	assert.Equal(t, codecs.BooleanType.TRUE, consensusModule.queries[0].Extended)
+	require.NotNil(t, query.extendedMembers)
+	assert.EqualValues(t, 43, query.extendedMembers.CorrelationId)
+	require.Len(t, query.extendedMembers.ActiveMembers, 1)
+	assert.EqualValues(t, 1024, query.extendedMembers.ActiveMembers[0].LogPosition)
+	assert.Equal(t, "in:1", string(query.extendedMembers.ActiveMembers[0].IngressEndpoint))
+	assert.Nil(t, query.members)
+}
+
+func TestQueryClusterMembers_Timeout(t *testing.T) {
+	opts := cluster.NewOptions()
+	controlPub, _ := aeron.NewInMemoryStream(opts.ControlChannel, opts.ConsensusModuleStreamId, 1, logbuffer.TermMinLength)
+	_, serviceImage := aeron.NewInMemoryStream(opts.ControlChannel, opts.ServiceStreamId, 2, logbuffer.TermMinLength)
+	query := newMembersQuery(controlPub, serviceImage, 42, false)
+	assert.ErrorIs(t, query.run(10*time.Millisecond), ErrQueryTimeout)
+}
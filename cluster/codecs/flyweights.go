@@ -0,0 +1,567 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package codecs
+
+import (
+	"fmt"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+)
+
+// Flyweight decoders and encoders
+//
+// These read and write messages in place on an atomic.Buffer, in the manner
+// of the flyweights SBE generates for Java and C++, so neither copy the
+// message nor allocate. They cover the messages of the service log, the
+// egress and the service's requests to the consensus module; the generated
+// codecs remain for everything else.
+//
+// A decoder wraps the message body following the MessageHeader with the
+// block length and version from that header. Fields added after the acting
+// version read as their null value, and variable length fields, which follow
+// the block, must be read or skipped in schema order. Variable length fields
+// are bounded by the end of the buffer, or of the message if its length is
+// set with SetMessageLength.
+
+// MessageHeaderLength is the encoded length of a MessageHeader
+const MessageHeaderLength = 8
+
+// flyweight holds the position of a wrapped message body
+type flyweight struct {
+	buffer            *atomic.Buffer
+	offset            int32
+	actingBlockLength uint16
+	actingVersion     uint16
+	limit             int32 // offset of the next variable length field
+	messageLimit      int32 // offset just past the end of the message
+}
+
+func (f *flyweight) wrap(buffer *atomic.Buffer, offset int32, actingBlockLength uint16, actingVersion uint16) {
+	f.buffer = buffer
+	f.offset = offset
+	f.actingBlockLength = actingBlockLength
+	f.actingVersion = actingVersion
+	f.limit = offset + int32(actingBlockLength)
+	f.messageLimit = buffer.Capacity()
+}
+
+// SetMessageLength bounds the variable length fields by the length of the
+// message body, rather than the capacity of the buffer
+func (f *flyweight) SetMessageLength(length int32) {
+	f.messageLimit = f.offset + length
+	if f.messageLimit > f.buffer.Capacity() {
+		f.messageLimit = f.buffer.Capacity()
+	}
+}
+
+// Buffer returns the wrapped buffer
+func (f *flyweight) Buffer() *atomic.Buffer {
+	return f.buffer
+}
+
+// Offset returns the offset of the message body in the buffer
+func (f *flyweight) Offset() int32 {
+	return f.offset
+}
+
+// Limit returns the offset just past the fields read so far
+func (f *flyweight) Limit() int32 {
+	return f.limit
+}
+
+// varDataLength reads the length of the next variable length field. It fails
+// if the field's header or data run past the end of the message.
+func (f *flyweight) varDataLength() (int32, error) {
+	if f.limit+4 > f.messageLimit {
+		return 0, fmt.Errorf("var data header at %d beyond message limit %d", f.limit, f.messageLimit)
+	}
+	length := f.buffer.GetInt32(f.limit)
+	if length < 0 || length > f.messageLimit-f.limit-4 {
+		return 0, fmt.Errorf("invalid var data length %d at %d, message limit %d", length, f.limit, f.messageLimit)
+	}
+	return length, nil
+}
+
+// getVarData appends the next variable length field to dst
+func (f *flyweight) getVarData(dst []byte) ([]byte, error) {
+	length, err := f.varDataLength()
+	if err != nil {
+		return dst, err
+	}
+	start := len(dst)
+	if cap(dst)-start < int(length) {
+		grown := make([]byte, start+int(length))
+		copy(grown, dst)
+		dst = grown
+	} else {
+		dst = dst[:start+int(length)]
+	}
+	f.buffer.GetBytes(f.limit+4, dst[start:])
+	f.limit += 4 + length
+	return dst, nil
+}
+
+func (f *flyweight) skipVarData() (int32, error) {
+	length, err := f.varDataLength()
+	if err != nil {
+		return 0, err
+	}
+	f.limit += 4 + length
+	return length, nil
+}
+
+// MessageHeaderDecoder reads the MessageHeader in front of each message
+type MessageHeaderDecoder struct {
+	buffer *atomic.Buffer
+	offset int32
+}
+
+func (d *MessageHeaderDecoder) Wrap(buffer *atomic.Buffer, offset int32) *MessageHeaderDecoder {
+	d.buffer = buffer
+	d.offset = offset
+	return d
+}
+
+func (d *MessageHeaderDecoder) BlockLength() uint16 {
+	return d.buffer.GetUInt16(d.offset)
+}
+
+func (d *MessageHeaderDecoder) TemplateId() uint16 {
+	return d.buffer.GetUInt16(d.offset + 2)
+}
+
+func (d *MessageHeaderDecoder) SchemaId() uint16 {
+	return d.buffer.GetUInt16(d.offset + 4)
+}
+
+func (d *MessageHeaderDecoder) Version() uint16 {
+	return d.buffer.GetUInt16(d.offset + 6)
+}
+
+// MessageHeaderEncoder writes the MessageHeader in front of each message
+type MessageHeaderEncoder struct {
+	buffer *atomic.Buffer
+	offset int32
+}
+
+func (e *MessageHeaderEncoder) Wrap(buffer *atomic.Buffer, offset int32) *MessageHeaderEncoder {
+	e.buffer = buffer
+	e.offset = offset
+	return e
+}
+
+// Set all the fields of the header
+func (e *MessageHeaderEncoder) Set(blockLength, templateId, schemaId, version uint16) *MessageHeaderEncoder {
+	e.buffer.PutUInt16(e.offset, blockLength)
+	e.buffer.PutUInt16(e.offset+2, templateId)
+	e.buffer.PutUInt16(e.offset+4, schemaId)
+	e.buffer.PutUInt16(e.offset+6, version)
+	return e
+}
+
+// SessionMessageHeaderDecoder reads the header of a message from a client or service
+type SessionMessageHeaderDecoder struct {
+	flyweight
+}
+
+func (d *SessionMessageHeaderDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *SessionMessageHeaderDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *SessionMessageHeaderDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *SessionMessageHeaderDecoder) ClusterSessionId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *SessionMessageHeaderDecoder) Timestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+// SessionMessageHeaderEncoder writes the header of a message from a client or service
+type SessionMessageHeaderEncoder struct {
+	buffer *atomic.Buffer
+	offset int32
+}
+
+// WrapAndApplyHeader writes the MessageHeader at offset and wraps the body which follows it
+func (e *SessionMessageHeaderEncoder) WrapAndApplyHeader(buffer *atomic.Buffer, offset int32) *SessionMessageHeaderEncoder {
+	var s SessionMessageHeader
+	var hdr MessageHeaderEncoder
+	hdr.Wrap(buffer, offset).Set(s.SbeBlockLength(), s.SbeTemplateId(), s.SbeSchemaId(), s.SbeSchemaVersion())
+	e.buffer = buffer
+	e.offset = offset + MessageHeaderLength
+	return e
+}
+
+func (e *SessionMessageHeaderEncoder) SetLeadershipTermId(value int64) *SessionMessageHeaderEncoder {
+	e.buffer.PutInt64(e.offset, value)
+	return e
+}
+
+func (e *SessionMessageHeaderEncoder) SetClusterSessionId(value int64) *SessionMessageHeaderEncoder {
+	e.buffer.PutInt64(e.offset+8, value)
+	return e
+}
+
+func (e *SessionMessageHeaderEncoder) SetTimestamp(value int64) *SessionMessageHeaderEncoder {
+	e.buffer.PutInt64(e.offset+16, value)
+	return e
+}
+
+// EncodedLength is the length of the MessageHeader and the SessionMessageHeader
+func (e *SessionMessageHeaderEncoder) EncodedLength() int32 {
+	return MessageHeaderLength + 24
+}
+
+// TimerEventDecoder reads a TimerEvent from the log
+type TimerEventDecoder struct {
+	flyweight
+}
+
+func (d *TimerEventDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *TimerEventDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *TimerEventDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *TimerEventDecoder) CorrelationId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *TimerEventDecoder) Timestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+// SessionOpenEventDecoder reads a SessionOpenEvent from the log
+type SessionOpenEventDecoder struct {
+	flyweight
+}
+
+func (d *SessionOpenEventDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *SessionOpenEventDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *SessionOpenEventDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *SessionOpenEventDecoder) CorrelationId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *SessionOpenEventDecoder) ClusterSessionId() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *SessionOpenEventDecoder) Timestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 24)
+}
+
+func (d *SessionOpenEventDecoder) ResponseStreamId() int32 {
+	return d.buffer.GetInt32(d.offset + 32)
+}
+
+// GetResponseChannel appends the response channel to dst
+func (d *SessionOpenEventDecoder) GetResponseChannel(dst []byte) ([]byte, error) {
+	return d.getVarData(dst)
+}
+
+func (d *SessionOpenEventDecoder) SkipResponseChannel() (int32, error) {
+	return d.skipVarData()
+}
+
+// GetEncodedPrincipal appends the encoded principal to dst, after the response channel is read or skipped
+func (d *SessionOpenEventDecoder) GetEncodedPrincipal(dst []byte) ([]byte, error) {
+	return d.getVarData(dst)
+}
+
+// SessionCloseEventDecoder reads a SessionCloseEvent from the log
+type SessionCloseEventDecoder struct {
+	flyweight
+}
+
+func (d *SessionCloseEventDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *SessionCloseEventDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *SessionCloseEventDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *SessionCloseEventDecoder) ClusterSessionId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *SessionCloseEventDecoder) Timestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *SessionCloseEventDecoder) CloseReason() CloseReasonEnum {
+	return CloseReasonEnum(d.buffer.GetInt32(d.offset + 24))
+}
+
+// ClusterActionRequestDecoder reads a ClusterActionRequest from the log
+type ClusterActionRequestDecoder struct {
+	flyweight
+}
+
+// clusterActionRequestFlagsOffset is where later protocol versions add the
+// flags to the end of the block
+const clusterActionRequestFlagsOffset = 28
+
+func (d *ClusterActionRequestDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *ClusterActionRequestDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *ClusterActionRequestDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *ClusterActionRequestDecoder) LogPosition() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *ClusterActionRequestDecoder) Timestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *ClusterActionRequestDecoder) Action() ClusterActionEnum {
+	return ClusterActionEnum(d.buffer.GetInt32(d.offset + 24))
+}
+
+// Flags qualifying the action, or 0 if the block predates them
+func (d *ClusterActionRequestDecoder) Flags() int32 {
+	if d.actingBlockLength < clusterActionRequestFlagsOffset+4 {
+		return 0
+	}
+	return d.buffer.GetInt32(d.offset + clusterActionRequestFlagsOffset)
+}
+
+// NewLeadershipTermEventDecoder reads a NewLeadershipTermEvent from the log
+type NewLeadershipTermEventDecoder struct {
+	flyweight
+}
+
+func (d *NewLeadershipTermEventDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *NewLeadershipTermEventDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *NewLeadershipTermEventDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *NewLeadershipTermEventDecoder) LogPosition() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *NewLeadershipTermEventDecoder) Timestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *NewLeadershipTermEventDecoder) TermBaseLogPosition() int64 {
+	return d.buffer.GetInt64(d.offset + 24)
+}
+
+func (d *NewLeadershipTermEventDecoder) LeaderMemberId() int32 {
+	return d.buffer.GetInt32(d.offset + 32)
+}
+
+func (d *NewLeadershipTermEventDecoder) LogSessionId() int32 {
+	return d.buffer.GetInt32(d.offset + 36)
+}
+
+func (d *NewLeadershipTermEventDecoder) TimeUnit() ClusterTimeUnitEnum {
+	var e NewLeadershipTermEvent
+	if !e.TimeUnitInActingVersion(d.actingVersion) {
+		return ClusterTimeUnit.NullValue
+	}
+	return ClusterTimeUnitEnum(d.buffer.GetInt32(d.offset + 40))
+}
+
+func (d *NewLeadershipTermEventDecoder) AppVersion() int32 {
+	var e NewLeadershipTermEvent
+	if !e.AppVersionInActingVersion(d.actingVersion) {
+		return e.AppVersionNullValue()
+	}
+	return d.buffer.GetInt32(d.offset + 44)
+}
+
+// MembershipChangeEventDecoder reads a MembershipChangeEvent from the log
+type MembershipChangeEventDecoder struct {
+	flyweight
+}
+
+func (d *MembershipChangeEventDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *MembershipChangeEventDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *MembershipChangeEventDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *MembershipChangeEventDecoder) LogPosition() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *MembershipChangeEventDecoder) Timestamp() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *MembershipChangeEventDecoder) LeaderMemberId() int32 {
+	return d.buffer.GetInt32(d.offset + 24)
+}
+
+func (d *MembershipChangeEventDecoder) ClusterSize() int32 {
+	return d.buffer.GetInt32(d.offset + 28)
+}
+
+func (d *MembershipChangeEventDecoder) ChangeType() ChangeTypeEnum {
+	return ChangeTypeEnum(d.buffer.GetInt32(d.offset + 32))
+}
+
+func (d *MembershipChangeEventDecoder) MemberId() int32 {
+	return d.buffer.GetInt32(d.offset + 36)
+}
+
+// GetClusterMembers appends the cluster members to dst
+func (d *MembershipChangeEventDecoder) GetClusterMembers(dst []byte) ([]byte, error) {
+	return d.getVarData(dst)
+}
+
+// SessionEventDecoder reads a SessionEvent from the egress
+type SessionEventDecoder struct {
+	flyweight
+}
+
+func (d *SessionEventDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *SessionEventDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *SessionEventDecoder) ClusterSessionId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *SessionEventDecoder) CorrelationId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *SessionEventDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset + 16)
+}
+
+func (d *SessionEventDecoder) LeaderMemberId() int32 {
+	return d.buffer.GetInt32(d.offset + 24)
+}
+
+func (d *SessionEventDecoder) Code() EventCodeEnum {
+	return EventCodeEnum(d.buffer.GetInt32(d.offset + 28))
+}
+
+func (d *SessionEventDecoder) Version() int32 {
+	var e SessionEvent
+	if !e.VersionInActingVersion(d.actingVersion) {
+		return e.VersionNullValue()
+	}
+	return d.buffer.GetInt32(d.offset + 32)
+}
+
+// GetDetail appends the detail to dst
+func (d *SessionEventDecoder) GetDetail(dst []byte) ([]byte, error) {
+	return d.getVarData(dst)
+}
+
+// NewLeaderEventDecoder reads a NewLeaderEvent from the egress
+type NewLeaderEventDecoder struct {
+	flyweight
+}
+
+func (d *NewLeaderEventDecoder) Wrap(buffer *atomic.Buffer, offset int32, actingBlockLength, actingVersion uint16) *NewLeaderEventDecoder {
+	d.wrap(buffer, offset, actingBlockLength, actingVersion)
+	return d
+}
+
+func (d *NewLeaderEventDecoder) LeadershipTermId() int64 {
+	return d.buffer.GetInt64(d.offset)
+}
+
+func (d *NewLeaderEventDecoder) ClusterSessionId() int64 {
+	return d.buffer.GetInt64(d.offset + 8)
+}
+
+func (d *NewLeaderEventDecoder) LeaderMemberId() int32 {
+	return d.buffer.GetInt32(d.offset + 16)
+}
+
+// GetIngressEndpoints appends the ingress endpoints to dst
+func (d *NewLeaderEventDecoder) GetIngressEndpoints(dst []byte) ([]byte, error) {
+	return d.getVarData(dst)
+}
+
+// ServiceAckEncoder writes a ServiceAck to the consensus module
+type ServiceAckEncoder struct {
+	buffer *atomic.Buffer
+	offset int32
+}
+
+// WrapAndApplyHeader writes the MessageHeader at offset and wraps the body which follows it
+func (e *ServiceAckEncoder) WrapAndApplyHeader(buffer *atomic.Buffer, offset int32) *ServiceAckEncoder {
+	var s ServiceAck
+	var hdr MessageHeaderEncoder
+	hdr.Wrap(buffer, offset).Set(s.SbeBlockLength(), s.SbeTemplateId(), s.SbeSchemaId(), s.SbeSchemaVersion())
+	e.buffer = buffer
+	e.offset = offset + MessageHeaderLength
+	return e
+}
+
+func (e *ServiceAckEncoder) SetLogPosition(value int64) *ServiceAckEncoder {
+	e.buffer.PutInt64(e.offset, value)
+	return e
+}
+
+func (e *ServiceAckEncoder) SetTimestamp(value int64) *ServiceAckEncoder {
+	e.buffer.PutInt64(e.offset+8, value)
+	return e
+}
+
+func (e *ServiceAckEncoder) SetAckId(value int64) *ServiceAckEncoder {
+	e.buffer.PutInt64(e.offset+16, value)
+	return e
+}
+
+func (e *ServiceAckEncoder) SetRelevantId(value int64) *ServiceAckEncoder {
+	e.buffer.PutInt64(e.offset+24, value)
+	return e
+}
+
+func (e *ServiceAckEncoder) SetServiceId(value int32) *ServiceAckEncoder {
+	e.buffer.PutInt32(e.offset+32, value)
+	return e
+}
+
+// EncodedLength is the length of the MessageHeader and the ServiceAck
+func (e *ServiceAckEncoder) EncodedLength() int32 {
+	return MessageHeaderLength + 36
+}
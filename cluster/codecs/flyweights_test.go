@@ -0,0 +1,170 @@
+package codecs
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func encode(t *testing.T, blockLength, templateId, version uint16, body func(m *SbeGoMarshaller, w *bytes.Buffer) error) *atomic.Buffer {
+	m := NewSbeGoMarshaller()
+	w := new(bytes.Buffer)
+	header := MessageHeader{BlockLength: blockLength, TemplateId: templateId, SchemaId: 111, Version: version}
+	require.NoError(t, header.Encode(m, w))
+	require.NoError(t, body(m, w))
+	return atomic.NewBufferSlice(w.Bytes())
+}
+
+func encodeSessionEvent(t *testing.T, e *SessionEvent) *atomic.Buffer {
+	return encode(t, e.SbeBlockLength(), e.SbeTemplateId(), e.SbeSchemaVersion(), func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+		return e.Encode(m, w, true)
+	})
+}
+
+func TestSessionEventDecoder(t *testing.T) {
+	buf := encodeSessionEvent(t, &SessionEvent{
+		ClusterSessionId: 1,
+		CorrelationId:    2,
+		LeadershipTermId: 3,
+		LeaderMemberId:   4,
+		Code:             EventCode.REDIRECT,
+		Version:          5,
+		Detail:           []uint8("0=localhost:9010"),
+	})
+
+	var hdr MessageHeaderDecoder
+	hdr.Wrap(buf, 0)
+	var d SessionEventDecoder
+	d.Wrap(buf, MessageHeaderLength, hdr.BlockLength(), hdr.Version())
+	assert.Equal(t, int64(1), d.ClusterSessionId())
+	assert.Equal(t, int64(2), d.CorrelationId())
+	assert.Equal(t, int64(3), d.LeadershipTermId())
+	assert.Equal(t, int32(4), d.LeaderMemberId())
+	assert.Equal(t, EventCode.REDIRECT, d.Code())
+	assert.Equal(t, int32(5), d.Version())
+	detail, err := d.GetDetail(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "0=localhost:9010", string(detail))
+	assert.Equal(t, buf.Capacity(), d.Limit())
+}
+
+func TestSessionEventDecoder_OlderVersion(t *testing.T) {
+	// Version 5 of the schema precedes the SessionEvent's Version field
+	var e SessionEvent
+	buf := encode(t, 32, e.SbeTemplateId(), 5, func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+		for _, v := range []int64{1, 2, 3} {
+			if err := m.WriteInt64(w, v); err != nil {
+				return err
+			}
+		}
+		if err := m.WriteInt32(w, 4); err != nil {
+			return err
+		}
+		if err := m.WriteInt32(w, int32(EventCode.OK)); err != nil {
+			return err
+		}
+		if err := m.WriteUint32(w, 2); err != nil {
+			return err
+		}
+		return m.WriteBytes(w, []byte("ok"))
+	})
+
+	var d SessionEventDecoder
+	d.Wrap(buf, MessageHeaderLength, 32, 5)
+	assert.Equal(t, EventCode.OK, d.Code())
+	assert.Equal(t, e.VersionNullValue(), d.Version())
+	detail, err := d.GetDetail(nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", string(detail))
+}
+
+func TestSessionEventDecoder_InvalidDetail(t *testing.T) {
+	buf := encodeSessionEvent(t, &SessionEvent{Code: EventCode.ERROR, Detail: []uint8("failed")})
+	detailOffset := MessageHeaderLength + int32((&SessionEvent{}).SbeBlockLength())
+	var d SessionEventDecoder
+
+	// The message ends before the var data header
+	d.Wrap(buf, MessageHeaderLength, 36, 8)
+	d.SetMessageLength(detailOffset - MessageHeaderLength + 2)
+	_, err := d.GetDetail(nil)
+	assert.EqualError(t, err, "var data header at 44 beyond message limit 46")
+
+	// The message ends before the var data
+	d.Wrap(buf, MessageHeaderLength, 36, 8)
+	d.SetMessageLength(detailOffset - MessageHeaderLength + 6)
+	_, err = d.GetDetail(nil)
+	assert.EqualError(t, err, "invalid var data length 6 at 44, message limit 50")
+
+	negative := atomic.NewBufferSlice(buf.GetBytesArray(0, buf.Capacity()))
+	negative.PutInt32(detailOffset, -1)
+	d.Wrap(negative, MessageHeaderLength, 36, 8)
+	_, err = d.GetDetail(nil)
+	assert.EqualError(t, err, "invalid var data length -1 at 44, message limit 54")
+
+	oversized := atomic.NewBufferSlice(buf.GetBytesArray(0, buf.Capacity()))
+	oversized.PutInt32(detailOffset, 1<<30)
+	d.Wrap(oversized, MessageHeaderLength, 36, 8)
+	_, err = d.GetDetail(nil)
+	assert.EqualError(t, err, "invalid var data length 1073741824 at 44, message limit 54")
+
+	// The limit does not advance past an invalid field
+	assert.Equal(t, detailOffset, d.Limit())
+}
+
+func TestClusterActionRequestDecoder_Flags(t *testing.T) {
+	req := &ClusterActionRequest{LeadershipTermId: 1, LogPosition: 2, Timestamp: 3, Action: ClusterAction.SNAPSHOT}
+	buf := encode(t, req.SbeBlockLength(), req.SbeTemplateId(), req.SbeSchemaVersion(), func(m *SbeGoMarshaller, w *bytes.Buffer) error {
+		return req.Encode(m, w, true)
+	})
+
+	var d ClusterActionRequestDecoder
+	d.Wrap(buf, MessageHeaderLength, req.SbeBlockLength(), req.SbeSchemaVersion())
+	assert.Equal(t, int64(2), d.LogPosition())
+	assert.Equal(t, ClusterAction.SNAPSHOT, d.Action())
+	assert.Equal(t, int32(0), d.Flags())
+
+	// A later version appends the flags to the block
+	withFlags := atomic.NewBufferSlice(make([]byte, buf.Capacity()+4))
+	withFlags.PutBytes(0, buf, 0, buf.Capacity())
+	withFlags.PutInt32(MessageHeaderLength+28, 1)
+	d.Wrap(withFlags, MessageHeaderLength, 32, req.SbeSchemaVersion()+1)
+	assert.Equal(t, ClusterAction.SNAPSHOT, d.Action())
+	assert.Equal(t, int32(1), d.Flags())
+}
+
+func TestServiceAckEncoder(t *testing.T) {
+	buf := atomic.NewBufferSlice(make([]byte, 64))
+	var e ServiceAckEncoder
+	e.WrapAndApplyHeader(buf, 0).
+		SetLogPosition(1).
+		SetTimestamp(2).
+		SetAckId(3).
+		SetRelevantId(4).
+		SetServiceId(5)
+
+	m := NewSbeGoMarshaller()
+	r := bytes.NewReader(buf.GetBytesArray(0, e.EncodedLength()))
+	var hdr MessageHeader
+	require.NoError(t, hdr.Decode(m, r, 0))
+	var ack ServiceAck
+	assert.Equal(t, ack.SbeTemplateId(), hdr.TemplateId)
+	assert.Equal(t, ack.SbeBlockLength(), hdr.BlockLength)
+	require.NoError(t, ack.Decode(m, r, hdr.Version, hdr.BlockLength, true))
+	assert.Equal(t, ServiceAck{LogPosition: 1, Timestamp: 2, AckId: 3, RelevantId: 4, ServiceId: 5}, ack)
+}
+
+func TestDecoders_DoNotAllocate(t *testing.T) {
+	buf := encodeSessionEvent(t, &SessionEvent{Code: EventCode.ERROR, Detail: []uint8("failed")})
+	var d SessionEventDecoder
+	detail := make([]byte, 0, 16)
+	allocs := testing.AllocsPerRun(100, func() {
+		d.Wrap(buf, MessageHeaderLength, 36, 8)
+		_ = d.ClusterSessionId() + d.CorrelationId()
+		detail, _ = d.GetDetail(detail[:0])
+	})
+	assert.Zero(t, allocs)
+	assert.Equal(t, "failed", string(detail))
+}
@@ -2,10 +2,12 @@ package cluster
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/lirm/aeron-go/aeron"
 	"github.com/lirm/aeron-go/aeron/atomic"
 	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
 	"github.com/lirm/aeron-go/cluster/codecs"
 )
 
@@ -21,6 +23,7 @@ type consensusModuleProxy struct {
 	rangeChecking bool
 	publication   *aeron.Publication
 	buffer        *atomic.Buffer
+	ackEncoder    codecs.ServiceAckEncoder
 }
 
 func newConsensusModuleProxy(
@@ -46,20 +49,36 @@ func (proxy *consensusModuleProxy) serviceAckRequest(
 	relevantID int64,
 	serviceID int32,
 ) {
-	// Create a packet and send it
-	bytes, err := codecs.ServiceAckRequestPacket(
-		proxy.marshaller,
-		proxy.rangeChecking,
-		logPosition,
-		timestamp,
-		ackID,
-		relevantID,
-		serviceID,
-	)
-	if err != nil {
-		panic(err)
+	length := proxy.encodeServiceAck(logPosition, timestamp, ackID, relevantID, serviceID)
+	for proxy.offer(proxy.buffer, length) < 0 {
+		proxy.idleStrategy.Idle(0)
 	}
-	proxy.send(bytes)
+}
+
+// serviceAckWithTimeout sends a service ack, giving up after timeout rather than
+// waiting indefinitely, as the consensus module may already have gone when the
+// service is terminating. Returns false if the ack was not sent.
+func (proxy *consensusModuleProxy) serviceAckWithTimeout(
+	logPosition int64,
+	timestamp int64,
+	ackID int64,
+	relevantID int64,
+	serviceID int32,
+	timeout time.Duration,
+) bool {
+	length := proxy.encodeServiceAck(logPosition, timestamp, ackID, relevantID, serviceID)
+	start := time.Now()
+	for time.Since(start) < timeout {
+		result := proxy.publication.Offer(proxy.buffer, 0, length, nil)
+		if result >= 0 {
+			return true
+		}
+		if result == aeron.PublicationClosed || result == aeron.MaxPositionExceeded {
+			return false
+		}
+		proxy.idleStrategy.Idle(0)
+	}
+	return false
 }
 
 func (proxy *consensusModuleProxy) closeSessionRequest(
@@ -90,6 +109,51 @@ func (proxy *consensusModuleProxy) cancelTimer(correlationId int64) bool {
 	return proxy.offer(buf, SBEHeaderLength+cancelTimerBlockLength) >= 0
 }
 
+// offerServiceMessage sends a service message, prefixed by its session message header,
+// to the consensus module to be appended to the log. Failures are returned as the
+// publication's result for the service to handle, as it would on any publication
+func (proxy *consensusModuleProxy) offerServiceMessage(
+	headerBuffer *atomic.Buffer,
+	buffer *atomic.Buffer,
+	offset int32,
+	length int32,
+) int64 {
+	return proxy.publication.Offer2(headerBuffer, 0, headerBuffer.Capacity(), buffer, offset, length, nil)
+}
+
+// tryClaimServiceMessage claims length bytes, which must include the session message header,
+// and writes the header at the start of the claimed range. Failures are returned as the
+// publication's result, as for offerServiceMessage
+func (proxy *consensusModuleProxy) tryClaimServiceMessage(
+	length int32,
+	bufferClaim *logbuffer.Claim,
+	headerBuffer *atomic.Buffer,
+) int64 {
+	result := proxy.publication.TryClaim(length, bufferClaim)
+	if result > 0 {
+		bufferClaim.Buffer().PutBytes(bufferClaim.Offset(), headerBuffer, 0, headerBuffer.Capacity())
+	}
+	return result
+}
+
+// encodeServiceAck encodes a service ack into the proxy's buffer, returning its length
+func (proxy *consensusModuleProxy) encodeServiceAck(
+	logPosition int64,
+	timestamp int64,
+	ackID int64,
+	relevantID int64,
+	serviceID int32,
+) int32 {
+	encoder := &proxy.ackEncoder
+	encoder.WrapAndApplyHeader(proxy.buffer, 0).
+		SetLogPosition(logPosition).
+		SetTimestamp(timestamp).
+		SetAckId(ackID).
+		SetRelevantId(relevantID).
+		SetServiceId(serviceID)
+	return encoder.EncodedLength()
+}
+
 func (proxy *consensusModuleProxy) initBuffer(templateId uint16, blockLength uint16) *atomic.Buffer {
 	buf := proxy.buffer
 	buf.PutUInt16(0, blockLength)
@@ -113,9 +177,15 @@ func (proxy *consensusModuleProxy) offer(buffer *atomic.Buffer, length int32) in
 		result = proxy.publication.Offer(buffer, 0, length, nil)
 		if result >= 0 {
 			break
-		} else if result == aeron.NotConnected || result == aeron.PublicationClosed || result == aeron.MaxPositionExceeded {
-			panic(fmt.Sprintf("offer failed, result=%d", result))
 		}
+		checkResult(result)
 	}
 	return result
 }
+
+// checkResult panics if the publication to the consensus module can no longer be used
+func checkResult(result int64) {
+	if result == aeron.NotConnected || result == aeron.PublicationClosed || result == aeron.MaxPositionExceeded {
+		panic(fmt.Sprintf("offer failed, result=%d", result))
+	}
+}
@@ -1,5 +1,7 @@
 package cluster
 
+import "fmt"
+
 const (
 	SBEHeaderLength            = 8
 	SessionMessageHeaderLength = 24
@@ -13,6 +15,37 @@ const (
 	Leader         = 2
 )
 
+// StartupPhase is the progress of a ClusteredServiceAgent through OnStart(),
+// reported to Options.StartupPhaseHandler
+type StartupPhase int32
+
+const (
+	StartupNotStarted             StartupPhase = iota // OnStart() has not been called
+	StartupAwaitingCommitPosition                     // waiting for the consensus module's commit position counter
+	StartupAwaitingRecoveryState                      // waiting for the consensus module's recovery state counter
+	StartupLoadingSnapshot                            // replaying the service's snapshot from the archive
+	StartupComplete                                   // the service has started
+	StartupFailed                                     // startup failed and the mark file is signalled
+)
+
+func (p StartupPhase) String() string {
+	switch p {
+	case StartupNotStarted:
+		return "NOT_STARTED"
+	case StartupAwaitingCommitPosition:
+		return "AWAITING_COMMIT_POSITION"
+	case StartupAwaitingRecoveryState:
+		return "AWAITING_RECOVERY_STATE"
+	case StartupLoadingSnapshot:
+		return "LOADING_SNAPSHOT"
+	case StartupComplete:
+		return "COMPLETE"
+	case StartupFailed:
+		return "FAILED"
+	}
+	return fmt.Sprintf("StartupPhase(%d)", int32(p))
+}
+
 const (
 	ClusterSchemaId                 = 111
 	ClusterSchemaVersion            = 8
@@ -36,4 +69,26 @@ const (
 	clientSessionTemplateId         = 102
 )
 
+// Admin and membership template ids
+const (
+	AdminRequestTemplateId                   = 26
+	AdminResponseTemplateId                  = 27
+	ClusterMembersQueryTemplateId            = 34
+	ClusterMembersResponseTemplateId         = 41
+	ClusterMembersExtendedResponseTemplateId = 43
+)
+
+// Backup template ids
+const (
+	ChallengeResponseTemplateId = 8
+	BackupQueryTemplateId       = 77
+	BackupResponseTemplateId    = 78
+)
+
 const SessionMessageHdrBlockLength = 24
+
+// Flags of a ClusterActionRequest, which qualify the action
+const (
+	ClusterActionFlagsDefault         = 0
+	ClusterActionFlagsStandbySnapshot = 1
+)
@@ -1,9 +1,11 @@
 package cluster
 
 import (
+	"fmt"
 	"os"
 	"time"
 
+	"github.com/lirm/aeron-go/aeron/flyweight"
 	"github.com/lirm/aeron-go/aeron/util"
 
 	"github.com/lirm/aeron-go/aeron/atomic"
@@ -16,10 +18,41 @@ const (
 	ErrorBufferLength = 1024 * 1024
 )
 
+// MarkFileFilename is the name of the consensus module's mark file within the cluster dir
+const MarkFileFilename = "cluster-mark.dat"
+
+// Cluster component types recorded in mark files
+const (
+	ComponentTypeUnknown         int32 = 0
+	ComponentTypeConsensusModule int32 = 1
+	ComponentTypeContainer       int32 = 2
+	ComponentTypeBackup          int32 = 3
+)
+
+// ComponentTypeName returns the name of a mark file component type
+func ComponentTypeName(componentType int32) string {
+	switch componentType {
+	case ComponentTypeConsensusModule:
+		return "CONSENSUS_MODULE"
+	case ComponentTypeContainer:
+		return "CONTAINER"
+	case ComponentTypeBackup:
+		return "BACKUP"
+	default:
+		return "UNKNOWN"
+	}
+}
+
 type ClusterMarkFile struct {
-	file      *memmap.File
-	buffer    *atomic.Buffer
-	flyweight *MarkFileHeaderFlyweight
+	file        *memmap.File
+	buffer      *atomic.Buffer
+	flyweight   *MarkFileHeaderFlyweight
+	errorBuffer flyweight.RawDataField
+}
+
+// MarkFileFilenameForService returns the name of the mark file, within the cluster dir, for a service id
+func MarkFileFilenameForService(serviceId int32) string {
+	return fmt.Sprintf("cluster-mark-service-%d.dat", serviceId)
 }
 
 func NewClusterMarkFile(filename string) (*ClusterMarkFile, error) {
@@ -40,11 +73,66 @@ func NewClusterMarkFile(filename string) (*ClusterMarkFile, error) {
 	fly.Pid.Set(int64(os.Getpid()))
 	fly.StartTimestamp.Set(time.Now().UnixMilli())
 
-	return &ClusterMarkFile{
+	cmf := &ClusterMarkFile{
 		file:      f,
 		buffer:    b,
 		flyweight: fly,
-	}, nil
+	}
+	cmf.errorBuffer.Wrap(b, HeaderLength, ErrorBufferLength)
+	return cmf, nil
+}
+
+// MapClusterMarkFile maps an existing mark file, written by this or any other
+// cluster component, for inspection
+func MapClusterMarkFile(filename string) (*ClusterMarkFile, error) {
+	f, err := memmap.MapExisting(filename, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+	if f.GetMemorySize() < HeaderLength {
+		f.Close()
+		return nil, fmt.Errorf("mark file %s too short: length=%d", filename, f.GetMemorySize())
+	}
+
+	b := atomic.NewBufferPointer(f.GetMemoryPtr(), int32(f.GetMemorySize()))
+
+	fly := &MarkFileHeaderFlyweight{}
+	fly.Wrap(b, 0)
+
+	cmf := &ClusterMarkFile{
+		file:      f,
+		buffer:    b,
+		flyweight: fly,
+	}
+	headerLength := fly.HeaderLength.Get()
+	errorBufferLength := fly.ErrorBufferLength.Get()
+	if headerLength < HeaderLength || errorBufferLength < 0 ||
+		int(headerLength)+int(errorBufferLength) > f.GetMemorySize() {
+		f.Close()
+		return nil, fmt.Errorf("mark file %s has invalid lengths: headerLength=%d errorBufferLength=%d fileLength=%d",
+			filename, headerLength, errorBufferLength, f.GetMemorySize())
+	}
+	cmf.errorBuffer.Wrap(b, int(headerLength), errorBufferLength)
+	return cmf, nil
+}
+
+// Header returns the flyweight over the mark file's header
+func (cmf *ClusterMarkFile) Header() *MarkFileHeaderFlyweight {
+	return cmf.flyweight
+}
+
+// ErrorBuffer returns the region of the mark file holding the distinct error log
+func (cmf *ClusterMarkFile) ErrorBuffer() *atomic.Buffer {
+	return cmf.errorBuffer.Get()
+}
+
+// IsActive is true if the activity timestamp was updated within timeoutMs of nowMs
+func (cmf *ClusterMarkFile) IsActive(nowMs, timeoutMs int64) bool {
+	return cmf.flyweight.ActivityTimestamp.Get()+timeoutMs > nowMs
+}
+
+func (cmf *ClusterMarkFile) Close() error {
+	return cmf.file.Close()
 }
 
 func (cmf *ClusterMarkFile) SignalReady() {
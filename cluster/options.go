@@ -1,9 +1,13 @@
 package cluster
 
 import (
+	"errors"
+	"strconv"
 	"time"
 
+	"github.com/lirm/aeron-go/aeron"
 	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/properties"
 	"github.com/lirm/aeron-go/archive"
 	"go.uber.org/zap/zapcore"
 )
@@ -26,6 +30,10 @@ type Options struct {
 	ReplayStreamId          int32
 	ArchiveOptions          *archive.Options
 	LogFragmentLimit        int
+	ErrorHandler            func(err error)          // [runtime] Called, if set, with each error recorded in the mark file
+	StandbySnapshotEnabled  bool                     // Take snapshots requested with ClusterActionFlagsStandbySnapshot, as on standby members
+	StartupTimeout          time.Duration            // [runtime] How long OnStart() waits for the consensus module and snapshot, zero to wait indefinitely
+	StartupPhaseHandler     func(phase StartupPhase) // [runtime] Called, if set, as OnStart() enters each phase
 }
 
 func NewOptions() *Options {
@@ -47,5 +55,86 @@ func NewOptions() *Options {
 		ReplayStreamId:          103,
 		ArchiveOptions:          archiveOpts,
 		LogFragmentLimit:        50,
+		StartupTimeout:          time.Second * 30,
 	}
 }
+
+// Property names read by NewOptionsFromEnv() and ApplyProperties(), shared
+// with the Java clustered service container
+const (
+	ClusterDirPropName              = "aeron.cluster.dir"                        // ClusterDir
+	ClusterIdPropName               = "aeron.cluster.id"                         // ClusterId
+	ServiceIdPropName               = "aeron.cluster.service.id"                 // ServiceId
+	ControlChannelPropName          = "aeron.cluster.control.channel"            // ControlChannel
+	ConsensusModuleStreamIdPropName = "aeron.cluster.consensus.module.stream.id" // ConsensusModuleStreamId
+	ServiceStreamIdPropName         = "aeron.cluster.service.stream.id"          // ServiceStreamId
+	SnapshotChannelPropName         = "aeron.cluster.snapshot.channel"           // SnapshotChannel
+	SnapshotStreamIdPropName        = "aeron.cluster.snapshot.stream.id"         // SnapshotStreamId
+	ReplayChannelPropName           = "aeron.cluster.replay.channel"             // ReplayChannel
+	ReplayStreamIdPropName          = "aeron.cluster.replay.stream.id"           // ReplayStreamId
+	LogFragmentLimitPropName        = "aeron.cluster.log.fragment.limit"         // LogFragmentLimit
+	StandbySnapshotEnabledPropName  = "aeron.cluster.standby.snapshot.enabled"   // StandbySnapshotEnabled
+)
+
+// NewOptionsFromEnv creates Options from the defaults overridden by the
+// AERON_* environment variables, e.g. AERON_CLUSTER_SERVICE_ID. See package
+// properties for the naming and precedence.
+func NewOptionsFromEnv() (*Options, error) {
+	return NewOptionsFromProperties(properties.FromEnv())
+}
+
+// NewOptionsFromProperties creates Options from the defaults overridden by
+// props, which may be merged from files and the environment with
+// properties.Load()
+func NewOptionsFromProperties(props properties.Properties) (*Options, error) {
+	opts := NewOptions()
+	if err := opts.ApplyProperties(props); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// ApplyProperties overrides the options, and the archive properties of
+// ArchiveOptions, with those set in props. The error lists each invalid
+// property, leaving the option unchanged for those.
+func (opts *Options) ApplyProperties(props properties.Properties) error {
+	var archiveErr error
+	if opts.ArchiveOptions != nil {
+		archiveErr = opts.ArchiveOptions.ApplyProperties(props)
+	}
+	return errors.Join(
+		props.StringVar(&opts.ClusterDir, ClusterDirPropName),
+		props.Int32Var(&opts.ClusterId, ClusterIdPropName),
+		props.Var(ServiceIdPropName, func(value string) error {
+			id, err := strconv.ParseInt(value, 10, 32)
+			if err != nil {
+				return errors.Unwrap(err)
+			}
+			if id < 0 {
+				return errors.New("service id must not be negative")
+			}
+			opts.ServiceId = int32(id)
+			return nil
+		}),
+		props.ChannelVar(&opts.ControlChannel, ControlChannelPropName, aeron.ValidateChannelUri),
+		props.Int32Var(&opts.ConsensusModuleStreamId, ConsensusModuleStreamIdPropName),
+		props.Int32Var(&opts.ServiceStreamId, ServiceStreamIdPropName),
+		props.ChannelVar(&opts.SnapshotChannel, SnapshotChannelPropName, aeron.ValidateChannelUri),
+		props.Int32Var(&opts.SnapshotStreamId, SnapshotStreamIdPropName),
+		props.ChannelVar(&opts.ReplayChannel, ReplayChannelPropName, aeron.ValidateChannelUri),
+		props.Int32Var(&opts.ReplayStreamId, ReplayStreamIdPropName),
+		props.Var(LogFragmentLimitPropName, func(value string) error {
+			limit, err := strconv.Atoi(value)
+			if err != nil {
+				return errors.Unwrap(err)
+			}
+			if limit <= 0 {
+				return errors.New("fragment limit must be positive")
+			}
+			opts.LogFragmentLimit = limit
+			return nil
+		}),
+		props.BoolVar(&opts.StandbySnapshotEnabled, StandbySnapshotEnabledPropName),
+		archiveErr,
+	)
+}
@@ -0,0 +1,142 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// RecordingLogFilename is the name of the consensus module's log of terms and snapshots within the cluster dir
+const RecordingLogFilename = "recording.log"
+
+// Layout of the fixed length entries in the recording log
+const (
+	RecordingLogEntryLength = 64
+
+	recordingLogRecordingIdOffset         = 0
+	recordingLogLeadershipTermIdOffset    = 8
+	recordingLogTermBaseLogPositionOffset = 16
+	recordingLogLogPositionOffset         = 24
+	recordingLogTimestampOffset           = 32
+	recordingLogServiceIdOffset           = 40
+	recordingLogEntryTypeOffset           = 44
+)
+
+// Recording log entry types
+const (
+	RecordingLogEntryTypeTerm     int32 = 0
+	RecordingLogEntryTypeSnapshot int32 = 1
+
+	// RecordingLogEntryTypeInvalidFlag is set on entries which have been invalidated
+	RecordingLogEntryTypeInvalidFlag int32 = -1 << 31
+)
+
+// RecordingLogEntry is a term or snapshot recorded by the consensus module
+type RecordingLogEntry struct {
+	Index               int
+	RecordingId         int64
+	LeadershipTermId    int64
+	TermBaseLogPosition int64
+	LogPosition         int64 // NullValue for a term which is still open
+	Timestamp           int64
+	ServiceId           int32 // -1 for the consensus module's snapshot
+	EntryType           int32
+}
+
+// IsValid is false for entries which have been invalidated
+func (e *RecordingLogEntry) IsValid() bool {
+	return e.EntryType&RecordingLogEntryTypeInvalidFlag == 0
+}
+
+// IsSnapshot is true for snapshot entries, otherwise the entry is for a term
+func (e *RecordingLogEntry) IsSnapshot() bool {
+	return e.EntryType&^RecordingLogEntryTypeInvalidFlag == RecordingLogEntryTypeSnapshot
+}
+
+func (e *RecordingLogEntry) String() string {
+	entryType := "TERM"
+	if e.IsSnapshot() {
+		entryType = "SNAPSHOT"
+	}
+	return fmt.Sprintf("Entry{index=%d, recordingId=%d, leadershipTermId=%d, termBaseLogPosition=%d, logPosition=%d, timestamp=%d, serviceId=%d, type=%s, isValid=%t}",
+		e.Index, e.RecordingId, e.LeadershipTermId, e.TermBaseLogPosition, e.LogPosition,
+		e.Timestamp, e.ServiceId, entryType, e.IsValid())
+}
+
+// ReadRecordingLog reads all entries, including invalidated ones, from the
+// recording log in clusterDir
+func ReadRecordingLog(clusterDir string) ([]RecordingLogEntry, error) {
+	data, err := os.ReadFile(filepath.Join(clusterDir, RecordingLogFilename))
+	if err != nil {
+		return nil, err
+	}
+	return DecodeRecordingLog(data)
+}
+
+// DecodeRecordingLog decodes the entries from the contents of a recording log
+func DecodeRecordingLog(data []byte) ([]RecordingLogEntry, error) {
+	if len(data)%RecordingLogEntryLength != 0 {
+		return nil, fmt.Errorf("recording log length %d is not a multiple of %d", len(data), RecordingLogEntryLength)
+	}
+	entries := make([]RecordingLogEntry, 0, len(data)/RecordingLogEntryLength)
+	for offset := 0; offset < len(data); offset += RecordingLogEntryLength {
+		entry := data[offset : offset+RecordingLogEntryLength]
+		entries = append(entries, RecordingLogEntry{
+			Index:               len(entries),
+			RecordingId:         int64(binary.LittleEndian.Uint64(entry[recordingLogRecordingIdOffset:])),
+			LeadershipTermId:    int64(binary.LittleEndian.Uint64(entry[recordingLogLeadershipTermIdOffset:])),
+			TermBaseLogPosition: int64(binary.LittleEndian.Uint64(entry[recordingLogTermBaseLogPositionOffset:])),
+			LogPosition:         int64(binary.LittleEndian.Uint64(entry[recordingLogLogPositionOffset:])),
+			Timestamp:           int64(binary.LittleEndian.Uint64(entry[recordingLogTimestampOffset:])),
+			ServiceId:           int32(binary.LittleEndian.Uint32(entry[recordingLogServiceIdOffset:])),
+			EntryType:           int32(binary.LittleEndian.Uint32(entry[recordingLogEntryTypeOffset:])),
+		})
+	}
+	return entries, nil
+}
+
+// AppendRecordingLog appends entries to the recording log in clusterDir,
+// creating it if it does not exist, and syncs it to storage
+func AppendRecordingLog(clusterDir string, entries ...RecordingLogEntry) error {
+	file, err := os.OpenFile(filepath.Join(clusterDir, RecordingLogFilename), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	for idx := range entries {
+		if _, err := file.Write(EncodeRecordingLogEntry(&entries[idx])); err != nil {
+			file.Close()
+			return err
+		}
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// EncodeRecordingLogEntry encodes an entry in the recording log format
+func EncodeRecordingLogEntry(entry *RecordingLogEntry) []byte {
+	data := make([]byte, RecordingLogEntryLength)
+	binary.LittleEndian.PutUint64(data[recordingLogRecordingIdOffset:], uint64(entry.RecordingId))
+	binary.LittleEndian.PutUint64(data[recordingLogLeadershipTermIdOffset:], uint64(entry.LeadershipTermId))
+	binary.LittleEndian.PutUint64(data[recordingLogTermBaseLogPositionOffset:], uint64(entry.TermBaseLogPosition))
+	binary.LittleEndian.PutUint64(data[recordingLogLogPositionOffset:], uint64(entry.LogPosition))
+	binary.LittleEndian.PutUint64(data[recordingLogTimestampOffset:], uint64(entry.Timestamp))
+	binary.LittleEndian.PutUint32(data[recordingLogServiceIdOffset:], uint32(entry.ServiceId))
+	binary.LittleEndian.PutUint32(data[recordingLogEntryTypeOffset:], uint32(entry.EntryType))
+	return data
+}
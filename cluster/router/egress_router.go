@@ -0,0 +1,47 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/cluster/client"
+)
+
+// EgressContext is passed to the handlers of an EgressRouter
+type EgressContext struct {
+	Cluster   *client.AeronCluster
+	Timestamp int64
+}
+
+// EgressRouter routes the messages received by an EgressListener, which
+// delegates its OnMessage to the router
+type EgressRouter struct {
+	*Router[EgressContext]
+}
+
+func NewEgressRouter() *EgressRouter {
+	return &EgressRouter{Router: NewRouter[EgressContext]()}
+}
+
+// OnMessage routes the message, with the signature of EgressListener.OnMessage
+func (r *EgressRouter) OnMessage(
+	cluster *client.AeronCluster,
+	timestamp int64,
+	buffer *atomic.Buffer,
+	offset int32,
+	length int32,
+	header *logbuffer.Header,
+) {
+	r.Route(EgressContext{Cluster: cluster, Timestamp: timestamp}, buffer, offset, length, header)
+}
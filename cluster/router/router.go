@@ -0,0 +1,172 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package router dispatches SBE encoded application messages to handlers
+// registered by schema id and template id. A SessionRouter routes the session
+// messages of a ClusteredService and an EgressRouter the messages of an
+// EgressListener.
+package router
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/aeron/logging"
+)
+
+// HeaderLength is the length of the SBE message header
+const HeaderLength = 8
+
+var logger = logging.MustGetLogger("cluster-router")
+
+// Header is the SBE message header at the start of each routed message
+type Header struct {
+	BlockLength uint16
+	TemplateId  uint16
+	SchemaId    uint16
+	Version     uint16
+}
+
+// Key identifies the handler of a message
+type Key struct {
+	SchemaId   uint16
+	TemplateId uint16
+}
+
+// Message is a routed message. Offset and Length are those of the message body
+// following the SBE header. It is only valid during the handler's call.
+type Message struct {
+	Header
+	Buffer    *atomic.Buffer
+	Offset    int32
+	Length    int32
+	LogHeader *logbuffer.Header
+}
+
+// Decoder is implemented by the message codecs generated by SBE for Go, with M
+// the *SbeGoMarshaller of the codec's package
+type Decoder[M any] interface {
+	Decode(m M, r io.Reader, actingVersion uint16, blockLength uint16, doRangeCheck bool) error
+}
+
+// Router dispatches messages to handlers called with a context of type C,
+// which carries the session or cluster the message came from
+type Router[C any] struct {
+	RangeChecking bool // Range check the fields of decoded messages, true by default
+
+	handlers  map[Key]func(ctx C, msg *Message)
+	onUnknown func(ctx C, msg *Message)
+	onError   func(ctx C, msg *Message, err error)
+	message   Message
+	decodeBuf bytes.Buffer
+}
+
+// NewRouter returns a router with no handlers, which logs unknown messages
+// and decode errors until hooks are set
+func NewRouter[C any]() *Router[C] {
+	return &Router[C]{
+		RangeChecking: true,
+		handlers:      map[Key]func(ctx C, msg *Message){},
+	}
+}
+
+// Register the handler for messages with the schema id and template id,
+// failing if one is already registered
+func (r *Router[C]) Register(schemaId, templateId uint16, handler func(ctx C, msg *Message)) error {
+	key := Key{SchemaId: schemaId, TemplateId: templateId}
+	if _, ok := r.handlers[key]; ok {
+		return fmt.Errorf("handler already registered for schemaId=%d templateId=%d", schemaId, templateId)
+	}
+	r.handlers[key] = handler
+	return nil
+}
+
+// OnUnknown sets the hook called with messages which have no handler, or are
+// too short for an SBE header, in which case the Header is zero
+func (r *Router[C]) OnUnknown(hook func(ctx C, msg *Message)) {
+	r.onUnknown = hook
+}
+
+// OnError sets the hook called when a message registered with Handle fails to decode
+func (r *Router[C]) OnError(hook func(ctx C, msg *Message, err error)) {
+	r.onError = hook
+}
+
+// Route decodes the SBE header of the message and calls its handler, returning
+// false if there is no handler
+func (r *Router[C]) Route(ctx C, buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) bool {
+	msg := &r.message
+	msg.Buffer = buffer
+	msg.LogHeader = header
+	if length < HeaderLength {
+		msg.Header = Header{}
+		msg.Offset = offset
+		msg.Length = length
+		r.unknown(ctx, msg)
+		return false
+	}
+	msg.BlockLength = buffer.GetUInt16(offset)
+	msg.TemplateId = buffer.GetUInt16(offset + 2)
+	msg.SchemaId = buffer.GetUInt16(offset + 4)
+	msg.Version = buffer.GetUInt16(offset + 6)
+	msg.Offset = offset + HeaderLength
+	msg.Length = length - HeaderLength
+
+	handler, ok := r.handlers[Key{SchemaId: msg.SchemaId, TemplateId: msg.TemplateId}]
+	if !ok {
+		r.unknown(ctx, msg)
+		return false
+	}
+	handler(ctx, msg)
+	return true
+}
+
+func (r *Router[C]) unknown(ctx C, msg *Message) {
+	if r.onUnknown != nil {
+		r.onUnknown(ctx, msg)
+		return
+	}
+	logger.Debugf("no handler for schemaId=%d templateId=%d version=%d length=%d",
+		msg.SchemaId, msg.TemplateId, msg.Version, msg.Length)
+}
+
+func (r *Router[C]) decodeError(ctx C, msg *Message, err error) {
+	if r.onError != nil {
+		r.onError(ctx, msg, err)
+		return
+	}
+	logger.Errorf("failed to decode schemaId=%d templateId=%d version=%d: %v",
+		msg.SchemaId, msg.TemplateId, msg.Version, err)
+}
+
+// Handle registers a typed handler for the schema id and template id. Messages
+// are decoded with the marshaller into a T, which is reused between messages,
+// using the version and block length from their header so that older and newer
+// encodings of the message are decoded as SBE intends.
+func Handle[C any, T any, M any, PT interface {
+	*T
+	Decoder[M]
+}](r *Router[C], schemaId, templateId uint16, marshaller M, handler func(ctx C, msg *T)) error {
+	decoded := PT(new(T))
+	return r.Register(schemaId, templateId, func(ctx C, msg *Message) {
+		r.decodeBuf.Reset()
+		msg.Buffer.WriteBytes(&r.decodeBuf, msg.Offset, msg.Length)
+		if err := decoded.Decode(marshaller, &r.decodeBuf, msg.Version, msg.BlockLength, r.RangeChecking); err != nil {
+			r.decodeError(ctx, msg, err)
+			return
+		}
+		handler(ctx, (*T)(decoded))
+	})
+}
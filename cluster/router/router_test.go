@@ -0,0 +1,133 @@
+package router
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/cluster/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testSchemaId   = 111
+	testTemplateId = 3
+)
+
+func encodeConnectRequest(t *testing.T, req *codecs.SessionConnectRequest) *atomic.Buffer {
+	marshaller := codecs.NewSbeGoMarshaller()
+	header := codecs.MessageHeader{
+		BlockLength: req.SbeBlockLength(),
+		TemplateId:  req.SbeTemplateId(),
+		SchemaId:    req.SbeSchemaId(),
+		Version:     req.SbeSchemaVersion(),
+	}
+	writer := new(bytes.Buffer)
+	require.NoError(t, header.Encode(marshaller, writer))
+	require.NoError(t, req.Encode(marshaller, writer, true))
+	return atomic.NewBufferSlice(writer.Bytes())
+}
+
+func TestSessionRouter_TypedHandler(t *testing.T) {
+	r := NewSessionRouter()
+	var received []codecs.SessionConnectRequest
+	var timestamps []int64
+	require.NoError(t, Handle(r.Router, testSchemaId, testTemplateId, codecs.NewSbeGoMarshaller(),
+		func(ctx SessionContext, msg *codecs.SessionConnectRequest) {
+			copied := *msg
+			copied.ResponseChannel = append([]uint8(nil), msg.ResponseChannel...)
+			received = append(received, copied)
+			timestamps = append(timestamps, ctx.Timestamp)
+		}))
+
+	buf := encodeConnectRequest(t, &codecs.SessionConnectRequest{
+		CorrelationId:    7,
+		ResponseStreamId: 102,
+		Version:          3,
+		ResponseChannel:  []uint8("aeron:ipc"),
+	})
+	r.OnSessionMessage(nil, 1234, buf, 0, buf.Capacity(), nil)
+
+	require.Len(t, received, 1)
+	assert.Equal(t, int64(7), received[0].CorrelationId)
+	assert.Equal(t, int32(102), received[0].ResponseStreamId)
+	assert.Equal(t, int32(3), received[0].Version)
+	assert.Equal(t, "aeron:ipc", string(received[0].ResponseChannel))
+	assert.Equal(t, []int64{1234}, timestamps)
+}
+
+func TestRouter_DecodesOlderVersion(t *testing.T) {
+	// Version 1 of the message precedes its Version field
+	marshaller := codecs.NewSbeGoMarshaller()
+	writer := new(bytes.Buffer)
+	header := codecs.MessageHeader{BlockLength: 12, TemplateId: testTemplateId, SchemaId: testSchemaId, Version: 1}
+	require.NoError(t, header.Encode(marshaller, writer))
+	require.NoError(t, marshaller.WriteInt64(writer, 9))
+	require.NoError(t, marshaller.WriteInt32(writer, 101))
+	require.NoError(t, marshaller.WriteUint32(writer, 0))
+	require.NoError(t, marshaller.WriteUint32(writer, 0))
+	buf := atomic.NewBufferSlice(writer.Bytes())
+
+	r := NewRouter[int]()
+	var decoded codecs.SessionConnectRequest
+	require.NoError(t, Handle(r, testSchemaId, testTemplateId, codecs.NewSbeGoMarshaller(),
+		func(ctx int, msg *codecs.SessionConnectRequest) {
+			decoded = *msg
+		}))
+	assert.True(t, r.Route(0, buf, 0, buf.Capacity(), nil))
+	assert.Equal(t, int64(9), decoded.CorrelationId)
+	assert.Equal(t, int32(101), decoded.ResponseStreamId)
+	assert.Equal(t, decoded.VersionNullValue(), decoded.Version)
+}
+
+func TestRouter_UnknownAndShortMessages(t *testing.T) {
+	r := NewRouter[int]()
+	var unknown []Header
+	r.OnUnknown(func(ctx int, msg *Message) {
+		unknown = append(unknown, msg.Header)
+	})
+
+	buf := encodeConnectRequest(t, &codecs.SessionConnectRequest{CorrelationId: 1})
+	assert.False(t, r.Route(0, buf, 0, buf.Capacity(), nil))
+	assert.False(t, r.Route(0, buf, 0, HeaderLength-1, nil))
+
+	require.Len(t, unknown, 2)
+	assert.Equal(t, uint16(testTemplateId), unknown[0].TemplateId)
+	assert.Equal(t, uint16(testSchemaId), unknown[0].SchemaId)
+	assert.Equal(t, Header{}, unknown[1])
+}
+
+func TestRouter_RawHandlerAndDuplicates(t *testing.T) {
+	r := NewRouter[int]()
+	var body []byte
+	handler := func(ctx int, msg *Message) {
+		body = msg.Buffer.GetBytesArray(msg.Offset, msg.Length)
+	}
+	require.NoError(t, r.Register(testSchemaId, testTemplateId, handler))
+	assert.Error(t, r.Register(testSchemaId, testTemplateId, handler))
+	assert.Error(t, Handle(r, testSchemaId, testTemplateId, codecs.NewSbeGoMarshaller(),
+		func(ctx int, msg *codecs.SessionConnectRequest) {}))
+
+	buf := encodeConnectRequest(t, &codecs.SessionConnectRequest{CorrelationId: 1})
+	assert.True(t, r.Route(0, buf, 0, buf.Capacity(), nil))
+	assert.Len(t, body, int(buf.Capacity())-HeaderLength)
+}
+
+func TestRouter_DecodeError(t *testing.T) {
+	r := NewRouter[int]()
+	called := false
+	require.NoError(t, Handle(r, testSchemaId, testTemplateId, codecs.NewSbeGoMarshaller(),
+		func(ctx int, msg *codecs.SessionConnectRequest) {
+			called = true
+		}))
+	var decodeErr error
+	r.OnError(func(ctx int, msg *Message, err error) {
+		decodeErr = err
+	})
+
+	buf := encodeConnectRequest(t, &codecs.SessionConnectRequest{CorrelationId: 1})
+	assert.True(t, r.Route(0, buf, 0, HeaderLength+4, nil))
+	assert.False(t, called)
+	assert.Error(t, decodeErr)
+}
@@ -0,0 +1,47 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package router
+
+import (
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/cluster"
+)
+
+// SessionContext is passed to the handlers of a SessionRouter
+type SessionContext struct {
+	Session   cluster.ClientSession
+	Timestamp int64
+}
+
+// SessionRouter routes the session messages of a ClusteredService, which
+// delegates its OnSessionMessage to the router
+type SessionRouter struct {
+	*Router[SessionContext]
+}
+
+func NewSessionRouter() *SessionRouter {
+	return &SessionRouter{Router: NewRouter[SessionContext]()}
+}
+
+// OnSessionMessage routes the message, with the signature of ClusteredService.OnSessionMessage
+func (r *SessionRouter) OnSessionMessage(
+	session cluster.ClientSession,
+	timestamp int64,
+	buffer *atomic.Buffer,
+	offset int32,
+	length int32,
+	header *logbuffer.Header,
+) {
+	r.Route(SessionContext{Session: session, Timestamp: timestamp}, buffer, offset, length, header)
+}
@@ -0,0 +1,129 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+)
+
+// ServiceContainer hosts several ClusteredServices with distinct
+// ServiceIds in one process. The services share a single Aeron client
+// and each has its own ClusteredServiceAgent and mark file.
+//
+// The services may be run together on one goroutine with StartAndRun(),
+// or each agent from Agents() may be run on its own goroutine with
+// ClusteredServiceAgent.StartAndRun().
+type ServiceContainer struct {
+	IdleStrategy idlestrategy.Idler // Idle strategy for the shared duty cycle of StartAndRun()
+	aeronClient  *aeron.Aeron
+	aeronCtx     *aeron.Context
+	agents       []*ClusteredServiceAgent
+}
+
+// NewServiceContainer connects to the media driver and returns an empty ServiceContainer
+func NewServiceContainer(aeronCtx *aeron.Context) (*ServiceContainer, error) {
+	aeronClient, err := aeron.Connect(aeronCtx)
+	if err != nil {
+		return nil, err
+	}
+	return &ServiceContainer{
+		IdleStrategy: idlestrategy.NewDefaultBackoffIdleStrategy(),
+		aeronClient:  aeronClient,
+		aeronCtx:     aeronCtx,
+	}, nil
+}
+
+// AddService creates the agent for a service. Each service must have
+// its own Options whose ServiceId differs from those already added,
+// the other options are typically the same for all services.
+func (container *ServiceContainer) AddService(options *Options, service ClusteredService) (*ClusteredServiceAgent, error) {
+	if err := validateOptions(options); err != nil {
+		return nil, err
+	}
+	for _, agent := range container.agents {
+		if agent.opts.ServiceId == options.ServiceId {
+			return nil, fmt.Errorf("duplicate serviceId: %d", options.ServiceId)
+		}
+		if agent.opts == options {
+			return nil, fmt.Errorf("options for serviceId %d must not be shared", options.ServiceId)
+		}
+	}
+	agent, err := newClusteredServiceAgent(container.aeronClient, container.aeronCtx, options, service)
+	if err != nil {
+		return nil, err
+	}
+	container.agents = append(container.agents, agent)
+	return agent, nil
+}
+
+// Agents returns the agents of the services in the order they were added
+func (container *ServiceContainer) Agents() []*ClusteredServiceAgent {
+	return container.agents
+}
+
+// StartAndRun starts each service in turn and then runs them all on
+// the calling goroutine until every service has terminated
+func (container *ServiceContainer) StartAndRun() error {
+	if len(container.agents) == 0 {
+		return fmt.Errorf("no services added to the container")
+	}
+	for _, agent := range container.agents {
+		if err := agent.OnStart(); err != nil {
+			return err
+		}
+	}
+	for container.isActive() {
+		container.IdleStrategy.Idle(container.DoWork())
+	}
+	var errs []error
+	for _, agent := range container.agents {
+		if agent.terminationErr != nil {
+			errs = append(errs, fmt.Errorf("serviceId %d: %w", agent.opts.ServiceId, agent.terminationErr))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// DoWork performs a duty cycle of each service which is still active
+func (container *ServiceContainer) DoWork() int {
+	work := 0
+	for _, agent := range container.agents {
+		if agent.isServiceActive {
+			work += agent.DoWork()
+		}
+	}
+	return work
+}
+
+// Close the agents of the services and then the shared Aeron client
+func (container *ServiceContainer) Close() error {
+	var errs []error
+	for _, agent := range container.agents {
+		errs = append(errs, agent.Close())
+	}
+	errs = append(errs, container.aeronClient.Close())
+	return errors.Join(errs...)
+}
+
+func (container *ServiceContainer) isActive() bool {
+	for _, agent := range container.agents {
+		if agent.isServiceActive {
+			return true
+		}
+	}
+	return false
+}
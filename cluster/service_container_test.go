@@ -0,0 +1,151 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/counters"
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/cluster/codecs"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOptions(t *testing.T) {
+	assert.NoError(t, validateOptions(NewOptions()))
+
+	opts := NewOptions()
+	opts.ArchiveOptions.RequestChannel = "aeron:udp?endpoint=localhost:8010"
+	assert.ErrorContains(t, validateOptions(opts), "archive request channel must be IPC")
+
+	opts = NewOptions()
+	opts.ArchiveOptions.ResponseChannel = "aeron:udp?endpoint=localhost:8020"
+	assert.ErrorContains(t, validateOptions(opts), "archive response channel must be IPC")
+
+	for _, serviceId := range []int32{-1, 128} {
+		opts = NewOptions()
+		opts.ServiceId = serviceId
+		assert.ErrorContains(t, validateOptions(opts), "serviceId is outside allowed range")
+	}
+	opts.ServiceId = 127
+	assert.NoError(t, validateOptions(opts))
+}
+
+// newTestCounters returns counters holding the commit position and a recovery
+// state with no leadership term, as for a cluster starting without a snapshot
+func newTestCounters(clusterId int32) *counters.Reader {
+	values := atomic.NewBufferSlice(make([]byte, 2*counters.CounterLength))
+	metaData := atomic.NewBufferSlice(make([]byte, 2*counters.MetadataLength))
+	metaData.PutInt32(counters.TypeIdOffset, commitPosCounterTypeId)
+	metaData.PutInt32(counters.KeyOffset, clusterId)
+	metaData.PutInt32(0, counters.RecordAllocated)
+
+	recoveryState := int32(counters.MetadataLength)
+	metaData.PutInt32(recoveryState+counters.TypeIdOffset, recoveryStateCounterTypeId)
+	metaData.PutInt64(recoveryState+counters.KeyOffset, -1)
+	metaData.PutInt64(recoveryState+counters.KeyOffset+8, 0)
+	metaData.PutInt64(recoveryState+counters.KeyOffset+16, 0)
+	metaData.PutInt32(recoveryState+counters.KeyOffset+24, clusterId)
+	metaData.PutInt32(recoveryState, counters.RecordAllocated)
+	return counters.NewReader(values, metaData)
+}
+
+// newTestContainer returns a container of agents for the services which can
+// start, with nothing to receive from the consensus module
+func newTestContainer(t *testing.T, serviceIds ...int32) (*ServiceContainer, []*testAgent) {
+	container := &ServiceContainer{IdleStrategy: idlestrategy.Busy{}}
+	var agents []*testAgent
+	for _, serviceId := range serviceIds {
+		ta := newTestAgent(t)
+		ta.opts.ServiceId = serviceId
+		ta.opts.StartupTimeout = 50 * time.Millisecond
+		ta.counters = newTestCounters(ta.opts.ClusterId)
+		ta.serviceAdapter = &serviceAdapter{
+			marshaller:   codecs.NewSbeGoMarshaller(),
+			agent:        ta.ClusteredServiceAgent,
+			subscription: aeron.NewSubscription(nil, ta.opts.ControlChannel, int64(serviceId), ta.opts.ServiceStreamId, 0),
+		}
+		ta.isServiceActive = false
+		container.agents = append(container.agents, ta.ClusteredServiceAgent)
+		agents = append(agents, ta)
+	}
+	return container, agents
+}
+
+func TestServiceContainer_AddService(t *testing.T) {
+	container, agents := newTestContainer(t, 1)
+
+	opts := NewOptions()
+	opts.ServiceId = 1
+	_, err := container.AddService(opts, &testService{})
+	assert.EqualError(t, err, "duplicate serviceId: 1")
+
+	_, err = container.AddService(agents[0].opts, &testService{})
+	assert.EqualError(t, err, "duplicate serviceId: 1")
+
+	opts.ServiceId = 128
+	_, err = container.AddService(opts, &testService{})
+	assert.ErrorContains(t, err, "serviceId is outside allowed range")
+	assert.Len(t, container.Agents(), 1)
+}
+
+func TestServiceContainer_StartAndRun(t *testing.T) {
+	container, agents := newTestContainer(t, 0, 1)
+	// Terminate at the first duty cycle
+	for _, ta := range agents {
+		ta.terminationPosition = 0
+	}
+
+	require.NoError(t, container.StartAndRun())
+	for _, ta := range agents {
+		assert.Equal(t, StartupComplete, ta.StartupPhase())
+		assert.False(t, ta.isServiceActive)
+		assert.Equal(t, 1, ta.service.terminated)
+		acks := ta.acks(t)
+		require.Len(t, acks, 2, "start and termination acks")
+		assert.Equal(t, []int64{0, 1}, []int64{acks[0].AckId, acks[1].AckId})
+		assert.Equal(t, ta.opts.ServiceId, acks[1].ServiceId)
+		assert.Empty(t, ta.errs)
+	}
+	assert.Zero(t, container.DoWork(), "terminated services are not polled")
+}
+
+func TestServiceContainer_StartAndRun_ServiceFailsToStart(t *testing.T) {
+	container, agents := newTestContainer(t, 0, 1)
+	// The second service never finds its commit position counter
+	agents[1].opts.ClusterId = 7
+
+	err := container.StartAndRun()
+	require.ErrorIs(t, err, ErrStartupTimeout)
+	assert.Contains(t, err.Error(), "serviceId 1 failed to start")
+	assert.Equal(t, StartupComplete, agents[0].StartupPhase())
+	assert.Equal(t, StartupFailed, agents[1].StartupPhase())
+	assert.Zero(t, agents[0].service.terminated, "the container does not run after a failed start")
+}
+
+func TestServiceContainer_StartAndRun_ServiceFails(t *testing.T) {
+	container, agents := newTestContainer(t, 0, 1)
+	// The first service terminates normally, after which the second loses
+	// the consensus module
+	agents[0].terminationPosition = 0
+	agents[0].service.onTerminate = func() { agents[1].publication.Close() }
+
+	err := container.StartAndRun()
+	require.ErrorIs(t, err, ErrConsensusModuleNotConnected)
+	assert.Contains(t, err.Error(), "serviceId 1: ")
+	assert.NotContains(t, err.Error(), "serviceId 0: ")
+	for _, ta := range agents {
+		assert.False(t, ta.isServiceActive)
+		assert.Equal(t, 1, ta.service.terminated)
+	}
+	assert.Empty(t, agents[0].errs)
+	require.NotEmpty(t, agents[1].errs)
+	assert.ErrorIs(t, agents[1].errs[len(agents[1].errs)-1], ErrConsensusModuleNotConnected)
+}
+
+func TestServiceContainer_StartAndRun_NoServices(t *testing.T) {
+	container := &ServiceContainer{IdleStrategy: idlestrategy.Busy{}}
+	assert.EqualError(t, container.StartAndRun(), "no services added to the container")
+}
@@ -0,0 +1,387 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cluster
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/idlestrategy"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/lirm/aeron-go/cluster/codecs"
+)
+
+// Snapshot records are framed with an SBE style message header using a schema
+// id of their own so they cannot be mistaken for cluster protocol messages.
+// The block holds the record type, total length and chunk offset, followed by
+// 4 bytes of zero padding so that the record data is 8 byte aligned. Readers
+// take the data offset from the block length in the header.
+const (
+	snapshotRecordSchemaId       = 0x5352 // "SR"
+	snapshotRecordSchemaVersion  = 1
+	snapshotRecordTemplateId     = 1
+	snapshotRecordBlockLength    = 16
+	snapshotRecordHeaderLength   = SBEHeaderLength + snapshotRecordBlockLength
+	snapshotRecordMinBlockLength = 12 // Without the padding
+
+	recordTypeOffset       = SBEHeaderLength
+	totalLengthOffset      = SBEHeaderLength + 4
+	chunkOffsetOffset      = SBEHeaderLength + 8
+	recordPaddingOffset    = SBEHeaderLength + 12
+	defaultSnapshotTimeout = 5 * time.Second
+)
+
+// ErrSnapshotIncomplete is returned by SnapshotReader.Next() if the snapshot image ends before the END marker
+var ErrSnapshotIncomplete = errors.New("snapshot ended without an END marker")
+
+// SnapshotRecord is a record read from a snapshot by a SnapshotReader
+type SnapshotRecord struct {
+	Type int32  // Type given to SnapshotWriter.Write()
+	Data []byte // Owned by the caller
+}
+
+// SnapshotWriter writes a service's state to the publication passed to
+// ClusteredService.OnTakeSnapshot() as typed records between BEGIN and
+// END SnapshotMarkers. Records larger than a message are split into
+// chunks and back pressured offers are retried using the cluster's
+// idle strategy until Timeout.
+//
+// Usage is Begin(), any number of Write() calls, then End().
+type SnapshotWriter struct {
+	Timeout        time.Duration // How long to retry an offer before failing
+	MaxChunkLength int32         // Maximum record data per message, defaults to the publication's maximum message length less the record header
+	RangeChecking  bool          // SnapshotMarker marshalling checks
+	cluster        Cluster
+	publication    *aeron.Publication
+	idleStrategy   idlestrategy.Idler
+	marshaller     *codecs.SbeGoMarshaller
+	typeId         int64
+	version        int32
+	header         *atomic.Buffer
+	inSnapshot     bool
+}
+
+// NewSnapshotWriter creates and returns a SnapshotWriter for a snapshot
+// of typeId, identifying the service's state, and version, which is
+// given to the SnapshotReader to allow for changes in the state format
+func NewSnapshotWriter(cluster Cluster, publication *aeron.Publication, typeId int64, version int32) *SnapshotWriter {
+	header := atomic.NewBufferSlice(make([]byte, snapshotRecordHeaderLength))
+	header.PutUInt16(0, snapshotRecordBlockLength)
+	header.PutUInt16(2, snapshotRecordTemplateId)
+	header.PutUInt16(4, snapshotRecordSchemaId)
+	header.PutUInt16(6, snapshotRecordSchemaVersion)
+	header.PutInt32(recordPaddingOffset, 0)
+	return &SnapshotWriter{
+		Timeout:        defaultSnapshotTimeout,
+		MaxChunkLength: publication.MaxMessageLength() - snapshotRecordHeaderLength,
+		RangeChecking:  true,
+		cluster:        cluster,
+		publication:    publication,
+		idleStrategy:   cluster.IdleStrategy(),
+		marshaller:     codecs.NewSbeGoMarshaller(),
+		typeId:         typeId,
+		version:        version,
+		header:         header,
+	}
+}
+
+// Begin the snapshot by writing the BEGIN marker
+func (writer *SnapshotWriter) Begin() error {
+	if writer.inSnapshot {
+		return errors.New("snapshot already begun")
+	}
+	if err := writer.mark(codecs.SnapshotMark.BEGIN); err != nil {
+		return err
+	}
+	writer.inSnapshot = true
+	return nil
+}
+
+// End the snapshot by writing the END marker
+func (writer *SnapshotWriter) End() error {
+	if !writer.inSnapshot {
+		return errors.New("snapshot not begun")
+	}
+	if err := writer.mark(codecs.SnapshotMark.END); err != nil {
+		return err
+	}
+	writer.inSnapshot = false
+	return nil
+}
+
+// Write a record of a service defined type
+func (writer *SnapshotWriter) Write(recordType int32, data []byte) error {
+	if len(data) == 0 {
+		return writer.WriteBuffer(recordType, nil, 0, 0)
+	}
+	return writer.WriteBuffer(recordType, atomic.NewBufferSlice(data), 0, int32(len(data)))
+}
+
+// WriteBuffer writes a record of a service defined type from a range of buffer
+func (writer *SnapshotWriter) WriteBuffer(recordType int32, buffer *atomic.Buffer, offset int32, length int32) error {
+	if !writer.inSnapshot {
+		return errors.New("snapshot not begun")
+	}
+	if writer.MaxChunkLength <= 0 {
+		return fmt.Errorf("invalid MaxChunkLength: %d", writer.MaxChunkLength)
+	}
+	header := writer.header
+	header.PutInt32(recordTypeOffset, recordType)
+	header.PutInt32(totalLengthOffset, length)
+	chunkOffset := int32(0)
+	for {
+		chunkLength := length - chunkOffset
+		if chunkLength > writer.MaxChunkLength {
+			chunkLength = writer.MaxChunkLength
+		}
+		header.PutInt32(chunkOffsetOffset, chunkOffset)
+		chunk := buffer
+		if chunkLength == 0 {
+			chunk = nil
+		}
+		if ret := writer.offer(header, chunk, offset+chunkOffset, chunkLength); ret < 0 {
+			return fmt.Errorf("snapshot record type %d offer failed: %d", recordType, ret)
+		}
+		chunkOffset += chunkLength
+		if chunkOffset >= length {
+			return nil
+		}
+	}
+}
+
+func (writer *SnapshotWriter) mark(mark codecs.SnapshotMarkEnum) error {
+	bytes, err := codecs.SnapshotMarkerPacket(
+		writer.marshaller,
+		writer.RangeChecking,
+		writer.typeId,
+		writer.cluster.LogPosition(),
+		NullValue,
+		0,
+		mark,
+		codecs.ClusterTimeUnit.NullValue,
+		writer.version,
+	)
+	if err != nil {
+		return err
+	}
+	if ret := writer.offer(atomic.NewBufferSlice(bytes), nil, 0, 0); ret < 0 {
+		return fmt.Errorf("snapshot marker offer failed: %d", ret)
+	}
+	return nil
+}
+
+// offer the header and optionally data, retrying while back pressured
+func (writer *SnapshotWriter) offer(header *atomic.Buffer, buffer *atomic.Buffer, offset int32, length int32) int64 {
+	start := time.Now()
+	var ret int64
+	for time.Since(start) < writer.Timeout {
+		if buffer == nil {
+			ret = writer.publication.Offer(header, 0, header.Capacity(), nil)
+		} else {
+			ret = writer.publication.Offer2(header, 0, header.Capacity(), buffer, offset, length, nil)
+		}
+		switch ret {
+		// Retry on these
+		case aeron.NotConnected, aeron.BackPressured, aeron.AdminAction:
+			writer.idleStrategy.Idle(0)
+		// Fail or succeed on other values
+		default:
+			return ret
+		}
+	}
+	// Give up, returning the last failure
+	return ret
+}
+
+// SnapshotReader reads the records written by a SnapshotWriter from the
+// image passed to ClusteredService.OnStart(). It checks for the BEGIN
+// and END markers and reassembles chunked records.
+//
+//	reader := cluster.NewSnapshotReader(c, image, typeId)
+//	for {
+//		record, err := reader.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		...
+//	}
+type SnapshotReader struct {
+	Timeout      time.Duration // How long to wait for the next fragment before failing
+	cluster      Cluster
+	image        aeron.Image
+	idleStrategy idlestrategy.Idler
+	marshaller   *codecs.SbeGoMarshaller
+	assembler    *aeron.FragmentAssembler
+	typeId       int64
+	version      int32
+	inSnapshot   bool
+	isDone       bool
+	pending      *SnapshotRecord
+	record       *SnapshotRecord
+	err          error
+}
+
+// NewSnapshotReader creates and returns a SnapshotReader for a snapshot of typeId
+func NewSnapshotReader(cluster Cluster, image aeron.Image, typeId int64) *SnapshotReader {
+	reader := &SnapshotReader{
+		Timeout:      defaultSnapshotTimeout,
+		cluster:      cluster,
+		image:        image,
+		idleStrategy: cluster.IdleStrategy(),
+		marshaller:   codecs.NewSbeGoMarshaller(),
+		typeId:       typeId,
+	}
+	reader.assembler = aeron.NewFragmentAssembler(reader.onMessage, aeron.DefaultFragmentAssemblyBufferLength)
+	return reader
+}
+
+// Version given to NewSnapshotWriter(), available once Next() has been called
+func (reader *SnapshotReader) Version() int32 {
+	return reader.version
+}
+
+// Next returns the next record of the snapshot, polling the image
+// until one is available.
+//
+// Returns io.EOF once the END marker has been read, otherwise an error
+// if the snapshot is malformed or incomplete, or on timeout
+func (reader *SnapshotReader) Next() (*SnapshotRecord, error) {
+	start := time.Now()
+	for reader.record == nil && reader.err == nil && !reader.isDone {
+		if reader.image.Poll(reader.assembler.OnFragment, 1) > 0 {
+			start = time.Now()
+			continue
+		}
+		if reader.image.IsClosed() || reader.image.IsEndOfStream() {
+			reader.err = ErrSnapshotIncomplete
+		} else if time.Since(start) > reader.Timeout {
+			return nil, fmt.Errorf("timed out reading snapshot at position %d", reader.image.Position())
+		} else {
+			reader.idleStrategy.Idle(0)
+		}
+	}
+	if reader.err != nil {
+		return nil, reader.err
+	}
+	if record := reader.record; record != nil {
+		reader.record = nil
+		return record, nil
+	}
+	return nil, io.EOF
+}
+
+func (reader *SnapshotReader) onMessage(buffer *atomic.Buffer, offset int32, length int32, header *logbuffer.Header) {
+	if length < SBEHeaderLength {
+		reader.err = fmt.Errorf("invalid snapshot message length %d at pos=%d", length, header.Position())
+		return
+	}
+	blockLength := buffer.GetUInt16(offset)
+	templateId := buffer.GetUInt16(offset + 2)
+	schemaId := buffer.GetUInt16(offset + 4)
+	version := buffer.GetUInt16(offset + 6)
+
+	switch {
+	case schemaId == ClusterSchemaId && templateId == snapshotMarkerTemplateId:
+		buf := &bytes.Buffer{}
+		buffer.WriteBytes(buf, offset+SBEHeaderLength, length-SBEHeaderLength)
+		marker := &codecs.SnapshotMarker{}
+		if err := marker.Decode(reader.marshaller, buf, version, blockLength, true); err != nil {
+			reader.err = err
+		} else {
+			reader.onMarker(marker, header)
+		}
+	case schemaId == snapshotRecordSchemaId && templateId == snapshotRecordTemplateId:
+		if blockLength < snapshotRecordMinBlockLength || length < SBEHeaderLength+int32(blockLength) {
+			reader.err = fmt.Errorf("invalid snapshot record length %d blockLen=%d at pos=%d",
+				length, blockLength, header.Position())
+		} else if !reader.inSnapshot {
+			reader.err = fmt.Errorf("snapshot record before BEGIN marker at pos=%d", header.Position())
+		} else {
+			reader.onRecord(buffer, offset, length, SBEHeaderLength+int32(blockLength), header)
+		}
+	default:
+		reader.err = fmt.Errorf("unexpected snapshot message schemaId=%d templateId=%d at pos=%d",
+			schemaId, templateId, header.Position())
+	}
+}
+
+func (reader *SnapshotReader) onMarker(marker *codecs.SnapshotMarker, header *logbuffer.Header) {
+	if marker.TypeId != reader.typeId {
+		reader.err = fmt.Errorf("unexpected snapshot type: %d", marker.TypeId)
+		return
+	}
+	switch marker.Mark {
+	case codecs.SnapshotMark.BEGIN:
+		if reader.inSnapshot {
+			reader.err = fmt.Errorf("already in snapshot, pos=%d", header.Position())
+			return
+		}
+		reader.inSnapshot = true
+		reader.version = marker.AppVersion
+	case codecs.SnapshotMark.END:
+		if !reader.inSnapshot {
+			reader.err = fmt.Errorf("missing begin snapshot, pos=%d", header.Position())
+		} else if reader.pending != nil {
+			reader.err = fmt.Errorf("incomplete snapshot record type %d, pos=%d", reader.pending.Type, header.Position())
+		} else {
+			reader.inSnapshot = false
+			reader.isDone = true
+		}
+	default:
+		reader.err = fmt.Errorf("unexpected snapshot mark, pos=%d inSnapshot=%v mark=%v",
+			header.Position(), reader.inSnapshot, marker.Mark)
+	}
+}
+
+func (reader *SnapshotReader) onRecord(
+	buffer *atomic.Buffer,
+	offset int32,
+	length int32,
+	dataOffset int32,
+	header *logbuffer.Header,
+) {
+	recordType := buffer.GetInt32(offset + recordTypeOffset)
+	totalLength := buffer.GetInt32(offset + totalLengthOffset)
+	chunkOffset := buffer.GetInt32(offset + chunkOffsetOffset)
+	chunkLength := length - dataOffset
+
+	if totalLength < 0 || chunkOffset < 0 {
+		reader.err = fmt.Errorf("invalid snapshot record type=%d length=%d offset=%d, pos=%d",
+			recordType, totalLength, chunkOffset, header.Position())
+		return
+	}
+	if chunkOffset == 0 {
+		if reader.pending != nil {
+			reader.err = fmt.Errorf("incomplete snapshot record type %d, pos=%d", reader.pending.Type, header.Position())
+			return
+		}
+		reader.pending = &SnapshotRecord{Type: recordType, Data: make([]byte, 0, totalLength)}
+	}
+	pending := reader.pending
+	if pending == nil || pending.Type != recordType || int32(len(pending.Data)) != chunkOffset ||
+		chunkOffset+chunkLength > totalLength {
+		reader.err = fmt.Errorf("unexpected snapshot record chunk type=%d offset=%d, pos=%d",
+			recordType, chunkOffset, header.Position())
+		return
+	}
+	pending.Data = append(pending.Data, buffer.GetBytesArray(offset+dataOffset, chunkLength)...)
+	if int32(len(pending.Data)) == totalLength {
+		reader.record = pending
+		reader.pending = nil
+	}
+}
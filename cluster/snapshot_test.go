@@ -0,0 +1,135 @@
+package cluster
+
+import (
+	"bytes"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lirm/aeron-go/aeron"
+	"github.com/lirm/aeron-go/aeron/atomic"
+	"github.com/lirm/aeron-go/aeron/logbuffer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testSnapshotTypeId = 42
+
+func newTestSnapshotStream(ta *testAgent) (*aeron.Publication, aeron.Image) {
+	return aeron.NewInMemoryStream(ta.opts.SnapshotChannel, ta.opts.SnapshotStreamId, 3, logbuffer.TermMinLength)
+}
+
+func TestSnapshotWriterAndReader(t *testing.T) {
+	ta := newTestAgent(t)
+	pub, image := newTestSnapshotStream(ta)
+	writer := NewSnapshotWriter(ta, pub, testSnapshotTypeId, 3)
+	writer.MaxChunkLength = 10
+
+	large := bytes.Repeat([]byte("0123456789abcdef"), 4)
+	require.NoError(t, writer.Begin())
+	require.NoError(t, writer.Write(1, []byte("small")))
+	require.NoError(t, writer.Write(2, large))
+	require.NoError(t, writer.Write(3, nil))
+	require.NoError(t, writer.End())
+
+	reader := NewSnapshotReader(ta, image, testSnapshotTypeId)
+	var records []SnapshotRecord
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		require.NoError(t, err)
+		records = append(records, *record)
+	}
+	assert.EqualValues(t, 3, reader.Version())
+	require.Len(t, records, 3)
+	assert.Equal(t, SnapshotRecord{Type: 1, Data: []byte("small")}, records[0])
+	assert.Equal(t, SnapshotRecord{Type: 2, Data: large}, records[1])
+	assert.EqualValues(t, 3, records[2].Type)
+	assert.Empty(t, records[2].Data)
+}
+
+func TestSnapshotWriter_RecordLayout(t *testing.T) {
+	ta := newTestAgent(t)
+	pub, image := newTestSnapshotStream(ta)
+	writer := NewSnapshotWriter(ta, pub, testSnapshotTypeId, 3)
+	require.NoError(t, writer.Begin())
+	require.NoError(t, writer.Write(7, []byte("data")))
+
+	var messages [][]byte
+	image.Poll(func(buffer *atomic.Buffer, offset, length int32, header *logbuffer.Header) {
+		messages = append(messages, buffer.GetBytesArray(offset, length))
+	}, 10)
+	require.Len(t, messages, 2)
+	record := atomic.NewBufferSlice(messages[1])
+	require.EqualValues(t, snapshotRecordHeaderLength+4, record.Capacity())
+	assert.EqualValues(t, snapshotRecordBlockLength, record.GetUInt16(0))
+	assert.EqualValues(t, snapshotRecordTemplateId, record.GetUInt16(2))
+	assert.EqualValues(t, snapshotRecordSchemaId, record.GetUInt16(4))
+	assert.EqualValues(t, 7, record.GetInt32(recordTypeOffset))
+	assert.EqualValues(t, 4, record.GetInt32(totalLengthOffset))
+	assert.EqualValues(t, 0, record.GetInt32(chunkOffsetOffset))
+	assert.EqualValues(t, 0, record.GetInt32(recordPaddingOffset), "padding is zeroed")
+	assert.Equal(t, "data", string(messages[1][snapshotRecordHeaderLength:]))
+}
+
+func TestSnapshotReader_BlockLength(t *testing.T) {
+	ta := newTestAgent(t)
+	pub, image := newTestSnapshotStream(ta)
+	writer := NewSnapshotWriter(ta, pub, testSnapshotTypeId, 3)
+	require.NoError(t, writer.Begin())
+
+	// A record without the padding, as a writer with a shorter block would send
+	record := atomic.NewBufferSlice(make([]byte, SBEHeaderLength+snapshotRecordMinBlockLength+4))
+	record.PutUInt16(0, snapshotRecordMinBlockLength)
+	record.PutUInt16(2, snapshotRecordTemplateId)
+	record.PutUInt16(4, snapshotRecordSchemaId)
+	record.PutUInt16(6, snapshotRecordSchemaVersion)
+	record.PutInt32(recordTypeOffset, 7)
+	record.PutInt32(totalLengthOffset, 4)
+	record.PutInt32(chunkOffsetOffset, 0)
+	record.PutBytesArray(SBEHeaderLength+snapshotRecordMinBlockLength, &[]byte{'d', 'a', 't', 'a'}, 0, 4)
+	require.Positive(t, pub.Offer(record, 0, record.Capacity(), nil))
+	require.NoError(t, writer.End())
+
+	reader := NewSnapshotReader(ta, image, testSnapshotTypeId)
+	read, err := reader.Next()
+	require.NoError(t, err)
+	assert.Equal(t, SnapshotRecord{Type: 7, Data: []byte("data")}, *read)
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestSnapshotReader_InvalidRecord(t *testing.T) {
+	ta := newTestAgent(t)
+	pub, image := newTestSnapshotStream(ta)
+	writer := NewSnapshotWriter(ta, pub, testSnapshotTypeId, 3)
+	require.NoError(t, writer.Begin())
+
+	record := atomic.NewBufferSlice(make([]byte, snapshotRecordHeaderLength))
+	record.PutUInt16(0, snapshotRecordBlockLength)
+	record.PutUInt16(2, snapshotRecordTemplateId)
+	record.PutUInt16(4, snapshotRecordSchemaId)
+	record.PutInt32(totalLengthOffset, -1)
+	require.Positive(t, pub.Offer(record, 0, record.Capacity(), nil))
+
+	reader := NewSnapshotReader(ta, image, testSnapshotTypeId)
+	_, err := reader.Next()
+	assert.ErrorContains(t, err, "invalid snapshot record")
+}
+
+func TestSnapshotReader_Timeout(t *testing.T) {
+	ta := newTestAgent(t)
+	pub, image := newTestSnapshotStream(ta)
+	writer := NewSnapshotWriter(ta, pub, testSnapshotTypeId, 3)
+	require.NoError(t, writer.Begin())
+	require.NoError(t, writer.Write(1, []byte("small")))
+
+	reader := NewSnapshotReader(ta, image, testSnapshotTypeId)
+	reader.Timeout = 10 * time.Millisecond
+	_, err := reader.Next()
+	require.NoError(t, err)
+	_, err = reader.Next()
+	assert.ErrorContains(t, err, "timed out reading snapshot")
+}